@@ -53,3 +53,33 @@ func TestGetSortedValuesStrings(t *testing.T) {
 		}
 	}
 }
+
+func TestDOTOptions(t *testing.T) {
+	options := DOTOptions{}
+	if actualValue, expectedValue := options.GraphName(), "G"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue, expectedValue := options.Label("k", 1), `"k"`; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue := options.Truncated(100); actualValue != false {
+		t.Errorf("Got %v expected %v", actualValue, false)
+	}
+
+	options = DOTOptions{Name: "my tree", Values: true, MaxDepth: 2}
+	if actualValue, expectedValue := options.GraphName(), `"my tree"`; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue, expectedValue := options.Label(`say "hi"`, 1.5), `"say \"hi\"\n1.5"`; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue, expectedValue := DOTQuote(`a\b`), `"a\\b"`; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue := options.Truncated(1); actualValue != false {
+		t.Errorf("Got %v expected %v", actualValue, false)
+	}
+	if actualValue := options.Truncated(2); actualValue != true {
+		t.Errorf("Got %v expected %v", actualValue, true)
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package containers
+
+import (
+	"github.com/dairongpeng/gds/utils"
+	"io"
+	"strings"
+)
+
+// DOTOptions configures the Graphviz DOT output of a container
+type DOTOptions struct {
+	Name     string // name of the graph, G if empty
+	Values   bool   // show the values below the keys, for containers of key-value pairs
+	MaxDepth int    // number of levels of a tree, or elements of a list, to output, all if zero; the rest is drawn as "..."
+}
+
+// DOTWriter provides Graphviz DOT output
+// DOTWriter 以Graphviz DOT格式输出容器的结构，便于调试时渲染
+type DOTWriter interface {
+	// WriteDOT writes the structure of the container as a Graphviz DOT digraph.
+	WriteDOT(w io.Writer, options DOTOptions) error
+}
+
+var dotEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// DOTQuote returns the value converted to string as a quoted DOT string.
+func DOTQuote(value interface{}) string {
+	return `"` + dotEscaper.Replace(utils.ToString(value)) + `"`
+}
+
+// GraphName returns the quoted name of the graph.
+func (options DOTOptions) GraphName() string {
+	if options.Name == "" {
+		return "G"
+	}
+	return DOTQuote(options.Name)
+}
+
+// Label returns the quoted label of a key, with the value on a second line if the options ask for values.
+func (options DOTOptions) Label(key interface{}, value interface{}) string {
+	if !options.Values {
+		return DOTQuote(key)
+	}
+	return DOTQuote(utils.ToString(key) + "\n" + utils.ToString(value))
+}
+
+// Truncated returns true if elements at the depth, counted from zero, are beyond MaxDepth.
+func (options DOTOptions) Truncated(depth int) bool {
+	return options.MaxDepth > 0 && depth >= options.MaxDepth
+}
@@ -4,6 +4,19 @@
 
 package containers
 
+import "errors"
+
+// ErrConcurrentModification is the value iterators panic with when they are moved after their container was
+// structurally modified, that is elements were added or removed, other than through the iterator itself.
+// Begin() and End() resynchronize an iterator with its container, an iterator in its initial state (one-before-first)
+// does not depend on the elements and follows the modifications.
+// ErrConcurrentModification 迭代期间容器被迭代器以外的操作修改时，迭代器以此值panic
+var ErrConcurrentModification = errors.New("containers: container was modified during iteration")
+
+// ErrIteratorNotOnElement is the value iterators panic with when Remove() is called while they are not on an element,
+// i.e. before the first call to Next(), after the end was reached or right after a previous Remove().
+var ErrIteratorNotOnElement = errors.New("containers: iterator is not on an element")
+
 // IteratorWithIndex is stateful iterator for ordered containers whose values can be fetched by an index.
 // IteratorWithIndex 基于下标的迭代器接口
 type IteratorWithIndex interface {
@@ -109,3 +122,16 @@ type ReverseIteratorWithKey interface {
 
 	IteratorWithKey
 }
+
+// IteratorWithRemove is a stateful iterator that can remove the current element from its container.
+//
+// Containers whose iterators are fail-fast count their structural modifications, an iterator panics with
+// ErrConcurrentModification when it is moved after the container was modified other than through the iterator.
+// Removing through the iterator keeps it valid.
+type IteratorWithRemove interface {
+	// Remove removes the current element from the container.
+	// Afterwards the iterator is between the neighbours of the removed element, Next() moves it to the element
+	// after the removed one and Prev() to the element before it. The current element is undefined until then.
+	// Panics with ErrIteratorNotOnElement if the iterator is not on an element.
+	Remove()
+}
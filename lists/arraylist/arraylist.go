@@ -27,6 +27,7 @@ func assertListImplementation() {
 type List struct {
 	elements []interface{}
 	size     int
+	modCount int // number of structural modifications, checked by iterators
 }
 
 const (
@@ -49,6 +50,7 @@ func New(values ...interface{}) *List {
 func (list *List) Add(values ...interface{}) {
 	// 判断是否触发了扩容条件。触发则进行切片2倍扩容
 	list.growBy(len(values))
+	list.modCount++
 	for _, value := range values {
 		list.elements[list.size] = value
 		list.size++
@@ -84,6 +86,7 @@ func (list *List) Remove(index int) {
 	// list.elements[index+1:list.size]范围填充list.elements[index:]范围，从而填补index位置被移除的位置
 	copy(list.elements[index:], list.elements[index+1:list.size]) // shift to the left by one (slow operation, need ways to optimize this)
 	list.size--
+	list.modCount++
 
 	// 根据缩容因子，判断是否要进行缩容，这里缩容因子默认为0.25
 	list.shrink()
@@ -152,6 +155,7 @@ func (list *List) Size() int {
 func (list *List) Clear() {
 	list.size = 0
 	list.elements = []interface{}{}
+	list.modCount++
 }
 
 // Sort sorts values (in-place) using.
@@ -193,6 +197,7 @@ func (list *List) Insert(index int, values ...interface{}) {
 	list.growBy(l)
 	// 扩大size
 	list.size += l
+	list.modCount++
 	copy(list.elements[index+l:], list.elements[index:list.size-l])
 	copy(list.elements[index:], values)
 }
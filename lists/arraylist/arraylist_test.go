@@ -6,6 +6,7 @@ package arraylist
 
 import (
 	"fmt"
+	"github.com/dairongpeng/gds/containers"
 	"github.com/dairongpeng/gds/utils"
 	"testing"
 )
@@ -500,6 +501,81 @@ func TestListIteratorLast(t *testing.T) {
 	}
 }
 
+func TestListIteratorRemove(t *testing.T) {
+	list := New("a", "b", "c", "d", "e")
+	it := list.Iterator()
+	for it.Next() {
+		if value := it.Value(); value == "b" || value == "c" || value == "e" {
+			it.Remove()
+		}
+	}
+	if actualValue, expectedValue := fmt.Sprint(list.Values()), "[a d]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+
+	list = New("a", "b", "c")
+	it = list.Iterator()
+	it.Next()
+	it.Next()
+	it.Remove()
+	if !it.Prev() || it.Value() != "a" {
+		t.Errorf("Got %v expected %v", it.Value(), "a")
+	}
+	it.Remove()
+	if !it.Next() || it.Value() != "c" || it.Index() != 0 {
+		t.Errorf("Got %v,%v expected %v,%v", it.Index(), it.Value(), 0, "c")
+	}
+	if it.Next() {
+		t.Errorf("Shouldn't iterate past the end")
+	}
+	if actualValue, expectedValue := fmt.Sprint(list.Values()), "[c]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+}
+
+// expectPanic checks that f panics with the expected error.
+func expectPanic(t *testing.T, expected error, f func()) {
+	t.Helper()
+	defer func() {
+		if r := recover(); r != expected {
+			t.Errorf("Got %v expected %v", r, expected)
+		}
+	}()
+	f()
+}
+
+func TestListIteratorConcurrentModification(t *testing.T) {
+	list := New("a", "b", "c")
+	it := list.Iterator()
+	it.Next()
+	list.Add("d")
+	expectPanic(t, containers.ErrConcurrentModification, func() { it.Next() })
+	expectPanic(t, containers.ErrConcurrentModification, func() { it.Prev() })
+	expectPanic(t, containers.ErrConcurrentModification, func() { it.Remove() })
+
+	// setting values is not a structural modification
+	it.Begin()
+	it.Next()
+	list.Set(0, "z")
+	list.Swap(1, 2)
+	if !it.Next() || it.Value() != "c" {
+		t.Errorf("Got %v expected %v", it.Value(), "c")
+	}
+
+	// resetting resynchronizes the iterator
+	list.Remove(0)
+	it.End()
+	if !it.Prev() || it.Value() != "d" {
+		t.Errorf("Got %v expected %v", it.Value(), "d")
+	}
+
+	it.Begin()
+	expectPanic(t, containers.ErrIteratorNotOnElement, func() { it.Remove() })
+	it.Next()
+	it.Remove()
+	expectPanic(t, containers.ErrIteratorNotOnElement, func() { it.Remove() })
+}
+
 func TestListSerialization(t *testing.T) {
 	list := New()
 	list.Add("a", "b", "c")
@@ -8,19 +8,22 @@ import "github.com/dairongpeng/gds/containers"
 
 func assertIteratorImplementation() {
 	var _ containers.ReverseIteratorWithIndex = (*Iterator)(nil)
+	var _ containers.IteratorWithRemove = (*Iterator)(nil)
 }
 
 // Iterator holding the iterator's state
 // Iterator 是List结构的一个迭代器，保存List迭代器的状态
 type Iterator struct {
-	list  *List
-	index int
+	list     *List
+	index    int
+	modCount int
+	removed  bool // the element at index was removed, index is where the next element moved to
 }
 
 // Iterator returns a stateful iterator whose values can be fetched by an index.
 // Iterator 初始化一个List结构的迭代器返回给调用者
 func (list *List) Iterator() Iterator {
-	return Iterator{list: list, index: -1}
+	return Iterator{list: list, index: -1, modCount: list.modCount}
 }
 
 // Next moves the iterator to the next element and returns true if there was a next element in the container.
@@ -29,6 +32,11 @@ func (list *List) Iterator() Iterator {
 // Modifies the state of the iterator.
 // Next 如果下一个元素存在，且在列表list的合理下标范围内，迭代器移动到下一个元素位置并且返回true
 func (iterator *Iterator) Next() bool {
+	iterator.checkModification()
+	if iterator.removed {
+		iterator.removed = false
+		return iterator.list.withinRange(iterator.index)
+	}
 	if iterator.index < iterator.list.size {
 		iterator.index++
 	}
@@ -40,6 +48,8 @@ func (iterator *Iterator) Next() bool {
 // Modifies the state of the iterator.
 // Prev 如果上一个元素存在，且在列表list的合理下标范围内，迭代器移动到上一个元素位置并返回为true
 func (iterator *Iterator) Prev() bool {
+	iterator.checkModification()
+	iterator.removed = false
 	if iterator.index >= 0 {
 		iterator.index--
 	}
@@ -65,6 +75,8 @@ func (iterator *Iterator) Index() int {
 // Begin 重置列表的迭代器
 func (iterator *Iterator) Begin() {
 	iterator.index = -1
+	iterator.removed = false
+	iterator.modCount = iterator.list.modCount
 }
 
 // End moves the iterator past the last element (one-past-the-end).
@@ -72,6 +84,8 @@ func (iterator *Iterator) Begin() {
 // End 移动列表List迭代器到最后位置，也就是列表的最后一个元素位置的下一个位置
 func (iterator *Iterator) End() {
 	iterator.index = iterator.list.size
+	iterator.removed = false
+	iterator.modCount = iterator.list.modCount
 }
 
 // First moves the iterator to the first element and returns true if there was a first element in the container.
@@ -91,3 +105,27 @@ func (iterator *Iterator) Last() bool {
 	iterator.End()
 	return iterator.Prev()
 }
+
+// Remove removes the current element from the list, the following elements shift one position to the left.
+// Next() moves the iterator to the element after the removed one and Prev() to the element before it.
+// Panics with containers.ErrIteratorNotOnElement if the iterator is not on an element.
+// Remove 移除迭代器当前所处位置的元素，迭代器仍然有效
+func (iterator *Iterator) Remove() {
+	iterator.checkModification()
+	if iterator.removed || !iterator.list.withinRange(iterator.index) {
+		panic(containers.ErrIteratorNotOnElement)
+	}
+	iterator.list.Remove(iterator.index)
+	iterator.modCount = iterator.list.modCount
+	iterator.removed = true
+}
+
+// checkModification panics if the list was structurally modified other than through the iterator.
+func (iterator *Iterator) checkModification() {
+	if iterator.modCount != iterator.list.modCount {
+		if iterator.index != -1 {
+			panic(containers.ErrConcurrentModification)
+		}
+		iterator.modCount = iterator.list.modCount
+	}
+}
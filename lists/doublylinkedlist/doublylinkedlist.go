@@ -23,9 +23,11 @@ func assertListImplementation() {
 
 // List holds the elements, where each element points to the next and previous element
 type List struct {
-	first *element
-	last  *element
-	size  int
+	first    *element
+	last     *element
+	size     int
+	modCount int // number of structural modifications, checked by iterators
+	pool     *pool
 }
 
 type element struct {
@@ -43,10 +45,22 @@ func New(values ...interface{}) *List {
 	return list
 }
 
+// NewWithPool instantiates a new list that allocates its elements slabSize at a time and reuses removed elements
+// for later insertions, and adds the passed values, if any, to the list. A slabSize of 1 only reuses removed elements.
+// A slab is kept in memory as long as any of its elements is in use.
+func NewWithPool(slabSize int, values ...interface{}) *List {
+	list := &List{pool: newPool(slabSize)}
+	if len(values) > 0 {
+		list.Add(values...)
+	}
+	return list
+}
+
 // Add appends a value (one or more) at the end of the list (same as Append())
 func (list *List) Add(values ...interface{}) {
 	for _, value := range values {
-		newElement := &element{value: value, prev: list.last}
+		newElement := list.newElement(value)
+		newElement.prev = list.last
 		if list.size == 0 {
 			list.first = newElement
 			list.last = newElement
@@ -55,6 +69,7 @@ func (list *List) Add(values ...interface{}) {
 			list.last = newElement
 		}
 		list.size++
+		list.modCount++
 	}
 }
 
@@ -67,7 +82,8 @@ func (list *List) Append(values ...interface{}) {
 func (list *List) Prepend(values ...interface{}) {
 	// in reverse to keep passed order i.e. ["c","d"] -> Prepend(["a","b"]) -> ["a","b","c",d"]
 	for v := len(values) - 1; v >= 0; v-- {
-		newElement := &element{value: values[v], next: list.first}
+		newElement := list.newElement(values[v])
+		newElement.next = list.first
 		if list.size == 0 {
 			list.first = newElement
 			list.last = newElement
@@ -76,6 +92,7 @@ func (list *List) Prepend(values ...interface{}) {
 			list.first = newElement
 		}
 		list.size++
+		list.modCount++
 	}
 }
 
@@ -107,11 +124,6 @@ func (list *List) Remove(index int) {
 		return
 	}
 
-	if list.size == 1 {
-		list.Clear()
-		return
-	}
-
 	var element *element
 	// determine traversal direction, last to first or first to last
 	if list.size-index < index {
@@ -124,6 +136,12 @@ func (list *List) Remove(index int) {
 		}
 	}
 
+	list.unlink(element)
+	list.freeElement(element)
+}
+
+// unlink removes the element from the list, the element keeps its links to its former neighbours.
+func (list *List) unlink(element *element) {
 	if element == list.first {
 		list.first = element.next
 	}
@@ -136,10 +154,8 @@ func (list *List) Remove(index int) {
 	if element.next != nil {
 		element.next.prev = element.prev
 	}
-
-	element = nil
-
 	list.size--
+	list.modCount++
 }
 
 // Contains check if values (one or more) are present in the set.
@@ -206,6 +222,7 @@ func (list *List) Clear() {
 	list.size = 0
 	list.first = nil
 	list.last = nil
+	list.modCount++
 }
 
 // Sort sorts values (in-place) using.
@@ -254,6 +271,7 @@ func (list *List) Insert(index int, values ...interface{}) {
 	}
 
 	list.size += len(values)
+	list.modCount++
 
 	var beforeElement *element
 	var foundElement *element
@@ -273,7 +291,7 @@ func (list *List) Insert(index int, values ...interface{}) {
 	if foundElement == list.first {
 		oldNextElement := list.first
 		for i, value := range values {
-			newElement := &element{value: value}
+			newElement := list.newElement(value)
 			if i == 0 {
 				list.first = newElement
 			} else {
@@ -287,7 +305,7 @@ func (list *List) Insert(index int, values ...interface{}) {
 	} else {
 		oldNextElement := beforeElement.next
 		for _, value := range values {
-			newElement := &element{value: value}
+			newElement := list.newElement(value)
 			newElement.prev = beforeElement
 			beforeElement.next = newElement
 			beforeElement = newElement
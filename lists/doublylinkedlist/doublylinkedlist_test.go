@@ -5,9 +5,11 @@
 package doublylinkedlist
 
 import (
+	"bytes"
 	"fmt"
 	"testing"
 
+	"github.com/dairongpeng/gds/containers"
 	"github.com/dairongpeng/gds/utils"
 )
 
@@ -506,6 +508,79 @@ func TestListIteratorLast(t *testing.T) {
 	}
 }
 
+func TestListIteratorRemove(t *testing.T) {
+	list := New("a", "b", "c", "d", "e")
+	it := list.Iterator()
+	for it.Next() {
+		if value := it.Value(); value == "a" || value == "c" || value == "e" {
+			it.Remove()
+		}
+	}
+	if actualValue, expectedValue := fmt.Sprint(list.Values()), "[b d]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+
+	list = New("a", "b", "c")
+	it = list.Iterator()
+	it.Last()
+	it.Remove()
+	if !it.Prev() || it.Index() != 1 || it.Value() != "b" {
+		t.Errorf("Got %v,%v expected %v,%v", it.Index(), it.Value(), 1, "b")
+	}
+	it.Remove()
+	if !it.Prev() || it.Index() != 0 || it.Value() != "a" {
+		t.Errorf("Got %v,%v expected %v,%v", it.Index(), it.Value(), 0, "a")
+	}
+	if it.Next() {
+		t.Errorf("Shouldn't iterate past the end")
+	}
+	list.Add("d")
+	it.End()
+	if !it.Prev() || it.Index() != 1 || it.Value() != "d" {
+		t.Errorf("Got %v,%v expected %v,%v", it.Index(), it.Value(), 1, "d")
+	}
+	it.Remove()
+	if it.Next() {
+		t.Errorf("Shouldn't iterate past the end")
+	}
+	if actualValue, expectedValue := fmt.Sprint(list.Values()), "[a]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+}
+
+// expectPanic checks that f panics with the expected error.
+func expectPanic(t *testing.T, expected error, f func()) {
+	t.Helper()
+	defer func() {
+		if r := recover(); r != expected {
+			t.Errorf("Got %v expected %v", r, expected)
+		}
+	}()
+	f()
+}
+
+func TestListIteratorConcurrentModification(t *testing.T) {
+	list := New("a", "b", "c")
+	it := list.Iterator()
+	it.Next()
+	list.Insert(1, "x")
+	expectPanic(t, containers.ErrConcurrentModification, func() { it.Next() })
+	expectPanic(t, containers.ErrConcurrentModification, func() { it.Prev() })
+	expectPanic(t, containers.ErrConcurrentModification, func() { it.Remove() })
+
+	it.End()
+	list.Swap(0, 3)
+	if !it.Prev() || it.Value() != "a" {
+		t.Errorf("Got %v expected %v", it.Value(), "a")
+	}
+
+	it.Begin()
+	expectPanic(t, containers.ErrIteratorNotOnElement, func() { it.Remove() })
+	it.Next()
+	it.Remove()
+	expectPanic(t, containers.ErrIteratorNotOnElement, func() { it.Remove() })
+}
+
 func TestListSerialization(t *testing.T) {
 	list := New()
 	list.Add("a", "b", "c")
@@ -532,6 +607,58 @@ func TestListSerialization(t *testing.T) {
 	assert()
 }
 
+func TestListPool(t *testing.T) {
+	list := NewWithPool(4, "a", "b", "c")
+	list.Remove(1)
+	list.Add("d")
+	if actualValue, expectedValue := fmt.Sprint(list.Values()), "[a c d]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	it := list.Iterator()
+	for it.Next() {
+		if it.Value() == "c" {
+			it.Remove()
+		}
+	}
+	list.Insert(1, "e")
+	list.Prepend("f")
+	if actualValue, expectedValue := fmt.Sprint(list.Values()), "[f a e d]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+
+	// the iterator keeps a copy of the removed element, whose element is reused by the list
+	it = list.Iterator()
+	it.Next()
+	it.Next()
+	it.Remove()
+	if actualValue, expectedValue := it.Value(), "a"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if !it.Prev() || it.Value() != "f" {
+		t.Errorf("Got %v expected %v", it.Value(), "f")
+	}
+	if !it.Next() || it.Value() != "e" {
+		t.Errorf("Got %v expected %v", it.Value(), "e")
+	}
+	list.Add("a")
+	if actualValue, expectedValue := fmt.Sprint(list.Values()), "[f e d a]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	list.Remove(3)
+	// removed elements are reused
+	allocs := testing.AllocsPerRun(10, func() {
+		for n := 0; n < 100; n++ {
+			list.Add(n)
+		}
+		for n := 0; n < 100; n++ {
+			list.Remove(list.Size() - 1)
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("Got %v expected %v", allocs, 0)
+	}
+}
+
 func benchmarkGet(b *testing.B, list *List, size int) {
 	for i := 0; i < b.N; i++ {
 		for n := 0; n < size; n++ {
@@ -556,6 +683,55 @@ func benchmarkRemove(b *testing.B, list *List, size int) {
 	}
 }
 
+func TestListWriteDOT(t *testing.T) {
+	list := New("a", "b", "c")
+	var buffer bytes.Buffer
+	if err := list.WriteDOT(&buffer, containers.DOTOptions{}); err != nil {
+		t.Errorf("Got %v expected %v", err, nil)
+	}
+	expectedValue := `digraph G {
+	rankdir=LR;
+	node [shape=box];
+	n0 [label="a"];
+	n0 -> n1 [dir=both];
+	n1 [label="b"];
+	n1 -> n2 [dir=both];
+	n2 [label="c"];
+}
+`
+	if actualValue := buffer.String(); actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+
+	buffer.Reset()
+	list.WriteDOT(&buffer, containers.DOTOptions{MaxDepth: 1})
+	expectedValue = `digraph G {
+	rankdir=LR;
+	node [shape=box];
+	n0 [label="a"];
+	n0 -> n1 [dir=both];
+	n1 [label="...", shape=plaintext];
+}
+`
+	if actualValue := buffer.String(); actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+}
+
+// benchmarkChurn adds all values to the back of the list and removes them from the front in every round.
+// It compares the allocations of plain lists with pooled lists, which reuse the removed elements.
+func benchmarkChurn(b *testing.B, list *List, size int) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		for n := 0; n < size; n++ {
+			list.Add(struct{}{})
+		}
+		for n := 0; n < size; n++ {
+			list.Remove(0)
+		}
+	}
+}
+
 func BenchmarkDoublyLinkedListGet100(b *testing.B) {
 	b.StopTimer()
 	size := 100
@@ -684,3 +860,19 @@ func BenchmarkDoublyLinkedListRemove100000(b *testing.B) {
 	b.StartTimer()
 	benchmarkRemove(b, list, size)
 }
+
+func BenchmarkDoublyLinkedListChurn1000(b *testing.B) {
+	benchmarkChurn(b, New(), 1000)
+}
+
+func BenchmarkDoublyLinkedListChurn10000(b *testing.B) {
+	benchmarkChurn(b, New(), 10000)
+}
+
+func BenchmarkDoublyLinkedListPooledChurn1000(b *testing.B) {
+	benchmarkChurn(b, NewWithPool(256), 1000)
+}
+
+func BenchmarkDoublyLinkedListPooledChurn10000(b *testing.B) {
+	benchmarkChurn(b, NewWithPool(256), 10000)
+}
@@ -8,18 +8,22 @@ import "github.com/dairongpeng/gds/containers"
 
 func assertIteratorImplementation() {
 	var _ containers.ReverseIteratorWithIndex = (*Iterator)(nil)
+	var _ containers.IteratorWithRemove = (*Iterator)(nil)
 }
 
 // Iterator holding the iterator's state
 type Iterator struct {
-	list    *List
-	index   int
-	element *element
+	list     *List
+	index    int
+	element  *element
+	modCount int
+	removed  bool    // element was removed, it points to gap whose links still lead to its former neighbours
+	gap      element // copy of the removed element, the list may reuse the element itself
 }
 
 // Iterator returns a stateful iterator whose values can be fetched by an index.
 func (list *List) Iterator() Iterator {
-	return Iterator{list: list, index: -1, element: nil}
+	return Iterator{list: list, index: -1, element: nil, modCount: list.modCount}
 }
 
 // Next moves the iterator to the next element and returns true if there was a next element in the container.
@@ -27,6 +31,12 @@ func (list *List) Iterator() Iterator {
 // If Next() was called for the first time, then it will point the iterator to the first element if it exists.
 // Modifies the state of the iterator.
 func (iterator *Iterator) Next() bool {
+	iterator.checkModification()
+	if iterator.removed {
+		iterator.removed = false
+		iterator.element = iterator.element.next
+		return iterator.element != nil
+	}
 	if iterator.index < iterator.list.size {
 		iterator.index++
 	}
@@ -46,6 +56,13 @@ func (iterator *Iterator) Next() bool {
 // If Prev() returns true, then previous element's index and value can be retrieved by Index() and Value().
 // Modifies the state of the iterator.
 func (iterator *Iterator) Prev() bool {
+	iterator.checkModification()
+	if iterator.removed {
+		iterator.removed = false
+		iterator.index--
+		iterator.element = iterator.element.prev
+		return iterator.element != nil
+	}
 	if iterator.index >= 0 {
 		iterator.index--
 	}
@@ -78,6 +95,8 @@ func (iterator *Iterator) Index() int {
 func (iterator *Iterator) Begin() {
 	iterator.index = -1
 	iterator.element = nil
+	iterator.removed = false
+	iterator.modCount = iterator.list.modCount
 }
 
 // End moves the iterator past the last element (one-past-the-end).
@@ -85,6 +104,8 @@ func (iterator *Iterator) Begin() {
 func (iterator *Iterator) End() {
 	iterator.index = iterator.list.size
 	iterator.element = iterator.list.last
+	iterator.removed = false
+	iterator.modCount = iterator.list.modCount
 }
 
 // First moves the iterator to the first element and returns true if there was a first element in the container.
@@ -102,3 +123,30 @@ func (iterator *Iterator) Last() bool {
 	iterator.End()
 	return iterator.Prev()
 }
+
+// Remove removes the current element from the list, the following elements shift one position to the left.
+// Next() moves the iterator to the element after the removed one and Prev() to the element before it,
+// until then Value() returns the removed value.
+// Panics with containers.ErrIteratorNotOnElement if the iterator is not on an element.
+func (iterator *Iterator) Remove() {
+	iterator.checkModification()
+	if iterator.removed || !iterator.list.withinRange(iterator.index) {
+		panic(containers.ErrIteratorNotOnElement)
+	}
+	iterator.list.unlink(iterator.element)
+	iterator.gap = *iterator.element
+	iterator.list.freeElement(iterator.element)
+	iterator.element = &iterator.gap
+	iterator.modCount = iterator.list.modCount
+	iterator.removed = true
+}
+
+// checkModification panics if the list was structurally modified other than through the iterator.
+func (iterator *Iterator) checkModification() {
+	if iterator.modCount != iterator.list.modCount {
+		if iterator.index != -1 {
+			panic(containers.ErrConcurrentModification)
+		}
+		iterator.modCount = iterator.list.modCount
+	}
+}
@@ -0,0 +1,38 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package singlylinkedlist
+
+import (
+	"fmt"
+	"github.com/dairongpeng/gds/containers"
+	"io"
+	"strings"
+)
+
+func assertDOTImplementation() {
+	var _ containers.DOTWriter = (*List)(nil)
+}
+
+// WriteDOT writes the list as a Graphviz DOT digraph of its elements from left to right, linked to their next element.
+// Elements beyond the maximum depth of the options are drawn as "...". Lists hold no keys, so the Values option has no effect.
+func (list *List) WriteDOT(w io.Writer, options containers.DOTOptions) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph %s {\n", options.GraphName())
+	b.WriteString("\trankdir=LR;\n\tnode [shape=box];\n")
+	index := 0
+	for element := list.first; element != nil; element, index = element.next, index+1 {
+		if index > 0 {
+			fmt.Fprintf(&b, "\tn%d -> n%d;\n", index-1, index)
+		}
+		if options.Truncated(index) {
+			fmt.Fprintf(&b, "\tn%d [label=\"...\", shape=plaintext];\n", index)
+			break
+		}
+		fmt.Fprintf(&b, "\tn%d [label=%s];\n", index, containers.DOTQuote(element.value))
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
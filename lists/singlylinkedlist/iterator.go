@@ -8,18 +8,22 @@ import "github.com/dairongpeng/gds/containers"
 
 func assertIteratorImplementation() {
 	var _ containers.IteratorWithIndex = (*Iterator)(nil)
+	var _ containers.IteratorWithRemove = (*Iterator)(nil)
 }
 
 // Iterator holding the iterator's state
 type Iterator struct {
-	list    *List
-	index   int
-	element *element
+	list     *List
+	index    int
+	element  *element
+	before   *element // element before the current one, nil for the first element
+	modCount int
+	removed  bool // the current element was removed, element is the one before it
 }
 
 // Iterator returns a stateful iterator whose values can be fetched by an index.
 func (list *List) Iterator() Iterator {
-	return Iterator{list: list, index: -1, element: nil}
+	return Iterator{list: list, index: -1, element: nil, modCount: list.modCount}
 }
 
 // Next moves the iterator to the next element and returns true if there was a next element in the container.
@@ -27,16 +31,21 @@ func (list *List) Iterator() Iterator {
 // If Next() was called for the first time, then it will point the iterator to the first element if it exists.
 // Modifies the state of the iterator.
 func (iterator *Iterator) Next() bool {
+	iterator.checkModification()
+	iterator.removed = false
 	if iterator.index < iterator.list.size {
 		iterator.index++
 	}
 	if !iterator.list.withinRange(iterator.index) {
 		iterator.element = nil
+		iterator.before = nil
 		return false
 	}
 	if iterator.index == 0 {
+		iterator.before = nil
 		iterator.element = iterator.list.first
 	} else {
+		iterator.before = iterator.element
 		iterator.element = iterator.element.next
 	}
 	return true
@@ -59,6 +68,9 @@ func (iterator *Iterator) Index() int {
 func (iterator *Iterator) Begin() {
 	iterator.index = -1
 	iterator.element = nil
+	iterator.before = nil
+	iterator.removed = false
+	iterator.modCount = iterator.list.modCount
 }
 
 // First moves the iterator to the first element and returns true if there was a first element in the container.
@@ -68,3 +80,30 @@ func (iterator *Iterator) First() bool {
 	iterator.Begin()
 	return iterator.Next()
 }
+
+// Remove removes the current element from the list, the following elements shift one position to the left.
+// Next() moves the iterator to the element after the removed one.
+// Panics with containers.ErrIteratorNotOnElement if the iterator is not on an element.
+func (iterator *Iterator) Remove() {
+	iterator.checkModification()
+	if iterator.removed || iterator.element == nil {
+		panic(containers.ErrIteratorNotOnElement)
+	}
+	iterator.list.unlink(iterator.before, iterator.element)
+	iterator.list.freeElement(iterator.element)
+	iterator.element = iterator.before
+	iterator.before = nil
+	iterator.index--
+	iterator.modCount = iterator.list.modCount
+	iterator.removed = true
+}
+
+// checkModification panics if the list was structurally modified other than through the iterator.
+func (iterator *Iterator) checkModification() {
+	if iterator.modCount != iterator.list.modCount {
+		if iterator.index != -1 {
+			panic(containers.ErrConcurrentModification)
+		}
+		iterator.modCount = iterator.list.modCount
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package singlylinkedlist
+
+// pool hands out the elements of a list from slabs and keeps removed elements in a free list for reuse.
+type pool struct {
+	slab     []element
+	free     *element // removed elements, linked through their next field
+	slabSize int
+}
+
+func newPool(slabSize int) *pool {
+	if slabSize < 1 {
+		slabSize = 1
+	}
+	return &pool{slabSize: slabSize}
+}
+
+// get returns a zeroed element, reusing a removed element if there is one.
+func (p *pool) get() *element {
+	if e := p.free; e != nil {
+		p.free = e.next
+		e.next = nil
+		return e
+	}
+	if len(p.slab) == 0 {
+		p.slab = make([]element, p.slabSize)
+	}
+	e := &p.slab[0]
+	p.slab = p.slab[1:]
+	return e
+}
+
+// put clears the element, so that its value can be garbage collected, and adds it to the free list.
+func (p *pool) put(e *element) {
+	*e = element{next: p.free}
+	p.free = e
+}
+
+// newElement returns an element holding the value, taken from the pool if the list has one.
+func (list *List) newElement(value interface{}) *element {
+	if list.pool == nil {
+		return &element{value: value}
+	}
+	e := list.pool.get()
+	e.value = value
+	return e
+}
+
+// freeElement returns the element unlinked from the list to the pool if the list has one.
+func (list *List) freeElement(e *element) {
+	if list.pool != nil {
+		list.pool.put(e)
+	}
+}
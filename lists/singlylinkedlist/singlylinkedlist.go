@@ -23,9 +23,11 @@ func assertListImplementation() {
 
 // List holds the elements, where each element points to the next element
 type List struct {
-	first *element
-	last  *element
-	size  int
+	first    *element
+	last     *element
+	size     int
+	modCount int // number of structural modifications, checked by iterators
+	pool     *pool
 }
 
 type element struct {
@@ -42,10 +44,21 @@ func New(values ...interface{}) *List {
 	return list
 }
 
+// NewWithPool instantiates a new list that allocates its elements slabSize at a time and reuses removed elements
+// for later insertions, and adds the passed values, if any, to the list. A slabSize of 1 only reuses removed elements.
+// A slab is kept in memory as long as any of its elements is in use.
+func NewWithPool(slabSize int, values ...interface{}) *List {
+	list := &List{pool: newPool(slabSize)}
+	if len(values) > 0 {
+		list.Add(values...)
+	}
+	return list
+}
+
 // Add appends a value (one or more) at the end of the list (same as Append())
 func (list *List) Add(values ...interface{}) {
 	for _, value := range values {
-		newElement := &element{value: value}
+		newElement := list.newElement(value)
 		if list.size == 0 {
 			list.first = newElement
 			list.last = newElement
@@ -54,6 +67,7 @@ func (list *List) Add(values ...interface{}) {
 			list.last = newElement
 		}
 		list.size++
+		list.modCount++
 	}
 }
 
@@ -66,12 +80,14 @@ func (list *List) Append(values ...interface{}) {
 func (list *List) Prepend(values ...interface{}) {
 	// in reverse to keep passed order i.e. ["c","d"] -> Prepend(["a","b"]) -> ["a","b","c",d"]
 	for v := len(values) - 1; v >= 0; v-- {
-		newElement := &element{value: values[v], next: list.first}
+		newElement := list.newElement(values[v])
+		newElement.next = list.first
 		list.first = newElement
 		if list.size == 0 {
 			list.last = newElement
 		}
 		list.size++
+		list.modCount++
 	}
 }
 
@@ -97,17 +113,18 @@ func (list *List) Remove(index int) {
 		return
 	}
 
-	if list.size == 1 {
-		list.Clear()
-		return
-	}
-
 	var beforeElement *element
 	element := list.first
 	for e := 0; e != index; e, element = e+1, element.next {
 		beforeElement = element
 	}
 
+	list.unlink(beforeElement, element)
+	list.freeElement(element)
+}
+
+// unlink removes the element that follows beforeElement, which is nil for the first element.
+func (list *List) unlink(beforeElement *element, element *element) {
 	if element == list.first {
 		list.first = element.next
 	}
@@ -117,10 +134,8 @@ func (list *List) Remove(index int) {
 	if beforeElement != nil {
 		beforeElement.next = element.next
 	}
-
-	element = nil
-
 	list.size--
+	list.modCount++
 }
 
 // Contains checks if values (one or more) are present in the set.
@@ -187,6 +202,7 @@ func (list *List) Clear() {
 	list.size = 0
 	list.first = nil
 	list.last = nil
+	list.modCount++
 }
 
 // Sort sort values (in-place) using.
@@ -235,6 +251,7 @@ func (list *List) Insert(index int, values ...interface{}) {
 	}
 
 	list.size += len(values)
+	list.modCount++
 
 	var beforeElement *element
 	foundElement := list.first
@@ -245,7 +262,7 @@ func (list *List) Insert(index int, values ...interface{}) {
 	if foundElement == list.first {
 		oldNextElement := list.first
 		for i, value := range values {
-			newElement := &element{value: value}
+			newElement := list.newElement(value)
 			if i == 0 {
 				list.first = newElement
 			} else {
@@ -257,7 +274,7 @@ func (list *List) Insert(index int, values ...interface{}) {
 	} else {
 		oldNextElement := beforeElement.next
 		for _, value := range values {
-			newElement := &element{value: value}
+			newElement := list.newElement(value)
 			beforeElement.next = newElement
 			beforeElement = newElement
 		}
@@ -5,9 +5,11 @@
 package singlylinkedlist
 
 import (
+	"bytes"
 	"fmt"
 	"testing"
 
+	"github.com/dairongpeng/gds/containers"
 	"github.com/dairongpeng/gds/utils"
 )
 
@@ -420,6 +422,70 @@ func TestListIteratorFirst(t *testing.T) {
 	}
 }
 
+func TestListIteratorRemove(t *testing.T) {
+	list := New("a", "b", "c", "d", "e")
+	it := list.Iterator()
+	for it.Next() {
+		if value := it.Value(); value == "a" || value == "c" || value == "e" {
+			it.Remove()
+		}
+	}
+	if actualValue, expectedValue := fmt.Sprint(list.Values()), "[b d]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	list.Add("f")
+	if actualValue, expectedValue := fmt.Sprint(list.Values()), "[b d f]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+
+	it.Begin()
+	it.Next()
+	it.Next()
+	it.Remove()
+	if !it.Next() || it.Index() != 1 || it.Value() != "f" {
+		t.Errorf("Got %v,%v expected %v,%v", it.Index(), it.Value(), 1, "f")
+	}
+	if actualValue, expectedValue := list.Size(), 2; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+}
+
+// expectPanic checks that f panics with the expected error.
+func expectPanic(t *testing.T, expected error, f func()) {
+	t.Helper()
+	defer func() {
+		if r := recover(); r != expected {
+			t.Errorf("Got %v expected %v", r, expected)
+		}
+	}()
+	f()
+}
+
+func TestListIteratorConcurrentModification(t *testing.T) {
+	list := New("a", "b", "c")
+	it := list.Iterator()
+	it.Next()
+	list.Remove(2)
+	expectPanic(t, containers.ErrConcurrentModification, func() { it.Next() })
+	expectPanic(t, containers.ErrConcurrentModification, func() { it.Remove() })
+
+	it.Begin()
+	list.Prepend("z")
+	if !it.Next() || it.Value() != "z" {
+		t.Errorf("Got %v expected %v", it.Value(), "z")
+	}
+	list.Set(1, "y")
+	if !it.Next() || it.Value() != "y" {
+		t.Errorf("Got %v expected %v", it.Value(), "y")
+	}
+
+	it.Begin()
+	expectPanic(t, containers.ErrIteratorNotOnElement, func() { it.Remove() })
+	it.Next()
+	it.Remove()
+	expectPanic(t, containers.ErrIteratorNotOnElement, func() { it.Remove() })
+}
+
 func TestListSerialization(t *testing.T) {
 	list := New()
 	list.Add("a", "b", "c")
@@ -446,6 +512,38 @@ func TestListSerialization(t *testing.T) {
 	assert()
 }
 
+func TestListPool(t *testing.T) {
+	list := NewWithPool(4, "a", "b", "c")
+	list.Remove(1)
+	list.Add("d")
+	if actualValue, expectedValue := fmt.Sprint(list.Values()), "[a c d]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	it := list.Iterator()
+	for it.Next() {
+		if it.Value() == "c" {
+			it.Remove()
+		}
+	}
+	list.Insert(1, "e")
+	list.Prepend("f")
+	if actualValue, expectedValue := fmt.Sprint(list.Values()), "[f a e d]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	// removed elements are reused
+	allocs := testing.AllocsPerRun(10, func() {
+		for n := 0; n < 100; n++ {
+			list.Add(n)
+		}
+		for n := 0; n < 100; n++ {
+			list.Remove(list.Size() - 1)
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("Got %v expected %v", allocs, 0)
+	}
+}
+
 func benchmarkGet(b *testing.B, list *List, size int) {
 	for i := 0; i < b.N; i++ {
 		for n := 0; n < size; n++ {
@@ -470,6 +568,55 @@ func benchmarkRemove(b *testing.B, list *List, size int) {
 	}
 }
 
+func TestListWriteDOT(t *testing.T) {
+	list := New("a", "b", "c")
+	var buffer bytes.Buffer
+	if err := list.WriteDOT(&buffer, containers.DOTOptions{}); err != nil {
+		t.Errorf("Got %v expected %v", err, nil)
+	}
+	expectedValue := `digraph G {
+	rankdir=LR;
+	node [shape=box];
+	n0 [label="a"];
+	n0 -> n1;
+	n1 [label="b"];
+	n1 -> n2;
+	n2 [label="c"];
+}
+`
+	if actualValue := buffer.String(); actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+
+	buffer.Reset()
+	list.WriteDOT(&buffer, containers.DOTOptions{MaxDepth: 1})
+	expectedValue = `digraph G {
+	rankdir=LR;
+	node [shape=box];
+	n0 [label="a"];
+	n0 -> n1;
+	n1 [label="...", shape=plaintext];
+}
+`
+	if actualValue := buffer.String(); actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+}
+
+// benchmarkChurn adds all values to the back of the list and removes them from the front in every round.
+// It compares the allocations of plain lists with pooled lists, which reuse the removed elements.
+func benchmarkChurn(b *testing.B, list *List, size int) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		for n := 0; n < size; n++ {
+			list.Add(struct{}{})
+		}
+		for n := 0; n < size; n++ {
+			list.Remove(0)
+		}
+	}
+}
+
 func BenchmarkSinglyLinkedListGet100(b *testing.B) {
 	b.StopTimer()
 	size := 100
@@ -598,3 +745,19 @@ func BenchmarkSinglyLinkedListRemove100000(b *testing.B) {
 	b.StartTimer()
 	benchmarkRemove(b, list, size)
 }
+
+func BenchmarkSinglyLinkedListChurn1000(b *testing.B) {
+	benchmarkChurn(b, New(), 1000)
+}
+
+func BenchmarkSinglyLinkedListChurn10000(b *testing.B) {
+	benchmarkChurn(b, New(), 10000)
+}
+
+func BenchmarkSinglyLinkedListPooledChurn1000(b *testing.B) {
+	benchmarkChurn(b, NewWithPool(256), 1000)
+}
+
+func BenchmarkSinglyLinkedListPooledChurn10000(b *testing.B) {
+	benchmarkChurn(b, NewWithPool(256), 10000)
+}
@@ -0,0 +1,291 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package arc implements an adaptive replacement cache, which balances between recency and frequency on its own.
+//
+// The cache holds the pairs used once since they entered it in the list T1 and the pairs used at least twice in
+// the list T2, both ordered by the last use. The keys of the pairs recently evicted from T1 and T2 are remembered,
+// without their values, in the ghost lists B1 and B2. Putting a key found in B1 means that T1 should have been
+// larger, putting a key found in B2 that T2 should have been larger, and the target size of T1 moves accordingly.
+// Evictions take the least recently used pair of T1 while it exceeds the target, otherwise the one of T2.
+// So a scan of keys used once cannot evict the frequently used pairs, while a changing working set still replaces
+// the old one. Get, Put and the eviction take O(1).
+//
+// Structure is not thread safe.
+//
+// References: https://www.usenix.org/legacy/events/fast03/tech/full_papers/megiddo/megiddo.pdf,
+// https://en.wikipedia.org/wiki/Adaptive_replacement_cache
+package arc
+
+import (
+	"fmt"
+	"github.com/dairongpeng/gds/maps"
+	"strings"
+)
+
+func assertCacheImplementation() {
+	var _ maps.Cache = (*Cache)(nil)
+}
+
+// Cache holds the pairs and the ghost keys in a hash table and in the lists T1, T2, B1 and B2
+// Cache ARC自适应替换缓存，T1保存只使用过一次的元素，T2保存使用过多次的元素，B1和B2记录最近从T1和T2淘汰的key，
+// 根据B1和B2的命中情况自动调整T1的目标大小
+type Cache struct {
+	OnEvict  maps.EvictionCallback // Called with every pair evicted to make room, if set
+	table    map[interface{}]*entry
+	t1       list // pairs used once, recency
+	t2       list // pairs used at least twice, frequency
+	b1       list // keys evicted from t1
+	b2       list // keys evicted from t2
+	capacity int
+	target   int // target size of t1
+	stats    maps.CacheStats
+}
+
+// list is a circular doubly-linked list of entries from the most to the least recently used one.
+type list struct {
+	head entry // sentinel
+	size int
+}
+
+type entry struct {
+	key   interface{}
+	value interface{} // nil in the ghost lists
+	list  *list
+	prev  *entry
+	next  *entry
+}
+
+// New instantiates a cache holding at most capacity pairs, and remembering at most capacity ghost keys.
+// Capacity should be positive, otherwise method panics.
+func New(capacity int) *Cache {
+	if capacity < 1 {
+		panic("Invalid capacity, must be positive")
+	}
+	cache := &Cache{capacity: capacity}
+	cache.Clear()
+	return cache
+}
+
+// Put inserts the pair into the cache, or replaces the value of the key, and counts it as a use of the pair.
+// If the cache is full, a pair is evicted from T1 or T2 to make room for a new pair.
+// Key should be comparable, i.e. usable as a key of a Go map, otherwise method panics.
+func (cache *Cache) Put(key interface{}, value interface{}) {
+	e, found := cache.table[key]
+	switch {
+	case found && (e.list == &cache.t1 || e.list == &cache.t2):
+		e.value = value
+		cache.move(e, &cache.t2)
+	case found && e.list == &cache.b1:
+		// T1 was evicted too early, let it grow
+		cache.target = min(cache.capacity, cache.target+max(cache.b2.size/cache.b1.size, 1))
+		cache.replace(false)
+		e.value = value
+		cache.move(e, &cache.t2)
+	case found:
+		// T2 was evicted too early, let it grow
+		cache.target = max(0, cache.target-max(cache.b1.size/cache.b2.size, 1))
+		cache.replace(true)
+		e.value = value
+		cache.move(e, &cache.t2)
+	default:
+		if cache.t1.size+cache.b1.size >= cache.capacity {
+			if cache.t1.size < cache.capacity {
+				cache.forget(&cache.b1)
+				cache.replace(false)
+			} else {
+				cache.evict(&cache.t1, nil)
+			}
+		} else if cache.t1.size+cache.t2.size+cache.b1.size+cache.b2.size >= cache.capacity {
+			if cache.t1.size+cache.t2.size+cache.b1.size+cache.b2.size >= 2*cache.capacity {
+				cache.forget(&cache.b2)
+			}
+			cache.replace(false)
+		}
+		e = &entry{key: key, value: value}
+		cache.table[key] = e
+		cache.t1.pushFront(e)
+	}
+}
+
+// Get searches the key in the cache and returns its value or nil if key is not found.
+// Second return parameter is true if key was found, otherwise false.
+// A found pair counts as used and moves to T2. The lookup is counted as a hit or a miss, a ghost key is a miss.
+func (cache *Cache) Get(key interface{}) (value interface{}, found bool) {
+	e, found := cache.table[key]
+	if !found || e.list == &cache.b1 || e.list == &cache.b2 {
+		cache.stats.Misses++
+		return nil, false
+	}
+	cache.stats.Hits++
+	cache.move(e, &cache.t2)
+	return e.value, true
+}
+
+// Peek searches the key in the cache like Get, but neither counts it as a use of the pair nor counts the lookup.
+func (cache *Cache) Peek(key interface{}) (value interface{}, found bool) {
+	if e, found := cache.table[key]; found && (e.list == &cache.t1 || e.list == &cache.t2) {
+		return e.value, true
+	}
+	return nil, false
+}
+
+// Remove removes the pair, or the ghost key, from the cache by key, without calling OnEvict.
+func (cache *Cache) Remove(key interface{}) {
+	if e, found := cache.table[key]; found {
+		e.list.remove(e)
+		delete(cache.table, key)
+	}
+}
+
+// Capacity returns the maximum number of pairs.
+func (cache *Cache) Capacity() int {
+	return cache.capacity
+}
+
+// Target returns the current target size of T1, the rest of the capacity being meant for T2.
+func (cache *Cache) Target() int {
+	return cache.target
+}
+
+// Stats returns the number of hits, misses and evictions since the cache was created.
+func (cache *Cache) Stats() maps.CacheStats {
+	return cache.stats
+}
+
+// Empty returns true if cache does not contain any elements
+func (cache *Cache) Empty() bool {
+	return cache.Size() == 0
+}
+
+// Size returns number of elements in the cache, not counting the ghost keys.
+func (cache *Cache) Size() int {
+	return cache.t1.size + cache.t2.size
+}
+
+// Keys returns the keys of T2 followed by the keys of T1, each from the most to the least recently used one.
+func (cache *Cache) Keys() []interface{} {
+	keys := make([]interface{}, 0, cache.Size())
+	cache.each(func(e *entry) {
+		keys = append(keys, e.key)
+	})
+	return keys
+}
+
+// Values returns all values in the order of Keys.
+func (cache *Cache) Values() []interface{} {
+	values := make([]interface{}, 0, cache.Size())
+	cache.each(func(e *entry) {
+		values = append(values, e.value)
+	})
+	return values
+}
+
+// Clear removes all elements and ghost keys from the cache, without calling OnEvict, and resets the target size
+// of T1. The statistics are kept.
+func (cache *Cache) Clear() {
+	cache.table = make(map[interface{}]*entry)
+	for _, l := range []*list{&cache.t1, &cache.t2, &cache.b1, &cache.b2} {
+		l.head.prev, l.head.next = &l.head, &l.head
+		l.size = 0
+	}
+	cache.target = 0
+}
+
+// String returns a string representation of container
+func (cache *Cache) String() string {
+	str := "ARCCache\nmap["
+	cache.each(func(e *entry) {
+		str += fmt.Sprintf("%v:%v ", e.key, e.value)
+	})
+	return strings.TrimRight(str, " ") + "]"
+}
+
+// replace evicts the least recently used pair of T1 into B1 if T1 exceeds its target, otherwise the one of T2 into
+// B2, provided the cache is full. fromB2 tells whether the replacement makes room for a key found in B2.
+func (cache *Cache) replace(fromB2 bool) {
+	if cache.t1.size+cache.t2.size < cache.capacity {
+		return
+	}
+	if cache.t1.size > 0 && (cache.t1.size > cache.target || (fromB2 && cache.t1.size == cache.target)) {
+		cache.evict(&cache.t1, &cache.b1)
+	} else {
+		cache.evict(&cache.t2, &cache.b2)
+	}
+}
+
+// evict removes the least recently used pair of the list, keeping its key in the ghost list if there is one.
+func (cache *Cache) evict(from *list, ghosts *list) {
+	e := from.back()
+	key, value := e.key, e.value
+	if ghosts != nil {
+		e.value = nil
+		cache.move(e, ghosts)
+	} else {
+		from.remove(e)
+		delete(cache.table, key)
+	}
+	cache.stats.Evictions++
+	if cache.OnEvict != nil {
+		cache.OnEvict(key, value)
+	}
+}
+
+// forget removes the least recently used key of the ghost list.
+func (cache *Cache) forget(ghosts *list) {
+	if ghosts.size == 0 {
+		return
+	}
+	e := ghosts.back()
+	ghosts.remove(e)
+	delete(cache.table, e.key)
+}
+
+// move moves the entry to the front of the list.
+func (cache *Cache) move(e *entry, to *list) {
+	e.list.remove(e)
+	to.pushFront(e)
+}
+
+// each calls the function for every pair in the order of Keys.
+func (cache *Cache) each(f func(e *entry)) {
+	for _, l := range []*list{&cache.t2, &cache.t1} {
+		for e := l.head.next; e != &l.head; e = e.next {
+			f(e)
+		}
+	}
+}
+
+func (l *list) pushFront(e *entry) {
+	e.list = l
+	e.prev, e.next = &l.head, l.head.next
+	e.next.prev = e
+	l.head.next = e
+	l.size++
+}
+
+func (l *list) remove(e *entry) {
+	e.prev.next = e.next
+	e.next.prev = e.prev
+	e.prev, e.next, e.list = nil, nil, nil
+	l.size--
+}
+
+func (l *list) back() *entry {
+	return l.head.prev
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
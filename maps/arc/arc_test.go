@@ -0,0 +1,235 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package arc
+
+import (
+	"fmt"
+	"github.com/dairongpeng/gds/maps"
+	"math/rand"
+	"testing"
+)
+
+func TestCachePut(t *testing.T) {
+	cache := New(3)
+	evicted := []interface{}{}
+	cache.OnEvict = func(key interface{}, value interface{}) {
+		evicted = append(evicted, key)
+	}
+	cache.Put(1, "a")
+	cache.Put(2, "b")
+	cache.Put(3, "c")
+	cache.Put(1, "x") //overwrite
+	if actualValue, expectedValue := fmt.Sprint(cache.Keys()), "[1 3 2]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	cache.Put(4, "d")
+	cache.Put(5, "e")
+	if actualValue, expectedValue := fmt.Sprint(cache.Keys()), "[1 5 4]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue, expectedValue := fmt.Sprint(cache.Values()), "[x e d]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue, expectedValue := fmt.Sprint(evicted), "[2 3]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue, expectedValue := cache.String(), "ARCCache\nmap[1:x 5:e 4:d]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue := cache.Size(); actualValue != 3 {
+		t.Errorf("Got %v expected %v", actualValue, 3)
+	}
+}
+
+func TestCacheGetPeek(t *testing.T) {
+	cache := New(2)
+	cache.Put(1, "a")
+	cache.Put(2, "b")
+
+	if actualValue, found := cache.Get(1); actualValue != "a" || !found {
+		t.Errorf("Got %v expected %v", actualValue, "a")
+	}
+	if actualValue, found := cache.Peek(2); actualValue != "b" || !found {
+		t.Errorf("Got %v expected %v", actualValue, "b")
+	}
+	cache.Put(3, "c")
+	// 2 was only peeked, so it left T1 for B1 and is a miss now
+	if actualValue, found := cache.Get(2); actualValue != nil || found {
+		t.Errorf("Got %v expected %v", actualValue, nil)
+	}
+	if actualValue, found := cache.Peek(2); actualValue != nil || found {
+		t.Errorf("Got %v expected %v", actualValue, nil)
+	}
+	if actualValue, expectedValue := cache.Stats(), (maps.CacheStats{Hits: 1, Misses: 1, Evictions: 1}); actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+}
+
+func TestCacheAdapt(t *testing.T) {
+	cache := New(4)
+	for key := 1; key <= 4; key++ {
+		cache.Put(key, key)
+	}
+	cache.Get(1)
+	cache.Get(2)
+	cache.Put(5, 5)
+	cache.Put(6, 6)
+	if actualValue, expectedValue := fmt.Sprint(cache.Keys()), "[2 1 6 5]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+
+	// putting keys evicted from T1 raises the target of T1
+	cache.Put(3, 3)
+	if actualValue, expectedValue := cache.Target(), 1; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	cache.Put(4, 4)
+	if actualValue, expectedValue := cache.Target(), 2; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue, expectedValue := fmt.Sprint(cache.Keys()), "[4 3 2 6]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+
+	// putting keys evicted from T2 lowers it again
+	cache.Put(7, 7)
+	cache.Put(8, 8)
+	cache.Put(9, 9)
+	cache.Put(1, 1)
+	if actualValue, expectedValue := cache.Target(), 1; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+}
+
+func TestCacheScan(t *testing.T) {
+	cache := New(10)
+	for round := 0; round < 2; round++ {
+		for key := 0; key < 5; key++ {
+			cache.Put(key, key)
+		}
+	}
+	// a scan of keys used once does not evict the pairs used twice
+	for key := 100; key < 200; key++ {
+		cache.Put(key, key)
+	}
+	for key := 0; key < 5; key++ {
+		if _, found := cache.Get(key); !found {
+			t.Errorf("Got %v expected %v for %v", found, true, key)
+		}
+	}
+	if actualValue, expectedValue := cache.Size(), 10; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+}
+
+func TestCacheRemove(t *testing.T) {
+	cache := New(2)
+	evictions := 0
+	cache.OnEvict = func(key interface{}, value interface{}) {
+		evictions++
+	}
+	cache.Put(1, "a")
+	cache.Put(2, "b")
+	cache.Put(3, "c")
+	cache.Remove(1)
+	cache.Remove(2)
+	cache.Remove(4)
+	cache.Put(1, "a")
+	if actualValue, expectedValue := fmt.Sprint(cache.Keys(), cache.Target()), "[1 3] 0"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	cache.Clear()
+	if actualValue := cache.Empty(); actualValue != true {
+		t.Errorf("Got %v expected %v", actualValue, true)
+	}
+	if actualValue := evictions; actualValue != 1 {
+		t.Errorf("Got %v expected %v", actualValue, 1)
+	}
+}
+
+func TestCacheRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	cache := New(50)
+	for i := 0; i < 20000; i++ {
+		// a shifting working set mixed with a hot set
+		key := r.Intn(20)
+		if r.Intn(2) == 0 {
+			key = 20 + i/100 + r.Intn(100)
+		}
+		switch r.Intn(10) {
+		case 0:
+			cache.Remove(key)
+		case 1, 2, 3:
+			if value, found := cache.Get(key); found && value != key {
+				t.Errorf("Got %v expected %v", value, key)
+			}
+		default:
+			cache.Put(key, key)
+		}
+		if i%500 == 0 {
+			assertValidCache(t, cache)
+		}
+	}
+	assertValidCache(t, cache)
+}
+
+// assertValidCache checks the bounds of the lists of the cache.
+func assertValidCache(t *testing.T, cache *Cache) {
+	c := cache.capacity
+	t1, t2, b1, b2 := cache.t1.size, cache.t2.size, cache.b1.size, cache.b2.size
+	if t1+t2 > c || t1+b1 > c || t1+t2+b1+b2 > 2*c || cache.target < 0 || cache.target > c {
+		t.Errorf("Got sizes %v %v %v %v and target %v for capacity %v", t1, t2, b1, b2, cache.target, c)
+	}
+	if actualValue, expectedValue := len(cache.table), t1+t2+b1+b2; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	for _, l := range []*list{&cache.t1, &cache.t2, &cache.b1, &cache.b2} {
+		count := 0
+		for e := l.head.next; e != &l.head; e = e.next {
+			if e.list != l || cache.table[e.key] != e {
+				t.Errorf("Got an inconsistent entry for %v", e.key)
+			}
+			count++
+		}
+		if count != l.size {
+			t.Errorf("Got %v expected %v", count, l.size)
+		}
+	}
+}
+
+func benchmarkPut(b *testing.B, cache *Cache, size int) {
+	for i := 0; i < b.N; i++ {
+		for n := 0; n < size; n++ {
+			cache.Put(n, n)
+		}
+	}
+}
+
+func benchmarkGet(b *testing.B, cache *Cache, size int) {
+	for i := 0; i < b.N; i++ {
+		for n := 0; n < size; n++ {
+			cache.Get(n)
+		}
+	}
+}
+
+func BenchmarkARCCachePut10000(b *testing.B) {
+	b.StopTimer()
+	size := 10000
+	cache := New(size / 2)
+	b.StartTimer()
+	benchmarkPut(b, cache, size)
+}
+
+func BenchmarkARCCacheGet10000(b *testing.B) {
+	b.StopTimer()
+	size := 10000
+	cache := New(size / 2)
+	for n := 0; n < size; n++ {
+		cache.Put(n, n)
+	}
+	b.StartTimer()
+	benchmarkGet(b, cache, size)
+}
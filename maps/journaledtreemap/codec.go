@@ -0,0 +1,59 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package journaledtreemap
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+// Codec converts keys or values to and from the bytes stored in the journal and the snapshot.
+// Codec 负责key或value与日志、快照中字节之间的相互转换
+type Codec interface {
+	Encode(v interface{}) ([]byte, error)
+	Decode(data []byte) (interface{}, error)
+}
+
+// JSONCodec encodes elements as JSON.
+// Decoded numbers are of type float64, so it is best suited for values or for keys compared by Float64Comparator or StringComparator.
+type JSONCodec struct{}
+
+// Encode outputs the JSON representation of v.
+func (JSONCodec) Encode(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Decode parses the JSON representation of an element.
+func (JSONCodec) Decode(data []byte) (interface{}, error) {
+	var v interface{}
+	err := json.Unmarshal(data, &v)
+	return v, err
+}
+
+// StringCodec encodes elements of type string as raw bytes.
+type StringCodec struct{}
+
+// Encode outputs the bytes of the string v.
+func (StringCodec) Encode(v interface{}) ([]byte, error) {
+	return []byte(v.(string)), nil
+}
+
+// Decode returns data as a string.
+func (StringCodec) Decode(data []byte) (interface{}, error) {
+	return string(data), nil
+}
+
+// IntCodec encodes elements of type int as decimal text.
+type IntCodec struct{}
+
+// Encode outputs the decimal representation of the int v.
+func (IntCodec) Encode(v interface{}) ([]byte, error) {
+	return strconv.AppendInt(nil, int64(v.(int)), 10), nil
+}
+
+// Decode parses the decimal representation of an int.
+func (IntCodec) Decode(data []byte) (interface{}, error) {
+	return strconv.Atoi(string(data))
+}
@@ -0,0 +1,246 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package journaledtreemap
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"syscall"
+)
+
+// Every record is laid out as
+//
+//	length   uint32 (little endian, size of payload)
+//	checksum uint32 (little endian, CRC-32C of payload)
+//	payload  op byte | uvarint key length | key | value
+//
+// Remove records carry no value, clear records neither a key nor a value.
+const (
+	headerSize    = 8
+	maxRecordSize = 1 << 30
+)
+
+const (
+	opPut    byte = 1
+	opRemove byte = 2
+	opClear  byte = 3
+)
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// ErrCorruptSnapshot is returned when the snapshot file fails its checksum or cannot be parsed.
+var ErrCorruptSnapshot = errors.New("journaledtreemap: corrupt snapshot")
+
+// errTornRecord marks a record that was only partially written or whose checksum does not match.
+var errTornRecord = errors.New("journaledtreemap: torn record")
+
+type record struct {
+	op    byte
+	key   []byte
+	value []byte
+}
+
+// appendRecord appends the framed representation of r to buf.
+func appendRecord(buf []byte, r record) []byte {
+	var varint [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(varint[:], uint64(len(r.key)))
+	length := 1 + n + len(r.key) + len(r.value)
+
+	start := len(buf)
+	buf = append(buf, make([]byte, headerSize)...)
+	buf = append(buf, r.op)
+	buf = append(buf, varint[:n]...)
+	buf = append(buf, r.key...)
+	buf = append(buf, r.value...)
+
+	payload := buf[start+headerSize:]
+	binary.LittleEndian.PutUint32(buf[start:], uint32(length))
+	binary.LittleEndian.PutUint32(buf[start+4:], crc32.Checksum(payload, crcTable))
+	return buf
+}
+
+// readRecord reads the next record from reader.
+// Returns io.EOF on a clean end of input and errTornRecord if the record is incomplete or damaged.
+func readRecord(reader *bufio.Reader) (r record, size int, err error) {
+	var header [headerSize]byte
+	if n, err := io.ReadFull(reader, header[:]); err != nil {
+		if err == io.EOF && n == 0 {
+			return r, 0, io.EOF
+		}
+		if err == io.ErrUnexpectedEOF {
+			return r, 0, errTornRecord
+		}
+		return r, 0, err
+	}
+	length := binary.LittleEndian.Uint32(header[:])
+	checksum := binary.LittleEndian.Uint32(header[4:])
+	if length == 0 || length > maxRecordSize {
+		return r, 0, errTornRecord
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(reader, payload); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return r, 0, errTornRecord
+		}
+		return r, 0, err
+	}
+	if crc32.Checksum(payload, crcTable) != checksum {
+		return r, 0, errTornRecord
+	}
+
+	r.op = payload[0]
+	keyLength, n := binary.Uvarint(payload[1:])
+	if n <= 0 || uint64(len(payload)-1-n) < keyLength {
+		return r, 0, errTornRecord
+	}
+	keyEnd := 1 + n + int(keyLength)
+	r.key = payload[1+n : keyEnd]
+	r.value = payload[keyEnd:]
+	if r.op != opPut && r.op != opRemove && r.op != opClear {
+		return r, 0, errTornRecord
+	}
+	return r, headerSize + int(length), nil
+}
+
+// replay applies the records of the file at path to the map.
+// Returns the offset just past the last intact record and whether damaged or incomplete bytes follow it.
+// A missing file is treated as empty.
+func (m *Map) replay(path string) (offset int64, torn bool, err error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	for {
+		r, size, err := readRecord(reader)
+		switch {
+		case err == io.EOF:
+			return offset, false, nil
+		case err == errTornRecord:
+			return offset, true, nil
+		case err != nil:
+			return offset, false, err
+		}
+		if err := m.apply(r); err != nil {
+			return offset, false, err
+		}
+		offset += int64(size)
+	}
+}
+
+// apply decodes the record and performs it on the in-memory map.
+func (m *Map) apply(r record) error {
+	if r.op == opClear {
+		m.m.Clear()
+		return nil
+	}
+	key, err := m.keyCodec.Decode(r.key)
+	if err != nil {
+		return err
+	}
+	if r.op == opRemove {
+		m.m.Remove(key)
+		return nil
+	}
+	value, err := m.valueCodec.Decode(r.value)
+	if err != nil {
+		return err
+	}
+	m.m.Put(key, value)
+	return nil
+}
+
+// encode builds the record for the given operation on the key-value pair.
+// The value is ignored for removals, the key and the value for clears.
+func (m *Map) encode(op byte, key interface{}, value interface{}) (record, error) {
+	if op == opClear {
+		return record{op: op}, nil
+	}
+	k, err := m.keyCodec.Encode(key)
+	if err != nil {
+		return record{}, err
+	}
+	r := record{op: op, key: k}
+	if op == opPut {
+		if r.value, err = m.valueCodec.Encode(value); err != nil {
+			return record{}, err
+		}
+	}
+	return r, nil
+}
+
+// writeSnapshot writes the whole map to a temporary file and atomically renames it over the snapshot.
+func (m *Map) writeSnapshot() error {
+	tmp := m.path + ".tmp"
+	file, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	writer := bufio.NewWriter(file)
+	var buf []byte
+	it := m.m.Iterator()
+	for it.Next() {
+		r, err := m.encode(opPut, it.Key(), it.Value())
+		if err == nil {
+			buf = appendRecord(buf[:0], r)
+			_, err = writer.Write(buf)
+		}
+		if err != nil {
+			file.Close()
+			os.Remove(tmp)
+			return err
+		}
+	}
+	if err = writer.Flush(); err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, m.path); err != nil {
+		return err
+	}
+	return syncDirectory(filepath.Dir(m.path))
+}
+
+// syncDirectory is the function syncing the directory of the snapshot, tests replace it to make the sync fail.
+var syncDirectory = syncDir
+
+// syncDir flushes directory metadata so that a rename survives a crash. Only the errors of platforms and file
+// systems that cannot sync directories (Windows, EINVAL and ENOTSUP) are ignored, any other error is returned.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	err = syncDirError(d.Sync())
+	if closeErr := d.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// syncDirError returns the error of syncing a directory, or nil if the platform or file system cannot sync directories.
+func syncDirError(err error) error {
+	if err != nil && (runtime.GOOS == "windows" || errors.Is(err, syscall.EINVAL) || errors.Is(err, syscall.ENOTSUP)) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,275 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package journaledtreemap implements a durable tree map backed by an append-only journal.
+//
+// Every Put and Remove is appended to a journal file as a length-prefixed, checksummed record
+// before it is applied to the in-memory treemap. Opening the map loads the last snapshot and
+// replays the journal on top of it; a partially written record at the end of the journal
+// (e.g. after a crash) is discarded. Compact writes a fresh snapshot and truncates the journal.
+//
+// The snapshot is stored at the given path and the journal at path + ".journal".
+//
+// Structure is not thread safe.
+//
+// Reference: https://en.wikipedia.org/wiki/Write-ahead_logging
+package journaledtreemap
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/dairongpeng/gds/containers"
+	"github.com/dairongpeng/gds/maps/treemap"
+	"github.com/dairongpeng/gds/utils"
+)
+
+func assertContainerImplementation() {
+	var _ containers.Container = (*Map)(nil)
+}
+
+// Map holds the elements in a treemap and the journal that makes them durable.
+// Map 持有一个内存中的有序表treemap，以及记录所有写操作的日志文件
+type Map struct {
+	m          *treemap.Map
+	comparator utils.Comparator
+	path       string
+	journal    *os.File
+	offset     int64 // size of the intact prefix of the journal
+	failed     error // set when a partially written record could not be dropped, appends fail until Compact
+	keyCodec   Codec
+	valueCodec Codec
+	buf        []byte
+}
+
+// Open opens (or creates) the journaled map stored at path with the custom comparator and codecs.
+// The snapshot is loaded and the journal replayed; a torn record at the tail of the journal is truncated away.
+// Open 打开（或创建）一个持久化的有序表，加载快照并重放日志，日志尾部写了一半的记录会被截断
+func Open(path string, comparator utils.Comparator, keyCodec Codec, valueCodec Codec) (*Map, error) {
+	m := &Map{
+		m:          treemap.NewWith(comparator),
+		comparator: comparator,
+		path:       path,
+		keyCodec:   keyCodec,
+		valueCodec: valueCodec,
+	}
+	if _, torn, err := m.replay(path); err != nil {
+		return nil, err
+	} else if torn {
+		return nil, ErrCorruptSnapshot
+	}
+
+	offset, torn, err := m.replay(m.journalPath())
+	if err != nil {
+		return nil, err
+	}
+	journal, err := os.OpenFile(m.journalPath(), os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if torn {
+		if err := journal.Truncate(offset); err != nil {
+			journal.Close()
+			return nil, err
+		}
+	}
+	if _, err := journal.Seek(offset, io.SeekStart); err != nil {
+		journal.Close()
+		return nil, err
+	}
+	m.journal = journal
+	m.offset = offset
+	return m, nil
+}
+
+// OpenWithIntComparator opens the journaled map stored at path whose keys are of type int.
+func OpenWithIntComparator(path string, valueCodec Codec) (*Map, error) {
+	return Open(path, utils.IntComparator, IntCodec{}, valueCodec)
+}
+
+// OpenWithStringComparator opens the journaled map stored at path whose keys are of type string.
+func OpenWithStringComparator(path string, valueCodec Codec) (*Map, error) {
+	return Open(path, utils.StringComparator, StringCodec{}, valueCodec)
+}
+
+// Put appends the key-value pair to the journal and inserts it into the map.
+// The map is left unchanged if the record cannot be encoded or written.
+// Key should adhere to the comparator's type assertion, otherwise method panics.
+// Put 先把写操作追加到日志中，写入成功后再更新内存中的有序表
+func (m *Map) Put(key interface{}, value interface{}) error {
+	if err := m.append(opPut, key, value); err != nil {
+		return err
+	}
+	m.m.Put(key, value)
+	return nil
+}
+
+// Remove appends the removal of key to the journal and removes the element from the map.
+// Nothing is written if the key is not in the map.
+// Key should adhere to the comparator's type assertion, otherwise method panics.
+func (m *Map) Remove(key interface{}) error {
+	if _, found := m.m.Get(key); !found {
+		return nil
+	}
+	if err := m.append(opRemove, key, nil); err != nil {
+		return err
+	}
+	m.m.Remove(key)
+	return nil
+}
+
+// Get searches the element in the map by key and returns its value or nil if key is not found in map.
+// Second return parameter is true if key was found, otherwise false.
+// Key should adhere to the comparator's type assertion, otherwise method panics.
+func (m *Map) Get(key interface{}) (value interface{}, found bool) {
+	return m.m.Get(key)
+}
+
+// Empty returns true if map does not contain any elements
+func (m *Map) Empty() bool {
+	return m.m.Empty()
+}
+
+// Size returns number of elements in the map.
+func (m *Map) Size() int {
+	return m.m.Size()
+}
+
+// Keys returns all keys in-order
+func (m *Map) Keys() []interface{} {
+	return m.m.Keys()
+}
+
+// Values returns all values in-order based on the key.
+func (m *Map) Values() []interface{} {
+	return m.m.Values()
+}
+
+// Clear removes all elements from the map, see Reset.
+// Clear drops the error of Reset; use Reset to observe it.
+func (m *Map) Clear() {
+	m.Reset()
+}
+
+// Reset removes all elements from the map by journaling a clear record, writing an empty snapshot and
+// truncating the journal. The clear record is synced before the snapshot is replaced, so a crash at any point
+// replays to either the previous contents or an empty map, never to the keys of the old journal alone.
+// The map is left unchanged if the clear record cannot be written, once it is the elements are removed
+// even if a later step fails, which only leaves the journal uncompacted.
+// Reset 先在日志中追加并同步一条清空记录，再写入空快照并截断日志
+func (m *Map) Reset() error {
+	if err := m.append(opClear, nil, nil); err != nil {
+		return err
+	}
+	m.m.Clear()
+	if err := m.journal.Sync(); err != nil {
+		return err
+	}
+	return m.Compact()
+}
+
+// Min returns the minimum key and its value from the map.
+// Returns nil, nil if map is empty.
+func (m *Map) Min() (key interface{}, value interface{}) {
+	return m.m.Min()
+}
+
+// Max returns the maximum key and its value from the map.
+// Returns nil, nil if map is empty.
+func (m *Map) Max() (key interface{}, value interface{}) {
+	return m.m.Max()
+}
+
+// Floor finds the floor key-value pair for the input key.
+// In case that no floor is found, then both returned values will be nil.
+// Key should adhere to the comparator's type assertion, otherwise method panics.
+func (m *Map) Floor(key interface{}) (foundKey interface{}, foundValue interface{}) {
+	return m.m.Floor(key)
+}
+
+// Ceiling finds the ceiling key-value pair for the input key.
+// In case that no ceiling is found, then both returned values will be nil.
+// Key should adhere to the comparator's type assertion, otherwise method panics.
+func (m *Map) Ceiling(key interface{}) (foundKey interface{}, foundValue interface{}) {
+	return m.m.Ceiling(key)
+}
+
+// JournalSize returns the size in bytes of the journal written since the last compaction.
+// Useful to decide when to call Compact.
+func (m *Map) JournalSize() int64 {
+	return m.offset
+}
+
+// Compact writes a snapshot of the current contents and truncates the journal.
+// The snapshot replaces the previous one atomically, so a crash at any point leaves a state that replays to the same contents.
+// Compact also repairs a journal whose partially written record could not be dropped, which makes appends fail.
+// Compact 把当前内容写成新的快照文件，并清空日志
+func (m *Map) Compact() error {
+	if err := m.writeSnapshot(); err != nil {
+		return err
+	}
+	if err := m.journal.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := m.journal.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	m.offset = 0
+	m.failed = nil
+	return m.journal.Sync()
+}
+
+// Sync commits the journal to stable storage.
+// Without it, appended records survive a process crash but not necessarily an operating system crash.
+func (m *Map) Sync() error {
+	return m.journal.Sync()
+}
+
+// Close syncs and closes the journal. The map must not be modified afterwards.
+func (m *Map) Close() error {
+	err := m.journal.Sync()
+	if closeErr := m.journal.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// String returns a string representation of container
+func (m *Map) String() string {
+	str := "JournaledTreeMap\nmap["
+	it := m.Iterator()
+	for it.Next() {
+		str += fmt.Sprintf("%v:%v ", it.Key(), it.Value())
+	}
+	return strings.TrimRight(str, " ") + "]"
+}
+
+func (m *Map) append(op byte, key interface{}, value interface{}) error {
+	if m.failed != nil {
+		return m.failed
+	}
+	r, err := m.encode(op, key, value)
+	if err != nil {
+		return err
+	}
+	m.buf = appendRecord(m.buf[:0], r)
+	if _, err := m.journal.Write(m.buf); err != nil {
+		// Drop a partially written record so that later appends are not hidden behind it on replay.
+		// If that fails the position of the next record is unknown, so the map refuses appends until Compact.
+		if truncateErr := m.journal.Truncate(m.offset); truncateErr != nil {
+			m.failed = truncateErr
+		} else if _, seekErr := m.journal.Seek(m.offset, io.SeekStart); seekErr != nil {
+			m.failed = seekErr
+		}
+		return err
+	}
+	m.offset += int64(len(m.buf))
+	return nil
+}
+
+func (m *Map) journalPath() string {
+	return m.path + ".journal"
+}
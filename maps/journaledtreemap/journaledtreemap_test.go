@@ -0,0 +1,347 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package journaledtreemap
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"syscall"
+	"testing"
+)
+
+func openInt(t *testing.T, path string) *Map {
+	m, err := OpenWithIntComparator(path, StringCodec{})
+	if err != nil {
+		t.Fatalf("Got error %v", err)
+	}
+	return m
+}
+
+func TestMapPutRemove(t *testing.T) {
+	m := openInt(t, filepath.Join(t.TempDir(), "map"))
+	defer m.Close()
+	m.Put(5, "e")
+	m.Put(6, "f")
+	m.Put(7, "g")
+	m.Put(3, "c")
+	m.Put(4, "d")
+	m.Put(1, "x")
+	m.Put(2, "b")
+	m.Put(1, "a") //overwrite
+	m.Remove(6)
+	m.Remove(8)
+
+	if actualValue := m.Size(); actualValue != 6 {
+		t.Errorf("Got %v expected %v", actualValue, 6)
+	}
+	if actualValue, expectedValue := fmt.Sprintf("%v", m.Keys()), "[1 2 3 4 5 7]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue, found := m.Get(1); actualValue != "a" || !found {
+		t.Errorf("Got %v expected %v", actualValue, "a")
+	}
+	if actualValue, found := m.Get(6); actualValue != nil || found {
+		t.Errorf("Got %v expected %v", actualValue, nil)
+	}
+}
+
+func TestMapReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "map")
+	m := openInt(t, path)
+	for i := 0; i < 100; i++ {
+		m.Put(i, fmt.Sprintf("v%d", i))
+	}
+	for i := 0; i < 100; i += 2 {
+		m.Remove(i)
+	}
+	m.Put(1, "one")
+	if err := m.Close(); err != nil {
+		t.Errorf("Got error %v", err)
+	}
+
+	m = openInt(t, path)
+	defer m.Close()
+	if actualValue := m.Size(); actualValue != 50 {
+		t.Errorf("Got %v expected %v", actualValue, 50)
+	}
+	if actualValue, _ := m.Get(1); actualValue != "one" {
+		t.Errorf("Got %v expected %v", actualValue, "one")
+	}
+	if actualValue, _ := m.Get(99); actualValue != "v99" {
+		t.Errorf("Got %v expected %v", actualValue, "v99")
+	}
+	if _, found := m.Get(98); found {
+		t.Errorf("Got %v expected %v", found, false)
+	}
+}
+
+func TestMapTornTail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "map")
+	m := openInt(t, path)
+	m.Put(1, "a")
+	m.Put(2, "b")
+	m.Put(3, "c")
+	m.Close()
+
+	// Simulate a crash in the middle of writing the last record.
+	info, err := os.Stat(path + ".journal")
+	if err != nil {
+		t.Fatalf("Got error %v", err)
+	}
+	if err := os.Truncate(path+".journal", info.Size()-3); err != nil {
+		t.Fatalf("Got error %v", err)
+	}
+
+	m = openInt(t, path)
+	if actualValue, expectedValue := fmt.Sprintf("%v", m.Keys()), "[1 2]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	m.Put(4, "d")
+	m.Close()
+
+	m = openInt(t, path)
+	defer m.Close()
+	if actualValue, expectedValue := fmt.Sprintf("%v", m.Keys()), "[1 2 4]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+}
+
+func TestMapCorruptTail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "map")
+	m := openInt(t, path)
+	m.Put(1, "a")
+	m.Put(2, "b")
+	m.Close()
+
+	data, _ := os.ReadFile(path + ".journal")
+	data[len(data)-1] ^= 0xff
+	os.WriteFile(path+".journal", data, 0644)
+
+	m = openInt(t, path)
+	defer m.Close()
+	if actualValue, expectedValue := fmt.Sprintf("%v", m.Keys()), "[1]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+}
+
+func TestMapCompact(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "map")
+	m := openInt(t, path)
+	for i := 0; i < 10; i++ {
+		m.Put(i, "x")
+		m.Put(i, fmt.Sprintf("v%d", i))
+	}
+	if actualValue := m.JournalSize(); actualValue == 0 {
+		t.Errorf("Got %v expected a non-empty journal", actualValue)
+	}
+	if err := m.Compact(); err != nil {
+		t.Errorf("Got error %v", err)
+	}
+	if actualValue := m.JournalSize(); actualValue != 0 {
+		t.Errorf("Got %v expected %v", actualValue, 0)
+	}
+	m.Remove(0)
+	m.Close()
+
+	m = openInt(t, path)
+	if actualValue, expectedValue := fmt.Sprintf("%v", m.Values()), "[v1 v2 v3 v4 v5 v6 v7 v8 v9]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if err := m.Reset(); err != nil {
+		t.Errorf("Got error %v", err)
+	}
+	m.Put(42, "z")
+	m.Close()
+
+	m = openInt(t, path)
+	defer m.Close()
+	if actualValue, expectedValue := fmt.Sprintf("%v", m.Keys()), "[42]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+}
+
+func TestMapCompactFailure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "map")
+	m := openInt(t, path)
+	m.Put(1, "a")
+	m.Put(2, "b")
+	size := m.JournalSize()
+
+	// a directory in place of the temporary snapshot makes writing the snapshot fail
+	if err := os.Mkdir(path+".tmp", 0755); err != nil {
+		t.Fatalf("Got error %v", err)
+	}
+	if err := m.Compact(); err == nil {
+		t.Errorf("Got %v expected an error", err)
+	}
+	if actualValue, expectedValue := m.JournalSize(), size; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	m.Close()
+
+	m = openInt(t, path)
+	defer m.Close()
+	if actualValue, expectedValue := fmt.Sprintf("%v", m.Values()), "[a b]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+}
+
+func TestMapResetCrash(t *testing.T) {
+	for _, snapshot := range []bool{false, true} {
+		path := filepath.Join(t.TempDir(), "map")
+		m := openInt(t, path)
+		m.Put(1, "a")
+		m.Put(2, "b")
+		m.Compact()
+		m.Put(3, "c")
+		m.Remove(1)
+
+		// Reset crashes after journaling the clear record, before or after the empty snapshot replaced the old one
+		if err := m.append(opClear, nil, nil); err != nil {
+			t.Fatalf("Got error %v", err)
+		}
+		if snapshot {
+			m.m.Clear()
+			if err := m.writeSnapshot(); err != nil {
+				t.Fatalf("Got error %v", err)
+			}
+		}
+		m.journal.Close()
+
+		m = openInt(t, path)
+		if actualValue, expectedValue := fmt.Sprintf("%v", m.Keys()), "[]"; actualValue != expectedValue {
+			t.Errorf("Got %v expected %v", actualValue, expectedValue)
+		}
+		m.Put(4, "d")
+		m.Close()
+		m = openInt(t, path)
+		if actualValue, expectedValue := fmt.Sprintf("%v", m.Keys()), "[4]"; actualValue != expectedValue {
+			t.Errorf("Got %v expected %v", actualValue, expectedValue)
+		}
+		m.Close()
+	}
+}
+
+func TestMapAppendFailure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "map")
+	m := openInt(t, path)
+	m.Put(1, "a")
+
+	// neither writing nor dropping the record works on a closed journal
+	m.journal.Close()
+	if err := m.Put(2, "b"); err == nil {
+		t.Errorf("Got %v expected an error", err)
+	}
+	journal, err := os.OpenFile(m.journalPath(), os.O_RDWR, 0644)
+	if err != nil {
+		t.Fatalf("Got error %v", err)
+	}
+	m.journal = journal
+
+	// the position of the next record is unknown until the journal is compacted
+	if err := m.Put(3, "c"); err == nil {
+		t.Errorf("Got %v expected an error", err)
+	}
+	if err := m.Compact(); err != nil {
+		t.Errorf("Got error %v", err)
+	}
+	if err := m.Put(4, "d"); err != nil {
+		t.Errorf("Got error %v", err)
+	}
+	m.Close()
+
+	m = openInt(t, path)
+	defer m.Close()
+	if actualValue, expectedValue := fmt.Sprintf("%v", m.Keys()), "[1 4]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+}
+
+func TestMapCompactSyncFailure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "map")
+	m := openInt(t, path)
+	defer m.Close()
+	m.Put(1, "a")
+	size := m.JournalSize()
+
+	defer func(sync func(dir string) error) { syncDirectory = sync }(syncDirectory)
+	syncDirectory = func(dir string) error { return syscall.EIO }
+	if err := m.Compact(); err != syscall.EIO {
+		t.Errorf("Got %v expected %v", err, syscall.EIO)
+	}
+	// the journal is kept, the renamed snapshot may not be durable
+	if actualValue, expectedValue := m.JournalSize(), size; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+
+	// only the errors of directories that cannot be synced are ignored
+	if runtime.GOOS != "windows" {
+		if err := syncDirError(syscall.EIO); err != syscall.EIO {
+			t.Errorf("Got %v expected %v", err, syscall.EIO)
+		}
+	}
+	for _, err := range []error{nil, syscall.EINVAL, &os.PathError{Op: "sync", Path: "dir", Err: syscall.ENOTSUP}} {
+		if actualValue := syncDirError(err); actualValue != nil {
+			t.Errorf("Got %v expected %v", actualValue, nil)
+		}
+	}
+}
+
+func TestMapCorruptSnapshot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "map")
+	m := openInt(t, path)
+	m.Put(1, "a")
+	m.Compact()
+	m.Close()
+
+	data, _ := os.ReadFile(path)
+	data[len(data)-1] ^= 0xff
+	os.WriteFile(path, data, 0644)
+
+	if _, err := OpenWithIntComparator(path, StringCodec{}); err != ErrCorruptSnapshot {
+		t.Errorf("Got %v expected %v", err, ErrCorruptSnapshot)
+	}
+}
+
+func TestMapJSONCodec(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "map")
+	m, err := OpenWithStringComparator(path, JSONCodec{})
+	if err != nil {
+		t.Fatalf("Got error %v", err)
+	}
+	m.Put("a", map[string]interface{}{"n": 1.5})
+	m.Put("b", []interface{}{"x", true})
+	m.Close()
+
+	m, err = OpenWithStringComparator(path, JSONCodec{})
+	if err != nil {
+		t.Fatalf("Got error %v", err)
+	}
+	defer m.Close()
+	if actualValue, expectedValue := fmt.Sprintf("%v", m.Values()), "[map[n:1.5] [x true]]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+}
+
+func benchmarkPut(b *testing.B, m *Map, size int) {
+	for i := 0; i < b.N; i++ {
+		for n := 0; n < size; n++ {
+			m.Put(n, "v")
+		}
+	}
+}
+
+func BenchmarkJournaledTreeMapPut1000(b *testing.B) {
+	b.StopTimer()
+	m, err := OpenWithIntComparator(filepath.Join(b.TempDir(), "map"), StringCodec{})
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer m.Close()
+	b.StartTimer()
+	benchmarkPut(b, m, 1000)
+}
@@ -0,0 +1,292 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package lfu implements a cache that evicts the least frequently used pairs.
+//
+// Every pair counts how often it was used by Get and Put. The pairs are kept in buckets of equal counts, which form
+// a doubly-linked list ordered by count, and every bucket is a doubly-linked list ordered by the last use of its
+// pairs. A use moves the pair to the bucket of the next count, and the eviction takes the least recently used pair
+// of the first bucket, so Get, Put and the eviction take O(1). Peek does not count as a use.
+//
+// Counts can be aged, halving all of them either on demand (Decay) or every given number of uses, so that pairs
+// that were hot in the past do not stay in the cache forever.
+//
+// Structure is not thread safe.
+//
+// References: http://dhruvbird.com/lfu.pdf,
+// https://en.wikipedia.org/wiki/Least_frequently_used
+package lfu
+
+import (
+	"fmt"
+	"github.com/dairongpeng/gds/maps"
+	"strings"
+)
+
+func assertCacheImplementation() {
+	var _ maps.Cache = (*Cache)(nil)
+}
+
+// Cache holds the pairs in a hash table and in buckets of equal use counts
+// Cache LFU缓存，按照使用次数把元素分桶，桶之间以及桶内部都用双向链表串起来，容量不足时淘汰使用次数最少的元素
+type Cache struct {
+	OnEvict  maps.EvictionCallback // Called with every pair evicted to make room, if set
+	table    map[interface{}]*entry
+	buckets  bucket // sentinel of the circular list of buckets, followed by the bucket of the smallest count
+	capacity int
+	period   int // number of uses after which the counts are halved, 0 if they are not aged
+	uses     int // uses since the counts were last halved
+	stats    maps.CacheStats
+}
+
+// bucket holds the pairs used count times, from the most to the least recently used one.
+type bucket struct {
+	count int
+	head  entry // sentinel of the circular list of pairs
+	prev  *bucket
+	next  *bucket
+}
+
+type entry struct {
+	key    interface{}
+	value  interface{}
+	bucket *bucket
+	prev   *entry
+	next   *entry
+}
+
+// New instantiates a cache holding at most capacity pairs, whose counts are not aged.
+// Capacity should be positive, otherwise method panics.
+func New(capacity int) *Cache {
+	return NewWithDecay(capacity, 0)
+}
+
+// NewWithDecay instantiates a cache holding at most capacity pairs, which halves the counts of its pairs
+// every period uses (Get that found the key or Put). A period of 0 disables the aging.
+// Capacity should be positive and period should not be negative, otherwise method panics.
+func NewWithDecay(capacity int, period int) *Cache {
+	if capacity < 1 {
+		panic("Invalid capacity, must be positive")
+	}
+	if period < 0 {
+		panic("Invalid decay period, must not be negative")
+	}
+	cache := &Cache{table: make(map[interface{}]*entry), capacity: capacity, period: period}
+	cache.buckets.prev, cache.buckets.next = &cache.buckets, &cache.buckets
+	return cache
+}
+
+// Put inserts the pair into the cache, or replaces the value of the key, and counts it as a use of the pair.
+// A new pair evicts the least recently used one among the least frequently used pairs if the cache is full.
+// Key should be comparable, i.e. usable as a key of a Go map, otherwise method panics.
+func (cache *Cache) Put(key interface{}, value interface{}) {
+	if e, found := cache.table[key]; found {
+		e.value = value
+		cache.use(e)
+		return
+	}
+	if len(cache.table) >= cache.capacity {
+		cache.evict()
+	}
+	first := cache.buckets.next
+	if first == &cache.buckets || first.count != 1 {
+		first = cache.insertBucket(1, &cache.buckets)
+	}
+	e := &entry{key: key, value: value}
+	cache.table[key] = e
+	first.pushFront(e)
+	cache.age()
+}
+
+// Get searches the key in the cache and returns its value or nil if key is not found.
+// Second return parameter is true if key was found, otherwise false.
+// A found pair counts as used. The lookup is counted as a hit or a miss.
+func (cache *Cache) Get(key interface{}) (value interface{}, found bool) {
+	e, found := cache.table[key]
+	if !found {
+		cache.stats.Misses++
+		return nil, false
+	}
+	cache.stats.Hits++
+	cache.use(e)
+	return e.value, true
+}
+
+// Peek searches the key in the cache like Get, but neither counts it as a use of the pair nor counts the lookup.
+func (cache *Cache) Peek(key interface{}) (value interface{}, found bool) {
+	if e, found := cache.table[key]; found {
+		return e.value, true
+	}
+	return nil, false
+}
+
+// Frequency returns the use count of the key, 0 if key is not found.
+func (cache *Cache) Frequency(key interface{}) int {
+	if e, found := cache.table[key]; found {
+		return e.bucket.count
+	}
+	return 0
+}
+
+// Remove removes the pair from the cache by key, without calling OnEvict.
+func (cache *Cache) Remove(key interface{}) {
+	if e, found := cache.table[key]; found {
+		cache.unlink(e)
+		delete(cache.table, key)
+	}
+}
+
+// Decay halves the use counts of all pairs, keeping them at least 1, in O(b) for b distinct counts.
+// Pairs whose counts become equal keep their order, the ones that were used more often counting as more recent.
+func (cache *Cache) Decay() {
+	for b := cache.buckets.next; b != &cache.buckets; {
+		next := b.next
+		b.count /= 2
+		if b.count < 1 {
+			b.count = 1
+		}
+		if prev := b.prev; prev != &cache.buckets && prev.count == b.count {
+			// move the pairs of the bucket in front of the ones of the previous bucket
+			for e := b.head.next; e != &b.head; e = e.next {
+				e.bucket = prev
+			}
+			last, first := b.head.prev, b.head.next
+			last.next, prev.head.next.prev = prev.head.next, last
+			prev.head.next, first.prev = first, &prev.head
+			cache.removeBucket(b)
+		}
+		b = next
+	}
+	cache.uses = 0
+}
+
+// Capacity returns the maximum number of pairs.
+func (cache *Cache) Capacity() int {
+	return cache.capacity
+}
+
+// Stats returns the number of hits, misses and evictions since the cache was created.
+func (cache *Cache) Stats() maps.CacheStats {
+	return cache.stats
+}
+
+// Empty returns true if cache does not contain any elements
+func (cache *Cache) Empty() bool {
+	return cache.Size() == 0
+}
+
+// Size returns number of elements in the cache.
+func (cache *Cache) Size() int {
+	return len(cache.table)
+}
+
+// Keys returns all keys from the most to the least frequently used one, pairs of equal counts from the most to the
+// least recently used one.
+func (cache *Cache) Keys() []interface{} {
+	keys := make([]interface{}, 0, cache.Size())
+	cache.each(func(e *entry) {
+		keys = append(keys, e.key)
+	})
+	return keys
+}
+
+// Values returns all values in the order of Keys.
+func (cache *Cache) Values() []interface{} {
+	values := make([]interface{}, 0, cache.Size())
+	cache.each(func(e *entry) {
+		values = append(values, e.value)
+	})
+	return values
+}
+
+// Clear removes all elements from the cache, without calling OnEvict. The statistics are kept.
+func (cache *Cache) Clear() {
+	cache.table = make(map[interface{}]*entry)
+	cache.buckets.prev, cache.buckets.next = &cache.buckets, &cache.buckets
+	cache.uses = 0
+}
+
+// String returns a string representation of container
+func (cache *Cache) String() string {
+	str := "LFUCache\nmap["
+	cache.each(func(e *entry) {
+		str += fmt.Sprintf("%v:%v ", e.key, e.value)
+	})
+	return strings.TrimRight(str, " ") + "]"
+}
+
+// use moves the pair to the bucket of the next count.
+func (cache *Cache) use(e *entry) {
+	current := e.bucket
+	next := current.next
+	if next == &cache.buckets || next.count != current.count+1 {
+		next = cache.insertBucket(current.count+1, current)
+	}
+	cache.unlink(e)
+	next.pushFront(e)
+	cache.age()
+}
+
+// age counts a use and halves the counts if the period is over.
+func (cache *Cache) age() {
+	if cache.period == 0 {
+		return
+	}
+	cache.uses++
+	if cache.uses >= cache.period {
+		cache.Decay()
+	}
+}
+
+// evict removes the least recently used pair of the bucket of the smallest count.
+func (cache *Cache) evict() {
+	e := cache.buckets.next.head.prev
+	cache.unlink(e)
+	delete(cache.table, e.key)
+	cache.stats.Evictions++
+	if cache.OnEvict != nil {
+		cache.OnEvict(e.key, e.value)
+	}
+}
+
+// each calls the function for every pair in the order of Keys.
+func (cache *Cache) each(f func(e *entry)) {
+	for b := cache.buckets.prev; b != &cache.buckets; b = b.prev {
+		for e := b.head.next; e != &b.head; e = e.next {
+			f(e)
+		}
+	}
+}
+
+// insertBucket inserts an empty bucket for the count after the given bucket.
+func (cache *Cache) insertBucket(count int, after *bucket) *bucket {
+	b := &bucket{count: count, prev: after, next: after.next}
+	b.head.prev, b.head.next = &b.head, &b.head
+	after.next.prev = b
+	after.next = b
+	return b
+}
+
+func (cache *Cache) removeBucket(b *bucket) {
+	b.prev.next = b.next
+	b.next.prev = b.prev
+	b.prev, b.next = nil, nil
+}
+
+// unlink removes the pair from its bucket, and the bucket if it became empty.
+func (cache *Cache) unlink(e *entry) {
+	e.prev.next = e.next
+	e.next.prev = e.prev
+	if b := e.bucket; b.head.next == &b.head {
+		cache.removeBucket(b)
+	}
+	e.prev, e.next, e.bucket = nil, nil, nil
+}
+
+func (b *bucket) pushFront(e *entry) {
+	e.bucket = b
+	e.prev, e.next = &b.head, b.head.next
+	e.next.prev = e
+	b.head.next = e
+}
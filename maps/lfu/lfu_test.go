@@ -0,0 +1,227 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lfu
+
+import (
+	"fmt"
+	"github.com/dairongpeng/gds/maps"
+	"math/rand"
+	"testing"
+)
+
+func TestCachePut(t *testing.T) {
+	cache := New(3)
+	evicted := []interface{}{}
+	cache.OnEvict = func(key interface{}, value interface{}) {
+		evicted = append(evicted, key)
+	}
+	cache.Put(1, "a")
+	cache.Put(2, "b")
+	cache.Put(3, "c")
+	cache.Put(1, "x") //overwrite
+	cache.Put(3, "c")
+	cache.Put(3, "c")
+	if actualValue, expectedValue := fmt.Sprint(cache.Keys()), "[3 1 2]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	cache.Put(4, "d")
+	cache.Put(5, "e")
+	if actualValue, expectedValue := fmt.Sprint(cache.Keys()), "[3 1 5]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue, expectedValue := fmt.Sprint(cache.Values()), "[c x e]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue, expectedValue := fmt.Sprint(evicted), "[2 4]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue, expectedValue := cache.String(), "LFUCache\nmap[3:c 1:x 5:e]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue, expectedValue := fmt.Sprint(cache.Frequency(3), cache.Frequency(1), cache.Frequency(5), cache.Frequency(2)), "3 2 1 0"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+}
+
+func TestCacheGetPeek(t *testing.T) {
+	cache := New(2)
+	cache.Put(1, "a")
+	cache.Put(2, "b")
+
+	if actualValue, found := cache.Get(1); actualValue != "a" || !found {
+		t.Errorf("Got %v expected %v", actualValue, "a")
+	}
+	if actualValue, found := cache.Get(3); actualValue != nil || found {
+		t.Errorf("Got %v expected %v", actualValue, nil)
+	}
+	if actualValue, found := cache.Peek(2); actualValue != "b" || !found {
+		t.Errorf("Got %v expected %v", actualValue, "b")
+	}
+	if actualValue, expectedValue := cache.Frequency(2), 1; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	cache.Put(3, "c")
+	if actualValue, found := cache.Peek(2); actualValue != nil || found {
+		t.Errorf("Got %v expected %v", actualValue, nil)
+	}
+	if actualValue, expectedValue := cache.Stats(), (maps.CacheStats{Hits: 1, Misses: 1, Evictions: 1}); actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+}
+
+func TestCacheScan(t *testing.T) {
+	cache := New(10)
+	for round := 0; round < 3; round++ {
+		for key := 0; key < 5; key++ {
+			cache.Put(key, key)
+		}
+	}
+	// a scan of keys used once does not evict the hot set
+	for key := 100; key < 200; key++ {
+		cache.Put(key, key)
+	}
+	for key := 0; key < 5; key++ {
+		if _, found := cache.Get(key); !found {
+			t.Errorf("Got %v expected %v for %v", found, true, key)
+		}
+	}
+	if actualValue, expectedValue := cache.Size(), 10; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+}
+
+func TestCacheRemove(t *testing.T) {
+	cache := New(3)
+	cache.Put(1, "a")
+	cache.Put(2, "b")
+	cache.Get(2)
+	cache.Remove(2)
+	cache.Remove(4)
+	cache.Put(3, "c")
+	cache.Put(4, "d")
+	if actualValue, expectedValue := fmt.Sprint(cache.Keys()), "[4 3 1]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	cache.Clear()
+	if actualValue := cache.Empty(); actualValue != true {
+		t.Errorf("Got %v expected %v", actualValue, true)
+	}
+	cache.Put(5, "e")
+	if actualValue, expectedValue := fmt.Sprint(cache.Keys(), cache.Frequency(5)), "[5] 1"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+}
+
+func TestCacheDecay(t *testing.T) {
+	cache := New(4)
+	for key, uses := range []int{1, 2, 3, 8} {
+		for i := 0; i < uses; i++ {
+			cache.Put(key, key)
+		}
+	}
+	cache.Decay()
+	if actualValue, expectedValue := fmt.Sprint(cache.Frequency(0), cache.Frequency(1), cache.Frequency(2), cache.Frequency(3)), "1 1 1 4"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue, expectedValue := fmt.Sprint(cache.Keys()), "[3 2 1 0]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	cache.Put(4, 4)
+	if actualValue, expectedValue := fmt.Sprint(cache.Keys()), "[3 4 2 1]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+
+	// the counts are halved after every 4 uses
+	cache = NewWithDecay(2, 4)
+	cache.Put(1, 1)
+	cache.Get(1)
+	cache.Get(1)
+	if actualValue, expectedValue := cache.Frequency(1), 3; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	cache.Put(2, 2)
+	if actualValue, expectedValue := fmt.Sprint(cache.Frequency(1), cache.Frequency(2)), "1 1"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+}
+
+func TestCacheRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	cache := NewWithDecay(50, 300)
+	for i := 0; i < 10000; i++ {
+		key := r.Intn(200)
+		switch r.Intn(4) {
+		case 0:
+			cache.Remove(key)
+		case 1:
+			cache.Get(key)
+		default:
+			cache.Put(key, key)
+		}
+		if i%500 == 0 {
+			assertValidCache(t, cache)
+		}
+	}
+	assertValidCache(t, cache)
+}
+
+// assertValidCache checks that the buckets hold all pairs, are not empty and are ordered by count.
+func assertValidCache(t *testing.T, cache *Cache) {
+	count := 0
+	previous := 0
+	for b := cache.buckets.next; b != &cache.buckets; b = b.next {
+		if b.count <= previous {
+			t.Errorf("Got %v expected a count larger than %v", b.count, previous)
+		}
+		previous = b.count
+		if b.head.next == &b.head {
+			t.Errorf("Got an empty bucket for %v", b.count)
+		}
+		for e := b.head.next; e != &b.head; e = e.next {
+			if e.bucket != b || e.next.prev != e || cache.table[e.key] != e {
+				t.Errorf("Got an inconsistent entry for %v", e.key)
+			}
+			count++
+		}
+	}
+	if count != cache.Size() || count > cache.Capacity() {
+		t.Errorf("Got %v expected %v", count, cache.Size())
+	}
+}
+
+func benchmarkPut(b *testing.B, cache *Cache, size int) {
+	for i := 0; i < b.N; i++ {
+		for n := 0; n < size; n++ {
+			cache.Put(n, n)
+		}
+	}
+}
+
+func benchmarkGet(b *testing.B, cache *Cache, size int) {
+	for i := 0; i < b.N; i++ {
+		for n := 0; n < size; n++ {
+			cache.Get(n)
+		}
+	}
+}
+
+func BenchmarkLFUCachePut10000(b *testing.B) {
+	b.StopTimer()
+	size := 10000
+	cache := New(size / 2)
+	b.StartTimer()
+	benchmarkPut(b, cache, size)
+}
+
+func BenchmarkLFUCacheGet10000(b *testing.B) {
+	b.StopTimer()
+	size := 10000
+	cache := New(size / 2)
+	for n := 0; n < size; n++ {
+		cache.Put(n, n)
+	}
+	b.StartTimer()
+	benchmarkGet(b, cache, size)
+}
@@ -11,19 +11,20 @@ import (
 
 func assertIteratorImplementation() {
 	var _ containers.ReverseIteratorWithKey = (*Iterator)(nil)
+	var _ containers.IteratorWithRemove = (*Iterator)(nil)
 }
 
 // Iterator holding the iterator's state
 type Iterator struct {
 	iterator doublylinkedlist.Iterator
-	table    map[interface{}]interface{}
+	m        *Map
 }
 
 // Iterator returns a stateful iterator whose elements are key/value pairs.
 func (m *Map) Iterator() Iterator {
 	return Iterator{
 		iterator: m.ordering.Iterator(),
-		table:    m.table}
+		m:        m}
 }
 
 // Next moves the iterator to the next element and returns true if there was a next element in the container.
@@ -45,7 +46,7 @@ func (iterator *Iterator) Prev() bool {
 // Does not modify the state of the iterator.
 func (iterator *Iterator) Value() interface{} {
 	key := iterator.iterator.Value()
-	return iterator.table[key]
+	return iterator.m.table[key]
 }
 
 // Key returns the current element's key.
@@ -79,3 +80,12 @@ func (iterator *Iterator) First() bool {
 func (iterator *Iterator) Last() bool {
 	return iterator.iterator.Last()
 }
+
+// Remove removes the current element from the map.
+// Next() moves the iterator to the element after the removed one and Prev() to the element before it.
+// Panics with containers.ErrIteratorNotOnElement if the iterator is not on an element.
+func (iterator *Iterator) Remove() {
+	iterator.iterator.Remove()
+	// the list iterator holds on to the removed key until it is moved
+	delete(iterator.m.table, iterator.iterator.Value())
+}
@@ -6,6 +6,7 @@ package linkedhashmap
 
 import (
 	"fmt"
+	"github.com/dairongpeng/gds/containers"
 	"testing"
 )
 
@@ -440,6 +441,45 @@ func TestMapIteratorLast(t *testing.T) {
 	}
 }
 
+func TestMapIteratorRemove(t *testing.T) {
+	m := New()
+	m.Put("c", 3)
+	m.Put("a", 1)
+	m.Put("b", 2)
+	it := m.Iterator()
+	for it.Next() {
+		if it.Key() != "a" {
+			it.Remove()
+		}
+	}
+	if actualValue, expectedValue := fmt.Sprint(m.Keys()), "[a]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if _, found := m.Get("c"); found {
+		t.Errorf("Got %v expected %v", found, false)
+	}
+	if actualValue, expectedValue := m.Size(), 1; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+
+	// updating a value is not a structural modification
+	it.Begin()
+	it.Next()
+	m.Put("a", 4)
+	if actualValue, expectedValue := it.Value(), 4; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	func() {
+		defer func() {
+			if r := recover(); r != containers.ErrConcurrentModification {
+				t.Errorf("Got %v expected %v", r, containers.ErrConcurrentModification)
+			}
+		}()
+		m.Put("d", 5)
+		it.Next()
+	}()
+}
+
 func TestMapSerialization(t *testing.T) {
 	for i := 0; i < 10; i++ {
 		original := New()
@@ -0,0 +1,221 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package lru implements a cache that evicts the least recently used pairs.
+//
+// It is backed by a hash table to find the pairs and a doubly-linked list to keep them in the order of their last
+// use, so Get, Put and the eviction take O(1). Get and Put count as a use of the pair, Peek does not.
+//
+// The capacity is a number of pairs, or a total cost if the cache was created with a cost function,
+// e.g. to bound the memory held by the values.
+//
+// Structure is not thread safe.
+//
+// Reference: https://en.wikipedia.org/wiki/Cache_replacement_policies#Least_recently_used_(LRU)
+package lru
+
+import (
+	"fmt"
+	"github.com/dairongpeng/gds/maps"
+	"strings"
+)
+
+func assertCacheImplementation() {
+	var _ maps.Cache = (*Cache)(nil)
+}
+
+// CostFunc returns the cost of a pair, which should not be negative.
+type CostFunc func(key interface{}, value interface{}) int
+
+// Cache holds the pairs in a hash table and in a doubly-linked list from the most to the least recently used one.
+// Cache LRU缓存，哈希表定位元素，双向链表维护元素最近一次使用的顺序，容量不足时淘汰最久未使用的元素
+type Cache struct {
+	OnEvict  maps.EvictionCallback // Called with every pair evicted to make room, if set
+	table    map[interface{}]*entry
+	head     entry // sentinel of the circular list, followed by the most recently used pair
+	capacity int
+	cost     CostFunc
+	total    int // sum of the costs of the pairs
+	stats    maps.CacheStats
+}
+
+type entry struct {
+	key   interface{}
+	value interface{}
+	cost  int
+	prev  *entry
+	next  *entry
+}
+
+// New instantiates a cache holding at most capacity pairs.
+// Capacity should be positive, otherwise method panics.
+func New(capacity int) *Cache {
+	return NewWithCost(capacity, nil)
+}
+
+// NewWithCost instantiates a cache whose pairs cost at most capacity in total, according to the cost function.
+// A nil cost function counts every pair as 1.
+// Capacity should be positive, otherwise method panics.
+func NewWithCost(capacity int, cost CostFunc) *Cache {
+	if capacity < 1 {
+		panic("Invalid capacity, must be positive")
+	}
+	cache := &Cache{table: make(map[interface{}]*entry), capacity: capacity, cost: cost}
+	cache.head.prev, cache.head.next = &cache.head, &cache.head
+	return cache
+}
+
+// Put inserts the pair into the cache, or replaces the value of the key, and marks it as the most recently used one.
+// Then the least recently used pairs are evicted until the total cost fits the capacity again,
+// which evicts the pair itself if its cost alone exceeds the capacity.
+// Key should be comparable, i.e. usable as a key of a Go map, otherwise method panics.
+func (cache *Cache) Put(key interface{}, value interface{}) {
+	cost := 1
+	if cache.cost != nil {
+		cost = cache.cost(key, value)
+	}
+	if e, found := cache.table[key]; found {
+		cache.total += cost - e.cost
+		e.value, e.cost = value, cost
+		cache.unlink(e)
+		cache.pushFront(e)
+	} else {
+		e := &entry{key: key, value: value, cost: cost}
+		cache.table[key] = e
+		cache.total += cost
+		cache.pushFront(e)
+	}
+	cache.evict()
+}
+
+// Get searches the key in the cache and returns its value or nil if key is not found.
+// Second return parameter is true if key was found, otherwise false.
+// A found pair becomes the most recently used one. The lookup is counted as a hit or a miss.
+func (cache *Cache) Get(key interface{}) (value interface{}, found bool) {
+	e, found := cache.table[key]
+	if !found {
+		cache.stats.Misses++
+		return nil, false
+	}
+	cache.stats.Hits++
+	cache.unlink(e)
+	cache.pushFront(e)
+	return e.value, true
+}
+
+// Peek searches the key in the cache like Get, but neither changes the order of the pairs nor counts the lookup.
+func (cache *Cache) Peek(key interface{}) (value interface{}, found bool) {
+	if e, found := cache.table[key]; found {
+		return e.value, true
+	}
+	return nil, false
+}
+
+// Remove removes the pair from the cache by key, without calling OnEvict.
+func (cache *Cache) Remove(key interface{}) {
+	if e, found := cache.table[key]; found {
+		cache.unlink(e)
+		delete(cache.table, key)
+		cache.total -= e.cost
+	}
+}
+
+// Resize changes the capacity and evicts the least recently used pairs until the total cost fits it.
+// Returns the number of evicted pairs.
+// Capacity should be positive, otherwise method panics.
+func (cache *Cache) Resize(capacity int) int {
+	if capacity < 1 {
+		panic("Invalid capacity, must be positive")
+	}
+	cache.capacity = capacity
+	return cache.evict()
+}
+
+// Capacity returns the maximum number of pairs, or the maximum total cost if the cache has a cost function.
+func (cache *Cache) Capacity() int {
+	return cache.capacity
+}
+
+// Cost returns the total cost of the pairs, which is their number if the cache has no cost function.
+func (cache *Cache) Cost() int {
+	return cache.total
+}
+
+// Stats returns the number of hits, misses and evictions since the cache was created.
+func (cache *Cache) Stats() maps.CacheStats {
+	return cache.stats
+}
+
+// Empty returns true if cache does not contain any elements
+func (cache *Cache) Empty() bool {
+	return cache.Size() == 0
+}
+
+// Size returns number of elements in the cache.
+func (cache *Cache) Size() int {
+	return len(cache.table)
+}
+
+// Keys returns all keys from the most to the least recently used one.
+func (cache *Cache) Keys() []interface{} {
+	keys := make([]interface{}, 0, cache.Size())
+	for e := cache.head.next; e != &cache.head; e = e.next {
+		keys = append(keys, e.key)
+	}
+	return keys
+}
+
+// Values returns all values from the most to the least recently used one.
+func (cache *Cache) Values() []interface{} {
+	values := make([]interface{}, 0, cache.Size())
+	for e := cache.head.next; e != &cache.head; e = e.next {
+		values = append(values, e.value)
+	}
+	return values
+}
+
+// Clear removes all elements from the cache, without calling OnEvict. The statistics are kept.
+func (cache *Cache) Clear() {
+	cache.table = make(map[interface{}]*entry)
+	cache.head.prev, cache.head.next = &cache.head, &cache.head
+	cache.total = 0
+}
+
+// String returns a string representation of container
+func (cache *Cache) String() string {
+	str := "LRUCache\nmap["
+	for e := cache.head.next; e != &cache.head; e = e.next {
+		str += fmt.Sprintf("%v:%v ", e.key, e.value)
+	}
+	return strings.TrimRight(str, " ") + "]"
+}
+
+// evict removes the least recently used pairs until the total cost fits the capacity and returns their number.
+func (cache *Cache) evict() int {
+	count := 0
+	for cache.total > cache.capacity {
+		e := cache.head.prev
+		cache.unlink(e)
+		delete(cache.table, e.key)
+		cache.total -= e.cost
+		cache.stats.Evictions++
+		count++
+		if cache.OnEvict != nil {
+			cache.OnEvict(e.key, e.value)
+		}
+	}
+	return count
+}
+
+func (cache *Cache) pushFront(e *entry) {
+	e.prev, e.next = &cache.head, cache.head.next
+	e.next.prev = e
+	cache.head.next = e
+}
+
+func (cache *Cache) unlink(e *entry) {
+	e.prev.next = e.next
+	e.next.prev = e.prev
+	e.prev, e.next = nil, nil
+}
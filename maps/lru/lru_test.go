@@ -0,0 +1,246 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lru
+
+import (
+	"fmt"
+	"github.com/dairongpeng/gds/maps"
+	"testing"
+)
+
+func TestCachePut(t *testing.T) {
+	cache := New(3)
+	evicted := []interface{}{}
+	cache.OnEvict = func(key interface{}, value interface{}) {
+		evicted = append(evicted, key)
+	}
+	cache.Put(1, "a")
+	cache.Put(2, "b")
+	cache.Put(3, "c")
+	cache.Put(1, "x") //overwrite
+	if actualValue, expectedValue := fmt.Sprint(cache.Keys()), "[1 3 2]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	cache.Put(4, "d")
+	cache.Put(5, "e")
+	if actualValue, expectedValue := fmt.Sprint(cache.Keys()), "[5 4 1]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue, expectedValue := fmt.Sprint(cache.Values()), "[e d x]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue, expectedValue := fmt.Sprint(evicted), "[2 3]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue, expectedValue := cache.String(), "LRUCache\nmap[5:e 4:d 1:x]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue := cache.Size(); actualValue != 3 {
+		t.Errorf("Got %v expected %v", actualValue, 3)
+	}
+}
+
+func TestCacheGetPeek(t *testing.T) {
+	cache := New(3)
+	cache.Put(1, "a")
+	cache.Put(2, "b")
+	cache.Put(3, "c")
+
+	if actualValue, found := cache.Get(1); actualValue != "a" || !found {
+		t.Errorf("Got %v expected %v", actualValue, "a")
+	}
+	if actualValue, found := cache.Peek(2); actualValue != "b" || !found {
+		t.Errorf("Got %v expected %v", actualValue, "b")
+	}
+	if actualValue, found := cache.Get(4); actualValue != nil || found {
+		t.Errorf("Got %v expected %v", actualValue, nil)
+	}
+	if actualValue, found := cache.Peek(4); actualValue != nil || found {
+		t.Errorf("Got %v expected %v", actualValue, nil)
+	}
+	// 2 was peeked only, so it is the least recently used pair
+	cache.Put(4, "d")
+	if actualValue, expectedValue := fmt.Sprint(cache.Keys()), "[4 1 3]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue, expectedValue := cache.Stats(), (maps.CacheStats{Hits: 1, Misses: 1, Evictions: 1}); actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue, expectedValue := cache.Stats().HitRatio(), 0.5; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+}
+
+func TestCacheRemove(t *testing.T) {
+	cache := New(3)
+	evictions := 0
+	cache.OnEvict = func(key interface{}, value interface{}) {
+		evictions++
+	}
+	cache.Put(1, "a")
+	cache.Put(2, "b")
+	cache.Put(3, "c")
+	cache.Remove(2)
+	cache.Remove(4)
+	cache.Put(4, "d")
+	if actualValue, expectedValue := fmt.Sprint(cache.Keys()), "[4 3 1]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	cache.Clear()
+	if actualValue := cache.Empty(); actualValue != true {
+		t.Errorf("Got %v expected %v", actualValue, true)
+	}
+	if actualValue := evictions; actualValue != 0 {
+		t.Errorf("Got %v expected %v", actualValue, 0)
+	}
+	cache.Put(5, "e")
+	if actualValue, expectedValue := fmt.Sprint(cache.Keys()), "[5]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+}
+
+func TestCacheCost(t *testing.T) {
+	cache := NewWithCost(10, func(key interface{}, value interface{}) int {
+		return len(value.(string))
+	})
+	cache.Put("a", "1234")
+	cache.Put("b", "123")
+	cache.Put("c", "12")
+	if actualValue, expectedValue := cache.Cost(), 9; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	cache.Put("b", "12345")
+	if actualValue, expectedValue := fmt.Sprint(cache.Keys()), "[b c]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue, expectedValue := cache.Cost(), 7; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+
+	// a pair costing more than the capacity does not stay
+	cache.Put("d", "12345678901")
+	if actualValue, expectedValue := fmt.Sprint(cache.Keys()), "[]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue, expectedValue := cache.Stats().Evictions, 4; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+}
+
+func TestCacheResize(t *testing.T) {
+	cache := New(5)
+	for i := 1; i <= 5; i++ {
+		cache.Put(i, i)
+	}
+	if actualValue, expectedValue := cache.Resize(2), 3; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue, expectedValue := fmt.Sprint(cache.Keys()), "[5 4]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue, expectedValue := cache.Resize(4), 0; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	cache.Put(6, 6)
+	cache.Put(7, 7)
+	if actualValue, expectedValue := fmt.Sprint(cache.Keys()), "[7 6 5 4]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue, expectedValue := cache.Capacity(), 4; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Got %v expected a panic", r)
+		}
+	}()
+	cache.Resize(0)
+}
+
+func TestCacheSerialization(t *testing.T) {
+	original := New(3)
+	original.Put("c", "3")
+	original.Put("b", "2")
+	original.Put("a", "1")
+	original.Get("c")
+
+	serialized, err := original.ToJSON()
+	if err != nil {
+		t.Errorf("Got error %v", err)
+	}
+	if actualValue, expectedValue := string(serialized), `{"c":"3","a":"1","b":"2"}`; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+
+	deserialized := New(2)
+	err = deserialized.FromJSON(serialized)
+	if err != nil {
+		t.Errorf("Got error %v", err)
+	}
+	if actualValue, expectedValue := deserialized.String(), "LRUCache\nmap[c:3 a:1]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if err := deserialized.FromJSON([]byte(`["a"]`)); err == nil {
+		t.Errorf("Got %v expected an error", err)
+	}
+}
+
+func TestCacheSerializationCost(t *testing.T) {
+	cache := NewWithCost(10, func(key interface{}, value interface{}) int {
+		// decoded values are generic JSON values
+		if text, ok := value.(string); ok {
+			return len(text)
+		}
+		return int(value.(map[string]interface{})["cost"].(float64))
+	})
+	err := cache.FromJSON([]byte(`{"a":"1234","b":{"cost":5},"c":"12","d":"123"}`))
+	if err != nil {
+		t.Errorf("Got error %v", err)
+	}
+	if actualValue, expectedValue := fmt.Sprint(cache.Keys()), "[a b]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue, expectedValue := cache.Cost(), 9; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue, expectedValue := cache.Stats().Evictions, 2; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+}
+
+func benchmarkPut(b *testing.B, cache *Cache, size int) {
+	for i := 0; i < b.N; i++ {
+		for n := 0; n < size; n++ {
+			cache.Put(n, n)
+		}
+	}
+}
+
+func benchmarkGet(b *testing.B, cache *Cache, size int) {
+	for i := 0; i < b.N; i++ {
+		for n := 0; n < size; n++ {
+			cache.Get(n)
+		}
+	}
+}
+
+func BenchmarkLRUCachePut10000(b *testing.B) {
+	b.StopTimer()
+	size := 10000
+	cache := New(size / 2)
+	b.StartTimer()
+	benchmarkPut(b, cache, size)
+}
+
+func BenchmarkLRUCacheGet10000(b *testing.B) {
+	b.StopTimer()
+	size := 10000
+	cache := New(size / 2)
+	for n := 0; n < size; n++ {
+		cache.Put(n, n)
+	}
+	b.StartTimer()
+	benchmarkGet(b, cache, size)
+}
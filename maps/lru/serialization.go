@@ -0,0 +1,83 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lru
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/dairongpeng/gds/containers"
+	"github.com/dairongpeng/gds/utils"
+)
+
+func assertSerializationImplementation() {
+	var _ containers.JSONSerializer = (*Cache)(nil)
+	var _ containers.JSONDeserializer = (*Cache)(nil)
+}
+
+// ToJSON outputs the JSON representation of the cache, whose pairs are ordered from the most to the least recently
+// used one. Keys are converted to strings, see utils.ToString.
+func (cache *Cache) ToJSON() ([]byte, error) {
+	buf := bytes.NewBuffer(nil)
+	buf.WriteRune('{')
+	for e := cache.head.next; e != &cache.head; e = e.next {
+		if e != cache.head.next {
+			buf.WriteRune(',')
+		}
+		km, err := json.Marshal(utils.ToString(e.key))
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(km)
+		buf.WriteRune(':')
+		vm, err := json.Marshal(e.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(vm)
+	}
+	buf.WriteRune('}')
+	return buf.Bytes(), nil
+}
+
+// FromJSON populates the cache from the input JSON representation, whose pairs are ordered from the most to the
+// least recently used one. Pairs beyond the capacity are evicted, the statistics are kept.
+// Keys are decoded as strings and values as generic JSON values (e.g. numbers as float64, objects as
+// map[string]interface{}), which the CostFunc of the cache is called with.
+func (cache *Cache) FromJSON(data []byte) error {
+	keys, values, err := decodeObject(data)
+	if err != nil {
+		return err
+	}
+	cache.Clear()
+	for i := len(keys) - 1; i >= 0; i-- {
+		cache.Put(keys[i], values[i])
+	}
+	return nil
+}
+
+// decodeObject returns the keys and values of the JSON object in the order they appear.
+func decodeObject(data []byte) (keys []interface{}, values []interface{}, err error) {
+	// reject anything but an object with the errors of encoding/json
+	if err = json.Unmarshal(data, &map[string]interface{}{}); err != nil {
+		return nil, nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if _, err = decoder.Token(); err != nil {
+		return nil, nil, err
+	}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, nil, err
+		}
+		var value interface{}
+		if err := decoder.Decode(&value); err != nil {
+			return nil, nil, err
+		}
+		keys, values = append(keys, token), append(values, value)
+	}
+	_, err = decoder.Token()
+	return keys, values, err
+}
@@ -37,3 +37,31 @@ type BidiMap interface {
 
 	Map
 }
+
+// Cache interface that all caches implement (extends the Map interface)
+// A cache holds a bounded number of pairs and evicts pairs according to its policy to make room for new ones.
+// Get counts as a use of the pair and updates the statistics, Peek does neither.
+type Cache interface {
+	Peek(key interface{}) (value interface{}, found bool)
+	Stats() CacheStats
+
+	Map
+}
+
+// CacheStats counts the lookups and evictions of a cache since it was created.
+type CacheStats struct {
+	Hits      int // Lookups by Get that found the key
+	Misses    int // Lookups by Get that did not find the key
+	Evictions int // Pairs evicted to make room, not counting the removed or replaced ones
+}
+
+// HitRatio returns the share of the lookups that found the key, 0 if there were no lookups.
+func (stats CacheStats) HitRatio() float64 {
+	if stats.Hits+stats.Misses == 0 {
+		return 0
+	}
+	return float64(stats.Hits) / float64(stats.Hits+stats.Misses)
+}
+
+// EvictionCallback is called with every pair a cache evicts to make room.
+type EvictionCallback func(key interface{}, value interface{})
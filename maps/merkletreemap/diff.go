@@ -0,0 +1,52 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package merkletreemap
+
+// diffFanout is the number of ranges a differing range is split into by Diff.
+const diffFanout = 16
+
+// Diff returns the consecutive key ranges in which the pairs of the maps differ, in key order.
+// Every returned range holds at least one key whose pair is missing from one of the maps or has another value,
+// and every such key lies within a returned range. Adjacent ranges are merged.
+//
+// Ranges whose hashes differ are split by the map holding more keys within them, until a differing range
+// holds at most one key of either map, so the cost is about O(d log^2 n) for d differing keys.
+// Both maps must use the same comparator and hasher.
+// Diff 自顶向下比较两个表的区间哈希，返回存在差异的key区间
+func Diff(a *Map, b *Map) []Range {
+	var ranges []Range
+	diff(a, b, Range{}, &ranges)
+	return ranges
+}
+
+func diff(a *Map, b *Map, r Range, ranges *[]Range) {
+	sa, sb := a.summarizeRange(r), b.summarizeRange(r)
+	if sa == sb {
+		return
+	}
+	larger, size := a, sa.size
+	if sb.size > size {
+		larger, size = b, sb.size
+	}
+	if size < 2 {
+		appendRange(a, ranges, r)
+		return
+	}
+	for _, child := range larger.Split(r, diffFanout) {
+		diff(a, b, child, ranges)
+	}
+}
+
+// appendRange appends the range, merging it with the last range if they are adjacent.
+func appendRange(m *Map, ranges *[]Range, r Range) {
+	if n := len(*ranges); n > 0 {
+		last := &(*ranges)[n-1]
+		if last.To != nil && r.From != nil && m.tree.Comparator(last.To, r.From) == 0 {
+			last.To = r.To
+			return
+		}
+	}
+	*ranges = append(*ranges, r)
+}
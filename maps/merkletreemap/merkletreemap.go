@@ -0,0 +1,305 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package merkletreemap implements a tree map with a Merkle index over its key ranges.
+//
+// Every key-value pair is hashed with SHA-256 and every node of the underlying red-black tree keeps the number of
+// pairs and the sum (modulo 2^256) of their digests within its subtree. The sum does not depend on the shape of
+// the tree, so two maps holding the same pairs have the same hash for every key range, no matter in which order
+// the pairs were put. The index is updated along with the tree on Put and Remove in O(log n), and the hash of any
+// key range is computed in O(log n).
+//
+// Replicas are compared top-down: starting with the root hash, a range whose hashes differ is split into smaller
+// ranges (Split), whose hashes (RangeHash) are compared in turn, until the differing ranges are small enough to be
+// exchanged. Diff does this for two maps at hand.
+//
+// Summing the digests is the AdHash construction, which is not collision resistant: with generalized birthday
+// attacks a set of pairs whose digests sum to a given range hash can be found far faster than a SHA-256 collision.
+// The hashes detect accidental divergence between replicas that trust each other, they must not be used to
+// verify data from an untrusted party.
+//
+// Structure is not thread safe.
+//
+// References: https://en.wikipedia.org/wiki/Merkle_tree
+package merkletreemap
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"github.com/dairongpeng/gds/maps"
+	rbt "github.com/dairongpeng/gds/trees/redblacktree"
+	"github.com/dairongpeng/gds/utils"
+	"strings"
+)
+
+func assertMapImplementation() {
+	var _ maps.Map = (*Map)(nil)
+}
+
+// Map holds the elements in a red-black tree whose nodes summarize the hashes of their subtrees.
+// Map 基于红黑树的有序表，每个节点维护以自身为头的子树中元素个数及元素哈希之和，用于副本之间的快速比对
+type Map struct {
+	tree   *rbt.Tree
+	hasher Hasher
+}
+
+// Hash is the SHA-256 digest of a key-value pair or the combined digest of the pairs within a key range.
+// Combined digests are sums, which can be forged, see the package documentation.
+type Hash [sha256.Size]byte
+
+// Hasher computes the digest of a key-value pair. Maps that are compared must use the same hasher.
+type Hasher func(key interface{}, value interface{}) Hash
+
+// Range is a range of keys, which includes From and excludes To.
+type Range struct {
+	From interface{} // Smallest key of the range, nil if the range is not bounded below
+	To   interface{} // Key following the range, nil if the range is not bounded above
+}
+
+// element is the value of a node in the tree, along with the digest of its pair.
+type element struct {
+	value interface{}
+	hash  Hash
+}
+
+// summary is the aggregate of a node, i.e. the number of pairs and the sum of their digests within its subtree.
+type summary struct {
+	size int
+	hash Hash
+}
+
+// NewWith instantiates a merkle tree map with the custom comparator.
+// Pairs are hashed by the string representations of the key and the value, see utils.ToString.
+func NewWith(comparator utils.Comparator) *Map {
+	return NewWithHasher(comparator, hashStrings)
+}
+
+// NewWithHasher instantiates a merkle tree map with the custom comparator and hasher.
+func NewWithHasher(comparator utils.Comparator, hasher Hasher) *Map {
+	m := &Map{hasher: hasher}
+	m.tree = rbt.NewWithAugmenter(comparator, summarize)
+	return m
+}
+
+// NewWithIntComparator instantiates a merkle tree map with the IntComparator, i.e. keys are of type int.
+func NewWithIntComparator() *Map {
+	return NewWith(utils.IntComparator)
+}
+
+// NewWithStringComparator instantiates a merkle tree map with the StringComparator, i.e. keys are of type string.
+func NewWithStringComparator() *Map {
+	return NewWith(utils.StringComparator)
+}
+
+// UsePool makes the map allocate its tree nodes slabSize at a time and reuse the nodes of removed keys,
+// see redblacktree.Tree.UsePool.
+func (m *Map) UsePool(slabSize int) {
+	m.tree.UsePool(slabSize)
+}
+
+// Put inserts key-value pair into the map and updates the hashes of the ranges containing the key.
+// Key should adhere to the comparator's type assertion, otherwise method panics.
+func (m *Map) Put(key interface{}, value interface{}) {
+	m.tree.Put(key, element{value: value, hash: m.hasher(key, value)})
+}
+
+// Get searches the element in the map by key and returns its value or nil if key is not found in tree.
+// Second return parameter is true if key was found, otherwise false.
+// Key should adhere to the comparator's type assertion, otherwise method panics.
+func (m *Map) Get(key interface{}) (value interface{}, found bool) {
+	if value, found := m.tree.Get(key); found {
+		return value.(element).value, true
+	}
+	return nil, false
+}
+
+// Remove removes the element from the map by key and updates the hashes of the ranges containing the key.
+// Key should adhere to the comparator's type assertion, otherwise method panics.
+func (m *Map) Remove(key interface{}) {
+	m.tree.Remove(key)
+}
+
+// Empty returns true if map does not contain any elements
+func (m *Map) Empty() bool {
+	return m.tree.Empty()
+}
+
+// Size returns number of elements in the map.
+func (m *Map) Size() int {
+	return m.tree.Size()
+}
+
+// Keys returns all keys in-order
+func (m *Map) Keys() []interface{} {
+	return m.tree.Keys()
+}
+
+// Values returns all values in-order based on the key.
+func (m *Map) Values() []interface{} {
+	values := m.tree.Values()
+	for i, value := range values {
+		values[i] = value.(element).value
+	}
+	return values
+}
+
+// Clear removes all elements from the map.
+func (m *Map) Clear() {
+	m.tree.Clear()
+}
+
+// RootHash returns the hash of all pairs in the map, which is the zero hash for an empty map.
+func (m *Map) RootHash() Hash {
+	return aggregate(m.tree.Root).hash
+}
+
+// RangeHash returns the hash of the pairs whose keys lie within the range in O(log n).
+// Bounds should adhere to the comparator's type assertion, otherwise method panics.
+func (m *Map) RangeHash(r Range) Hash {
+	return m.summarizeRange(r).hash
+}
+
+// RangeSize returns the number of pairs whose keys lie within the range in O(log n).
+// Bounds should adhere to the comparator's type assertion, otherwise method panics.
+func (m *Map) RangeSize(r Range) int {
+	return m.summarizeRange(r).size
+}
+
+// Split divides the range into at most n consecutive ranges that hold about the same number of keys of the map,
+// i.e. the children of the range in the Merkle index. The ranges cover the whole range and, apart from the first
+// one, start with a key of the map. A range holding fewer than two keys is not split.
+// Split 把区间按照本表中的key数量尽量均匀地切分为至多n个连续的子区间
+func (m *Map) Split(r Range, n int) []Range {
+	low, high := 0, m.tree.Size()
+	if r.From != nil {
+		low = m.before(r.From).size
+	}
+	if r.To != nil {
+		high = m.before(r.To).size
+	}
+	count := high - low
+	if n > count {
+		n = count
+	}
+	if n < 2 {
+		return []Range{r}
+	}
+	ranges := make([]Range, n)
+	from := r.From
+	for i := 1; i < n; i++ {
+		to := m.keyAt(low + i*count/n)
+		ranges[i-1] = Range{From: from, To: to}
+		from = to
+	}
+	ranges[n-1] = Range{From: from, To: r.To}
+	return ranges
+}
+
+// String returns a string representation of container
+func (m *Map) String() string {
+	str := "MerkleTreeMap\nmap["
+	it := m.tree.Iterator()
+	for it.Next() {
+		str += fmt.Sprintf("%v:%v ", it.Key(), it.Value().(element).value)
+	}
+	return strings.TrimRight(str, " ") + "]"
+}
+
+// summarizeRange returns the summary of the pairs within the range as the difference of the summaries of the pairs
+// before its bounds.
+func (m *Map) summarizeRange(r Range) summary {
+	s := aggregate(m.tree.Root)
+	if r.To != nil {
+		s = m.before(r.To)
+	}
+	if r.From != nil {
+		s = s.minus(m.before(r.From))
+	}
+	if s.size < 0 {
+		// the bounds are reversed
+		return summary{}
+	}
+	return s
+}
+
+// before returns the summary of the pairs whose keys are smaller than the key.
+func (m *Map) before(key interface{}) summary {
+	var s summary
+	for node := m.tree.Root; node != nil; {
+		if m.tree.Comparator(key, node.Key) <= 0 {
+			node = node.Left
+			continue
+		}
+		s = s.plus(aggregate(node.Left))
+		s = s.plus(summary{size: 1, hash: node.Value.(element).hash})
+		node = node.Right
+	}
+	return s
+}
+
+// keyAt returns the key with the rank (0-based) among the keys of the map, which must be within bounds.
+func (m *Map) keyAt(rank int) interface{} {
+	node := m.tree.Root
+	for {
+		left := aggregate(node.Left).size
+		switch {
+		case rank < left:
+			node = node.Left
+		case rank == left:
+			return node.Key
+		default:
+			rank -= left + 1
+			node = node.Right
+		}
+	}
+}
+
+// summarize is the augmenter of the tree.
+func summarize(node *rbt.Node) interface{} {
+	s := summary{size: 1, hash: node.Value.(element).hash}
+	return s.plus(aggregate(node.Left)).plus(aggregate(node.Right))
+}
+
+func aggregate(node *rbt.Node) summary {
+	if node == nil {
+		return summary{}
+	}
+	return node.Aggregate.(summary)
+}
+
+// plus adds the summaries, the hashes are added as 256-bit big-endian numbers (AdHash).
+func (s summary) plus(other summary) summary {
+	s.size += other.size
+	carry := 0
+	for i := len(s.hash) - 1; i >= 0; i-- {
+		sum := int(s.hash[i]) + int(other.hash[i]) + carry
+		s.hash[i] = byte(sum)
+		carry = sum >> 8
+	}
+	return s
+}
+
+func (s summary) minus(other summary) summary {
+	s.size -= other.size
+	borrow := 0
+	for i := len(s.hash) - 1; i >= 0; i-- {
+		difference := int(s.hash[i]) - int(other.hash[i]) - borrow
+		s.hash[i] = byte(difference)
+		borrow = 0
+		if difference < 0 {
+			borrow = 1
+		}
+	}
+	return s
+}
+
+// hashStrings hashes the length-prefixed string representations of the key and the value.
+func hashStrings(key interface{}, value interface{}) Hash {
+	k, v := utils.ToString(key), utils.ToString(value)
+	h := sha256.New()
+	fmt.Fprintf(h, "%d:%s%d:%s", len(k), k, len(v), v)
+	var hash Hash
+	h.Sum(hash[:0])
+	return hash
+}
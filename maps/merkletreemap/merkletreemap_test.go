@@ -0,0 +1,335 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package merkletreemap
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+func TestMapPut(t *testing.T) {
+	m := NewWithIntComparator()
+	m.Put(5, "e")
+	m.Put(6, "f")
+	m.Put(7, "g")
+	m.Put(3, "c")
+	m.Put(4, "d")
+	m.Put(1, "x")
+	m.Put(2, "b")
+	m.Put(1, "a") //overwrite
+
+	if actualValue := m.Size(); actualValue != 7 {
+		t.Errorf("Got %v expected %v", actualValue, 7)
+	}
+	if actualValue, expectedValue := fmt.Sprint(m.Keys()), "[1 2 3 4 5 6 7]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue, expectedValue := fmt.Sprint(m.Values()), "[a b c d e f g]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue, found := m.Get(1); actualValue != "a" || !found {
+		t.Errorf("Got %v expected %v", actualValue, "a")
+	}
+	if actualValue, found := m.Get(8); actualValue != nil || found {
+		t.Errorf("Got %v expected %v", actualValue, nil)
+	}
+	if actualValue, expectedValue := m.String(), "MerkleTreeMap\nmap[1:a 2:b 3:c 4:d 5:e 6:f 7:g]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+
+	m.Remove(5)
+	m.Remove(8)
+	if actualValue, expectedValue := fmt.Sprint(m.Keys()), "[1 2 3 4 6 7]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	m.Clear()
+	if actualValue := m.Empty(); actualValue != true {
+		t.Errorf("Got %v expected %v", actualValue, true)
+	}
+	if actualValue, expectedValue := m.RootHash(), (Hash{}); actualValue != expectedValue {
+		t.Errorf("Got %x expected %x", actualValue, expectedValue)
+	}
+}
+
+func TestMapRootHash(t *testing.T) {
+	a := NewWithIntComparator()
+	b := NewWithIntComparator()
+	b.UsePool(8) // pooling does not change the hashes
+	for _, i := range rand.Perm(100) {
+		a.Put(i, fmt.Sprint(i))
+	}
+	for _, i := range rand.Perm(120) {
+		b.Put(i, fmt.Sprint(i))
+	}
+
+	// the hashes do not depend on the order of the insertions
+	for i := 100; i < 120; i++ {
+		b.Remove(i)
+	}
+	if a.RootHash() != b.RootHash() {
+		t.Errorf("Got %x expected %x", b.RootHash(), a.RootHash())
+	}
+
+	b.Put(50, "x")
+	if a.RootHash() == b.RootHash() {
+		t.Errorf("Got %x expected a different hash", b.RootHash())
+	}
+	b.Put(50, "50")
+	if a.RootHash() != b.RootHash() {
+		t.Errorf("Got %x expected %x", b.RootHash(), a.RootHash())
+	}
+
+	// a map of a single pair hashes to the hash of the pair
+	c := NewWithIntComparator()
+	c.Put(1, "a")
+	if actualValue, expectedValue := c.RootHash(), hashStrings(1, "a"); actualValue != expectedValue {
+		t.Errorf("Got %x expected %x", actualValue, expectedValue)
+	}
+}
+
+func TestMapRangeHash(t *testing.T) {
+	m := NewWithIntComparator()
+	for i := 0; i < 100; i += 2 {
+		m.Put(i, i)
+	}
+
+	tests := []struct {
+		r    Range
+		size int
+	}{
+		{Range{}, 50},
+		{Range{From: 10}, 45},
+		{Range{To: 10}, 5},
+		{Range{From: 10, To: 20}, 5},
+		{Range{From: 11, To: 21}, 5},
+		{Range{From: 11, To: 12}, 0},
+		{Range{From: 20, To: 10}, 0},
+		{Range{From: 98, To: 1000}, 1},
+	}
+	for _, test := range tests {
+		if actualValue, expectedValue := m.RangeSize(test.r), test.size; actualValue != expectedValue {
+			t.Errorf("Got %v expected %v for %v", actualValue, expectedValue, test.r)
+		}
+
+		// the hash of a range is the hash of a map holding only its pairs
+		expected := NewWithIntComparator()
+		for i := 0; i < 100; i += 2 {
+			if (test.r.From == nil || i >= test.r.From.(int)) && (test.r.To == nil || i < test.r.To.(int)) {
+				expected.Put(i, i)
+			}
+		}
+		if actualValue, expectedValue := m.RangeHash(test.r), expected.RootHash(); actualValue != expectedValue {
+			t.Errorf("Got %x expected %x for %v", actualValue, expectedValue, test.r)
+		}
+	}
+	if actualValue, expectedValue := m.RangeHash(Range{}), m.RootHash(); actualValue != expectedValue {
+		t.Errorf("Got %x expected %x", actualValue, expectedValue)
+	}
+}
+
+func TestMapSplit(t *testing.T) {
+	m := NewWithIntComparator()
+	for i := 0; i < 10; i++ {
+		m.Put(i, i)
+	}
+
+	tests := []struct {
+		r        Range
+		n        int
+		expected string
+	}{
+		{Range{}, 2, "[{<nil> 5} {5 <nil>}]"},
+		{Range{}, 3, "[{<nil> 3} {3 6} {6 <nil>}]"},
+		{Range{From: -5, To: 3}, 4, "[{-5 1} {1 2} {2 3}]"},
+		{Range{From: 4, To: 5}, 4, "[{4 5}]"},
+		{Range{From: 20}, 4, "[{20 <nil>}]"},
+		{Range{}, 1, "[{<nil> <nil>}]"},
+	}
+	for _, test := range tests {
+		ranges := m.Split(test.r, test.n)
+		if actualValue, expectedValue := fmt.Sprint(ranges), test.expected; actualValue != expectedValue {
+			t.Errorf("Got %v expected %v", actualValue, expectedValue)
+		}
+
+		// the hashes of the children sum up to the hash of the range
+		var s summary
+		for _, r := range ranges {
+			s = s.plus(summary{size: m.RangeSize(r), hash: m.RangeHash(r)})
+		}
+		if actualValue, expectedValue := s.hash, m.RangeHash(test.r); actualValue != expectedValue {
+			t.Errorf("Got %x expected %x", actualValue, expectedValue)
+		}
+	}
+}
+
+func TestMapDiff(t *testing.T) {
+	a := NewWithIntComparator()
+	b := NewWithIntComparator()
+	if actualValue := Diff(a, b); len(actualValue) != 0 {
+		t.Errorf("Got %v expected %v", actualValue, "[]")
+	}
+	for i := 0; i < 100; i++ {
+		a.Put(i, i)
+		b.Put(i, i)
+	}
+	if actualValue := Diff(a, b); len(actualValue) != 0 {
+		t.Errorf("Got %v expected %v", actualValue, "[]")
+	}
+
+	b.Put(42, "x")
+	ranges := Diff(a, b)
+	if len(ranges) != 1 || !contains(ranges[0], 42) || contains(ranges[0], 40) || contains(ranges[0], 44) {
+		t.Errorf("Got %v expected a range around %v", ranges, 42)
+	}
+
+	b.Clear()
+	if actualValue, expectedValue := fmt.Sprint(Diff(a, b)), "[{<nil> <nil>}]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue, expectedValue := fmt.Sprint(Diff(b, a)), "[{<nil> <nil>}]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+}
+
+func TestMapDiffRandom(t *testing.T) {
+	for round := 0; round < 20; round++ {
+		a := NewWithIntComparator()
+		b := NewWithIntComparator()
+		for i := 0; i < 1000; i++ {
+			key := rand.Intn(2000)
+			a.Put(key, key)
+			b.Put(key, key)
+		}
+		for i := rand.Intn(20); i > 0; i-- {
+			key := rand.Intn(2000)
+			switch rand.Intn(3) {
+			case 0:
+				a.Remove(key)
+			case 1:
+				b.Put(key, key)
+			default:
+				b.Put(key, -key)
+			}
+		}
+
+		var differing []int
+		for key := 0; key < 2000; key++ {
+			va, fa := a.Get(key)
+			vb, fb := b.Get(key)
+			if fa != fb || va != vb {
+				differing = append(differing, key)
+			}
+		}
+		ranges := Diff(a, b)
+		for _, key := range differing {
+			found := false
+			for _, r := range ranges {
+				found = found || contains(r, key)
+			}
+			if !found {
+				t.Errorf("Got %v expected a range containing %v", ranges, key)
+			}
+		}
+		for _, r := range ranges {
+			found := false
+			for _, key := range differing {
+				found = found || contains(r, key)
+			}
+			if !found {
+				t.Errorf("Got %v expected a differing key in %v", differing, r)
+			}
+		}
+	}
+}
+
+func contains(r Range, key int) bool {
+	return (r.From == nil || key >= r.From.(int)) && (r.To == nil || key < r.To.(int))
+}
+
+func TestMapIterator(t *testing.T) {
+	m := NewWithStringComparator()
+	m.Put("c", 3)
+	m.Put("a", 1)
+	m.Put("b", 2)
+
+	it := m.Iterator()
+	str := ""
+	for it.Next() {
+		str += fmt.Sprintf("%v:%v ", it.Key(), it.Value())
+	}
+	if actualValue, expectedValue := str, "a:1 b:2 c:3 "; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+
+	// removing through the iterator updates the index
+	expected := NewWithStringComparator()
+	expected.Put("a", 1)
+	expected.Put("c", 3)
+	for it.Prev() {
+		if it.Key() == "b" {
+			it.Remove()
+		}
+	}
+	if actualValue, expectedValue := m.RootHash(), expected.RootHash(); actualValue != expectedValue {
+		t.Errorf("Got %x expected %x", actualValue, expectedValue)
+	}
+}
+
+func TestMapSerialization(t *testing.T) {
+	original := NewWithStringComparator()
+	original.Put("d", "4")
+	original.Put("e", "5")
+	original.Put("c", "3")
+	original.Put("b", "2")
+	original.Put("a", "1")
+
+	serialized, err := original.ToJSON()
+	if err != nil {
+		t.Errorf("Got error %v", err)
+	}
+	if actualValue, expectedValue := string(serialized), `{"a":"1","b":"2","c":"3","d":"4","e":"5"}`; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+
+	deserialized := NewWithStringComparator()
+	err = deserialized.FromJSON(serialized)
+	if err != nil {
+		t.Errorf("Got error %v", err)
+	}
+	if actualValue, expectedValue := deserialized.RootHash(), original.RootHash(); actualValue != expectedValue {
+		t.Errorf("Got %x expected %x", actualValue, expectedValue)
+	}
+}
+
+func benchmarkPut(b *testing.B, m *Map, size int) {
+	for i := 0; i < b.N; i++ {
+		for n := 0; n < size; n++ {
+			m.Put(n, n)
+		}
+	}
+}
+
+func BenchmarkMerkleTreeMapPut1000(b *testing.B) {
+	benchmarkPut(b, NewWithIntComparator(), 1000)
+}
+
+func BenchmarkMerkleTreeMapDiff10000(b *testing.B) {
+	b.StopTimer()
+	size := 10000
+	m1 := NewWithIntComparator()
+	m2 := NewWithIntComparator()
+	for n := 0; n < size; n++ {
+		m1.Put(n, n)
+		m2.Put(n, n)
+	}
+	for n := 0; n < size; n += size / 10 {
+		m2.Put(n, -n)
+	}
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		Diff(m1, m2)
+	}
+}
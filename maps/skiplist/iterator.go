@@ -0,0 +1,151 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package skiplist
+
+import "github.com/dairongpeng/gds/containers"
+
+func assertIteratorImplementation() {
+	var _ containers.ReverseIteratorWithKey = (*Iterator)(nil)
+	var _ containers.IteratorWithRemove = (*Iterator)(nil)
+}
+
+// Iterator holding the iterator's state
+type Iterator struct {
+	m        *Map
+	element  *element
+	position position
+	modCount int
+	removed  bool // element was removed, its links still lead to its former neighbours
+}
+
+type position byte
+
+const (
+	begin, between, end position = 0, 1, 2
+)
+
+// Iterator returns a stateful iterator whose elements are key/value pairs.
+func (m *Map) Iterator() Iterator {
+	return Iterator{m: m, element: nil, position: begin, modCount: m.modCount}
+}
+
+// Next moves the iterator to the next element and returns true if there was a next element in the container.
+// If Next() returns true, then next element's key and value can be retrieved by Key() and Value().
+// If Next() was called for the first time, then it will point the iterator to the first element if it exists.
+// Modifies the state of the iterator.
+func (iterator *Iterator) Next() bool {
+	iterator.checkModification()
+	iterator.removed = false
+	switch iterator.position {
+	case begin:
+		iterator.position = between
+		iterator.element = iterator.m.head.forward[0]
+	case between:
+		iterator.element = iterator.element.forward[0]
+	}
+
+	if iterator.element == nil {
+		iterator.position = end
+		return false
+	}
+	return true
+}
+
+// Prev moves the iterator to the previous element and returns true if there was a previous element in the container.
+// If Prev() returns true, then previous element's key and value can be retrieved by Key() and Value().
+// Modifies the state of the iterator.
+func (iterator *Iterator) Prev() bool {
+	iterator.checkModification()
+	iterator.removed = false
+	switch iterator.position {
+	case end:
+		iterator.position = between
+		iterator.element = iterator.m.tail
+	case between:
+		iterator.element = iterator.element.backward
+	}
+
+	if iterator.element == nil {
+		iterator.position = begin
+		return false
+	}
+	return true
+}
+
+// Value returns the current element's value.
+// Does not modify the state of the iterator.
+func (iterator *Iterator) Value() interface{} {
+	if iterator.element == nil {
+		return nil
+	}
+	return iterator.element.value
+}
+
+// Key returns the current element's key.
+// Does not modify the state of the iterator.
+func (iterator *Iterator) Key() interface{} {
+	if iterator.element == nil {
+		return nil
+	}
+	return iterator.element.key
+}
+
+// Begin resets the iterator to its initial state (one-before-first)
+// Call Next() to fetch the first element if any.
+func (iterator *Iterator) Begin() {
+	iterator.element = nil
+	iterator.position = begin
+	iterator.removed = false
+	iterator.modCount = iterator.m.modCount
+}
+
+// End moves the iterator past the last element (one-past-the-end).
+// Call Prev() to fetch the last element if any.
+func (iterator *Iterator) End() {
+	iterator.element = nil
+	iterator.position = end
+	iterator.removed = false
+	iterator.modCount = iterator.m.modCount
+}
+
+// First moves the iterator to the first element and returns true if there was a first element in the container.
+// If First() returns true, then first element's key and value can be retrieved by Key() and Value().
+// Modifies the state of the iterator
+func (iterator *Iterator) First() bool {
+	iterator.Begin()
+	return iterator.Next()
+}
+
+// Last moves the iterator to the last element and returns true if there was a last element in the container.
+// If Last() returns true, then last element's key and value can be retrieved by Key() and Value().
+// Modifies the state of the iterator.
+func (iterator *Iterator) Last() bool {
+	iterator.End()
+	return iterator.Prev()
+}
+
+// Remove removes the current element from the map.
+// Next() moves the iterator to the element after the removed one and Prev() to the element before it,
+// until then Key() and Value() return the removed element.
+// Panics with containers.ErrIteratorNotOnElement if the iterator is not on an element.
+func (iterator *Iterator) Remove() {
+	iterator.checkModification()
+	if iterator.removed || iterator.position != between {
+		panic(containers.ErrIteratorNotOnElement)
+	}
+	iterator.m.Remove(iterator.element.key)
+	iterator.modCount = iterator.m.modCount
+	iterator.removed = true
+}
+
+// checkModification panics if the map was structurally modified other than through the iterator.
+func (iterator *Iterator) checkModification() {
+	if iterator.modCount != iterator.m.modCount {
+		if iterator.position != begin {
+			panic(containers.ErrConcurrentModification)
+		}
+		iterator.modCount = iterator.m.modCount
+	}
+}
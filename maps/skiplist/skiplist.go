@@ -0,0 +1,430 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package skiplist implements an ordered map backed by a skip list.
+//
+// Elements are ordered by key in the map. Every element is linked on a random number of levels, so that
+// searches skip over most of the elements and take O(log n) expected time. Elements are also linked backwards
+// on the lowest level, which allows reverse iteration.
+//
+// In indexable mode every link additionally records how many elements it skips, so elements can be fetched by
+// their position (GetAt) and the position of a key can be found (Rank) in O(log n) expected time.
+//
+// Structure is not thread safe.
+//
+// Reference: https://en.wikipedia.org/wiki/Skip_list
+package skiplist
+
+import (
+	"fmt"
+	"github.com/dairongpeng/gds/maps"
+	"github.com/dairongpeng/gds/utils"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+func assertMapImplementation() {
+	var _ maps.Map = (*Map)(nil)
+}
+
+const (
+	// DefaultMaxLevel is the maximum level used when the configured one is not positive.
+	DefaultMaxLevel = 32
+	// DefaultProbability is the probability used when the configured one is not within (0, 1).
+	DefaultProbability = 0.25
+)
+
+// Config holds the settings of a skip list
+type Config struct {
+	MaxLevel    int         // Maximum number of levels an element can be linked on
+	Probability float64     // Probability that an element linked on a level is also linked on the next one
+	Source      rand.Source // Source of randomness for the levels of new elements, randomly seeded if nil
+	Indexable   bool        // Maintain the spans of links, which enables GetAt and Rank
+}
+
+// Map holds the elements in a skip list
+// Map 基于跳表实现的有序map
+type Map struct {
+	head       *element // sentinel linked on all levels
+	tail       *element
+	level      int // number of levels in use
+	size       int
+	comparator utils.Comparator
+	config     Config
+	random     *rand.Rand
+	modCount   int        // number of inserted and removed elements, checked by iterators
+	update     []*element // scratch of Put and Remove, last element before the key on each level, cleared before they return
+	rank       []int      // scratch of Put, number of elements up to and including update[i], only when indexable
+}
+
+type element struct {
+	key      interface{}
+	value    interface{}
+	forward  []*element // next element on each level
+	span     []int      // number of elements skipped by each forward link (including its target), only when indexable
+	backward *element   // previous element on the lowest level, nil for the first element
+}
+
+// NewWith instantiates a skip list map with the custom comparator and the default configuration.
+func NewWith(comparator utils.Comparator) *Map {
+	return NewWithConfig(comparator, Config{})
+}
+
+// NewWithIntComparator instantiates a skip list map with the IntComparator, i.e. keys are of type int.
+func NewWithIntComparator() *Map {
+	return NewWith(utils.IntComparator)
+}
+
+// NewWithStringComparator instantiates a skip list map with the StringComparator, i.e. keys are of type string.
+func NewWithStringComparator() *Map {
+	return NewWith(utils.StringComparator)
+}
+
+// NewWithConfig instantiates a skip list map with the custom comparator and configuration.
+// Settings that are not set or invalid are replaced with their defaults.
+func NewWithConfig(comparator utils.Comparator, config Config) *Map {
+	if config.MaxLevel <= 0 {
+		config.MaxLevel = DefaultMaxLevel
+	}
+	if config.Probability <= 0 || config.Probability >= 1 {
+		config.Probability = DefaultProbability
+	}
+	if config.Source == nil {
+		config.Source = rand.NewSource(time.Now().UnixNano())
+	}
+	m := &Map{comparator: comparator, config: config, random: rand.New(config.Source)}
+	m.Clear()
+	return m
+}
+
+// Put inserts key-value pair into the map.
+// Key should adhere to the comparator's type assertion, otherwise method panics.
+func (m *Map) Put(key interface{}, value interface{}) {
+	if m.size == 0 {
+		// there is no other key to compare with, a key of the wrong type fails here rather than on a later call
+		m.comparator(key, key)
+	}
+	m.grow(m.level)
+	update, rank := m.update, m.rank
+	x := m.head
+	for i := m.level - 1; i >= 0; i-- {
+		if m.config.Indexable {
+			if rank[i] = 0; i < m.level-1 {
+				rank[i] = rank[i+1]
+			}
+		}
+		for x.forward[i] != nil && m.comparator(x.forward[i].key, key) < 0 {
+			if m.config.Indexable {
+				rank[i] += x.span[i]
+			}
+			x = x.forward[i]
+		}
+		update[i] = x
+	}
+	if next := x.forward[0]; next != nil && m.comparator(next.key, key) == 0 {
+		next.key = key
+		next.value = value
+		m.release(m.level)
+		return
+	}
+
+	level := m.randomLevel()
+	if level > m.level {
+		m.grow(level)
+		update, rank = m.update, m.rank
+		for i := m.level; i < level; i++ {
+			update[i] = m.head
+			if m.config.Indexable {
+				rank[i] = 0
+				m.head.span[i] = m.size
+			}
+		}
+		m.level = level
+	}
+	x = m.newElement(key, value, level)
+	for i := 0; i < level; i++ {
+		x.forward[i] = update[i].forward[i]
+		update[i].forward[i] = x
+		if m.config.Indexable {
+			x.span[i] = update[i].span[i] - (rank[0] - rank[i])
+			update[i].span[i] = rank[0] - rank[i] + 1
+		}
+	}
+	if m.config.Indexable {
+		// links above the new element now skip one more element
+		for i := level; i < m.level; i++ {
+			update[i].span[i]++
+		}
+	}
+
+	if update[0] != m.head {
+		x.backward = update[0]
+	}
+	if x.forward[0] != nil {
+		x.forward[0].backward = x
+	} else {
+		m.tail = x
+	}
+	m.release(m.level)
+	m.size++
+	m.modCount++
+}
+
+// Get searches the element in the map by key and returns its value or nil if key is not found in map.
+// Second return parameter is true if key was found, otherwise false.
+// Key should adhere to the comparator's type assertion, otherwise method panics.
+func (m *Map) Get(key interface{}) (value interface{}, found bool) {
+	if x := m.lowerBound(key); x != nil && m.comparator(x.key, key) == 0 {
+		return x.value, true
+	}
+	return nil, false
+}
+
+// Remove removes the element from the map by key.
+// Key should adhere to the comparator's type assertion, otherwise method panics.
+func (m *Map) Remove(key interface{}) {
+	levels := m.level
+	m.grow(levels)
+	update := m.update
+	x := m.head
+	for i := m.level - 1; i >= 0; i-- {
+		for x.forward[i] != nil && m.comparator(x.forward[i].key, key) < 0 {
+			x = x.forward[i]
+		}
+		update[i] = x
+	}
+	x = x.forward[0]
+	if x == nil || m.comparator(x.key, key) != 0 {
+		m.release(levels)
+		return
+	}
+
+	for i := 0; i < m.level; i++ {
+		if update[i].forward[i] == x {
+			if m.config.Indexable {
+				update[i].span[i] += x.span[i] - 1
+			}
+			update[i].forward[i] = x.forward[i]
+		} else if m.config.Indexable {
+			update[i].span[i]--
+		}
+	}
+	if x.forward[0] != nil {
+		x.forward[0].backward = x.backward
+	} else {
+		m.tail = x.backward
+	}
+	for m.level > 1 && m.head.forward[m.level-1] == nil {
+		m.level--
+	}
+	m.release(levels)
+	m.size--
+	m.modCount++
+}
+
+// GetAt returns the key and value of the element at the given position in the key order.
+// Third return parameter is false if index is out of bounds.
+// Requires the map to be indexable, otherwise method panics.
+// GetAt 返回按照key排序后第index个元素
+func (m *Map) GetAt(index int) (key interface{}, value interface{}, found bool) {
+	m.mustBeIndexable()
+	if index < 0 || index >= m.size {
+		return nil, nil, false
+	}
+	rank := index + 1
+	traversed := 0
+	x := m.head
+	for i := m.level - 1; i >= 0; i-- {
+		for x.forward[i] != nil && traversed+x.span[i] <= rank {
+			traversed += x.span[i]
+			x = x.forward[i]
+		}
+		if traversed == rank {
+			return x.key, x.value, true
+		}
+	}
+	return nil, nil, false
+}
+
+// Rank returns the position of the key in the key order, or -1 if the key is not found.
+// Requires the map to be indexable, otherwise method panics.
+// Key should adhere to the comparator's type assertion, otherwise method panics.
+// Rank 返回key在排序后的位置，不存在则返回-1
+func (m *Map) Rank(key interface{}) int {
+	m.mustBeIndexable()
+	rank := 0
+	x := m.head
+	for i := m.level - 1; i >= 0; i-- {
+		for x.forward[i] != nil && m.comparator(x.forward[i].key, key) <= 0 {
+			rank += x.span[i]
+			x = x.forward[i]
+		}
+		if x != m.head && m.comparator(x.key, key) == 0 {
+			return rank - 1
+		}
+	}
+	return -1
+}
+
+// Indexable returns true if the map maintains the spans needed by GetAt and Rank.
+func (m *Map) Indexable() bool {
+	return m.config.Indexable
+}
+
+// Empty returns true if map does not contain any elements
+func (m *Map) Empty() bool {
+	return m.size == 0
+}
+
+// Size returns number of elements in the map.
+func (m *Map) Size() int {
+	return m.size
+}
+
+// Keys returns all keys in-order
+func (m *Map) Keys() []interface{} {
+	keys := make([]interface{}, 0, m.size)
+	for x := m.head.forward[0]; x != nil; x = x.forward[0] {
+		keys = append(keys, x.key)
+	}
+	return keys
+}
+
+// Values returns all values in-order based on the key.
+func (m *Map) Values() []interface{} {
+	values := make([]interface{}, 0, m.size)
+	for x := m.head.forward[0]; x != nil; x = x.forward[0] {
+		values = append(values, x.value)
+	}
+	return values
+}
+
+// Clear removes all elements from the map.
+func (m *Map) Clear() {
+	m.head = m.newElement(nil, nil, m.config.MaxLevel)
+	m.tail = nil
+	m.level = 1
+	m.size = 0
+	m.modCount++
+}
+
+// Min returns the minimum key and its value from the map.
+// Returns nil, nil if map is empty.
+func (m *Map) Min() (key interface{}, value interface{}) {
+	if x := m.head.forward[0]; x != nil {
+		return x.key, x.value
+	}
+	return nil, nil
+}
+
+// Max returns the maximum key and its value from the map.
+// Returns nil, nil if map is empty.
+func (m *Map) Max() (key interface{}, value interface{}) {
+	if m.tail != nil {
+		return m.tail.key, m.tail.value
+	}
+	return nil, nil
+}
+
+// Floor finds the floor key-value pair for the input key.
+// In case that no floor is found, then both returned values will be nil.
+// It's generally enough to check the first value (key) for nil, which determines if floor was found.
+//
+// Floor key is defined as the largest key that is smaller than or equal to the given key.
+// A floor key may not be found, either because the map is empty, or because
+// all keys in the map are larger than the given key.
+//
+// Key should adhere to the comparator's type assertion, otherwise method panics.
+func (m *Map) Floor(key interface{}) (foundKey interface{}, foundValue interface{}) {
+	x := m.lowerBound(key)
+	if x != nil && m.comparator(x.key, key) == 0 {
+		return x.key, x.value
+	}
+	if x == nil {
+		x = m.tail
+	} else {
+		x = x.backward
+	}
+	if x != nil {
+		return x.key, x.value
+	}
+	return nil, nil
+}
+
+// Ceiling finds the ceiling key-value pair for the input key.
+// In case that no ceiling is found, then both returned values will be nil.
+// It's generally enough to check the first value (key) for nil, which determines if ceiling was found.
+//
+// Ceiling key is defined as the smallest key that is larger than or equal to the given key.
+// A ceiling key may not be found, either because the map is empty, or because
+// all keys in the map are smaller than the given key.
+//
+// Key should adhere to the comparator's type assertion, otherwise method panics.
+func (m *Map) Ceiling(key interface{}) (foundKey interface{}, foundValue interface{}) {
+	if x := m.lowerBound(key); x != nil {
+		return x.key, x.value
+	}
+	return nil, nil
+}
+
+// String returns a string representation of container
+func (m *Map) String() string {
+	str := "SkipList\nmap["
+	for x := m.head.forward[0]; x != nil; x = x.forward[0] {
+		str += fmt.Sprintf("%v:%v ", x.key, x.value)
+	}
+	return strings.TrimRight(str, " ") + "]"
+}
+
+// lowerBound returns the first element whose key is larger than or equal to the key, or nil if there is none.
+func (m *Map) lowerBound(key interface{}) *element {
+	x := m.head
+	for i := m.level - 1; i >= 0; i-- {
+		for x.forward[i] != nil && m.comparator(x.forward[i].key, key) < 0 {
+			x = x.forward[i]
+		}
+	}
+	return x.forward[0]
+}
+
+// randomLevel returns the number of levels of a new element, which is geometrically distributed.
+func (m *Map) randomLevel() int {
+	level := 1
+	for level < m.config.MaxLevel && m.random.Float64() < m.config.Probability {
+		level++
+	}
+	return level
+}
+
+// grow extends the scratch slices to hold at least the given number of levels.
+func (m *Map) grow(level int) {
+	for len(m.update) < level {
+		m.update = append(m.update, nil)
+		if m.config.Indexable {
+			m.rank = append(m.rank, 0)
+		}
+	}
+}
+
+// release clears the scratch slots of the given number of levels, so that they keep no removed elements alive.
+func (m *Map) release(levels int) {
+	for i := range m.update[:levels] {
+		m.update[i] = nil
+	}
+}
+
+func (m *Map) newElement(key interface{}, value interface{}, level int) *element {
+	x := &element{key: key, value: value, forward: make([]*element, level)}
+	if m.config.Indexable {
+		x.span = make([]int, level)
+	}
+	return x
+}
+
+func (m *Map) mustBeIndexable() {
+	if !m.config.Indexable {
+		panic("Positional access requires an indexable skip list")
+	}
+}
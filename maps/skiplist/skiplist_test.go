@@ -0,0 +1,565 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package skiplist
+
+import (
+	"fmt"
+	"github.com/dairongpeng/gds/containers"
+	"github.com/dairongpeng/gds/utils"
+	"math/rand"
+	"testing"
+)
+
+func newIndexable() *Map {
+	return NewWithConfig(utils.IntComparator, Config{Source: rand.NewSource(1), Indexable: true})
+}
+
+// assertLinks checks the ordering, backward links and spans of all levels.
+func assertLinks(t *testing.T, m *Map) {
+	positions := map[*element]int{m.head: 0}
+	var previous *element
+	for x, i := m.head.forward[0], 1; x != nil; x, i = x.forward[0], i+1 {
+		positions[x] = i
+		if x.backward != previous {
+			t.Errorf("Got backward %v expected %v for %v", x.backward, previous, x.key)
+		}
+		if previous != nil && m.comparator(previous.key, x.key) >= 0 {
+			t.Errorf("Keys out of order: %v before %v", previous.key, x.key)
+		}
+		previous = x
+	}
+	if m.tail != previous {
+		t.Errorf("Got tail %v expected %v", m.tail, previous)
+	}
+	if actualValue := len(positions) - 1; actualValue != m.size {
+		t.Errorf("Got %v expected %v", actualValue, m.size)
+	}
+	if !m.config.Indexable {
+		return
+	}
+	for i := 0; i < m.level; i++ {
+		for x := m.head; x.forward[i] != nil; x = x.forward[i] {
+			if expected := positions[x.forward[i]] - positions[x]; x.span[i] != expected {
+				t.Errorf("Got span %v expected %v on level %v", x.span[i], expected, i)
+			}
+		}
+	}
+}
+
+func TestSkipListPut(t *testing.T) {
+	m := NewWithIntComparator()
+	m.Put(5, "e")
+	m.Put(6, "f")
+	m.Put(7, "g")
+	m.Put(3, "c")
+	m.Put(4, "d")
+	m.Put(1, "x")
+	m.Put(2, "b")
+	m.Put(1, "a") //overwrite
+	assertLinks(t, m)
+
+	if actualValue := m.Size(); actualValue != 7 {
+		t.Errorf("Got %v expected %v", actualValue, 7)
+	}
+	if actualValue, expectedValue := m.Keys(), []interface{}{1, 2, 3, 4, 5, 6, 7}; !sameElements(actualValue, expectedValue) {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue, expectedValue := m.Values(), []interface{}{"a", "b", "c", "d", "e", "f", "g"}; !sameElements(actualValue, expectedValue) {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+
+	// key,expectedValue,expectedFound
+	tests1 := [][]interface{}{
+		{1, "a", true},
+		{2, "b", true},
+		{3, "c", true},
+		{4, "d", true},
+		{5, "e", true},
+		{6, "f", true},
+		{7, "g", true},
+		{8, nil, false},
+	}
+
+	for _, test := range tests1 {
+		// retrievals
+		actualValue, actualFound := m.Get(test[0])
+		if actualValue != test[1] || actualFound != test[2] {
+			t.Errorf("Got %v expected %v", actualValue, test[1])
+		}
+	}
+}
+
+func TestSkipListRemove(t *testing.T) {
+	m := NewWithIntComparator()
+	m.Put(5, "e")
+	m.Put(6, "f")
+	m.Put(7, "g")
+	m.Put(3, "c")
+	m.Put(4, "d")
+	m.Put(1, "x")
+	m.Put(2, "b")
+	m.Put(1, "a") //overwrite
+
+	m.Remove(5)
+	m.Remove(6)
+	m.Remove(7)
+	m.Remove(8)
+	m.Remove(5)
+	assertLinks(t, m)
+
+	if actualValue, expectedValue := m.Keys(), []interface{}{1, 2, 3, 4}; !sameElements(actualValue, expectedValue) {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue, expectedValue := m.Values(), []interface{}{"a", "b", "c", "d"}; !sameElements(actualValue, expectedValue) {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue := m.Size(); actualValue != 4 {
+		t.Errorf("Got %v expected %v", actualValue, 4)
+	}
+
+	m.Remove(1)
+	m.Remove(4)
+	m.Remove(2)
+	m.Remove(3)
+	m.Remove(2)
+	m.Remove(2)
+
+	if actualValue, expectedValue := fmt.Sprintf("%s", m.Keys()), "[]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue := m.Empty(); actualValue != true {
+		t.Errorf("Got %v expected %v", actualValue, true)
+	}
+	if key, value := m.Max(); key != nil || value != nil {
+		t.Errorf("Got %v->%v expected %v->%v", key, value, nil, nil)
+	}
+}
+
+func TestSkipListMinMaxFloorCeiling(t *testing.T) {
+	m := NewWithIntComparator()
+
+	if key, value := m.Min(); key != nil || value != nil {
+		t.Errorf("Got %v->%v expected %v->%v", key, value, nil, nil)
+	}
+	if key, _ := m.Floor(1); key != nil {
+		t.Errorf("Got %v expected %v", key, nil)
+	}
+	if key, _ := m.Ceiling(1); key != nil {
+		t.Errorf("Got %v expected %v", key, nil)
+	}
+
+	for _, key := range []int{50, 30, 70, 10, 40} {
+		m.Put(key, key*10)
+	}
+	if key, value := m.Min(); key != 10 || value != 100 {
+		t.Errorf("Got %v->%v expected %v->%v", key, value, 10, 100)
+	}
+	if key, value := m.Max(); key != 70 || value != 700 {
+		t.Errorf("Got %v->%v expected %v->%v", key, value, 70, 700)
+	}
+
+	tests := [][]interface{}{
+		// key, floor, ceiling
+		{40, 40, 40},
+		{45, 40, 50},
+		{5, nil, 10},
+		{75, 70, nil},
+		{31, 30, 40},
+	}
+	for _, test := range tests {
+		if key, _ := m.Floor(test[0]); key != test[1] {
+			t.Errorf("Got %v expected %v for floor of %v", key, test[1], test[0])
+		}
+		if key, _ := m.Ceiling(test[0]); key != test[2] {
+			t.Errorf("Got %v expected %v for ceiling of %v", key, test[2], test[0])
+		}
+	}
+}
+
+func TestSkipListGetAtAndRank(t *testing.T) {
+	m := newIndexable()
+	for _, key := range []int{50, 10, 40, 20, 30} {
+		m.Put(key, fmt.Sprintf("%d", key))
+	}
+	assertLinks(t, m)
+	for index, key := range []int{10, 20, 30, 40, 50} {
+		actualKey, actualValue, found := m.GetAt(index)
+		if actualKey != key || actualValue != fmt.Sprintf("%d", key) || !found {
+			t.Errorf("Got %v->%v expected %v->%v", actualKey, actualValue, key, key)
+		}
+		if actualValue := m.Rank(key); actualValue != index {
+			t.Errorf("Got %v expected %v", actualValue, index)
+		}
+	}
+	if _, _, found := m.GetAt(5); found {
+		t.Errorf("Got %v expected %v", found, false)
+	}
+	if _, _, found := m.GetAt(-1); found {
+		t.Errorf("Got %v expected %v", found, false)
+	}
+	if actualValue := m.Rank(35); actualValue != -1 {
+		t.Errorf("Got %v expected %v", actualValue, -1)
+	}
+	if actualValue := m.Rank(5); actualValue != -1 {
+		t.Errorf("Got %v expected %v", actualValue, -1)
+	}
+}
+
+func TestSkipListGetAtNotIndexable(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Expected panic for positional access on a map that is not indexable")
+		}
+	}()
+	NewWithIntComparator().GetAt(0)
+}
+
+func TestSkipListRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, config := range []Config{
+		{Source: rand.NewSource(1), Indexable: true},
+		{Source: rand.NewSource(2), Indexable: true, MaxLevel: 4, Probability: 0.5},
+		{Source: rand.NewSource(3)},
+	} {
+		m := NewWithConfig(utils.IntComparator, config)
+		expected := make(map[int]int)
+		for i := 0; i < 3000; i++ {
+			key := r.Intn(300)
+			if r.Intn(3) == 0 {
+				m.Remove(key)
+				delete(expected, key)
+			} else {
+				m.Put(key, i)
+				expected[key] = i
+			}
+		}
+		assertLinks(t, m)
+		if actualValue := m.Size(); actualValue != len(expected) {
+			t.Errorf("Got %v expected %v", actualValue, len(expected))
+		}
+		for key, value := range expected {
+			if actualValue, found := m.Get(key); actualValue != value || !found {
+				t.Errorf("Got %v expected %v", actualValue, value)
+			}
+		}
+		if !m.Indexable() {
+			continue
+		}
+		for index, key := range m.Keys() {
+			if actualValue := m.Rank(key); actualValue != index {
+				t.Errorf("Got %v expected %v", actualValue, index)
+			}
+			if actualValue, _, _ := m.GetAt(index); actualValue != key {
+				t.Errorf("Got %v expected %v", actualValue, key)
+			}
+		}
+	}
+}
+
+func TestSkipListIteratorNextOnEmpty(t *testing.T) {
+	m := NewWithStringComparator()
+	it := m.Iterator()
+	for it.Next() {
+		t.Errorf("Shouldn't iterate on empty map")
+	}
+}
+
+func TestSkipListIteratorPrevOnEmpty(t *testing.T) {
+	m := NewWithStringComparator()
+	it := m.Iterator()
+	for it.Prev() {
+		t.Errorf("Shouldn't iterate on empty map")
+	}
+}
+
+func TestSkipListIterator(t *testing.T) {
+	m := NewWithStringComparator()
+	m.Put("c", 3)
+	m.Put("a", 1)
+	m.Put("b", 2)
+
+	it := m.Iterator()
+	count := 0
+	for it.Next() {
+		count++
+		key := it.Key()
+		value := it.Value()
+		switch key {
+		case "a":
+			if actualValue, expectedValue := value, 1; actualValue != expectedValue {
+				t.Errorf("Got %v expected %v", actualValue, expectedValue)
+			}
+		case "b":
+			if actualValue, expectedValue := value, 2; actualValue != expectedValue {
+				t.Errorf("Got %v expected %v", actualValue, expectedValue)
+			}
+		case "c":
+			if actualValue, expectedValue := value, 3; actualValue != expectedValue {
+				t.Errorf("Got %v expected %v", actualValue, expectedValue)
+			}
+		default:
+			t.Errorf("Too many")
+		}
+		if actualValue, expectedValue := value, count; actualValue != expectedValue {
+			t.Errorf("Got %v expected %v", actualValue, expectedValue)
+		}
+	}
+	if actualValue, expectedValue := count, 3; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+
+	for it.Prev() {
+		if actualValue, expectedValue := it.Value(), count; actualValue != expectedValue {
+			t.Errorf("Got %v expected %v", actualValue, expectedValue)
+		}
+		count--
+	}
+	if actualValue, expectedValue := count, 0; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+
+	if actualValue := it.Last(); actualValue != true || it.Key() != "c" {
+		t.Errorf("Got %v expected %v", it.Key(), "c")
+	}
+	if actualValue := it.First(); actualValue != true || it.Key() != "a" {
+		t.Errorf("Got %v expected %v", it.Key(), "a")
+	}
+}
+
+func TestSkipListIteratorRemove(t *testing.T) {
+	m := newIndexable()
+	for _, key := range rand.Perm(100) {
+		m.Put(key, key)
+	}
+	it := m.Iterator()
+	for it.Next() {
+		if it.Key().(int)%2 == 0 {
+			it.Remove()
+			assertLinks(t, m)
+		}
+	}
+	for it.Prev() {
+		if it.Key().(int)%3 == 0 {
+			it.Remove()
+			assertLinks(t, m)
+		}
+	}
+	if actualValue, expectedValue := m.Size(), 33; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	for i, key := range m.Keys() {
+		if key.(int)%2 == 0 || key.(int)%3 == 0 {
+			t.Errorf("Got %v at %v", key, i)
+		}
+	}
+
+	it.First()
+	it.Remove()
+	if actualValue, expectedValue := it.Key(), 1; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if it.Prev() {
+		t.Errorf("Shouldn't iterate before the beginning")
+	}
+	func() {
+		defer func() {
+			if r := recover(); r != containers.ErrConcurrentModification {
+				t.Errorf("Got %v expected %v", r, containers.ErrConcurrentModification)
+			}
+		}()
+		it.Next()
+		m.Put(1, 1)
+		it.Next()
+	}()
+}
+
+func TestSkipListSerialization(t *testing.T) {
+	m := NewWithStringComparator()
+	m.Put("a", "1")
+	m.Put("b", "2")
+	m.Put("c", "3")
+
+	var err error
+	assert := func() {
+		if actualValue, expectedValue := m.Keys(), []interface{}{"a", "b", "c"}; !sameElements(actualValue, expectedValue) {
+			t.Errorf("Got %v expected %v", actualValue, expectedValue)
+		}
+		if actualValue, expectedValue := m.Values(), []interface{}{"1", "2", "3"}; !sameElements(actualValue, expectedValue) {
+			t.Errorf("Got %v expected %v", actualValue, expectedValue)
+		}
+		if actualValue, expectedValue := m.Size(), 3; actualValue != expectedValue {
+			t.Errorf("Got %v expected %v", actualValue, expectedValue)
+		}
+		if err != nil {
+			t.Errorf("Got error %v", err)
+		}
+	}
+
+	assert()
+
+	json, err := m.ToJSON()
+	assert()
+
+	err = m.FromJSON(json)
+	assert()
+}
+
+func TestSkipListString(t *testing.T) {
+	m := NewWithStringComparator()
+	m.Put("b", 2)
+	m.Put("a", 1)
+	if actualValue, expectedValue := m.String(), "SkipList\nmap[a:1 b:2]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+}
+
+func TestSkipListScratch(t *testing.T) {
+	for _, m := range []*Map{NewWithIntComparator(), newIndexable()} {
+		for i := 0; i < 200; i++ {
+			m.Put(i, i)
+		}
+		m.Put(0, 0)
+		for i := 0; i < 200; i += 2 {
+			m.Remove(i)
+		}
+		m.Remove(-1)
+		// the scratch keeps no elements alive, removed ones in particular
+		for i, x := range m.update {
+			if x != nil {
+				t.Errorf("Got %v expected %v at level %v", x.key, nil, i)
+			}
+		}
+	}
+
+	// a key of the wrong type fails on the first Put
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Got %v expected a panic", r)
+		}
+	}()
+	NewWithIntComparator().Put("a", 1)
+}
+
+func TestSkipListAllocations(t *testing.T) {
+	for _, m := range []*Map{NewWithIntComparator(), newIndexable()} {
+		keys := make([]interface{}, 100)
+		for n := range keys {
+			keys[n] = n
+			m.Put(n, n)
+		}
+		// replacing values and removing missing keys only search the list
+		allocs := testing.AllocsPerRun(10, func() {
+			for _, key := range keys {
+				m.Put(key, key)
+			}
+			m.Remove(-1)
+		})
+		if allocs != 0 {
+			t.Errorf("Got %v expected %v", allocs, 0)
+		}
+		assertLinks(t, m)
+	}
+}
+
+func sameElements(a []interface{}, b []interface{}) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func benchmarkGet(b *testing.B, m *Map, size int) {
+	for i := 0; i < b.N; i++ {
+		for n := 0; n < size; n++ {
+			m.Get(n)
+		}
+	}
+}
+
+func benchmarkPut(b *testing.B, m *Map, size int) {
+	for i := 0; i < b.N; i++ {
+		for n := 0; n < size; n++ {
+			m.Put(n, struct{}{})
+		}
+	}
+}
+
+func benchmarkRemove(b *testing.B, m *Map, size int) {
+	for i := 0; i < b.N; i++ {
+		for n := 0; n < size; n++ {
+			m.Remove(n)
+		}
+	}
+}
+
+func BenchmarkSkipListGet10000(b *testing.B) {
+	b.StopTimer()
+	size := 10000
+	m := NewWithIntComparator()
+	for n := 0; n < size; n++ {
+		m.Put(n, struct{}{})
+	}
+	b.StartTimer()
+	benchmarkGet(b, m, size)
+}
+
+func BenchmarkSkipListPut10000(b *testing.B) {
+	b.StopTimer()
+	size := 10000
+	m := NewWithIntComparator()
+	b.StartTimer()
+	benchmarkPut(b, m, size)
+}
+
+func BenchmarkSkipListIndexablePut10000(b *testing.B) {
+	b.StopTimer()
+	size := 10000
+	m := NewWithConfig(utils.IntComparator, Config{Indexable: true})
+	b.StartTimer()
+	benchmarkPut(b, m, size)
+}
+
+func BenchmarkSkipListRemove10000(b *testing.B) {
+	b.StopTimer()
+	size := 10000
+	m := NewWithIntComparator()
+	for n := 0; n < size; n++ {
+		m.Put(n, struct{}{})
+	}
+	b.StartTimer()
+	benchmarkRemove(b, m, size)
+}
+
+// benchmarkChurn inserts and removes all keys in every round, the keys are boxed up front
+// so that the reported allocations are those of the new elements.
+func benchmarkChurn(b *testing.B, m *Map, size int) {
+	keys := make([]interface{}, size)
+	for n := range keys {
+		keys[n] = n
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, key := range keys {
+			m.Put(key, struct{}{})
+		}
+		for _, key := range keys {
+			m.Remove(key)
+		}
+	}
+}
+
+func BenchmarkSkipListChurn10000(b *testing.B) {
+	benchmarkChurn(b, NewWithIntComparator(), 10000)
+}
+
+func BenchmarkSkipListIndexableChurn10000(b *testing.B) {
+	benchmarkChurn(b, NewWithConfig(utils.IntComparator, Config{Indexable: true}), 10000)
+}
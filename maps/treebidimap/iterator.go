@@ -11,16 +11,19 @@ import (
 
 func assertIteratorImplementation() {
 	var _ containers.ReverseIteratorWithKey = (*Iterator)(nil)
+	var _ containers.IteratorWithRemove = (*Iterator)(nil)
 }
 
 // Iterator holding the iterator's state
 type Iterator struct {
-	iterator rbt.Iterator
+	iterator  rbt.Iterator
+	m         *Map
+	onElement bool // the last move found an element that was not removed since
 }
 
 // Iterator returns a stateful iterator whose elements are key/value pairs.
 func (m *Map) Iterator() Iterator {
-	return Iterator{iterator: m.forwardMap.Iterator()}
+	return Iterator{iterator: m.forwardMap.Iterator(), m: m}
 }
 
 // Next moves the iterator to the next element and returns true if there was a next element in the container.
@@ -28,14 +31,16 @@ func (m *Map) Iterator() Iterator {
 // If Next() was called for the first time, then it will point the iterator to the first element if it exists.
 // Modifies the state of the iterator.
 func (iterator *Iterator) Next() bool {
-	return iterator.iterator.Next()
+	iterator.onElement = iterator.iterator.Next()
+	return iterator.onElement
 }
 
 // Prev moves the iterator to the previous element and returns true if there was a previous element in the container.
 // If Prev() returns true, then previous element's key and value can be retrieved by Key() and Value().
 // Modifies the state of the iterator.
 func (iterator *Iterator) Prev() bool {
-	return iterator.iterator.Prev()
+	iterator.onElement = iterator.iterator.Prev()
+	return iterator.onElement
 }
 
 // Value returns the current element's value.
@@ -54,24 +59,42 @@ func (iterator *Iterator) Key() interface{} {
 // Call Next() to fetch the first element if any.
 func (iterator *Iterator) Begin() {
 	iterator.iterator.Begin()
+	iterator.onElement = false
 }
 
 // End moves the iterator past the last element (one-past-the-end).
 // Call Prev() to fetch the last element if any.
 func (iterator *Iterator) End() {
 	iterator.iterator.End()
+	iterator.onElement = false
 }
 
 // First moves the iterator to the first element and returns true if there was a first element in the container.
 // If First() returns true, then first element's key and value can be retrieved by Key() and Value().
 // Modifies the state of the iterator
 func (iterator *Iterator) First() bool {
-	return iterator.iterator.First()
+	iterator.onElement = iterator.iterator.First()
+	return iterator.onElement
 }
 
 // Last moves the iterator to the last element and returns true if there was a last element in the container.
 // If Last() returns true, then last element's key and value can be retrieved by Key() and Value().
 // Modifies the state of the iterator.
 func (iterator *Iterator) Last() bool {
-	return iterator.iterator.Last()
+	iterator.onElement = iterator.iterator.Last()
+	return iterator.onElement
+}
+
+// Remove removes the current element from the map, i.e. its key and its value.
+// Next() moves the iterator to the element after the removed one and Prev() to the element before it.
+// Panics with containers.ErrIteratorNotOnElement if the iterator is not on an element.
+func (iterator *Iterator) Remove() {
+	var value interface{}
+	if iterator.onElement {
+		value = iterator.iterator.Value().(*data).value
+	}
+	// panics if the iterator is not on an element, before the value is removed
+	iterator.iterator.Remove()
+	iterator.onElement = false
+	iterator.m.inverseMap.Remove(value)
 }
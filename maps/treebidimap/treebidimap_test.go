@@ -6,6 +6,7 @@ package treebidimap
 
 import (
 	"fmt"
+	"github.com/dairongpeng/gds/containers"
 	"github.com/dairongpeng/gds/utils"
 	"testing"
 )
@@ -473,6 +474,52 @@ func TestMapIteratorLast(t *testing.T) {
 	}
 }
 
+func TestMapIteratorRemove(t *testing.T) {
+	m := NewWith(utils.IntComparator, utils.StringComparator)
+	for i := 0; i < 10; i++ {
+		m.Put(i, fmt.Sprint(i))
+	}
+	it := m.Iterator()
+	for it.Next() {
+		if it.Key().(int)%2 == 0 {
+			it.Remove()
+		}
+	}
+	if actualValue, expectedValue := fmt.Sprint(m.Keys()), "[1 3 5 7 9]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue, expectedValue := fmt.Sprint(m.Values()), "[1 3 5 7 9]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if _, found := m.GetKey("4"); found {
+		t.Errorf("Got %v expected %v", found, false)
+	}
+	if !it.Prev() || it.Key() != 9 {
+		t.Errorf("Got %v expected %v", it.Key(), 9)
+	}
+	it.Remove()
+	if !it.Prev() || it.Key() != 7 || it.Value() != "7" {
+		t.Errorf("Got %v,%v expected %v,%v", it.Key(), it.Value(), 7, "7")
+	}
+	// the removed value can be put again under another key
+	m.Put(10, "9")
+	if actualValue, expectedValue := fmt.Sprint(m.Keys()), "[1 3 5 7 10]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	it.Begin()
+	func() {
+		defer func() {
+			if r := recover(); r != containers.ErrIteratorNotOnElement {
+				t.Errorf("Got %v expected %v", r, containers.ErrIteratorNotOnElement)
+			}
+		}()
+		it.Remove()
+	}()
+	if actualValue, expectedValue := m.Size(), 5; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+}
+
 func TestMapSerialization(t *testing.T) {
 	for i := 0; i < 10; i++ {
 		original := NewWith(utils.StringComparator, utils.StringComparator)
@@ -36,6 +36,13 @@ func NewWith(comparator utils.Comparator) *Map {
 	return &Map{tree: rbt.NewWith(comparator)}
 }
 
+// NewWithPool instantiates a tree map with the custom comparator whose tree allocates its nodes slabSize at a time
+// and reuses the nodes of removed keys for later insertions, see redblacktree.NewWithPool.
+// NewWithPool 实例化一个有序表treemap，节点按slabSize批量分配，删除的节点会被之后的插入复用
+func NewWithPool(comparator utils.Comparator, slabSize int) *Map {
+	return &Map{tree: rbt.NewWithPool(comparator, slabSize)}
+}
+
 // NewWithIntComparator instantiates a tree map with the IntComparator, i.e. keys are of type int.
 // NewWithIntComparator 创建一个int类型的有序表treemap
 func NewWithIntComparator() *Map {
@@ -6,6 +6,8 @@ package treemap
 
 import (
 	"fmt"
+	"github.com/dairongpeng/gds/containers"
+	"github.com/dairongpeng/gds/utils"
 	"testing"
 )
 
@@ -496,6 +498,38 @@ func TestMapIteratorLast(t *testing.T) {
 	}
 }
 
+func TestMapIteratorRemove(t *testing.T) {
+	m := NewWithIntComparator()
+	for i := 0; i < 10; i++ {
+		m.Put(i, fmt.Sprint(i))
+	}
+	it := m.Iterator()
+	for it.Next() {
+		if it.Key().(int)%2 == 0 {
+			it.Remove()
+		}
+	}
+	if actualValue, expectedValue := fmt.Sprint(m.Keys()), "[1 3 5 7 9]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if !it.Prev() || it.Key() != 9 {
+		t.Errorf("Got %v expected %v", it.Key(), 9)
+	}
+	it.Remove()
+	if !it.Prev() || it.Key() != 7 || it.Value() != "7" {
+		t.Errorf("Got %v,%v expected %v,%v", it.Key(), it.Value(), 7, "7")
+	}
+	func() {
+		defer func() {
+			if r := recover(); r != containers.ErrConcurrentModification {
+				t.Errorf("Got %v expected %v", r, containers.ErrConcurrentModification)
+			}
+		}()
+		m.Remove(1)
+		it.Prev()
+	}()
+}
+
 func TestMapSerialization(t *testing.T) {
 	for i := 0; i < 10; i++ {
 		original := NewWithStringComparator()
@@ -545,6 +579,23 @@ func assertSerialization(m *Map, txt string, t *testing.T) {
 	}
 }
 
+func TestMapPool(t *testing.T) {
+	m := NewWithPool(utils.IntComparator, 4)
+	for i := 0; i < 10; i++ {
+		m.Put(i, i)
+	}
+	for i := 0; i < 10; i += 2 {
+		m.Remove(i)
+	}
+	m.Put(4, "d")
+	if actualValue, expectedValue := fmt.Sprint(m.Keys()), "[1 3 4 5 7 9]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue, expectedValue := fmt.Sprint(m.Values()), "[1 3 d 5 7 9]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+}
+
 func benchmarkGet(b *testing.B, m *Map, size int) {
 	for i := 0; i < b.N; i++ {
 		for n := 0; n < size; n++ {
@@ -0,0 +1,148 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package versionedtreemap
+
+import "github.com/dairongpeng/gds/containers"
+
+func assertIteratorImplementation() {
+	var _ containers.ReverseIteratorWithKey = (*Iterator)(nil)
+}
+
+// Iterator holding the iterator's state
+type Iterator struct {
+	root     *node
+	path     []*node // nodes from the root down to the current node
+	position position
+}
+
+type position byte
+
+const (
+	begin, between, end position = 0, 1, 2
+)
+
+// Iterator returns a stateful iterator whose elements are the key/value pairs of the map as it was at the version,
+// in key order. The version never changes, so the iterator is not affected by later writes or by pruning.
+// Panics with ErrVersionNotAvailable if the version was pruned or not created yet.
+func (m *Map) Iterator(version int) Iterator {
+	return Iterator{root: m.at(version).root, position: begin}
+}
+
+// Next moves the iterator to the next element and returns true if there was a next element in the container.
+// If Next() returns true, then next element's key and value can be retrieved by Key() and Value().
+// If Next() was called for the first time, then it will point the iterator to the first element if it exists.
+// Modifies the state of the iterator.
+func (iterator *Iterator) Next() bool {
+	switch iterator.position {
+	case begin:
+		iterator.descend(iterator.root, true)
+	case between:
+		if current := iterator.current(); current.right != nil {
+			iterator.descend(current.right, true)
+		} else {
+			iterator.ascend(true)
+		}
+	}
+	return iterator.settle(end)
+}
+
+// Prev moves the iterator to the previous element and returns true if there was a previous element in the container.
+// If Prev() returns true, then previous element's key and value can be retrieved by Key() and Value().
+// Modifies the state of the iterator.
+func (iterator *Iterator) Prev() bool {
+	switch iterator.position {
+	case end:
+		iterator.descend(iterator.root, false)
+	case between:
+		if current := iterator.current(); current.left != nil {
+			iterator.descend(current.left, false)
+		} else {
+			iterator.ascend(false)
+		}
+	}
+	return iterator.settle(begin)
+}
+
+// Value returns the current element's value.
+// Does not modify the state of the iterator.
+func (iterator *Iterator) Value() interface{} {
+	return iterator.current().value
+}
+
+// Key returns the current element's key.
+// Does not modify the state of the iterator.
+func (iterator *Iterator) Key() interface{} {
+	return iterator.current().key
+}
+
+// Begin resets the iterator to its initial state (one-before-first)
+// Call Next() to fetch the first element if any.
+func (iterator *Iterator) Begin() {
+	iterator.path = iterator.path[:0]
+	iterator.position = begin
+}
+
+// End moves the iterator past the last element (one-past-the-end).
+// Call Prev() to fetch the last element if any.
+func (iterator *Iterator) End() {
+	iterator.path = iterator.path[:0]
+	iterator.position = end
+}
+
+// First moves the iterator to the first element and returns true if there was a first element in the container.
+// If First() returns true, then first element's key and value can be retrieved by Key() and Value().
+// Modifies the state of the iterator.
+func (iterator *Iterator) First() bool {
+	iterator.Begin()
+	return iterator.Next()
+}
+
+// Last moves the iterator to the last element and returns true if there was a last element in the container.
+// If Last() returns true, then last element's key and value can be retrieved by Key() and Value().
+// Modifies the state of the iterator.
+func (iterator *Iterator) Last() bool {
+	iterator.End()
+	return iterator.Prev()
+}
+
+func (iterator *Iterator) current() *node {
+	return iterator.path[len(iterator.path)-1]
+}
+
+// descend appends the path from the node down to the smallest (or largest) node of its subtree.
+func (iterator *Iterator) descend(n *node, smallest bool) {
+	for n != nil {
+		iterator.path = append(iterator.path, n)
+		if smallest {
+			n = n.left
+		} else {
+			n = n.right
+		}
+	}
+}
+
+// ascend walks up to the first ancestor whose left (or right) subtree holds the current node,
+// the path ends up empty if there is none.
+func (iterator *Iterator) ascend(fromLeft bool) {
+	for len(iterator.path) > 1 {
+		child := iterator.current()
+		iterator.path = iterator.path[:len(iterator.path)-1]
+		parent := iterator.current()
+		if (fromLeft && parent.left == child) || (!fromLeft && parent.right == child) {
+			return
+		}
+	}
+	iterator.path = iterator.path[:0]
+}
+
+// settle moves the iterator between the elements if the path leads to one, otherwise to the given position.
+func (iterator *Iterator) settle(outside position) bool {
+	if len(iterator.path) == 0 {
+		iterator.position = outside
+		return false
+	}
+	iterator.position = between
+	return true
+}
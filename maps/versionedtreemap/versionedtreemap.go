@@ -0,0 +1,353 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package versionedtreemap implements a tree map that keeps its earlier versions readable (multiversion concurrency
+// control).
+//
+// Every Put, Remove and Clear creates a new version numbered one above the latest, starting with the empty map at
+// version 0. Get and Iterator read the map as it was at any version that was not pruned, while writes continue.
+//
+// The map is a persistent left-leaning red-black tree: a write copies the nodes on the path from the root to the
+// changed node, as well as the few nodes the rebalancing changes, and shares all other nodes with the previous
+// version. A write thus takes O(log n) time and space, and every version keeps its own root. Prune drops the roots
+// of old versions, so the nodes that no later version shares are garbage collected.
+//
+// Structure is not thread safe. Since versions never change, readers of a version may however run alongside
+// each other.
+//
+// References: https://en.wikipedia.org/wiki/Persistent_data_structure,
+// https://en.wikipedia.org/wiki/Left-leaning_red%E2%80%93black_tree
+package versionedtreemap
+
+import (
+	"errors"
+	"fmt"
+	"github.com/dairongpeng/gds/containers"
+	"github.com/dairongpeng/gds/utils"
+	"strings"
+)
+
+func assertMapImplementation() {
+	var _ containers.Container = (*Map)(nil)
+}
+
+// ErrVersionNotAvailable is the value methods panic with when they are asked for a version that was pruned
+// or not created yet.
+var ErrVersionNotAvailable = errors.New("versionedtreemap: version is pruned or not created yet")
+
+// Map holds the roots of the available versions of a persistent red-black tree
+// Map 多版本有序表，基于路径复制的可持久化红黑树，每次写操作产生一个新版本，可以读取任意未被清理的历史版本
+type Map struct {
+	Comparator utils.Comparator
+	versions   []version // available versions, the oldest first
+	oldest     int       // number of the oldest available version
+}
+
+// version is the state of the map after a write.
+type version struct {
+	root *node
+	size int
+}
+
+// node is a node of the persistent tree. Nodes are only changed by the write that created them.
+type node struct {
+	key     interface{}
+	value   interface{}
+	left    *node
+	right   *node
+	red     bool
+	version int // version whose write created the node
+}
+
+// NewWith instantiates a versioned tree map with the custom comparator.
+func NewWith(comparator utils.Comparator) *Map {
+	return &Map{Comparator: comparator, versions: []version{{}}}
+}
+
+// NewWithIntComparator instantiates a versioned tree map with the IntComparator, i.e. keys are of type int.
+func NewWithIntComparator() *Map {
+	return NewWith(utils.IntComparator)
+}
+
+// NewWithStringComparator instantiates a versioned tree map with the StringComparator, i.e. keys are of type string.
+func NewWithStringComparator() *Map {
+	return NewWith(utils.StringComparator)
+}
+
+// Put inserts the key-value pair into the map, or replaces the value of the key, and returns the new version.
+// Key should adhere to the comparator's type assertion, otherwise method panics.
+func (m *Map) Put(key interface{}, value interface{}) int {
+	latest := m.latest()
+	size := latest.size
+	if _, found := m.lookup(latest.root, key); !found {
+		size++
+	}
+	root := m.put(latest.root, key, value)
+	root.red = false
+	return m.commit(version{root: root, size: size})
+}
+
+// Get searches the key in the map as it was at the version and returns its value or nil if key was not found.
+// Second return parameter is true if key was found, otherwise false.
+// Key should adhere to the comparator's type assertion, otherwise method panics.
+// Panics with ErrVersionNotAvailable if the version was pruned or not created yet.
+func (m *Map) Get(key interface{}, version int) (value interface{}, found bool) {
+	return m.lookup(m.at(version).root, key)
+}
+
+// Remove removes the key from the map and returns the new version, which is created even if the key was not found.
+// Key should adhere to the comparator's type assertion, otherwise method panics.
+func (m *Map) Remove(key interface{}) int {
+	latest := m.latest()
+	if _, found := m.lookup(latest.root, key); !found {
+		return m.commit(latest)
+	}
+	root := latest.root
+	if !isRed(root.left) && !isRed(root.right) {
+		root = m.mutable(root)
+		root.red = true
+	}
+	root = m.remove(root, key)
+	if root != nil {
+		root.red = false
+	}
+	return m.commit(version{root: root, size: latest.size - 1})
+}
+
+// Version returns the number of the latest version.
+func (m *Map) Version() int {
+	return m.oldest + len(m.versions) - 1
+}
+
+// Oldest returns the number of the oldest version that was not pruned.
+func (m *Map) Oldest() int {
+	return m.oldest
+}
+
+// Prune drops the versions before the given version, so that the nodes no later version shares can be garbage
+// collected. The latest version is always kept, versions that were already pruned are ignored.
+func (m *Map) Prune(before int) {
+	if before > m.Version() {
+		before = m.Version()
+	}
+	if before <= m.oldest {
+		return
+	}
+	m.versions = append([]version(nil), m.versions[before-m.oldest:]...)
+	m.oldest = before
+}
+
+// Empty returns true if the latest version of the map does not contain any elements
+func (m *Map) Empty() bool {
+	return m.latest().size == 0
+}
+
+// Size returns number of elements in the latest version of the map.
+func (m *Map) Size() int {
+	return m.latest().size
+}
+
+// Keys returns all keys of the latest version in-order
+func (m *Map) Keys() []interface{} {
+	keys := make([]interface{}, 0, m.Size())
+	it := m.Iterator(m.Version())
+	for it.Next() {
+		keys = append(keys, it.Key())
+	}
+	return keys
+}
+
+// Values returns all values of the latest version in-order based on the key.
+func (m *Map) Values() []interface{} {
+	values := make([]interface{}, 0, m.Size())
+	it := m.Iterator(m.Version())
+	for it.Next() {
+		values = append(values, it.Value())
+	}
+	return values
+}
+
+// Clear removes all elements from the map, which creates a new version. Earlier versions are kept.
+func (m *Map) Clear() {
+	m.commit(version{})
+}
+
+// String returns a string representation of the latest version of container
+func (m *Map) String() string {
+	str := "VersionedTreeMap\nmap["
+	it := m.Iterator(m.Version())
+	for it.Next() {
+		str += fmt.Sprintf("%v:%v ", it.Key(), it.Value())
+	}
+	return strings.TrimRight(str, " ") + "]"
+}
+
+func (m *Map) latest() version {
+	return m.versions[len(m.versions)-1]
+}
+
+func (m *Map) at(number int) version {
+	if number < m.oldest || number > m.Version() {
+		panic(ErrVersionNotAvailable)
+	}
+	return m.versions[number-m.oldest]
+}
+
+// commit appends the version and returns its number.
+func (m *Map) commit(v version) int {
+	m.versions = append(m.versions, v)
+	return m.Version()
+}
+
+func (m *Map) lookup(n *node, key interface{}) (value interface{}, found bool) {
+	for n != nil {
+		compare := m.Comparator(key, n.key)
+		switch {
+		case compare == 0:
+			return n.value, true
+		case compare < 0:
+			n = n.left
+		default:
+			n = n.right
+		}
+	}
+	return nil, false
+}
+
+// mutable returns the node if it was created by the current write, otherwise a copy of it created by the write.
+func (m *Map) mutable(n *node) *node {
+	writing := m.Version() + 1
+	if n.version == writing {
+		return n
+	}
+	c := *n
+	c.version = writing
+	return &c
+}
+
+func (m *Map) put(n *node, key interface{}, value interface{}) *node {
+	if n == nil {
+		return &node{key: key, value: value, red: true, version: m.Version() + 1}
+	}
+	n = m.mutable(n)
+	compare := m.Comparator(key, n.key)
+	switch {
+	case compare == 0:
+		n.key, n.value = key, value
+	case compare < 0:
+		n.left = m.put(n.left, key, value)
+	default:
+		n.right = m.put(n.right, key, value)
+	}
+	return m.balance(n)
+}
+
+// remove removes the key, which must be in the subtree of the node, while keeping either the node or its left
+// child red on the way down.
+func (m *Map) remove(n *node, key interface{}) *node {
+	n = m.mutable(n)
+	if m.Comparator(key, n.key) < 0 {
+		if !isRed(n.left) && !isRed(n.left.left) {
+			n = m.moveRedLeft(n)
+		}
+		n.left = m.remove(n.left, key)
+		return m.balance(n)
+	}
+	if isRed(n.left) {
+		n = m.rotateRight(n)
+	}
+	if m.Comparator(key, n.key) == 0 && n.right == nil {
+		return nil
+	}
+	if !isRed(n.right) && !isRed(n.right.left) {
+		n = m.moveRedRight(n)
+	}
+	if m.Comparator(key, n.key) == 0 {
+		// replace the node by its successor, which is removed from the right subtree
+		successor := n.right
+		for successor.left != nil {
+			successor = successor.left
+		}
+		n.key, n.value = successor.key, successor.value
+		n.right = m.removeMin(n.right)
+	} else {
+		n.right = m.remove(n.right, key)
+	}
+	return m.balance(n)
+}
+
+func (m *Map) removeMin(n *node) *node {
+	if n.left == nil {
+		return nil
+	}
+	n = m.mutable(n)
+	if !isRed(n.left) && !isRed(n.left.left) {
+		n = m.moveRedLeft(n)
+	}
+	n.left = m.removeMin(n.left)
+	return m.balance(n)
+}
+
+// balance restores the invariants of the left-leaning red-black tree on the way up, the node must be mutable.
+func (m *Map) balance(n *node) *node {
+	if isRed(n.right) && !isRed(n.left) {
+		n = m.rotateLeft(n)
+	}
+	if isRed(n.left) && isRed(n.left.left) {
+		n = m.rotateRight(n)
+	}
+	if isRed(n.left) && isRed(n.right) {
+		m.flipColors(n)
+	}
+	return n
+}
+
+func (m *Map) moveRedLeft(n *node) *node {
+	m.flipColors(n)
+	if isRed(n.right.left) {
+		n.right = m.rotateRight(n.right)
+		n = m.rotateLeft(n)
+		m.flipColors(n)
+	}
+	return n
+}
+
+func (m *Map) moveRedRight(n *node) *node {
+	m.flipColors(n)
+	if isRed(n.left.left) {
+		n = m.rotateRight(n)
+		m.flipColors(n)
+	}
+	return n
+}
+
+func (m *Map) rotateLeft(n *node) *node {
+	n = m.mutable(n)
+	x := m.mutable(n.right)
+	n.right = x.left
+	x.left = n
+	x.red, n.red = n.red, true
+	return x
+}
+
+func (m *Map) rotateRight(n *node) *node {
+	n = m.mutable(n)
+	x := m.mutable(n.left)
+	n.left = x.right
+	x.right = n
+	x.red, n.red = n.red, true
+	return x
+}
+
+// flipColors flips the colors of the mutable node and its children.
+func (m *Map) flipColors(n *node) {
+	n.left, n.right = m.mutable(n.left), m.mutable(n.right)
+	n.red = !n.red
+	n.left.red = !n.left.red
+	n.right.red = !n.right.red
+}
+
+func isRed(n *node) bool {
+	return n != nil && n.red
+}
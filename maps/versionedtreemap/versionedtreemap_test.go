@@ -0,0 +1,334 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package versionedtreemap
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+func TestMapPut(t *testing.T) {
+	m := NewWithIntComparator()
+	m.Put(5, "e")
+	m.Put(6, "f")
+	m.Put(7, "g")
+	m.Put(3, "c")
+	m.Put(4, "d")
+	m.Put(1, "x")
+	m.Put(2, "b")
+	if actualValue, expectedValue := m.Put(1, "a"), 8; actualValue != expectedValue { //overwrite
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	assertValidTree(t, m)
+
+	if actualValue := m.Size(); actualValue != 7 {
+		t.Errorf("Got %v expected %v", actualValue, 7)
+	}
+	if actualValue, expectedValue := fmt.Sprint(m.Keys()), "[1 2 3 4 5 6 7]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue, expectedValue := fmt.Sprint(m.Values()), "[a b c d e f g]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue, expectedValue := m.String(), "VersionedTreeMap\nmap[1:a 2:b 3:c 4:d 5:e 6:f 7:g]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+
+	tests := [][]interface{}{
+		{1, 8, "a", true},
+		{1, 7, "x", true},
+		{1, 5, nil, false},
+		{5, 1, "e", true},
+		{5, 0, nil, false},
+		{8, 8, nil, false},
+	}
+	for _, test := range tests {
+		actualValue, actualFound := m.Get(test[0], test[1].(int))
+		if actualValue != test[2] || actualFound != test[3] {
+			t.Errorf("Got %v,%v expected %v,%v for %v at version %v", actualValue, actualFound, test[2], test[3], test[0], test[1])
+		}
+	}
+}
+
+func TestMapRemove(t *testing.T) {
+	m := NewWithIntComparator()
+	for i := 1; i <= 7; i++ {
+		m.Put(i, i)
+	}
+	m.Remove(5)
+	m.Remove(6)
+	m.Remove(7)
+	if actualValue, expectedValue := m.Remove(8), 11; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	m.Remove(5)
+	assertValidTree(t, m)
+
+	if actualValue, expectedValue := fmt.Sprint(m.Keys()), "[1 2 3 4]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue, expectedValue := m.Size(), 4; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue, found := m.Get(6, 8); actualValue != 6 || !found {
+		t.Errorf("Got %v expected %v", actualValue, 6)
+	}
+	if actualValue, found := m.Get(6, 9); actualValue != nil || found {
+		t.Errorf("Got %v expected %v", actualValue, nil)
+	}
+
+	m.Clear()
+	if actualValue := m.Empty(); actualValue != true {
+		t.Errorf("Got %v expected %v", actualValue, true)
+	}
+	if actualValue, found := m.Get(1, m.Version()-1); actualValue != 1 || !found {
+		t.Errorf("Got %v expected %v", actualValue, 1)
+	}
+}
+
+func TestMapVersions(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	m := NewWithIntComparator()
+	expected := []map[int]int{{}}
+	for i := 0; i < 2000; i++ {
+		state := map[int]int{}
+		for key, value := range expected[len(expected)-1] {
+			state[key] = value
+		}
+		key := r.Intn(200)
+		if r.Intn(3) == 0 {
+			m.Remove(key)
+			delete(state, key)
+		} else {
+			m.Put(key, i)
+			state[key] = i
+		}
+		expected = append(expected, state)
+		if i%100 == 0 {
+			assertValidTree(t, m)
+		}
+	}
+	assertValidTree(t, m)
+
+	if actualValue, expectedValue := m.Version(), len(expected)-1; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	for version, state := range expected {
+		count := 0
+		previous := -1
+		for it := m.Iterator(version); it.Next(); count++ {
+			key := it.Key().(int)
+			if key <= previous {
+				t.Errorf("Got %v expected a key larger than %v", key, previous)
+			}
+			previous = key
+			if actualValue, expectedValue := it.Value(), state[key]; actualValue != expectedValue {
+				t.Errorf("Got %v expected %v for %v at version %v", actualValue, expectedValue, key, version)
+			}
+		}
+		if actualValue, expectedValue := count, len(state); actualValue != expectedValue {
+			t.Errorf("Got %v expected %v at version %v", actualValue, expectedValue, version)
+		}
+		key := r.Intn(200)
+		value, found := m.Get(key, version)
+		if expectedValue, expectedFound := state[key]; found != expectedFound || (found && value != expectedValue) {
+			t.Errorf("Got %v,%v expected %v,%v for %v at version %v", value, found, expectedValue, expectedFound, key, version)
+		}
+	}
+}
+
+func TestMapPrune(t *testing.T) {
+	m := NewWithStringComparator()
+	m.Put("a", 1)
+	m.Put("b", 2)
+	m.Put("a", 3)
+	m.Remove("b")
+
+	m.Prune(2)
+	if actualValue, expectedValue := m.Oldest(), 2; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue, found := m.Get("b", 2); actualValue != 2 || !found {
+		t.Errorf("Got %v expected %v", actualValue, 2)
+	}
+	assertPanics(t, func() { m.Get("a", 1) })
+	assertPanics(t, func() { m.Get("a", 5) })
+	assertPanics(t, func() { m.Iterator(-1) })
+
+	m.Prune(1)
+	if actualValue, expectedValue := m.Oldest(), 2; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	m.Prune(100)
+	if actualValue, expectedValue := m.Oldest(), 4; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue, found := m.Get("a", 4); actualValue != 3 || !found {
+		t.Errorf("Got %v expected %v", actualValue, 3)
+	}
+	if actualValue, expectedValue := m.Put("c", 4), 5; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue, expectedValue := fmt.Sprint(m.Keys()), "[a c]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+}
+
+func TestMapIterator(t *testing.T) {
+	m := NewWithIntComparator()
+	for _, key := range []int{4, 2, 6, 1, 3, 5, 7} {
+		m.Put(key, key*10)
+	}
+	version := m.Version()
+	it := m.Iterator(version)
+	m.Remove(4)
+	m.Put(8, 80)
+
+	// the iterator reads the version it was created for
+	str := ""
+	for it.Next() {
+		str += fmt.Sprintf("%v:%v ", it.Key(), it.Value())
+	}
+	if actualValue, expectedValue := str, "1:10 2:20 3:30 4:40 5:50 6:60 7:70 "; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if it.Next() {
+		t.Errorf("Shouldn't iterate on past the end")
+	}
+	str = ""
+	for it.Prev() {
+		str += fmt.Sprintf("%v ", it.Key())
+	}
+	if actualValue, expectedValue := str, "7 6 5 4 3 2 1 "; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if !it.Next() || it.Key() != 1 {
+		t.Errorf("Got %v expected %v", it.Key(), 1)
+	}
+	if !it.Last() || it.Key() != 7 {
+		t.Errorf("Got %v expected %v", it.Key(), 7)
+	}
+	if !it.Prev() || it.Key() != 6 {
+		t.Errorf("Got %v expected %v", it.Key(), 6)
+	}
+	if !it.First() || it.Key() != 1 {
+		t.Errorf("Got %v expected %v", it.Key(), 1)
+	}
+
+	it = m.Iterator(0)
+	if it.Next() || it.Prev() || it.First() || it.Last() {
+		t.Errorf("Shouldn't iterate on empty map")
+	}
+}
+
+func TestMapSerialization(t *testing.T) {
+	original := NewWithStringComparator()
+	original.Put("d", "4")
+	original.Put("e", "5")
+	original.Put("c", "3")
+	original.Put("b", "2")
+	original.Put("a", "1")
+
+	serialized, err := original.ToJSON()
+	if err != nil {
+		t.Errorf("Got error %v", err)
+	}
+	if actualValue, expectedValue := string(serialized), `{"a":"1","b":"2","c":"3","d":"4","e":"5"}`; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+
+	deserialized := NewWithStringComparator()
+	deserialized.Put("x", "0")
+	err = deserialized.FromJSON(serialized)
+	if err != nil {
+		t.Errorf("Got error %v", err)
+	}
+	if actualValue, expectedValue := deserialized.String(), original.String(); actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue, found := deserialized.Get("x", 1); actualValue != "0" || !found {
+		t.Errorf("Got %v expected %v", actualValue, "0")
+	}
+}
+
+// assertValidTree checks the invariants of the left-leaning red-black tree of the latest version.
+func assertValidTree(t *testing.T, m *Map) {
+	root := m.latest().root
+	if isRed(root) {
+		t.Errorf("Got a red root")
+	}
+	var check func(n *node, min interface{}, max interface{}) (blacks int, size int)
+	check = func(n *node, min interface{}, max interface{}) (int, int) {
+		if n == nil {
+			return 1, 0
+		}
+		if (min != nil && m.Comparator(n.key, min) <= 0) || (max != nil && m.Comparator(n.key, max) >= 0) {
+			t.Errorf("Got %v out of order", n.key)
+		}
+		if isRed(n.right) {
+			t.Errorf("Got a red right child of %v", n.key)
+		}
+		if isRed(n) && isRed(n.left) {
+			t.Errorf("Got two red nodes in a row at %v", n.key)
+		}
+		leftBlacks, leftSize := check(n.left, min, n.key)
+		rightBlacks, rightSize := check(n.right, n.key, max)
+		if leftBlacks != rightBlacks {
+			t.Errorf("Got black heights %v and %v below %v", leftBlacks, rightBlacks, n.key)
+		}
+		if !isRed(n) {
+			leftBlacks++
+		}
+		return leftBlacks, leftSize + rightSize + 1
+	}
+	if _, size := check(root, nil, nil); size != m.Size() {
+		t.Errorf("Got %v expected %v", size, m.Size())
+	}
+}
+
+func assertPanics(t *testing.T, f func()) {
+	defer func() {
+		if r := recover(); r != ErrVersionNotAvailable {
+			t.Errorf("Got %v expected %v", r, ErrVersionNotAvailable)
+		}
+	}()
+	f()
+}
+
+func benchmarkPut(b *testing.B, m *Map, size int) {
+	for i := 0; i < b.N; i++ {
+		for n := 0; n < size; n++ {
+			m.Put(n, n)
+		}
+	}
+}
+
+func benchmarkGet(b *testing.B, m *Map, size int) {
+	for i := 0; i < b.N; i++ {
+		for n := 0; n < size; n++ {
+			m.Get(n, n)
+		}
+	}
+}
+
+func BenchmarkVersionedTreeMapPut10000(b *testing.B) {
+	b.StopTimer()
+	size := 10000
+	m := NewWithIntComparator()
+	b.StartTimer()
+	benchmarkPut(b, m, size)
+}
+
+func BenchmarkVersionedTreeMapGet10000(b *testing.B) {
+	b.StopTimer()
+	size := 10000
+	m := NewWithIntComparator()
+	for n := 0; n < size; n++ {
+		m.Put(n, n)
+	}
+	b.StartTimer()
+	benchmarkGet(b, m, size)
+}
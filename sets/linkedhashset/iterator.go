@@ -11,16 +11,18 @@ import (
 
 func assertIteratorImplementation() {
 	var _ containers.ReverseIteratorWithIndex = (*Iterator)(nil)
+	var _ containers.IteratorWithRemove = (*Iterator)(nil)
 }
 
 // Iterator holding the iterator's state
 type Iterator struct {
 	iterator doublylinkedlist.Iterator
+	set      *Set
 }
 
 // Iterator returns a stateful iterator whose values can be fetched by an index.
 func (set *Set) Iterator() Iterator {
-	return Iterator{iterator: set.ordering.Iterator()}
+	return Iterator{iterator: set.ordering.Iterator(), set: set}
 }
 
 // Next moves the iterator to the next element and returns true if there was a next element in the container.
@@ -75,3 +77,12 @@ func (iterator *Iterator) First() bool {
 func (iterator *Iterator) Last() bool {
 	return iterator.iterator.Last()
 }
+
+// Remove removes the current element from the set.
+// Next() moves the iterator to the element after the removed one and Prev() to the element before it.
+// Panics with containers.ErrIteratorNotOnElement if the iterator is not on an element.
+func (iterator *Iterator) Remove() {
+	iterator.iterator.Remove()
+	// the list iterator holds on to the removed item until it is moved
+	delete(iterator.set.table, iterator.iterator.Value())
+}
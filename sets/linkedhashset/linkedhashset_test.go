@@ -6,6 +6,7 @@ package linkedhashset
 
 import (
 	"fmt"
+	"github.com/dairongpeng/gds/containers"
 	"testing"
 )
 
@@ -332,6 +333,36 @@ func TestSetIteratorLast(t *testing.T) {
 	}
 }
 
+func TestSetIteratorRemove(t *testing.T) {
+	set := New("c", "a", "b")
+	it := set.Iterator()
+	it.Next()
+	it.Remove()
+	if !it.Next() || it.Index() != 0 || it.Value() != "a" {
+		t.Errorf("Got %v,%v expected %v,%v", it.Index(), it.Value(), 0, "a")
+	}
+	if actualValue, expectedValue := fmt.Sprint(set.Values()), "[a b]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if set.Contains("c") {
+		t.Errorf("Got %v expected %v", true, false)
+	}
+	set.Add("c")
+	it.End()
+	if !it.Prev() || it.Value() != "c" {
+		t.Errorf("Got %v expected %v", it.Value(), "c")
+	}
+	func() {
+		defer func() {
+			if r := recover(); r != containers.ErrConcurrentModification {
+				t.Errorf("Got %v expected %v", r, containers.ErrConcurrentModification)
+			}
+		}()
+		set.Remove("a")
+		it.Prev()
+	}()
+}
+
 func TestSetSerialization(t *testing.T) {
 	set := New()
 	set.Add("a", "b", "c")
@@ -11,6 +11,7 @@ import (
 
 func assertIteratorImplementation() {
 	var _ containers.ReverseIteratorWithIndex = (*Iterator)(nil)
+	var _ containers.IteratorWithRemove = (*Iterator)(nil)
 }
 
 // Iterator returns a stateful iterator whose values can be fetched by an index.
@@ -18,6 +19,7 @@ type Iterator struct {
 	index    int
 	iterator rbt.Iterator
 	tree     *rbt.Tree
+	removed  bool // the element at index was removed, index is where the next element moved to
 }
 
 // Iterator holding the iterator's state
@@ -30,20 +32,25 @@ func (set *Set) Iterator() Iterator {
 // If Next() was called for the first time, then it will point the iterator to the first element if it exists.
 // Modifies the state of the iterator.
 func (iterator *Iterator) Next() bool {
-	if iterator.index < iterator.tree.Size() {
+	ok := iterator.iterator.Next()
+	if iterator.removed {
+		iterator.removed = false
+	} else if iterator.index < iterator.tree.Size() {
 		iterator.index++
 	}
-	return iterator.iterator.Next()
+	return ok
 }
 
 // Prev moves the iterator to the previous element and returns true if there was a previous element in the container.
 // If Prev() returns true, then previous element's index and value can be retrieved by Index() and Value().
 // Modifies the state of the iterator.
 func (iterator *Iterator) Prev() bool {
+	ok := iterator.iterator.Prev()
+	iterator.removed = false
 	if iterator.index >= 0 {
 		iterator.index--
 	}
-	return iterator.iterator.Prev()
+	return ok
 }
 
 // Value returns the current element's value.
@@ -62,6 +69,7 @@ func (iterator *Iterator) Index() int {
 // Call Next() to fetch the first element if any.
 func (iterator *Iterator) Begin() {
 	iterator.index = -1
+	iterator.removed = false
 	iterator.iterator.Begin()
 }
 
@@ -69,6 +77,7 @@ func (iterator *Iterator) Begin() {
 // Call Prev() to fetch the last element if any.
 func (iterator *Iterator) End() {
 	iterator.index = iterator.tree.Size()
+	iterator.removed = false
 	iterator.iterator.End()
 }
 
@@ -87,3 +96,11 @@ func (iterator *Iterator) Last() bool {
 	iterator.End()
 	return iterator.Prev()
 }
+
+// Remove removes the current element from the set.
+// Next() moves the iterator to the element after the removed one and Prev() to the element before it.
+// Panics with containers.ErrIteratorNotOnElement if the iterator is not on an element.
+func (iterator *Iterator) Remove() {
+	iterator.iterator.Remove()
+	iterator.removed = true
+}
@@ -6,6 +6,7 @@ package treeset
 
 import (
 	"fmt"
+	"github.com/dairongpeng/gds/containers"
 	"testing"
 )
 
@@ -341,6 +342,40 @@ func TestSetIteratorLast(t *testing.T) {
 	}
 }
 
+func TestSetIteratorRemove(t *testing.T) {
+	set := NewWithIntComparator(0, 1, 2, 3, 4, 5)
+	it := set.Iterator()
+	for it.Next() {
+		if it.Value().(int)%2 == 1 {
+			it.Remove()
+		}
+	}
+	if actualValue, expectedValue := fmt.Sprint(set.Values()), "[0 2 4]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+
+	it.Begin()
+	it.Next()
+	it.Next()
+	it.Remove()
+	if !it.Next() || it.Index() != 1 || it.Value() != 4 {
+		t.Errorf("Got %v,%v expected %v,%v", it.Index(), it.Value(), 1, 4)
+	}
+	it.Remove()
+	if !it.Prev() || it.Index() != 0 || it.Value() != 0 {
+		t.Errorf("Got %v,%v expected %v,%v", it.Index(), it.Value(), 0, 0)
+	}
+	func() {
+		defer func() {
+			if r := recover(); r != containers.ErrConcurrentModification {
+				t.Errorf("Got %v expected %v", r, containers.ErrConcurrentModification)
+			}
+		}()
+		set.Add(7)
+		it.Next()
+	}()
+}
+
 func TestSetSerialization(t *testing.T) {
 	set := NewWithStringComparator()
 	set.Add("a", "b", "c")
@@ -0,0 +1,160 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vebset
+
+import "github.com/dairongpeng/gds/containers"
+
+func assertIteratorImplementation() {
+	var _ containers.ReverseIteratorWithIndex = (*Iterator)(nil)
+	var _ containers.IteratorWithRemove = (*Iterator)(nil)
+}
+
+// Iterator holding the iterator's state
+type Iterator struct {
+	set      *Set
+	element  uint32
+	index    int
+	position position
+	modCount int
+	removed  bool // the current element was removed, Next() and Prev() continue from where it was
+}
+
+type position byte
+
+const (
+	begin, between, end position = 0, 1, 2
+)
+
+// Iterator returns a stateful iterator whose values can be fetched by an index, in ascending order.
+// Each step takes O(log log U).
+func (set *Set) Iterator() Iterator {
+	return Iterator{set: set, index: -1, position: begin, modCount: set.modCount}
+}
+
+// Next moves the iterator to the next element and returns true if there was a next element in the container.
+// If Next() returns true, then next element's index and value can be retrieved by Index() and Value().
+// If Next() was called for the first time, then it will point the iterator to the first element if it exists.
+// Modifies the state of the iterator.
+func (iterator *Iterator) Next() bool {
+	iterator.checkModification()
+	ok := false
+	switch iterator.position {
+	case begin:
+		iterator.element, ok = iterator.set.Min()
+	case between:
+		iterator.element, ok = iterator.set.Successor(iterator.element)
+	}
+	if iterator.removed {
+		// the next element took the index of the removed one
+		iterator.removed = false
+	} else if iterator.index < iterator.set.size {
+		iterator.index++
+	}
+	if !ok {
+		iterator.position = end
+		return false
+	}
+	iterator.position = between
+	return true
+}
+
+// Prev moves the iterator to the previous element and returns true if there was a previous element in the container.
+// If Prev() returns true, then previous element's index and value can be retrieved by Index() and Value().
+// Modifies the state of the iterator.
+func (iterator *Iterator) Prev() bool {
+	iterator.checkModification()
+	ok := false
+	switch iterator.position {
+	case between:
+		iterator.element, ok = iterator.set.Predecessor(iterator.element)
+	case end:
+		iterator.element, ok = iterator.set.Max()
+	}
+	iterator.removed = false
+	if iterator.index >= 0 {
+		iterator.index--
+	}
+	if !ok {
+		iterator.position = begin
+		return false
+	}
+	iterator.position = between
+	return true
+}
+
+// Value returns the current element's value.
+// Does not modify the state of the iterator.
+func (iterator *Iterator) Value() interface{} {
+	return iterator.element
+}
+
+// Element returns the current element without boxing it.
+// Does not modify the state of the iterator.
+func (iterator *Iterator) Element() uint32 {
+	return iterator.element
+}
+
+// Index returns the current element's index.
+// Does not modify the state of the iterator.
+func (iterator *Iterator) Index() int {
+	return iterator.index
+}
+
+// Begin resets the iterator to its initial state (one-before-first)
+// Call Next() to fetch the first element if any.
+func (iterator *Iterator) Begin() {
+	iterator.index = -1
+	iterator.position = begin
+	iterator.removed = false
+	iterator.modCount = iterator.set.modCount
+}
+
+// End moves the iterator past the last element (one-past-the-end).
+// Call Prev() to fetch the last element if any.
+func (iterator *Iterator) End() {
+	iterator.index = iterator.set.size
+	iterator.position = end
+	iterator.removed = false
+	iterator.modCount = iterator.set.modCount
+}
+
+// First moves the iterator to the first element and returns true if there was a first element in the container.
+// If First() returns true, then first element's index and value can be retrieved by Index() and Value().
+// Modifies the state of the iterator.
+func (iterator *Iterator) First() bool {
+	iterator.Begin()
+	return iterator.Next()
+}
+
+// Last moves the iterator to the last element and returns true if there was a last element in the container.
+// If Last() returns true, then last element's index and value can be retrieved by Index() and Value().
+// Modifies the state of the iterator.
+func (iterator *Iterator) Last() bool {
+	iterator.End()
+	return iterator.Prev()
+}
+
+// Remove removes the current element from the set.
+// Next() moves the iterator to the element after the removed one and Prev() to the element before it.
+// Panics with containers.ErrIteratorNotOnElement if the iterator is not on an element.
+func (iterator *Iterator) Remove() {
+	iterator.checkModification()
+	if iterator.position != between || iterator.removed {
+		panic(containers.ErrIteratorNotOnElement)
+	}
+	iterator.set.Delete(iterator.element)
+	iterator.modCount = iterator.set.modCount
+	iterator.removed = true
+}
+
+// checkModification panics if the set was structurally modified other than through the iterator.
+func (iterator *Iterator) checkModification() {
+	if iterator.modCount != iterator.set.modCount {
+		if iterator.position != begin || iterator.removed {
+			panic(containers.ErrConcurrentModification)
+		}
+		iterator.modCount = iterator.set.modCount
+	}
+}
@@ -0,0 +1,33 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vebset
+
+import (
+	"encoding/json"
+	"github.com/dairongpeng/gds/containers"
+)
+
+func assertSerializationImplementation() {
+	var _ containers.JSONSerializer = (*Set)(nil)
+	var _ containers.JSONDeserializer = (*Set)(nil)
+}
+
+// ToJSON outputs the JSON representation of the set.
+func (set *Set) ToJSON() ([]byte, error) {
+	return json.Marshal(set.Elements())
+}
+
+// FromJSON populates the set from the input JSON representation.
+func (set *Set) FromJSON(data []byte) error {
+	elements := []uint32{}
+	err := json.Unmarshal(data, &elements)
+	if err == nil {
+		set.Clear()
+		for _, element := range elements {
+			set.Insert(element)
+		}
+	}
+	return err
+}
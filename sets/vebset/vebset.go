@@ -0,0 +1,406 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package vebset implements an ordered set of uint32 elements backed by a van Emde Boas tree.
+//
+// A van Emde Boas tree over a universe of 2^k integers splits every element into its high and low k/2 bits.
+// A node keeps its minimum apart, a cluster for every high half holding the low halves, and a summary holding the
+// high halves of its non-empty clusters, so Insert, Delete, Successor and Predecessor recurse into a single
+// universe of half the bits and take O(log log U), i.e. a handful of steps for 32-bit elements.
+// Universes of up to 64 integers are kept in a bitmap. Clusters are kept in hash maps and only while they hold
+// elements, so the space is linear in the number of elements.
+//
+// The typed methods (Insert, Delete, Member, Successor, Predecessor, Min, Max, Elements and the iterator's
+// Element) do not box elements into interface{} and do not call a comparator. The methods of sets.Set accept
+// uint32 elements only.
+//
+// Structure is not thread safe.
+//
+// Reference: https://en.wikipedia.org/wiki/Van_Emde_Boas_tree
+package vebset
+
+import (
+	"fmt"
+	"github.com/dairongpeng/gds/sets"
+	"math/bits"
+	"strings"
+)
+
+func assertSetImplementation() {
+	var _ sets.Set = (*Set)(nil)
+}
+
+// Set holds the elements in a van Emde Boas tree
+// Set 基于van Emde Boas树的uint32有序集合，各操作的时间复杂度为O(log log U)
+type Set struct {
+	root     *node // nil if the set is empty
+	size     int
+	modCount int // number of inserted and removed elements, checked by iterators
+}
+
+// universe is the number of bits of the elements.
+const universe = 32
+
+// bitmapBits is the largest number of bits of a universe kept in a bitmap.
+const bitmapBits = 6
+
+// node is a non-empty van Emde Boas tree over the integers of the given number of bits.
+// Nodes of up to bitmapBits bits only use the bitmap. Larger nodes hold their minimum apart from the clusters,
+// the maximum is held by a cluster too, unless it is the minimum.
+type node struct {
+	bits     uint
+	bitmap   uint64
+	min      uint32
+	max      uint32
+	summary  *node            // high halves of the non-empty clusters, nil if there are none
+	clusters map[uint32]*node // low halves by high half
+}
+
+// New instantiates a new empty set and adds the passed values, if any, to the set.
+func New(values ...uint32) *Set {
+	set := &Set{}
+	for _, value := range values {
+		set.Insert(value)
+	}
+	return set
+}
+
+// Insert adds the element to the set in O(log log U).
+// Returns true if the element was not in the set yet.
+func (set *Set) Insert(element uint32) bool {
+	if set.root == nil {
+		set.root = newNode(universe, element)
+	} else if !set.root.insert(element) {
+		return false
+	}
+	set.size++
+	set.modCount++
+	return true
+}
+
+// Delete removes the element from the set in O(log log U).
+// Returns true if the element was in the set.
+func (set *Set) Delete(element uint32) bool {
+	if set.root == nil {
+		return false
+	}
+	root, deleted := set.root.delete(element)
+	if !deleted {
+		return false
+	}
+	set.root = root
+	set.size--
+	set.modCount++
+	return true
+}
+
+// Member returns true if the element is in the set, in O(log log U).
+func (set *Set) Member(element uint32) bool {
+	return set.root != nil && set.root.member(element)
+}
+
+// Successor returns the smallest element of the set larger than the element in O(log log U).
+// Second return parameter is false if there is no such element.
+func (set *Set) Successor(element uint32) (uint32, bool) {
+	if set.root == nil {
+		return 0, false
+	}
+	return set.root.successor(element)
+}
+
+// Predecessor returns the largest element of the set smaller than the element in O(log log U).
+// Second return parameter is false if there is no such element.
+func (set *Set) Predecessor(element uint32) (uint32, bool) {
+	if set.root == nil {
+		return 0, false
+	}
+	return set.root.predecessor(element)
+}
+
+// Min returns the smallest element of the set in O(1).
+// Second return parameter is false if the set is empty.
+func (set *Set) Min() (uint32, bool) {
+	if set.root == nil {
+		return 0, false
+	}
+	return set.root.minimum(), true
+}
+
+// Max returns the largest element of the set in O(1).
+// Second return parameter is false if the set is empty.
+func (set *Set) Max() (uint32, bool) {
+	if set.root == nil {
+		return 0, false
+	}
+	return set.root.maximum(), true
+}
+
+// Elements returns all elements of the set in ascending order.
+func (set *Set) Elements() []uint32 {
+	elements := make([]uint32, 0, set.size)
+	element, ok := set.Min()
+	for ; ok; element, ok = set.Successor(element) {
+		elements = append(elements, element)
+	}
+	return elements
+}
+
+// Add adds the items (one or more) to the set.
+// Items should be of type uint32, otherwise method panics.
+func (set *Set) Add(items ...interface{}) {
+	for _, item := range items {
+		set.Insert(item.(uint32))
+	}
+}
+
+// Remove removes the items (one or more) from the set.
+// Items should be of type uint32, otherwise method panics.
+func (set *Set) Remove(items ...interface{}) {
+	for _, item := range items {
+		set.Delete(item.(uint32))
+	}
+}
+
+// Contains checks weather items (one or more) are present in the set.
+// All items have to be present in the set for the method to return true.
+// Returns true if no arguments are passed at all, i.e. set is always superset of empty set.
+// Items should be of type uint32, otherwise method panics.
+func (set *Set) Contains(items ...interface{}) bool {
+	for _, item := range items {
+		if !set.Member(item.(uint32)) {
+			return false
+		}
+	}
+	return true
+}
+
+// Empty returns true if set does not contain any elements.
+func (set *Set) Empty() bool {
+	return set.size == 0
+}
+
+// Size returns number of elements within the set.
+func (set *Set) Size() int {
+	return set.size
+}
+
+// Clear clears all values in the set.
+func (set *Set) Clear() {
+	set.root = nil
+	set.size = 0
+	set.modCount++
+}
+
+// Values returns all items in the set in ascending order.
+func (set *Set) Values() []interface{} {
+	values := make([]interface{}, 0, set.size)
+	for _, element := range set.Elements() {
+		values = append(values, element)
+	}
+	return values
+}
+
+// String returns a string representation of container
+func (set *Set) String() string {
+	str := "VEBSet\n"
+	items := []string{}
+	for _, element := range set.Elements() {
+		items = append(items, fmt.Sprintf("%v", element))
+	}
+	str += strings.Join(items, ", ")
+	return str
+}
+
+// newNode returns a node of the given number of bits holding the element only.
+func newNode(bits uint, element uint32) *node {
+	if bits <= bitmapBits {
+		return &node{bits: bits, bitmap: 1 << element}
+	}
+	return &node{bits: bits, min: element, max: element}
+}
+
+// insert adds the element to the node and returns true if it was not in the node yet.
+func (n *node) insert(element uint32) bool {
+	if n.bits <= bitmapBits {
+		if n.bitmap&(1<<element) != 0 {
+			return false
+		}
+		n.bitmap |= 1 << element
+		return true
+	}
+	if element == n.min {
+		return false
+	}
+	if element < n.min {
+		// the new minimum is kept apart, the former one moves into its cluster
+		element, n.min = n.min, element
+	}
+	high, low := n.split(element)
+	if cluster := n.clusters[high]; cluster != nil {
+		if !cluster.insert(low) {
+			return false
+		}
+	} else {
+		if n.clusters == nil {
+			n.clusters = make(map[uint32]*node)
+		}
+		n.clusters[high] = newNode(n.lowBits(), low)
+		if n.summary == nil {
+			n.summary = newNode(n.bits-n.lowBits(), high)
+		} else {
+			n.summary.insert(high)
+		}
+	}
+	if element > n.max {
+		n.max = element
+	}
+	return true
+}
+
+// delete removes the element from the node and returns the node, which is nil if it became empty,
+// and true if the element was in the node.
+func (n *node) delete(element uint32) (*node, bool) {
+	if n.bits <= bitmapBits {
+		if n.bitmap&(1<<element) == 0 {
+			return n, false
+		}
+		n.bitmap &^= 1 << element
+		if n.bitmap == 0 {
+			return nil, true
+		}
+		return n, true
+	}
+	if element < n.min || element > n.max {
+		return n, false
+	}
+	if element == n.min {
+		if n.summary == nil {
+			return nil, true
+		}
+		// the smallest element of the clusters becomes the minimum and is removed from its cluster
+		high := n.summary.minimum()
+		element = n.join(high, n.clusters[high].minimum())
+		n.min = element
+	}
+	high, low := n.split(element)
+	cluster := n.clusters[high]
+	if cluster == nil {
+		return n, false
+	}
+	cluster, deleted := cluster.delete(low)
+	if !deleted {
+		return n, false
+	}
+	if cluster == nil {
+		delete(n.clusters, high)
+		n.summary, _ = n.summary.delete(high)
+	}
+	if element == n.max {
+		if n.summary == nil {
+			n.max = n.min
+		} else {
+			high := n.summary.maximum()
+			n.max = n.join(high, n.clusters[high].maximum())
+		}
+	}
+	return n, true
+}
+
+// member returns true if the element is in the node.
+func (n *node) member(element uint32) bool {
+	if n.bits <= bitmapBits {
+		return n.bitmap&(1<<element) != 0
+	}
+	if element == n.min || element == n.max {
+		return true
+	}
+	high, low := n.split(element)
+	cluster := n.clusters[high]
+	return cluster != nil && cluster.member(low)
+}
+
+// successor returns the smallest element of the node larger than the element.
+func (n *node) successor(element uint32) (uint32, bool) {
+	if n.bits <= bitmapBits {
+		// shifting by 64 for the largest element of a bitmap leaves no element
+		larger := n.bitmap &^ (uint64(2)<<element - 1)
+		if larger == 0 {
+			return 0, false
+		}
+		return uint32(bits.TrailingZeros64(larger)), true
+	}
+	if element < n.min {
+		return n.min, true
+	}
+	high, low := n.split(element)
+	if cluster := n.clusters[high]; cluster != nil && low < cluster.maximum() {
+		low, _ = cluster.successor(low)
+		return n.join(high, low), true
+	}
+	if n.summary == nil {
+		return 0, false
+	}
+	high, ok := n.summary.successor(high)
+	if !ok {
+		return 0, false
+	}
+	return n.join(high, n.clusters[high].minimum()), true
+}
+
+// predecessor returns the largest element of the node smaller than the element.
+func (n *node) predecessor(element uint32) (uint32, bool) {
+	if n.bits <= bitmapBits {
+		smaller := n.bitmap & (uint64(1)<<element - 1)
+		if smaller == 0 {
+			return 0, false
+		}
+		return uint32(63 - bits.LeadingZeros64(smaller)), true
+	}
+	if element > n.max {
+		return n.max, true
+	}
+	high, low := n.split(element)
+	if cluster := n.clusters[high]; cluster != nil && low > cluster.minimum() {
+		low, _ = cluster.predecessor(low)
+		return n.join(high, low), true
+	}
+	if n.summary != nil {
+		if high, ok := n.summary.predecessor(high); ok {
+			return n.join(high, n.clusters[high].maximum()), true
+		}
+	}
+	if element > n.min {
+		return n.min, true
+	}
+	return 0, false
+}
+
+func (n *node) minimum() uint32 {
+	if n.bits <= bitmapBits {
+		return uint32(bits.TrailingZeros64(n.bitmap))
+	}
+	return n.min
+}
+
+func (n *node) maximum() uint32 {
+	if n.bits <= bitmapBits {
+		return uint32(63 - bits.LeadingZeros64(n.bitmap))
+	}
+	return n.max
+}
+
+// lowBits returns the number of bits of the clusters.
+func (n *node) lowBits() uint {
+	return n.bits / 2
+}
+
+// split returns the high half of the element, i.e. its cluster, and the low half, i.e. its position in the cluster.
+func (n *node) split(element uint32) (high uint32, low uint32) {
+	return element >> n.lowBits(), element & (1<<n.lowBits() - 1)
+}
+
+// join returns the element of the given cluster and position in the cluster.
+func (n *node) join(high uint32, low uint32) uint32 {
+	return high<<n.lowBits() | low
+}
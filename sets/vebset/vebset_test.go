@@ -0,0 +1,348 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vebset
+
+import (
+	"fmt"
+	"github.com/dairongpeng/gds/containers"
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestSetNew(t *testing.T) {
+	set := New(2, 1)
+	if actualValue := set.Size(); actualValue != 2 {
+		t.Errorf("Got %v expected %v", actualValue, 2)
+	}
+	if actualValue := set.Contains(uint32(1)); actualValue != true {
+		t.Errorf("Got %v expected %v", actualValue, true)
+	}
+	if actualValue := set.Contains(uint32(2)); actualValue != true {
+		t.Errorf("Got %v expected %v", actualValue, true)
+	}
+	if actualValue := set.Contains(uint32(3)); actualValue != false {
+		t.Errorf("Got %v expected %v", actualValue, false)
+	}
+}
+
+func TestSetInsertDelete(t *testing.T) {
+	set := New()
+	if actualValue := set.Empty(); actualValue != true {
+		t.Errorf("Got %v expected %v", actualValue, true)
+	}
+	if _, ok := set.Min(); ok {
+		t.Errorf("Got %v expected %v", ok, false)
+	}
+	if _, ok := set.Successor(0); ok {
+		t.Errorf("Got %v expected %v", ok, false)
+	}
+	if actualValue := set.Insert(70000); actualValue != true {
+		t.Errorf("Got %v expected %v", actualValue, true)
+	}
+	set.Insert(3)
+	set.Insert(math.MaxUint32)
+	set.Insert(0)
+	set.Insert(64)
+	if actualValue := set.Insert(3); actualValue != false {
+		t.Errorf("Got %v expected %v", actualValue, false)
+	}
+	if actualValue := set.Insert(math.MaxUint32); actualValue != false {
+		t.Errorf("Got %v expected %v", actualValue, false)
+	}
+	if actualValue, expectedValue := fmt.Sprint(set.Elements()), "[0 3 64 70000 4294967295]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue, expectedValue := set.String(), "VEBSet\n0, 3, 64, 70000, 4294967295"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue, ok := set.Min(); actualValue != 0 || !ok {
+		t.Errorf("Got %v expected %v", actualValue, 0)
+	}
+	if actualValue, ok := set.Max(); actualValue != math.MaxUint32 || !ok {
+		t.Errorf("Got %v expected %v", actualValue, uint32(math.MaxUint32))
+	}
+
+	tests := [][]interface{}{
+		{uint32(0), uint32(3), true, uint32(0), false},
+		{uint32(3), uint32(64), true, uint32(0), true},
+		{uint32(4), uint32(64), true, uint32(3), true},
+		{uint32(64), uint32(70000), true, uint32(3), true},
+		{uint32(69999), uint32(70000), true, uint32(64), true},
+		{uint32(70001), uint32(math.MaxUint32), true, uint32(70000), true},
+		{uint32(math.MaxUint32), uint32(0), false, uint32(70000), true},
+	}
+	for _, test := range tests {
+		if actualValue, ok := set.Successor(test[0].(uint32)); ok != test[2] || (ok && actualValue != test[1]) {
+			t.Errorf("Got %v,%v expected %v,%v for the successor of %v", actualValue, ok, test[1], test[2], test[0])
+		}
+		if actualValue, ok := set.Predecessor(test[0].(uint32)); ok != test[4] || (ok && actualValue != test[3]) {
+			t.Errorf("Got %v,%v expected %v,%v for the predecessor of %v", actualValue, ok, test[3], test[4], test[0])
+		}
+	}
+
+	if actualValue := set.Delete(1); actualValue != false {
+		t.Errorf("Got %v expected %v", actualValue, false)
+	}
+	if actualValue := set.Delete(0); actualValue != true {
+		t.Errorf("Got %v expected %v", actualValue, true)
+	}
+	set.Delete(math.MaxUint32)
+	set.Remove(uint32(64))
+	if actualValue, expectedValue := fmt.Sprint(set.Values()), "[3 70000]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue, ok := set.Max(); actualValue != 70000 || !ok {
+		t.Errorf("Got %v expected %v", actualValue, 70000)
+	}
+	set.Delete(3)
+	set.Delete(70000)
+	if actualValue := set.Size(); actualValue != 0 {
+		t.Errorf("Got %v expected %v", actualValue, 0)
+	}
+	if actualValue := set.Member(70000); actualValue != false {
+		t.Errorf("Got %v expected %v", actualValue, false)
+	}
+}
+
+func TestSetRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, limit := range []int64{100, 5000, math.MaxUint32 + 1} {
+		set := New()
+		expected := map[uint32]bool{}
+		for i := 0; i < 5000; i++ {
+			element := uint32(r.Int63n(limit))
+			if r.Intn(3) == 0 {
+				if actualValue, expectedValue := set.Delete(element), expected[element]; actualValue != expectedValue {
+					t.Errorf("Got %v expected %v", actualValue, expectedValue)
+				}
+				delete(expected, element)
+			} else {
+				if actualValue, expectedValue := set.Insert(element), !expected[element]; actualValue != expectedValue {
+					t.Errorf("Got %v expected %v", actualValue, expectedValue)
+				}
+				expected[element] = true
+			}
+		}
+
+		sorted := []uint32{}
+		for element := range expected {
+			sorted = append(sorted, element)
+		}
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+		if actualValue, expectedValue := fmt.Sprint(set.Elements()), fmt.Sprint(sorted); actualValue != expectedValue {
+			t.Errorf("Got %v expected %v", actualValue, expectedValue)
+		}
+		if actualValue, expectedValue := set.Size(), len(sorted); actualValue != expectedValue {
+			t.Errorf("Got %v expected %v", actualValue, expectedValue)
+		}
+		for i := 0; i < 1000; i++ {
+			element := uint32(r.Int63n(limit))
+			if actualValue, expectedValue := set.Member(element), expected[element]; actualValue != expectedValue {
+				t.Errorf("Got %v expected %v for %v", actualValue, expectedValue, element)
+			}
+			index := sort.Search(len(sorted), func(i int) bool { return sorted[i] > element })
+			if actualValue, ok := set.Successor(element); ok != (index < len(sorted)) || (ok && actualValue != sorted[index]) {
+				t.Errorf("Got %v,%v for the successor of %v", actualValue, ok, element)
+			}
+			index = sort.Search(len(sorted), func(i int) bool { return sorted[i] >= element }) - 1
+			if actualValue, ok := set.Predecessor(element); ok != (index >= 0) || (ok && actualValue != sorted[index]) {
+				t.Errorf("Got %v,%v for the predecessor of %v", actualValue, ok, element)
+			}
+		}
+
+		for _, element := range sorted {
+			set.Delete(element)
+		}
+		if actualValue := set.Empty(); actualValue != true {
+			t.Errorf("Got %v expected %v", actualValue, true)
+		}
+	}
+}
+
+func TestSetIteratorNext(t *testing.T) {
+	set := New(3, 1, 2)
+	it := set.Iterator()
+	count := 0
+	for it.Next() {
+		count++
+		index := it.Index()
+		value := it.Element()
+		switch index {
+		case 0:
+			if actualValue, expectedValue := value, uint32(1); actualValue != expectedValue {
+				t.Errorf("Got %v expected %v", actualValue, expectedValue)
+			}
+		case 1:
+			if actualValue, expectedValue := value, uint32(2); actualValue != expectedValue {
+				t.Errorf("Got %v expected %v", actualValue, expectedValue)
+			}
+		case 2:
+			if actualValue, expectedValue := value, uint32(3); actualValue != expectedValue {
+				t.Errorf("Got %v expected %v", actualValue, expectedValue)
+			}
+		default:
+			t.Errorf("Too many")
+		}
+		if actualValue, expectedValue := index, count-1; actualValue != expectedValue {
+			t.Errorf("Got %v expected %v", actualValue, expectedValue)
+		}
+	}
+	if actualValue, expectedValue := count, 3; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if it.Next() {
+		t.Errorf("Shouldn't iterate on past the end")
+	}
+}
+
+func TestSetIteratorPrev(t *testing.T) {
+	set := New(3, 1, 2)
+	it := set.Iterator()
+	count := 0
+	for it.Last(); ; {
+		count++
+		if actualValue, expectedValue := it.Value(), uint32(4-count); actualValue != expectedValue {
+			t.Errorf("Got %v expected %v", actualValue, expectedValue)
+		}
+		if actualValue, expectedValue := it.Index(), 3-count; actualValue != expectedValue {
+			t.Errorf("Got %v expected %v", actualValue, expectedValue)
+		}
+		if !it.Prev() {
+			break
+		}
+	}
+	if actualValue, expectedValue := count, 3; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if !it.Next() || it.Element() != 1 {
+		t.Errorf("Got %v expected %v", it.Element(), 1)
+	}
+
+	empty := New()
+	it = empty.Iterator()
+	if it.Prev() || it.Next() || it.First() || it.Last() {
+		t.Errorf("Shouldn't iterate on empty set")
+	}
+}
+
+func TestSetIteratorRemove(t *testing.T) {
+	set := New(0, 1, 2, 3, 4, 5)
+	it := set.Iterator()
+	for it.Next() {
+		if it.Element()%2 == 1 {
+			it.Remove()
+		}
+	}
+	if actualValue, expectedValue := fmt.Sprint(set.Values()), "[0 2 4]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+
+	it.Begin()
+	it.Next()
+	it.Next()
+	it.Remove()
+	if !it.Next() || it.Index() != 1 || it.Element() != 4 {
+		t.Errorf("Got %v,%v expected %v,%v", it.Index(), it.Element(), 1, 4)
+	}
+	it.Remove()
+	if !it.Prev() || it.Index() != 0 || it.Element() != 0 {
+		t.Errorf("Got %v,%v expected %v,%v", it.Index(), it.Element(), 0, 0)
+	}
+	func() {
+		defer func() {
+			if r := recover(); r != containers.ErrIteratorNotOnElement {
+				t.Errorf("Got %v expected %v", r, containers.ErrIteratorNotOnElement)
+			}
+		}()
+		it.Remove()
+		it.Remove()
+	}()
+	func() {
+		defer func() {
+			if r := recover(); r != containers.ErrConcurrentModification {
+				t.Errorf("Got %v expected %v", r, containers.ErrConcurrentModification)
+			}
+		}()
+		set.Insert(7)
+		it.Next()
+	}()
+}
+
+func TestSetSerialization(t *testing.T) {
+	set := New(1, 70000, math.MaxUint32)
+
+	json, err := set.ToJSON()
+	if err != nil {
+		t.Errorf("Got error %v", err)
+	}
+	if actualValue, expectedValue := string(json), "[1,70000,4294967295]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+
+	deserialized := New(5)
+	err = deserialized.FromJSON(json)
+	if err != nil {
+		t.Errorf("Got error %v", err)
+	}
+	if actualValue, expectedValue := fmt.Sprint(deserialized.Elements()), "[1 70000 4294967295]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+}
+
+func benchmarkMember(b *testing.B, set *Set, size int) {
+	for i := 0; i < b.N; i++ {
+		for n := 0; n < size; n++ {
+			set.Member(uint32(n))
+		}
+	}
+}
+
+func benchmarkInsert(b *testing.B, set *Set, size int) {
+	for i := 0; i < b.N; i++ {
+		for n := 0; n < size; n++ {
+			set.Insert(uint32(n))
+		}
+	}
+}
+
+func benchmarkSuccessor(b *testing.B, set *Set, size int) {
+	for i := 0; i < b.N; i++ {
+		for n := 0; n < size; n++ {
+			set.Successor(uint32(n))
+		}
+	}
+}
+
+func BenchmarkVEBSetMember100000(b *testing.B) {
+	b.StopTimer()
+	size := 100000
+	set := New()
+	for n := 0; n < size; n++ {
+		set.Insert(uint32(n))
+	}
+	b.StartTimer()
+	benchmarkMember(b, set, size)
+}
+
+func BenchmarkVEBSetInsert100000(b *testing.B) {
+	b.StopTimer()
+	size := 100000
+	set := New()
+	b.StartTimer()
+	benchmarkInsert(b, set, size)
+}
+
+func BenchmarkVEBSetSuccessor100000(b *testing.B) {
+	b.StopTimer()
+	size := 100000
+	set := New()
+	for n := 0; n < size; n++ {
+		set.Insert(uint32(n))
+	}
+	b.StartTimer()
+	benchmarkSuccessor(b, set, size)
+}
@@ -6,6 +6,7 @@ package arraystack
 
 import (
 	"fmt"
+	"github.com/dairongpeng/gds/containers"
 	"testing"
 )
 
@@ -232,6 +233,39 @@ func TestStackIteratorLast(t *testing.T) {
 	}
 }
 
+func TestStackIteratorConcurrentModification(t *testing.T) {
+	stack := New()
+	stack.Push("a")
+	stack.Push("b")
+	it := stack.Iterator()
+	it.Next()
+	stack.Pop()
+	func() {
+		defer func() {
+			if r := recover(); r != containers.ErrConcurrentModification {
+				t.Errorf("Got %v expected %v", r, containers.ErrConcurrentModification)
+			}
+		}()
+		it.Next()
+	}()
+
+	stack.Pop()
+	it.Begin()
+	if it.Next() {
+		t.Errorf("Shouldn't iterate on empty stack")
+	}
+	// popping an empty stack does not modify it
+	stack.Pop()
+	if it.Next() {
+		t.Errorf("Shouldn't iterate on empty stack")
+	}
+	stack.Push("c")
+	it.Begin()
+	if !it.Next() || it.Value() != "c" {
+		t.Errorf("Got %v expected %v", it.Value(), "c")
+	}
+}
+
 func TestStackSerialization(t *testing.T) {
 	stack := New()
 	stack.Push("a")
@@ -12,13 +12,14 @@ func assertIteratorImplementation() {
 
 // Iterator returns a stateful iterator whose values can be fetched by an index.
 type Iterator struct {
-	stack *Stack
-	index int
+	stack    *Stack
+	index    int
+	modCount int
 }
 
 // Iterator returns a stateful iterator whose values can be fetched by an index.
 func (stack *Stack) Iterator() Iterator {
-	return Iterator{stack: stack, index: -1}
+	return Iterator{stack: stack, index: -1, modCount: stack.modCount}
 }
 
 // Next moves the iterator to the next element and returns true if there was a next element in the container.
@@ -26,6 +27,7 @@ func (stack *Stack) Iterator() Iterator {
 // If Next() was called for the first time, then it will point the iterator to the first element if it exists.
 // Modifies the state of the iterator.
 func (iterator *Iterator) Next() bool {
+	iterator.checkModification()
 	if iterator.index < iterator.stack.Size() {
 		iterator.index++
 	}
@@ -36,6 +38,7 @@ func (iterator *Iterator) Next() bool {
 // If Prev() returns true, then previous element's index and value can be retrieved by Index() and Value().
 // Modifies the state of the iterator.
 func (iterator *Iterator) Prev() bool {
+	iterator.checkModification()
 	if iterator.index >= 0 {
 		iterator.index--
 	}
@@ -59,12 +62,14 @@ func (iterator *Iterator) Index() int {
 // Call Next() to fetch the first element if any.
 func (iterator *Iterator) Begin() {
 	iterator.index = -1
+	iterator.modCount = iterator.stack.modCount
 }
 
 // End moves the iterator past the last element (one-past-the-end).
 // Call Prev() to fetch the last element if any.
 func (iterator *Iterator) End() {
 	iterator.index = iterator.stack.Size()
+	iterator.modCount = iterator.stack.modCount
 }
 
 // First moves the iterator to the first element and returns true if there was a first element in the container.
@@ -82,3 +87,13 @@ func (iterator *Iterator) Last() bool {
 	iterator.End()
 	return iterator.Prev()
 }
+
+// checkModification panics if the stack was pushed or popped other than through the iterator.
+func (iterator *Iterator) checkModification() {
+	if iterator.modCount != iterator.stack.modCount {
+		if iterator.index != -1 {
+			panic(containers.ErrConcurrentModification)
+		}
+		iterator.modCount = iterator.stack.modCount
+	}
+}
@@ -12,13 +12,14 @@ func assertIteratorImplementation() {
 
 // Iterator returns a stateful iterator whose values can be fetched by an index.
 type Iterator struct {
-	stack *Stack
-	index int
+	stack    *Stack
+	index    int
+	modCount int
 }
 
 // Iterator returns a stateful iterator whose values can be fetched by an index.
 func (stack *Stack) Iterator() Iterator {
-	return Iterator{stack: stack, index: -1}
+	return Iterator{stack: stack, index: -1, modCount: stack.modCount}
 }
 
 // Next moves the iterator to the next element and returns true if there was a next element in the container.
@@ -26,6 +27,7 @@ func (stack *Stack) Iterator() Iterator {
 // If Next() was called for the first time, then it will point the iterator to the first element if it exists.
 // Modifies the state of the iterator.
 func (iterator *Iterator) Next() bool {
+	iterator.checkModification()
 	if iterator.index < iterator.stack.Size() {
 		iterator.index++
 	}
@@ -49,6 +51,7 @@ func (iterator *Iterator) Index() int {
 // Call Next() to fetch the first element if any.
 func (iterator *Iterator) Begin() {
 	iterator.index = -1
+	iterator.modCount = iterator.stack.modCount
 }
 
 // First moves the iterator to the first element and returns true if there was a first element in the container.
@@ -58,3 +61,13 @@ func (iterator *Iterator) First() bool {
 	iterator.Begin()
 	return iterator.Next()
 }
+
+// checkModification panics if the stack was pushed or popped other than through the iterator.
+func (iterator *Iterator) checkModification() {
+	if iterator.modCount != iterator.stack.modCount {
+		if iterator.index != -1 {
+			panic(containers.ErrConcurrentModification)
+		}
+		iterator.modCount = iterator.stack.modCount
+	}
+}
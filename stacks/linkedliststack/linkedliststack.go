@@ -23,7 +23,8 @@ func assertStackImplementation() {
 // Stack holds elements in a singly-linked-list
 // Stack 基于双向链表实现栈结构
 type Stack struct {
-	list *singlylinkedlist.List
+	list     *singlylinkedlist.List
+	modCount int // number of pushes and pops, checked by iterators
 }
 
 // New nnstantiates a new empty stack
@@ -33,6 +34,7 @@ func New() *Stack {
 
 // Push adds a value onto the top of the stack
 func (stack *Stack) Push(value interface{}) {
+	stack.modCount++
 	stack.list.Prepend(value)
 }
 
@@ -41,6 +43,9 @@ func (stack *Stack) Push(value interface{}) {
 func (stack *Stack) Pop() (value interface{}, ok bool) {
 	value, ok = stack.list.Get(0)
 	stack.list.Remove(0)
+	if ok {
+		stack.modCount++
+	}
 	return
 }
 
@@ -63,6 +68,7 @@ func (stack *Stack) Size() int {
 // Clear removes all elements from the stack.
 func (stack *Stack) Clear() {
 	stack.list.Clear()
+	stack.modCount++
 }
 
 // Values returns all elements in the stack (LIFO order).
@@ -6,6 +6,7 @@ package linkedliststack
 
 import (
 	"fmt"
+	"github.com/dairongpeng/gds/containers"
 	"testing"
 )
 
@@ -148,6 +149,39 @@ func TestStackIteratorFirst(t *testing.T) {
 	}
 }
 
+func TestStackIteratorConcurrentModification(t *testing.T) {
+	stack := New()
+	stack.Push("a")
+	stack.Push("b")
+	it := stack.Iterator()
+	it.Next()
+	stack.Pop()
+	func() {
+		defer func() {
+			if r := recover(); r != containers.ErrConcurrentModification {
+				t.Errorf("Got %v expected %v", r, containers.ErrConcurrentModification)
+			}
+		}()
+		it.Next()
+	}()
+
+	stack.Pop()
+	it.Begin()
+	if it.Next() {
+		t.Errorf("Shouldn't iterate on empty stack")
+	}
+	// popping an empty stack does not modify it
+	stack.Pop()
+	if it.Next() {
+		t.Errorf("Shouldn't iterate on empty stack")
+	}
+	stack.Push("c")
+	it.Begin()
+	if !it.Next() || it.Value() != "c" {
+		t.Errorf("Got %v expected %v", it.Value(), "c")
+	}
+}
+
 func TestStackSerialization(t *testing.T) {
 	stack := New()
 	stack.Push("a")
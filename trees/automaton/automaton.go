@@ -0,0 +1,312 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package automaton implements the Aho-Corasick automaton, which finds all occurrences of a set of patterns in a
+// single pass over the input.
+//
+// The patterns are stored in a trie of runes (see package trie). Every node of the trie has a failure link to the
+// node of the longest proper suffix of its path that is also in the trie, and a dictionary link to the nearest such
+// node that ends a pattern. Matching follows the trie while it can and the failure links otherwise, so the input is
+// read only once and a search takes O(n + m) time for an input of length n with m matches.
+//
+// Build computes the links. Searches build them as well if patterns were added since, so an automaton whose
+// patterns were added after the last Build must not be searched concurrently. Once built, searches do not modify
+// the automaton and may run concurrently.
+//
+// Structure is not thread safe.
+//
+// Reference: https://en.wikipedia.org/wiki/Aho%E2%80%93Corasick_algorithm
+package automaton
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/dairongpeng/gds/trees"
+	"github.com/dairongpeng/gds/trees/trie"
+	"io"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+func assertTreeImplementation() {
+	var _ trees.Tree = (*Automaton)(nil)
+}
+
+// Automaton holds the trie of patterns and its links
+// Automaton AC自动机，在模式串构成的前缀树上建立失配指针，一次扫描即可找出所有匹配
+type Automaton struct {
+	patterns        *trie.Tree            // patterns (in lower case if case-insensitive), the values are entries
+	links           map[*trie.Node]*links // links of every node, nil if patterns were added since the last build
+	longest         int                   // number of runes of the longest pattern
+	caseInsensitive bool
+}
+
+// entry is the value of a pattern in the trie.
+type entry struct {
+	pattern string // pattern as it was added
+	value   interface{}
+}
+
+type links struct {
+	fail       *trie.Node // node of the longest proper suffix in the trie
+	dictionary *trie.Node // nearest node along the failure links that ends a pattern
+	depth      int        // number of runes from the root
+}
+
+// Match is an occurrence of a pattern in the input
+type Match struct {
+	Pattern string      // pattern as it was added
+	Value   interface{} // value attached to the pattern
+	Start   int         // byte offset of the first byte of the occurrence
+	End     int         // byte offset following the last byte of the occurrence
+}
+
+// New instantiates an automaton that matches patterns exactly.
+func New() *Automaton {
+	return &Automaton{patterns: trie.New()}
+}
+
+// NewCaseInsensitive instantiates an automaton that matches patterns regardless of case,
+// comparing the runes of patterns and input in lower case.
+func NewCaseInsensitive() *Automaton {
+	return &Automaton{patterns: trie.New(), caseInsensitive: true}
+}
+
+// Put adds the pattern with the attached value to the automaton, replacing the value if the pattern already exists.
+// Patterns that only differ in case are the same pattern in a case-insensitive automaton.
+// The links are built again by the next Build or search.
+// Pattern should not be empty, otherwise method panics.
+func (automaton *Automaton) Put(pattern string, value interface{}) {
+	if pattern == "" {
+		panic("Cannot add an empty pattern")
+	}
+	key := automaton.foldString(pattern)
+	automaton.patterns.Put(key, entry{pattern: pattern, value: value})
+	if length := utf8.RuneCountInString(key); length > automaton.longest {
+		automaton.longest = length
+	}
+	automaton.links = nil
+}
+
+// Get returns the value attached to the pattern or nil if the pattern is not in the automaton.
+// Second return parameter is true if the pattern was found, otherwise false.
+func (automaton *Automaton) Get(pattern string) (value interface{}, found bool) {
+	if e, found := automaton.patterns.Get(automaton.foldString(pattern)); found {
+		return e.(entry).value, true
+	}
+	return nil, false
+}
+
+// Build computes the failure and dictionary links of the patterns, breadth first so that the links of
+// shallower nodes are known. Searches after it do not modify the automaton until patterns are added again.
+// Build 计算失配指针和输出指针，之后的查找不再修改自动机，可以并发进行
+func (automaton *Automaton) Build() {
+	if automaton.links != nil {
+		return
+	}
+	root := automaton.patterns.Root()
+	all := map[*trie.Node]*links{root: {fail: root}}
+	queue := []*trie.Node{root}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, child := range current.Children() {
+			l := &links{fail: root, depth: all[current].depth + 1}
+			if current != root {
+				fail := all[current].fail
+				for fail != root && fail.Child(child.Label()) == nil {
+					fail = all[fail].fail
+				}
+				if target := fail.Child(child.Label()); target != nil {
+					l.fail = target
+				}
+			}
+			if l.fail.Terminal() {
+				l.dictionary = l.fail
+			} else {
+				l.dictionary = all[l.fail].dictionary
+			}
+			all[child] = l
+			queue = append(queue, child)
+		}
+	}
+	automaton.links = all
+}
+
+// FindAll returns all occurrences of the patterns in the text.
+// Matches are ordered by their end and, for the same end, from the longest to the shortest pattern.
+// FindAll 返回text中所有模式串出现的位置
+func (automaton *Automaton) FindAll(text string) []Match {
+	matches := []Match{}
+	offset := 0
+	automaton.scan(func() (rune, int, bool) {
+		if offset >= len(text) {
+			return 0, 0, false
+		}
+		r, size := utf8.DecodeRuneInString(text[offset:])
+		offset += size
+		return r, size, true
+	}, func(match Match) bool {
+		matches = append(matches, match)
+		return true
+	})
+	return matches
+}
+
+// FindAllBytes returns all occurrences of the patterns in the text, like FindAll.
+func (automaton *Automaton) FindAllBytes(text []byte) []Match {
+	matches := []Match{}
+	offset := 0
+	automaton.scan(func() (rune, int, bool) {
+		if offset >= len(text) {
+			return 0, 0, false
+		}
+		r, size := utf8.DecodeRune(text[offset:])
+		offset += size
+		return r, size, true
+	}, func(match Match) bool {
+		matches = append(matches, match)
+		return true
+	})
+	return matches
+}
+
+// Scan reads the reader to its end and calls the function with every occurrence of the patterns as soon as
+// it has been read, until the function returns false. Offsets of the matches are relative to the start of the reader.
+// Returns the error of the reader, if any other than io.EOF.
+// Scan 流式读取输入，每找到一个匹配就回调f
+func (automaton *Automaton) Scan(reader io.Reader, f func(match Match) bool) error {
+	buffered := bufio.NewReader(reader)
+	var err error
+	automaton.scan(func() (rune, int, bool) {
+		r, size, e := buffered.ReadRune()
+		if e != nil {
+			if e != io.EOF {
+				err = e
+			}
+			return 0, 0, false
+		}
+		return r, size, true
+	}, f)
+	return err
+}
+
+// Patterns returns all patterns in sorted order.
+func (automaton *Automaton) Patterns() []string {
+	entries := automaton.entries()
+	patterns := make([]string, len(entries))
+	for i, e := range entries {
+		patterns[i] = e.pattern
+	}
+	return patterns
+}
+
+// Empty returns true if automaton does not contain any patterns
+func (automaton *Automaton) Empty() bool {
+	return automaton.patterns.Empty()
+}
+
+// Size returns number of patterns in the automaton.
+func (automaton *Automaton) Size() int {
+	return automaton.patterns.Size()
+}
+
+// Clear removes all patterns from the automaton.
+func (automaton *Automaton) Clear() {
+	automaton.patterns.Clear()
+	automaton.longest = 0
+	automaton.links = nil
+}
+
+// Values returns the values attached to the patterns, in sorted order of the patterns.
+func (automaton *Automaton) Values() []interface{} {
+	entries := automaton.entries()
+	values := make([]interface{}, len(entries))
+	for i, e := range entries {
+		values[i] = e.value
+	}
+	return values
+}
+
+// String returns a string representation of container
+func (automaton *Automaton) String() string {
+	str := "Automaton\n"
+	patterns := []string{}
+	for _, e := range automaton.entries() {
+		patterns = append(patterns, fmt.Sprintf("%v:%v", e.pattern, e.value))
+	}
+	str += strings.Join(patterns, ", ")
+	return str
+}
+
+// scan runs the automaton over the runes returned by next and reports the matches to f until it returns false.
+func (automaton *Automaton) scan(next func() (r rune, size int, ok bool), f func(match Match) bool) {
+	if automaton.patterns.Empty() {
+		return
+	}
+	automaton.Build()
+	// byte offsets of the last runes, enough to find the start of the longest pattern
+	starts := make([]int, automaton.longest)
+	root := automaton.patterns.Root()
+	current := root
+	offset := 0
+	for count := 0; ; count++ {
+		r, size, ok := next()
+		if !ok {
+			return
+		}
+		starts[count%automaton.longest] = offset
+		offset += size
+		r = automaton.fold(r)
+		for {
+			if child := current.Child(r); child != nil {
+				current = child
+				break
+			}
+			if current == root {
+				break
+			}
+			current = automaton.links[current].fail
+		}
+		output := current
+		if !output.Terminal() {
+			output = automaton.links[output].dictionary
+		}
+		for ; output != nil; output = automaton.links[output].dictionary {
+			e := output.Value().(entry)
+			start := starts[(count+1-automaton.links[output].depth)%automaton.longest]
+			if !f(Match{Pattern: e.pattern, Value: e.value, Start: start, End: offset}) {
+				return
+			}
+		}
+	}
+}
+
+// entries returns the entries of the patterns, sorted by pattern.
+func (automaton *Automaton) entries() []entry {
+	entries := make([]entry, 0, automaton.patterns.Size())
+	for _, e := range automaton.patterns.Values() {
+		entries = append(entries, e.(entry))
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].pattern < entries[j].pattern
+	})
+	return entries
+}
+
+func (automaton *Automaton) fold(r rune) rune {
+	if automaton.caseInsensitive {
+		return unicode.ToLower(r)
+	}
+	return r
+}
+
+func (automaton *Automaton) foldString(s string) string {
+	if automaton.caseInsensitive {
+		return strings.Map(unicode.ToLower, s)
+	}
+	return s
+}
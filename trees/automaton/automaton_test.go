@@ -0,0 +1,300 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package automaton
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func formatMatches(matches []Match) string {
+	parts := []string{}
+	for _, match := range matches {
+		parts = append(parts, fmt.Sprintf("%v@%v-%v", match.Pattern, match.Start, match.End))
+	}
+	return strings.Join(parts, " ")
+}
+
+func TestAutomatonFindAll(t *testing.T) {
+	automaton := New()
+	for i, pattern := range []string{"he", "she", "his", "hers"} {
+		automaton.Put(pattern, i)
+	}
+
+	if actualValue := automaton.Size(); actualValue != 4 {
+		t.Errorf("Got %v expected %v", actualValue, 4)
+	}
+	matches := automaton.FindAll("ushers")
+	if actualValue, expectedValue := formatMatches(matches), "she@1-4 he@2-4 hers@2-6"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue := matches[0].Value; actualValue != 1 {
+		t.Errorf("Got %v expected %v", actualValue, 1)
+	}
+	if actualValue, expectedValue := formatMatches(automaton.FindAllBytes([]byte("hishe"))), "his@0-3 she@2-5 he@3-5"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue := automaton.FindAll("xyz"); len(actualValue) != 0 {
+		t.Errorf("Got %v expected %v", actualValue, "[]")
+	}
+}
+
+func TestAutomatonOverlapping(t *testing.T) {
+	automaton := New()
+	automaton.Put("a", nil)
+	automaton.Put("aa", nil)
+	automaton.Put("aaa", nil)
+	if actualValue, expectedValue := formatMatches(automaton.FindAll("aaaa")), "a@0-1 aa@0-2 a@1-2 aaa@0-3 aa@1-3 a@2-3 aaa@1-4 aa@2-4 a@3-4"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+}
+
+func TestAutomatonUnicode(t *testing.T) {
+	automaton := New()
+	automaton.Put("日本", "japan")
+	automaton.Put("本語", "language")
+	automaton.Put("é", "e")
+	if actualValue, expectedValue := formatMatches(automaton.FindAll("café 日本語")), "é@3-5 日本@6-12 本語@9-15"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+}
+
+func TestAutomatonCaseInsensitive(t *testing.T) {
+	automaton := NewCaseInsensitive()
+	automaton.Put("Error", 1)
+	automaton.Put("TIMEOUT", 2)
+	automaton.Put("error", 3) // same pattern
+
+	if actualValue := automaton.Size(); actualValue != 2 {
+		t.Errorf("Got %v expected %v", actualValue, 2)
+	}
+	if actualValue, found := automaton.Get("ERROR"); actualValue != 3 || !found {
+		t.Errorf("Got %v expected %v", actualValue, 3)
+	}
+	text := "ERROR: Timeout after 3s, error"
+	if actualValue, expectedValue := formatMatches(automaton.FindAll(text)), "error@0-5 TIMEOUT@7-14 error@25-30"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+
+	// the Kelvin sign is three bytes long, its lower case is a plain k
+	automaton.Put("ok", 4)
+	matches := automaton.FindAll("oK!")
+	if actualValue, expectedValue := formatMatches(matches), "ok@0-4"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+
+	exact := New()
+	exact.Put("Error", 1)
+	if actualValue := exact.FindAll(text); len(actualValue) != 0 {
+		t.Errorf("Got %v expected %v", formatMatches(actualValue), "")
+	}
+}
+
+func TestAutomatonScan(t *testing.T) {
+	automaton := New()
+	automaton.Put("needle", nil)
+	text := strings.Repeat("hay", 5000) + "needle" + strings.Repeat("hay", 5000) + "needle"
+
+	matches := []Match{}
+	err := automaton.Scan(strings.NewReader(text), func(match Match) bool {
+		matches = append(matches, match)
+		return true
+	})
+	if err != nil {
+		t.Errorf("Got error %v", err)
+	}
+	if actualValue, expectedValue := formatMatches(matches), "needle@15000-15006 needle@30006-30012"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+
+	// stop after the first match
+	count := 0
+	automaton.Scan(strings.NewReader(text), func(match Match) bool {
+		count++
+		return false
+	})
+	if actualValue := count; actualValue != 1 {
+		t.Errorf("Got %v expected %v", actualValue, 1)
+	}
+
+	failing := io.MultiReader(strings.NewReader("needle"), &errorReader{})
+	count = 0
+	err = automaton.Scan(failing, func(match Match) bool {
+		count++
+		return true
+	})
+	if err == nil || count != 1 {
+		t.Errorf("Got %v,%v expected an error after %v match", err, count, 1)
+	}
+}
+
+type errorReader struct{}
+
+func (r *errorReader) Read(p []byte) (int, error) {
+	return 0, errors.New("broken")
+}
+
+func TestAutomatonAddAfterSearch(t *testing.T) {
+	automaton := New()
+	automaton.Put("abc", nil)
+	if actualValue := len(automaton.FindAll("xabcd")); actualValue != 1 {
+		t.Errorf("Got %v expected %v", actualValue, 1)
+	}
+	automaton.Put("bcd", nil)
+	if actualValue, expectedValue := formatMatches(automaton.FindAll("xabcd")), "abc@1-4 bcd@2-5"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	automaton.Clear()
+	if actualValue := automaton.FindAll("xabcd"); len(actualValue) != 0 || !automaton.Empty() {
+		t.Errorf("Got %v expected %v", formatMatches(actualValue), "")
+	}
+}
+
+func TestAutomatonEmptyPattern(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Expected panic for an empty pattern")
+		}
+	}()
+	New().Put("", nil)
+}
+
+func TestAutomatonRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	randomString := func(length int) string {
+		b := make([]byte, length)
+		for i := range b {
+			b[i] = "abc"[r.Intn(3)]
+		}
+		return string(b)
+	}
+	for round := 0; round < 20; round++ {
+		automaton := New()
+		patterns := map[string]bool{}
+		for i := 0; i < 10; i++ {
+			pattern := randomString(1 + r.Intn(4))
+			patterns[pattern] = true
+			automaton.Put(pattern, nil)
+		}
+		text := randomString(200)
+
+		expected := 0
+		for pattern := range patterns {
+			for i := 0; i+len(pattern) <= len(text); i++ {
+				if text[i:i+len(pattern)] == pattern {
+					expected++
+				}
+			}
+		}
+		matches := automaton.FindAll(text)
+		if actualValue := len(matches); actualValue != expected {
+			t.Errorf("Got %v expected %v matches", actualValue, expected)
+		}
+		for _, match := range matches {
+			if actualValue := text[match.Start:match.End]; actualValue != match.Pattern {
+				t.Errorf("Got %v expected %v", actualValue, match.Pattern)
+			}
+		}
+	}
+}
+
+func TestAutomatonPatternsAndValues(t *testing.T) {
+	automaton := New()
+	automaton.Put("b", 2)
+	automaton.Put("a", 1)
+	automaton.Put("ab", 3)
+	if actualValue, expectedValue := fmt.Sprintf("%v", automaton.Patterns()), "[a ab b]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue, expectedValue := fmt.Sprintf("%v", automaton.Values()), "[1 3 2]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue, expectedValue := automaton.String(), "Automaton\na:1, ab:3, b:2"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if _, found := automaton.Get("c"); found {
+		t.Errorf("Got %v expected %v", found, false)
+	}
+}
+
+func TestAutomatonStats(t *testing.T) {
+	automaton := New()
+	if actualValue, expectedValue := fmt.Sprintf("%+v", automaton.Stats()), "{Height:0 Nodes:0 AverageDepth:0 Rotations:0 Splits:0 Merges:0 Comparisons:0}"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	for _, pattern := range []string{"he", "she", "his", "hers"} {
+		automaton.Put(pattern, pattern)
+	}
+	if actualValue, expectedValue := fmt.Sprintf("%+v", automaton.Stats()), "{Height:5 Nodes:10 AverageDepth:3 Rotations:0 Splits:0 Merges:0 Comparisons:0}"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+}
+
+func TestAutomatonBuild(t *testing.T) {
+	automaton := New()
+	automaton.Build()
+	for i, pattern := range []string{"he", "she", "his", "hers"} {
+		automaton.Put(pattern, i)
+	}
+	automaton.Build()
+	automaton.Build()
+	var wg sync.WaitGroup
+	results := make([]string, 8)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = formatMatches(automaton.FindAll("ushers hishe"))
+		}(i)
+	}
+	wg.Wait()
+	for _, actualValue := range results {
+		if expectedValue := "she@1-4 he@2-4 hers@2-6 his@7-10 she@9-12 he@10-12"; actualValue != expectedValue {
+			t.Errorf("Got %v expected %v", actualValue, expectedValue)
+		}
+	}
+}
+
+func benchmarkData() (patterns []string, text string) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		patterns = append(patterns, fmt.Sprintf("keyword%d", r.Intn(1000000)))
+	}
+	lines := []string{}
+	for i := 0; i < 1000; i++ {
+		lines = append(lines, fmt.Sprintf("request %d served in %dms keyword%d", i, r.Intn(100), r.Intn(1000000)))
+	}
+	return patterns, strings.Join(lines, "\n")
+}
+
+func BenchmarkAutomatonFindAll(b *testing.B) {
+	b.StopTimer()
+	patterns, text := benchmarkData()
+	automaton := New()
+	for _, pattern := range patterns {
+		automaton.Put(pattern, nil)
+	}
+	automaton.Build()
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		automaton.FindAll(text)
+	}
+}
+
+func BenchmarkStringsContains(b *testing.B) {
+	b.StopTimer()
+	patterns, text := benchmarkData()
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		for _, pattern := range patterns {
+			strings.Contains(text, pattern)
+		}
+	}
+}
@@ -0,0 +1,13 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package automaton
+
+import "github.com/dairongpeng/gds/trees"
+
+// Stats returns the shape of the trie of the automaton, where the depth of a pattern is its number of runes,
+// see trie.Tree.Stats.
+func (automaton *Automaton) Stats() trees.Stats {
+	return automaton.patterns.Stats()
+}
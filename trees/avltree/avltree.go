@@ -25,24 +25,51 @@ type Tree struct {
 	Root       *Node            // Root node
 	Comparator utils.Comparator // Key comparator
 	size       int              // Total number of keys in the tree
+	augmenter  Augmenter        // Computes node aggregates, may be nil
+	multi      bool             // Equal keys are kept as distinct nodes
+	modCount   int              // Number of inserted and removed nodes, checked by iterators
+	pool       *pool            // Allocates the nodes, may be nil
+	stats      stats            // Counts the work done by the tree
 }
 
 // Node is a single element within the tree
 // Node AVL树的节点结构，二叉树的节点，节点含有key-value,及指向父亲节点的指针，两个孩子指针,及以自身为头节点的平衡因子
 type Node struct {
-	Key      interface{}
-	Value    interface{}
-	Parent   *Node    // Parent node
-	Children [2]*Node // Children nodes
-	b        int8
+	Key       interface{}
+	Value     interface{}
+	Aggregate interface{} // Maintained by the tree's Augmenter, nil if the tree has none
+	Parent    *Node       // Parent node
+	Children  [2]*Node    // Children nodes
+	b         int8
 }
 
+// Augmenter computes the aggregate of a node from its key, value and the aggregates of its children.
+// The tree calls it bottom-up whenever the subtree rooted at the node changes (inserts, removals and rotations),
+// so the children passed along with the node always hold up-to-date aggregates.
+// Augmenter 根据节点自身的key、value以及左右孩子的聚合值，计算以该节点为头的子树的聚合值
+type Augmenter func(node *Node) interface{}
+
 // NewWith instantiates an AVL tree with the custom comparator.
 // NewWith 实例化一颗AVL树，传入指定的比较器
 func NewWith(comparator utils.Comparator) *Tree {
 	return &Tree{Comparator: comparator}
 }
 
+// NewWithAugmenter instantiates an AVL tree with the custom comparator that maintains per-node aggregates with the augmenter.
+// The aggregates make it possible to answer queries over key ranges (sums, minimum and maximum of values, ...) in O(log n).
+// NewWithAugmenter 实例化一颗AVL树，树在插入、删除和旋转时通过augmenter维护每个节点的聚合值
+func NewWithAugmenter(comparator utils.Comparator, augmenter Augmenter) *Tree {
+	return &Tree{Comparator: comparator, augmenter: augmenter}
+}
+
+// NewWithPool instantiates an AVL tree with the custom comparator whose nodes come from a pool, see UsePool.
+// NewWithPool 实例化一颗AVL树，节点按slabSize批量分配，删除的节点会被之后的插入复用
+func NewWithPool(comparator utils.Comparator, slabSize int) *Tree {
+	t := NewWith(comparator)
+	t.UsePool(slabSize)
+	return t
+}
+
 // NewWithIntComparator instantiates an AVL tree with the IntComparator, i.e. keys are of type int.
 // NewWithIntComparator 实例化一颗AVL树，基于int比较器
 func NewWithIntComparator() *Tree {
@@ -55,7 +82,36 @@ func NewWithStringComparator() *Tree {
 	return &Tree{Comparator: utils.StringComparator}
 }
 
+// NewMultiWith instantiates an AVL tree with the custom comparator in multi-key mode.
+// In multi-key mode Put keeps equal keys as distinct nodes, which are visited in insertion order.
+// NewMultiWith 实例化一颗允许重复key的AVL树，相同的key按照插入顺序保存为不同的节点
+func NewMultiWith(comparator utils.Comparator) *Tree {
+	return &Tree{Comparator: comparator, multi: true}
+}
+
+// NewMultiWithIntComparator instantiates an AVL tree in multi-key mode with the IntComparator, i.e. keys are of type int.
+func NewMultiWithIntComparator() *Tree {
+	return NewMultiWith(utils.IntComparator)
+}
+
+// NewMultiWithStringComparator instantiates an AVL tree in multi-key mode with the StringComparator, i.e. keys are of type string.
+func NewMultiWithStringComparator() *Tree {
+	return NewMultiWith(utils.StringComparator)
+}
+
+// UsePool makes the tree allocate its nodes slabSize at a time and reuse the nodes of removed keys for later
+// insertions, so that inserting and removing keys at a high rate leaves little garbage. A slabSize of 1 only reuses
+// removed nodes. It can be combined with NewWithAugmenter and NewMultiWith, and takes effect for the nodes
+// inserted afterwards, so it is best called on an empty tree.
+// Nodes obtained from the tree (Left, Right, Floor, Ceiling) must not be used once their key was removed,
+// since the node may already hold another key, and a slab is kept in memory as long as any of its nodes is.
+// UsePool 让AVL树的节点按slabSize批量分配，删除的节点会被之后的插入复用，可以和其他构造函数组合使用
+func (t *Tree) UsePool(slabSize int) {
+	t.pool = newPool(slabSize)
+}
+
 // Put inserts node into the tree.
+// In multi-key mode a key equal to existing keys is inserted after them, otherwise the node of the key is updated.
 // Key should adhere to the comparator's type assertion, otherwise method panics.
 // Put会把一组k-v Put到AVL树中，k需要是该AVL树的比较器能够比较的类型
 func (t *Tree) Put(key interface{}, value interface{}) {
@@ -63,28 +119,67 @@ func (t *Tree) Put(key interface{}, value interface{}) {
 }
 
 // Get searches the node in the tree by key and returns its value or nil if key is not found in tree.
+// In multi-key mode it returns the value of the first node of the key.
 // Second return parameter is true if key was found, otherwise false.
 // Key should adhere to the comparator's type assertion, otherwise method panics.
 func (t *Tree) Get(key interface{}) (value interface{}, found bool) {
-	n := t.Root
-	for n != nil {
-		cmp := t.Comparator(key, n.Key)
-		switch {
-		case cmp == 0:
-			return n.Value, true
-		case cmp < 0:
-			n = n.Children[0]
-		case cmp > 0:
-			n = n.Children[1]
-		}
+	if n := t.lookup(key); n != nil {
+		return n.Value, true
 	}
 	return nil, false
 }
 
 // Remove remove the node from the tree by key.
+// In multi-key mode it removes all nodes of the key, like RemoveAll.
 // Key should adhere to the comparator's type assertion, otherwise method panics.
 func (t *Tree) Remove(key interface{}) {
-	t.remove(key, &t.Root)
+	if t.multi {
+		t.RemoveAll(key)
+		return
+	}
+	t.RemoveOne(key)
+}
+
+// RemoveOne removes the first node of the key from the tree, the one inserted first in multi-key mode.
+// Key should adhere to the comparator's type assertion, otherwise method panics.
+func (t *Tree) RemoveOne(key interface{}) {
+	if n := t.lookup(key); n != nil {
+		t.removeNode(n)
+	}
+}
+
+// RemoveAll removes all nodes of the key from the tree.
+// Key should adhere to the comparator's type assertion, otherwise method panics.
+func (t *Tree) RemoveAll(key interface{}) {
+	for n := t.lookup(key); n != nil; n = t.lookup(key) {
+		t.removeNode(n)
+	}
+}
+
+// Count returns the number of nodes of the key, at most one unless in multi-key mode.
+// Key should adhere to the comparator's type assertion, otherwise method panics.
+func (t *Tree) Count(key interface{}) int {
+	count := 0
+	for n := t.lookup(key); n != nil && t.compare(key, n.Key) == 0; n = n.Next() {
+		count++
+	}
+	return count
+}
+
+// GetAll returns the values of all nodes of the key in insertion order, an empty slice if key is not found in tree.
+// Key should adhere to the comparator's type assertion, otherwise method panics.
+// GetAll 按照插入顺序返回key对应的所有value
+func (t *Tree) GetAll(key interface{}) []interface{} {
+	values := []interface{}{}
+	for n := t.lookup(key); n != nil && t.compare(key, n.Key) == 0; n = n.Next() {
+		values = append(values, n.Value)
+	}
+	return values
+}
+
+// Multi returns true if the tree keeps equal keys as distinct nodes.
+func (t *Tree) Multi() bool {
+	return t.multi
 }
 
 // Empty returns true if tree does not contain any nodes.
@@ -133,6 +228,7 @@ func (t *Tree) Right() *Node {
 // Second return parameter is true if floor was found, otherwise false.
 //
 // Floor node is defined as the largest node that is smaller than or equal to the given node.
+// In multi-key mode it is the last node of equal keys.
 // A floor node may not be found, either because the tree is empty, or because
 // all nodes in the tree is larger than the given node.
 //
@@ -141,13 +237,13 @@ func (t *Tree) Floor(key interface{}) (floor *Node, found bool) {
 	found = false
 	n := t.Root
 	for n != nil {
-		c := t.Comparator(key, n.Key)
+		c := t.compare(key, n.Key)
 		switch {
-		case c == 0:
+		case c == 0 && !t.multi:
 			return n, true
 		case c < 0:
 			n = n.Children[0]
-		case c > 0:
+		default:
 			floor, found = n, true
 			n = n.Children[1]
 		}
@@ -162,6 +258,7 @@ func (t *Tree) Floor(key interface{}) (floor *Node, found bool) {
 // Second return parameter is true if ceiling was found, otherwise false.
 //
 // Ceiling node is defined as the smallest node that is larger than or equal to the given node.
+// In multi-key mode it is the first node of equal keys.
 // A ceiling node may not be found, either because the tree is empty, or because
 // all nodes in the tree is smaller than the given node.
 //
@@ -170,15 +267,15 @@ func (t *Tree) Ceiling(key interface{}) (floor *Node, found bool) {
 	found = false
 	n := t.Root
 	for n != nil {
-		c := t.Comparator(key, n.Key)
+		c := t.compare(key, n.Key)
 		switch {
-		case c == 0:
+		case c == 0 && !t.multi:
 			return n, true
-		case c < 0:
-			floor, found = n, true
-			n = n.Children[0]
 		case c > 0:
 			n = n.Children[1]
+		default:
+			floor, found = n, true
+			n = n.Children[0]
 		}
 	}
 	if found {
@@ -191,6 +288,7 @@ func (t *Tree) Ceiling(key interface{}) (floor *Node, found bool) {
 func (t *Tree) Clear() {
 	t.Root = nil
 	t.size = 0
+	t.modCount++
 }
 
 // String returns a string representation of container
@@ -210,14 +308,17 @@ func (t *Tree) put(key interface{}, value interface{}, p *Node, qp **Node) bool
 	q := *qp
 	if q == nil {
 		t.size++
-		*qp = &Node{Key: key, Value: value, Parent: p}
+		t.modCount++
+		*qp = t.newNode(key, value, p)
+		t.augment(*qp)
 		return true
 	}
 
-	c := t.Comparator(key, q.Key)
-	if c == 0 {
+	c := t.compare(key, q.Key)
+	if c == 0 && !t.multi {
 		q.Key = key
 		q.Value = value
+		t.augment(q)
 		return false
 	}
 
@@ -230,67 +331,93 @@ func (t *Tree) put(key interface{}, value interface{}, p *Node, qp **Node) bool
 	var fix bool
 	fix = t.put(key, value, q, &q.Children[a])
 	if fix {
-		return putFix(int8(c), qp)
+		fix = t.putFix(int8(c), qp)
 	}
-	return false
+	t.augment(*qp)
+	return fix
 }
 
-func (t *Tree) remove(key interface{}, qp **Node) bool {
-	q := *qp
-	if q == nil {
-		return false
+// removeNode removes the node from the tree and restores the balance from its parent up to the root.
+// A node with two children takes the key and value of its successor, whose node is unlinked instead.
+func (t *Tree) removeNode(n *Node) {
+	t.size--
+	t.modCount++
+	if n.Children[0] != nil && n.Children[1] != nil {
+		s := n.Children[1]
+		for s.Children[0] != nil {
+			s = s.Children[0]
+		}
+		n.Key = s.Key
+		n.Value = s.Value
+		n = s
 	}
 
-	c := t.Comparator(key, q.Key)
-	if c == 0 {
-		t.size--
-		if q.Children[1] == nil {
-			if q.Children[0] != nil {
-				q.Children[0].Parent = q.Parent
-			}
-			*qp = q.Children[0]
-			return true
+	// the node has at most one child, which takes its place
+	child := n.Children[0]
+	if child == nil {
+		child = n.Children[1]
+	}
+	if child != nil {
+		child.Parent = n.Parent
+	}
+	p, c := n.Parent, n.side()
+	*t.link(n) = child
+	t.freeNode(n)
+
+	// a rotation replaces the subtree root, so its parent and side are taken before
+	for shrank := true; p != nil; {
+		qp, parent, side := t.link(p), p.Parent, p.side()
+		if shrank {
+			shrank = t.removeFix(-c, qp)
 		}
-		fix := removeMin(&q.Children[1], &q.Key, &q.Value)
-		if fix {
-			return removeFix(-1, qp)
+		t.augment(*qp)
+		if !shrank && t.augmenter == nil {
+			return
 		}
-		return false
+		p, c = parent, side
 	}
+}
 
-	if c < 0 {
-		c = -1
-	} else {
-		c = 1
+// link returns the child pointer of the node's parent that points to the node, or the root pointer.
+func (t *Tree) link(n *Node) **Node {
+	if n.Parent == nil {
+		return &t.Root
 	}
-	a := (c + 1) / 2
-	fix := t.remove(key, &q.Children[a])
-	if fix {
-		return removeFix(int8(-c), qp)
+	return &n.Parent.Children[(n.side()+1)/2]
+}
+
+// side returns -1 if the node is the left child of its parent and 1 otherwise.
+func (n *Node) side() int8 {
+	if n.Parent != nil && n.Parent.Children[0] == n {
+		return -1
 	}
-	return false
+	return 1
 }
 
-func removeMin(qp **Node, minKey *interface{}, minVal *interface{}) bool {
-	q := *qp
-	if q.Children[0] == nil {
-		*minKey = q.Key
-		*minVal = q.Value
-		if q.Children[1] != nil {
-			q.Children[1].Parent = q.Parent
+// lookup returns the node of the key, the first one in multi-key mode, or nil if key is not found in tree.
+func (t *Tree) lookup(key interface{}) *Node {
+	var found *Node
+	n := t.Root
+	for n != nil {
+		cmp := t.compare(key, n.Key)
+		switch {
+		case cmp == 0:
+			if !t.multi {
+				return n
+			}
+			found = n
+			n = n.Children[0]
+		case cmp < 0:
+			n = n.Children[0]
+		case cmp > 0:
+			n = n.Children[1]
 		}
-		*qp = q.Children[1]
-		return true
-	}
-	fix := removeMin(&q.Children[0], minKey, minVal)
-	if fix {
-		return removeFix(1, qp)
 	}
-	return false
+	return found
 }
 
-func putFix(c int8, t **Node) bool {
-	s := *t
+func (t *Tree) putFix(c int8, qp **Node) bool {
+	s := *qp
 	if s.b == 0 {
 		s.b = c
 		return true
@@ -302,16 +429,16 @@ func putFix(c int8, t **Node) bool {
 	}
 
 	if s.Children[(c+1)/2].b == c {
-		s = singlerot(c, s)
+		s = t.singlerot(c, s)
 	} else {
-		s = doublerot(c, s)
+		s = t.doublerot(c, s)
 	}
-	*t = s
+	*qp = s
 	return false
 }
 
-func removeFix(c int8, t **Node) bool {
-	s := *t
+func (t *Tree) removeFix(c int8, qp **Node) bool {
+	s := *qp
 	if s.b == 0 {
 		s.b = c
 		return false
@@ -324,33 +451,33 @@ func removeFix(c int8, t **Node) bool {
 
 	a := (c + 1) / 2
 	if s.Children[a].b == 0 {
-		s = rotate(c, s)
+		s = t.rotate(c, s)
 		s.b = -c
-		*t = s
+		*qp = s
 		return false
 	}
 
 	if s.Children[a].b == c {
-		s = singlerot(c, s)
+		s = t.singlerot(c, s)
 	} else {
-		s = doublerot(c, s)
+		s = t.doublerot(c, s)
 	}
-	*t = s
+	*qp = s
 	return true
 }
 
-func singlerot(c int8, s *Node) *Node {
+func (t *Tree) singlerot(c int8, s *Node) *Node {
 	s.b = 0
-	s = rotate(c, s)
+	s = t.rotate(c, s)
 	s.b = 0
 	return s
 }
 
-func doublerot(c int8, s *Node) *Node {
+func (t *Tree) doublerot(c int8, s *Node) *Node {
 	a := (c + 1) / 2
 	r := s.Children[a]
-	s.Children[a] = rotate(-c, s.Children[a])
-	p := rotate(c, s)
+	s.Children[a] = t.rotate(-c, s.Children[a])
+	p := t.rotate(c, s)
 
 	switch {
 	default:
@@ -368,7 +495,8 @@ func doublerot(c int8, s *Node) *Node {
 	return p
 }
 
-func rotate(c int8, s *Node) *Node {
+func (t *Tree) rotate(c int8, s *Node) *Node {
+	t.stats.rotations++
 	a := (c + 1) / 2
 	r := s.Children[a]
 	s.Children[a] = r.Children[a^1]
@@ -378,9 +506,18 @@ func rotate(c int8, s *Node) *Node {
 	r.Children[a^1] = s
 	r.Parent = s.Parent
 	s.Parent = r
+	t.augment(s)
+	t.augment(r)
 	return r
 }
 
+// augment recomputes the aggregate of the node from its children.
+func (t *Tree) augment(n *Node) {
+	if t.augmenter != nil {
+		n.Aggregate = t.augmenter(n)
+	}
+}
+
 func (t *Tree) bottom(d int) *Node {
 	n := t.Root
 	if n == nil {
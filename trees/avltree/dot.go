@@ -0,0 +1,58 @@
+// Copyright (c) 2017, Benjamin Scher Purcell. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package avltree
+
+import (
+	"fmt"
+	"github.com/dairongpeng/gds/containers"
+	"io"
+	"strings"
+)
+
+func assertDOTImplementation() {
+	var _ containers.DOTWriter = (*Tree)(nil)
+}
+
+// WriteDOT writes the tree as a Graphviz DOT digraph with the balance factor of every node next to it.
+// Missing children are laid out invisibly, so left and right children can be told apart.
+// Subtrees beyond the maximum depth of the options are drawn as "...".
+// WriteDOT 以DOT格式输出AVL树，节点旁标注平衡因子
+func (t *Tree) WriteDOT(w io.Writer, options containers.DOTOptions) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph %s {\n", options.GraphName())
+	b.WriteString("\tnode [shape=ellipse];\n")
+	id := 0
+	var write func(n *Node, depth int) string
+	write = func(n *Node, depth int) string {
+		name := fmt.Sprintf("n%d", id)
+		id++
+		switch {
+		case n == nil:
+			fmt.Fprintf(&b, "\t%s [shape=point, style=invis];\n", name)
+		case options.Truncated(depth):
+			fmt.Fprintf(&b, "\t%s [label=\"...\", shape=plaintext];\n", name)
+		default:
+			balance := fmt.Sprint(n.b)
+			if n.b > 0 {
+				balance = "+" + balance
+			}
+			fmt.Fprintf(&b, "\t%s [label=%s, xlabel=\"%s\"];\n", name, options.Label(n.Key, n.Value), balance)
+			for _, c := range n.Children {
+				if c == nil {
+					fmt.Fprintf(&b, "\t%s -> %s [style=invis];\n", name, write(c, depth+1))
+				} else {
+					fmt.Fprintf(&b, "\t%s -> %s;\n", name, write(c, depth+1))
+				}
+			}
+		}
+		return name
+	}
+	if t.Root != nil {
+		write(t.Root, 0)
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
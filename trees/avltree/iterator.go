@@ -8,6 +8,7 @@ import "github.com/dairongpeng/gds/containers"
 
 func assertIteratorImplementation() {
 	var _ containers.ReverseIteratorWithKey = (*Iterator)(nil)
+	var _ containers.IteratorWithRemove = (*Iterator)(nil)
 }
 
 // Iterator holding the iterator's state
@@ -15,6 +16,8 @@ type Iterator struct {
 	tree     *Tree
 	node     *Node
 	position position
+	modCount int
+	removed  bool // the current node was removed, node is its predecessor
 }
 
 type position byte
@@ -24,8 +27,9 @@ const (
 )
 
 // Iterator returns a stateful iterator whose elements are key/value pairs.
+// The iterator is an *Iterator, which also implements containers.IteratorWithRemove.
 func (tree *Tree) Iterator() containers.ReverseIteratorWithKey {
-	return &Iterator{tree: tree, node: nil, position: begin}
+	return &Iterator{tree: tree, node: nil, position: begin, modCount: tree.modCount}
 }
 
 // Next moves the iterator to the next element and returns true if there was a next element in the container.
@@ -33,11 +37,16 @@ func (tree *Tree) Iterator() containers.ReverseIteratorWithKey {
 // If Next() was called for the first time, then it will point the iterator to the first element if it exists.
 // Modifies the state of the iterator.
 func (iterator *Iterator) Next() bool {
-	switch iterator.position {
-	case begin:
+	iterator.checkModification()
+	switch {
+	case iterator.removed && iterator.node == nil:
+		iterator.removed = false
+		iterator.node = iterator.tree.Left()
+	case iterator.position == begin:
 		iterator.position = between
 		iterator.node = iterator.tree.Left()
-	case between:
+	case iterator.position == between:
+		iterator.removed = false
 		iterator.node = iterator.node.Next()
 	}
 
@@ -53,6 +62,15 @@ func (iterator *Iterator) Next() bool {
 // If Prev() was called for the first time, then it will point the iterator to the first element if it exists.
 // Modifies the state of the iterator.
 func (iterator *Iterator) Prev() bool {
+	iterator.checkModification()
+	if iterator.removed {
+		iterator.removed = false
+		if iterator.node == nil {
+			iterator.position = begin
+			return false
+		}
+		return true
+	}
 	switch iterator.position {
 	case end:
 		iterator.position = between
@@ -91,6 +109,8 @@ func (iterator *Iterator) Key() interface{} {
 func (iterator *Iterator) Begin() {
 	iterator.node = nil
 	iterator.position = begin
+	iterator.removed = false
+	iterator.modCount = iterator.tree.modCount
 }
 
 // End moves the iterator past the last element (one-past-the-end).
@@ -98,6 +118,8 @@ func (iterator *Iterator) Begin() {
 func (iterator *Iterator) End() {
 	iterator.node = nil
 	iterator.position = end
+	iterator.removed = false
+	iterator.modCount = iterator.tree.modCount
 }
 
 // First moves the iterator to the first element and returns true if there was a first element in the container.
@@ -115,3 +137,29 @@ func (iterator *Iterator) Last() bool {
 	iterator.End()
 	return iterator.Prev()
 }
+
+// Remove removes the current node from the tree.
+// Next() moves the iterator to the node after the removed one and Prev() to the node before it.
+// Panics with containers.ErrIteratorNotOnElement if the iterator is not on a node.
+func (iterator *Iterator) Remove() {
+	iterator.checkModification()
+	if iterator.removed || iterator.position != between {
+		panic(containers.ErrIteratorNotOnElement)
+	}
+	// removing a node with two children moves its successor into it, the predecessor stays in place
+	prev := iterator.node.Prev()
+	iterator.tree.removeNode(iterator.node)
+	iterator.node = prev
+	iterator.modCount = iterator.tree.modCount
+	iterator.removed = true
+}
+
+// checkModification panics if the tree was structurally modified other than through the iterator.
+func (iterator *Iterator) checkModification() {
+	if iterator.modCount != iterator.tree.modCount {
+		if iterator.position != begin || iterator.removed {
+			panic(containers.ErrConcurrentModification)
+		}
+		iterator.modCount = iterator.tree.modCount
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright (c) 2017, Benjamin Scher Purcell. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package avltree
+
+// pool hands out the nodes of a tree from slabs and keeps removed nodes in a free list for reuse.
+type pool struct {
+	slab     []Node
+	free     *Node // removed nodes, linked through their right child
+	slabSize int
+}
+
+func newPool(slabSize int) *pool {
+	if slabSize < 1 {
+		slabSize = 1
+	}
+	return &pool{slabSize: slabSize}
+}
+
+// get returns a zeroed node, reusing a removed node if there is one.
+func (p *pool) get() *Node {
+	if n := p.free; n != nil {
+		p.free = n.Children[1]
+		n.Children[1] = nil
+		return n
+	}
+	if len(p.slab) == 0 {
+		p.slab = make([]Node, p.slabSize)
+	}
+	n := &p.slab[0]
+	p.slab = p.slab[1:]
+	return n
+}
+
+// put clears the node, so that its key and value can be garbage collected, and adds it to the free list.
+func (p *pool) put(n *Node) {
+	*n = Node{}
+	n.Children[1] = p.free
+	p.free = n
+}
+
+// newNode returns a node holding the key and value, taken from the pool if the tree has one.
+func (t *Tree) newNode(key interface{}, value interface{}, p *Node) *Node {
+	if t.pool == nil {
+		return &Node{Key: key, Value: value, Parent: p}
+	}
+	n := t.pool.get()
+	n.Key = key
+	n.Value = value
+	n.Parent = p
+	return n
+}
+
+// freeNode returns the node unlinked from the tree to the pool if the tree has one.
+func (t *Tree) freeNode(n *Node) {
+	if t.pool != nil {
+		t.pool.put(n)
+	}
+}
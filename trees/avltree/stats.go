@@ -0,0 +1,47 @@
+// Copyright (c) 2017, Benjamin Scher Purcell. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package avltree
+
+import "github.com/dairongpeng/gds/trees"
+
+// stats counts the work done by the tree since it was created.
+type stats struct {
+	rotations   int
+	comparisons int
+}
+
+// Stats returns the shape of the tree and the number of rotations and comparator calls since it was created.
+// The shape is measured by walking the tree in O(n).
+func (t *Tree) Stats() trees.Stats {
+	s := trees.Stats{
+		Nodes:       t.size,
+		Rotations:   t.stats.rotations,
+		Comparisons: t.stats.comparisons,
+	}
+	depths := 0
+	var walk func(n *Node, depth int)
+	walk = func(n *Node, depth int) {
+		if n == nil {
+			return
+		}
+		depths += depth
+		if depth+1 > s.Height {
+			s.Height = depth + 1
+		}
+		walk(n.Children[0], depth+1)
+		walk(n.Children[1], depth+1)
+	}
+	walk(t.Root, 0)
+	if t.size > 0 {
+		s.AverageDepth = float64(depths) / float64(t.size)
+	}
+	return s
+}
+
+// compare calls the comparator and counts the call.
+func (t *Tree) compare(a interface{}, b interface{}) int {
+	t.stats.comparisons++
+	return t.Comparator(a, b)
+}
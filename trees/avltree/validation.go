@@ -0,0 +1,69 @@
+// Copyright (c) 2017, Benjamin Scher Purcell. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package avltree
+
+import "fmt"
+
+// Validate checks the invariants of the tree and returns an error naming the offending key of the first violation, or nil.
+// It checks that keys are in order under the comparator (equal keys are allowed in multi-key mode), that every child
+// links to its parent, that the size matches the number of nodes and that the balance factor of every node is the height
+// of its right subtree minus the height of its left subtree and lies between -1 and 1.
+// Validate visits every node, it is meant for tests of code that extends the tree.
+// Validate 校验AVL树的有序性、父指针、节点数量以及平衡因子
+func (t *Tree) Validate() error {
+	if t.Root != nil && t.Root.Parent != nil {
+		return fmt.Errorf("avltree: root %v has a parent", t.Root.Key)
+	}
+	count := 0
+	if _, err := t.validate(t.Root, nil, nil, &count); err != nil {
+		return err
+	}
+	if count != t.size {
+		return fmt.Errorf("avltree: size is %d but tree holds %d nodes", t.size, count)
+	}
+	return nil
+}
+
+// validate counts the nodes of the subtree and returns its height.
+// Keys of the subtree have to lie between the keys of lower and upper, which are nil if unbounded.
+func (t *Tree) validate(n *Node, lower *Node, upper *Node, count *int) (int, error) {
+	if n == nil {
+		return 0, nil
+	}
+	*count++
+	// equal keys may be on both sides of a node in multi-key mode, rotations keep them in order but not in place
+	if lower != nil {
+		if c := t.Comparator(n.Key, lower.Key); c < 0 || c == 0 && !t.multi {
+			return 0, fmt.Errorf("avltree: key %v is not after %v", n.Key, lower.Key)
+		}
+	}
+	if upper != nil {
+		if c := t.Comparator(n.Key, upper.Key); c > 0 || c == 0 && !t.multi {
+			return 0, fmt.Errorf("avltree: key %v is not before %v", n.Key, upper.Key)
+		}
+	}
+	for _, c := range n.Children {
+		if c != nil && c.Parent != n {
+			return 0, fmt.Errorf("avltree: key %v does not link to its parent %v", c.Key, n.Key)
+		}
+	}
+	left, err := t.validate(n.Children[0], lower, n, count)
+	if err != nil {
+		return 0, err
+	}
+	right, err := t.validate(n.Children[1], n, upper, count)
+	if err != nil {
+		return 0, err
+	}
+	if b := right - left; b < -1 || b > 1 {
+		return 0, fmt.Errorf("avltree: key %v is unbalanced with subtree heights %d and %d", n.Key, left, right)
+	} else if int8(b) != n.b {
+		return 0, fmt.Errorf("avltree: key %v has balance factor %d but subtree heights %d and %d", n.Key, n.b, left, right)
+	}
+	if left > right {
+		return left + 1, nil
+	}
+	return right + 1, nil
+}
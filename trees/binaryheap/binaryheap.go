@@ -25,8 +25,10 @@ func assertTreeImplementation() {
 
 // Heap holds elements in an array-list
 type Heap struct {
-	list       *arraylist.List
-	Comparator utils.Comparator
+	list        *arraylist.List
+	Comparator  utils.Comparator
+	modCount    int // number of pushed and popped values, checked by iterators
+	comparisons int // calls of the comparator
 }
 
 // NewWith instantiates a new empty heap tree with the custom comparator.
@@ -48,11 +50,13 @@ func NewWithStringComparator() *Heap {
 func (heap *Heap) Push(values ...interface{}) {
 	if len(values) == 1 {
 		heap.list.Add(values[0])
+		heap.modCount++
 		heap.bubbleUp()
 	} else {
 		// Reference: https://en.wikipedia.org/wiki/Binary_heap#Building_a_heap
 		for _, value := range values {
 			heap.list.Add(value)
+			heap.modCount++
 		}
 		size := heap.list.Size()/2 + 1
 		for i := size; i >= 0; i-- {
@@ -71,6 +75,7 @@ func (heap *Heap) Pop() (value interface{}, ok bool) {
 	lastIndex := heap.list.Size() - 1
 	heap.list.Swap(0, lastIndex)
 	heap.list.Remove(lastIndex)
+	heap.modCount++
 	heap.bubbleDown()
 	return
 }
@@ -94,6 +99,7 @@ func (heap *Heap) Size() int {
 // Clear removes all elements from the heap.
 func (heap *Heap) Clear() {
 	heap.list.Clear()
+	heap.modCount++
 }
 
 // Values returns all elements in the heap.
@@ -127,12 +133,12 @@ func (heap *Heap) bubbleDownIndex(index int) {
 		smallerIndex := leftIndex
 		leftValue, _ := heap.list.Get(leftIndex)
 		rightValue, _ := heap.list.Get(rightIndex)
-		if rightIndex < size && heap.Comparator(leftValue, rightValue) > 0 {
+		if rightIndex < size && heap.compare(leftValue, rightValue) > 0 {
 			smallerIndex = rightIndex
 		}
 		indexValue, _ := heap.list.Get(index)
 		smallerValue, _ := heap.list.Get(smallerIndex)
-		if heap.Comparator(indexValue, smallerValue) > 0 {
+		if heap.compare(indexValue, smallerValue) > 0 {
 			heap.list.Swap(index, smallerIndex)
 		} else {
 			break
@@ -149,7 +155,7 @@ func (heap *Heap) bubbleUp() {
 	for parentIndex := (index - 1) >> 1; index > 0; parentIndex = (index - 1) >> 1 {
 		indexValue, _ := heap.list.Get(index)
 		parentValue, _ := heap.list.Get(parentIndex)
-		if heap.Comparator(parentValue, indexValue) <= 0 {
+		if heap.compare(parentValue, indexValue) <= 0 {
 			break
 		}
 		heap.list.Swap(index, parentIndex)
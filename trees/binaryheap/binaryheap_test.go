@@ -5,6 +5,9 @@
 package binaryheap
 
 import (
+	"bytes"
+	"fmt"
+	"github.com/dairongpeng/gds/containers"
 	"math/rand"
 	"testing"
 )
@@ -259,6 +262,49 @@ func TestBinaryHeapIteratorLast(t *testing.T) {
 	}
 }
 
+func TestBinaryHeapIteratorConcurrentModification(t *testing.T) {
+	heap := NewWithIntComparator()
+	heap.Push(3, 1, 2)
+	it := heap.Iterator()
+	it.Next()
+	heap.Pop()
+	func() {
+		defer func() {
+			if r := recover(); r != containers.ErrConcurrentModification {
+				t.Errorf("Got %v expected %v", r, containers.ErrConcurrentModification)
+			}
+		}()
+		it.Next()
+	}()
+
+	it.End()
+	heap.Push()
+	if !it.Prev() || it.Index() != 1 {
+		t.Errorf("Got %v expected %v", it.Index(), 1)
+	}
+}
+
+func TestBinaryHeapStats(t *testing.T) {
+	heap := NewWithIntComparator()
+	if actualValue, expectedValue := fmt.Sprintf("%+v", heap.Stats()), "{Height:0 Nodes:0 AverageDepth:0 Rotations:0 Splits:0 Merges:0 Comparisons:0}"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	// every value but the first is compared with its parent only
+	for i := 1; i <= 7; i++ {
+		heap.Push(i)
+	}
+	if actualValue, expectedValue := fmt.Sprintf("%+v", heap.Stats()), "{Height:3 Nodes:7 AverageDepth:1.4285714285714286 Rotations:0 Splits:0 Merges:0 Comparisons:6}"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	for i := 8; i <= 10; i++ {
+		heap.Push(i)
+	}
+	stats := heap.Stats()
+	if actualValue, expectedValue := fmt.Sprint(stats.Height, stats.Nodes, stats.AverageDepth), "4 10 1.9"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+}
+
 func TestBinaryHeapSerialization(t *testing.T) {
 	heap := NewWithStringComparator()
 
@@ -307,6 +353,50 @@ func benchmarkPop(b *testing.B, heap *Heap, size int) {
 	}
 }
 
+func TestBinaryHeapWriteDOT(t *testing.T) {
+	heap := NewWithIntComparator()
+	heap.Push(5, 3, 4, 1, 2)
+	var buffer bytes.Buffer
+	if err := heap.WriteDOT(&buffer, containers.DOTOptions{}); err != nil {
+		t.Errorf("Got %v expected %v", err, nil)
+	}
+	expectedValue := `digraph G {
+	node [shape=circle];
+	n0 [label="1"];
+	n0 -> n1;
+	n0 -> n2;
+	n1 [label="2"];
+	n1 -> n3;
+	n1 -> n4;
+	n2 [label="4"];
+	n3 [label="3"];
+	n4 [label="5"];
+}
+`
+	if actualValue := buffer.String(); actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+
+	buffer.Reset()
+	heap.WriteDOT(&buffer, containers.DOTOptions{MaxDepth: 2})
+	expectedValue = `digraph G {
+	node [shape=circle];
+	n0 [label="1"];
+	n0 -> n1;
+	n0 -> n2;
+	n1 [label="2"];
+	n3 [label="...", shape=plaintext];
+	n1 -> n3;
+	n4 [label="...", shape=plaintext];
+	n1 -> n4;
+	n2 [label="4"];
+}
+`
+	if actualValue := buffer.String(); actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+}
+
 func BenchmarkBinaryHeapPop100(b *testing.B) {
 	b.StopTimer()
 	size := 100
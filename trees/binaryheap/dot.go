@@ -0,0 +1,49 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package binaryheap
+
+import (
+	"fmt"
+	"github.com/dairongpeng/gds/containers"
+	"io"
+	"strings"
+)
+
+func assertDOTImplementation() {
+	var _ containers.DOTWriter = (*Heap)(nil)
+}
+
+// WriteDOT writes the heap as a Graphviz DOT digraph of the implicit binary tree over its array,
+// the children of the element at index i being the elements at 2i+1 and 2i+2. Nodes are named after their index.
+// Levels beyond the maximum depth of the options are drawn as "...". Heaps hold no separate values,
+// so the Values option has no effect.
+func (heap *Heap) WriteDOT(w io.Writer, options containers.DOTOptions) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph %s {\n", options.GraphName())
+	b.WriteString("\tnode [shape=circle];\n")
+	values := heap.list.Values()
+	depth := 0
+	for i, value := range values {
+		if i == 1<<(depth+1)-1 {
+			depth++
+		}
+		if options.Truncated(depth) {
+			break
+		}
+		fmt.Fprintf(&b, "\tn%d [label=%s];\n", i, containers.DOTQuote(value))
+		for _, child := range []int{2*i + 1, 2*i + 2} {
+			if child >= len(values) {
+				break
+			}
+			if options.Truncated(depth + 1) {
+				fmt.Fprintf(&b, "\tn%d [label=\"...\", shape=plaintext];\n", child)
+			}
+			fmt.Fprintf(&b, "\tn%d -> n%d;\n", i, child)
+		}
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
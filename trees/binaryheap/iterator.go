@@ -12,13 +12,14 @@ func assertIteratorImplementation() {
 
 // Iterator returns a stateful iterator whose values can be fetched by an index.
 type Iterator struct {
-	heap  *Heap
-	index int
+	heap     *Heap
+	index    int
+	modCount int
 }
 
 // Iterator returns a stateful iterator whose values can be fetched by an index.
 func (heap *Heap) Iterator() Iterator {
-	return Iterator{heap: heap, index: -1}
+	return Iterator{heap: heap, index: -1, modCount: heap.modCount}
 }
 
 // Next moves the iterator to the next element and returns true if there was a next element in the container.
@@ -26,6 +27,7 @@ func (heap *Heap) Iterator() Iterator {
 // If Next() was called for the first time, then it will point the iterator to the first element if it exists.
 // Modifies the state of the iterator.
 func (iterator *Iterator) Next() bool {
+	iterator.checkModification()
 	if iterator.index < iterator.heap.Size() {
 		iterator.index++
 	}
@@ -36,6 +38,7 @@ func (iterator *Iterator) Next() bool {
 // If Prev() returns true, then previous element's index and value can be retrieved by Index() and Value().
 // Modifies the state of the iterator.
 func (iterator *Iterator) Prev() bool {
+	iterator.checkModification()
 	if iterator.index >= 0 {
 		iterator.index--
 	}
@@ -59,12 +62,14 @@ func (iterator *Iterator) Index() int {
 // Call Next() to fetch the first element if any.
 func (iterator *Iterator) Begin() {
 	iterator.index = -1
+	iterator.modCount = iterator.heap.modCount
 }
 
 // End moves the iterator past the last element (one-past-the-end).
 // Call Prev() to fetch the last element if any.
 func (iterator *Iterator) End() {
 	iterator.index = iterator.heap.Size()
+	iterator.modCount = iterator.heap.modCount
 }
 
 // First moves the iterator to the first element and returns true if there was a first element in the container.
@@ -82,3 +87,13 @@ func (iterator *Iterator) Last() bool {
 	iterator.End()
 	return iterator.Prev()
 }
+
+// checkModification panics if the heap was pushed or popped other than through the iterator.
+func (iterator *Iterator) checkModification() {
+	if iterator.modCount != iterator.heap.modCount {
+		if iterator.index != -1 {
+			panic(containers.ErrConcurrentModification)
+		}
+		iterator.modCount = iterator.heap.modCount
+	}
+}
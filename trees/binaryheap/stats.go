@@ -0,0 +1,33 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package binaryheap
+
+import "github.com/dairongpeng/gds/trees"
+
+// Stats returns the shape of the heap and the number of comparator calls since it was created.
+// The heap is a complete binary tree, so its shape follows from its size and is computed in O(log n).
+func (heap *Heap) Stats() trees.Stats {
+	s := trees.Stats{Nodes: heap.Size(), Comparisons: heap.comparisons}
+	depths := 0
+	for level := 1; level <= s.Nodes; level *= 2 {
+		// the level holds up to level nodes, the ones following the level-1 nodes above it
+		nodes := s.Nodes - (level - 1)
+		if nodes > level {
+			nodes = level
+		}
+		depths += s.Height * nodes
+		s.Height++
+	}
+	if s.Nodes > 0 {
+		s.AverageDepth = float64(depths) / float64(s.Nodes)
+	}
+	return s
+}
+
+// compare calls the comparator and counts the call.
+func (heap *Heap) compare(a interface{}, b interface{}) int {
+	heap.comparisons++
+	return heap.Comparator(a, b)
+}
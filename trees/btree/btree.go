@@ -34,6 +34,9 @@ type Tree struct {
 	Comparator utils.Comparator // Key comparator
 	size       int              // Total number of keys in the tree
 	m          int              // order (maximum number of children)
+	modCount   int              // number of inserted and removed entries, checked by iterators
+	pool       *pool            // allocates the nodes and entries, may be nil
+	stats      stats            // counts the work done by the tree
 }
 
 // Node is a single element within the tree
@@ -57,6 +60,17 @@ func NewWith(order int, comparator utils.Comparator) *Tree {
 	return &Tree{m: order, Comparator: comparator}
 }
 
+// NewWithPool instantiates a B-tree with the order (maximum number of children) and a custom key comparator
+// that allocates its nodes and entries slabSize at a time and reuses those of removed keys for later insertions.
+// A slabSize of 1 only reuses removed nodes and entries.
+// Nodes and entries obtained from the tree (Root, Left, Right) must not be used once the tree was modified,
+// since they may already hold other keys, and a slab is kept in memory as long as any of its nodes or entries is.
+func NewWithPool(order int, comparator utils.Comparator, slabSize int) *Tree {
+	tree := NewWith(order, comparator)
+	tree.pool = newPool(slabSize, order)
+	return tree
+}
+
 // NewWithIntComparator instantiates a B-tree with the order (maximum number of children) and the IntComparator, i.e. keys are of type int.
 func NewWithIntComparator(order int) *Tree {
 	return NewWith(order, utils.IntComparator)
@@ -71,16 +85,19 @@ func NewWithStringComparator(order int) *Tree {
 // If key already exists, then its value is updated with the new value.
 // Key should adhere to the comparator's type assertion, otherwise method panics.
 func (tree *Tree) Put(key interface{}, value interface{}) {
-	entry := &Entry{Key: key, Value: value}
+	entry := tree.newEntry(key, value)
 
 	if tree.Root == nil {
-		tree.Root = &Node{Entries: []*Entry{entry}, Children: []*Node{}}
+		tree.Root = tree.newNode(nil)
+		tree.Root.Entries = append(tree.Root.Entries, entry)
 		tree.size++
+		tree.modCount++
 		return
 	}
 
 	if tree.insert(tree.Root, entry) {
 		tree.size++
+		tree.modCount++
 	}
 }
 
@@ -102,6 +119,7 @@ func (tree *Tree) Remove(key interface{}) {
 	if found {
 		tree.delete(node, index)
 		tree.size--
+		tree.modCount++
 	}
 }
 
@@ -139,6 +157,7 @@ func (tree *Tree) Values() []interface{} {
 func (tree *Tree) Clear() {
 	tree.Root = nil
 	tree.size = 0
+	tree.modCount++
 }
 
 // Height returns the height of the tree.
@@ -266,7 +285,7 @@ func (tree *Tree) search(node *Node, key interface{}) (index int, found bool) {
 	var mid int
 	for low <= high {
 		mid = (high + low) / 2
-		compare := tree.Comparator(key, node.Entries[mid].Key)
+		compare := tree.compare(key, node.Entries[mid].Key)
 		switch {
 		case compare > 0:
 			low = mid + 1
@@ -307,6 +326,7 @@ func (tree *Tree) insert(node *Node, entry *Entry) (inserted bool) {
 func (tree *Tree) insertIntoLeaf(node *Node, entry *Entry) (inserted bool) {
 	insertPosition, found := tree.search(node, entry.Key)
 	if found {
+		tree.freeEntry(node.Entries[insertPosition])
 		node.Entries[insertPosition] = entry
 		return false
 	}
@@ -321,6 +341,7 @@ func (tree *Tree) insertIntoLeaf(node *Node, entry *Entry) (inserted bool) {
 func (tree *Tree) insertIntoInternal(node *Node, entry *Entry) (inserted bool) {
 	insertPosition, found := tree.search(node, entry.Key)
 	if found {
+		tree.freeEntry(node.Entries[insertPosition])
 		node.Entries[insertPosition] = entry
 		return false
 	}
@@ -332,6 +353,7 @@ func (tree *Tree) split(node *Node) {
 		return
 	}
 
+	tree.stats.splits++
 	if node == tree.Root {
 		tree.splitRoot()
 		return
@@ -344,13 +366,15 @@ func (tree *Tree) splitNonRoot(node *Node) {
 	middle := tree.middle()
 	parent := node.Parent
 
-	left := &Node{Entries: append([]*Entry(nil), node.Entries[:middle]...), Parent: parent}
-	right := &Node{Entries: append([]*Entry(nil), node.Entries[middle+1:]...), Parent: parent}
+	left := tree.newNode(parent)
+	left.Entries = append(left.Entries, node.Entries[:middle]...)
+	right := tree.newNode(parent)
+	right.Entries = append(right.Entries, node.Entries[middle+1:]...)
 
 	// Move children from the node to be split into left and right nodes
 	if !tree.isLeaf(node) {
-		left.Children = append([]*Node(nil), node.Children[:middle+1]...)
-		right.Children = append([]*Node(nil), node.Children[middle+1:]...)
+		left.Children = append(left.Children, node.Children[:middle+1]...)
+		right.Children = append(right.Children, node.Children[middle+1:]...)
 		setParent(left.Children, left)
 		setParent(right.Children, right)
 	}
@@ -370,31 +394,34 @@ func (tree *Tree) splitNonRoot(node *Node) {
 	copy(parent.Children[insertPosition+2:], parent.Children[insertPosition+1:])
 	parent.Children[insertPosition+1] = right
 
+	tree.freeNode(node)
 	tree.split(parent)
 }
 
 func (tree *Tree) splitRoot() {
 	middle := tree.middle()
 
-	left := &Node{Entries: append([]*Entry(nil), tree.Root.Entries[:middle]...)}
-	right := &Node{Entries: append([]*Entry(nil), tree.Root.Entries[middle+1:]...)}
+	left := tree.newNode(nil)
+	left.Entries = append(left.Entries, tree.Root.Entries[:middle]...)
+	right := tree.newNode(nil)
+	right.Entries = append(right.Entries, tree.Root.Entries[middle+1:]...)
 
 	// Move children from the node to be split into left and right nodes
 	if !tree.isLeaf(tree.Root) {
-		left.Children = append([]*Node(nil), tree.Root.Children[:middle+1]...)
-		right.Children = append([]*Node(nil), tree.Root.Children[middle+1:]...)
+		left.Children = append(left.Children, tree.Root.Children[:middle+1]...)
+		right.Children = append(right.Children, tree.Root.Children[middle+1:]...)
 		setParent(left.Children, left)
 		setParent(right.Children, right)
 	}
 
 	// Root is a node with one entry and two children (left and right)
-	newRoot := &Node{
-		Entries:  []*Entry{tree.Root.Entries[middle]},
-		Children: []*Node{left, right},
-	}
+	newRoot := tree.newNode(nil)
+	newRoot.Entries = append(newRoot.Entries, tree.Root.Entries[middle])
+	newRoot.Children = append(newRoot.Children, left, right)
 
 	left.Parent = newRoot
 	right.Parent = newRoot
+	tree.freeNode(tree.Root)
 	tree.Root = newRoot
 }
 
@@ -461,18 +488,21 @@ func (tree *Tree) rightSibling(node *Node, key interface{}) (*Node, int) {
 func (tree *Tree) delete(node *Node, index int) {
 	// deleting from a leaf node
 	if tree.isLeaf(node) {
-		deletedKey := node.Entries[index].Key
+		deletedEntry := node.Entries[index]
 		tree.deleteEntry(node, index)
-		tree.rebalance(node, deletedKey)
+		tree.rebalance(node, deletedEntry.Key)
 		if len(tree.Root.Entries) == 0 {
+			tree.freeNode(tree.Root)
 			tree.Root = nil
 		}
+		tree.freeEntry(deletedEntry)
 		return
 	}
 
 	// deleting from an internal node
 	leftLargestNode := tree.right(node.Children[index]) // largest node in the left sub-tree (assumed to exist)
 	leftLargestEntryIndex := len(leftLargestNode.Entries) - 1
+	tree.freeEntry(node.Entries[index])
 	node.Entries[index] = leftLargestNode.Entries[leftLargestEntryIndex]
 	deletedKey := leftLargestNode.Entries[leftLargestEntryIndex].Key
 	tree.deleteEntry(leftLargestNode, leftLargestEntryIndex)
@@ -491,13 +521,19 @@ func (tree *Tree) rebalance(node *Node, deletedKey interface{}) {
 	leftSibling, leftSiblingIndex := tree.leftSibling(node, deletedKey)
 	if leftSibling != nil && len(leftSibling.Entries) > tree.minEntries() {
 		// rotate right
-		node.Entries = append([]*Entry{node.Parent.Entries[leftSiblingIndex]}, node.Entries...) // prepend parent's separator entry to node's entries
+		tree.stats.rotations++
+		// prepend parent's separator entry to node's entries
+		node.Entries = append(node.Entries, nil)
+		copy(node.Entries[1:], node.Entries)
+		node.Entries[0] = node.Parent.Entries[leftSiblingIndex]
 		node.Parent.Entries[leftSiblingIndex] = leftSibling.Entries[len(leftSibling.Entries)-1]
 		tree.deleteEntry(leftSibling, len(leftSibling.Entries)-1)
 		if !tree.isLeaf(leftSibling) {
 			leftSiblingRightMostChild := leftSibling.Children[len(leftSibling.Children)-1]
 			leftSiblingRightMostChild.Parent = node
-			node.Children = append([]*Node{leftSiblingRightMostChild}, node.Children...)
+			node.Children = append(node.Children, nil)
+			copy(node.Children[1:], node.Children)
+			node.Children[0] = leftSiblingRightMostChild
 			tree.deleteChild(leftSibling, len(leftSibling.Children)-1)
 		}
 		return
@@ -507,6 +543,7 @@ func (tree *Tree) rebalance(node *Node, deletedKey interface{}) {
 	rightSibling, rightSiblingIndex := tree.rightSibling(node, deletedKey)
 	if rightSibling != nil && len(rightSibling.Entries) > tree.minEntries() {
 		// rotate left
+		tree.stats.rotations++
 		node.Entries = append(node.Entries, node.Parent.Entries[rightSiblingIndex-1]) // append parent's separator entry to node's entries
 		node.Parent.Entries[rightSiblingIndex-1] = rightSibling.Entries[0]
 		tree.deleteEntry(rightSibling, 0)
@@ -522,25 +559,30 @@ func (tree *Tree) rebalance(node *Node, deletedKey interface{}) {
 	// merge with siblings
 	if rightSibling != nil {
 		// merge with right sibling
+		tree.stats.merges++
 		node.Entries = append(node.Entries, node.Parent.Entries[rightSiblingIndex-1])
 		node.Entries = append(node.Entries, rightSibling.Entries...)
 		deletedKey = node.Parent.Entries[rightSiblingIndex-1].Key
 		tree.deleteEntry(node.Parent, rightSiblingIndex-1)
-		tree.appendChildren(node.Parent.Children[rightSiblingIndex], node)
+		tree.appendChildren(rightSibling, node)
 		tree.deleteChild(node.Parent, rightSiblingIndex)
+		tree.freeNode(rightSibling)
 	} else if leftSibling != nil {
-		// merge with left sibling
-		entries := append([]*Entry(nil), leftSibling.Entries...)
-		entries = append(entries, node.Parent.Entries[leftSiblingIndex])
-		node.Entries = append(entries, node.Entries...)
+		// merge into left sibling
+		tree.stats.merges++
+		leftSibling.Entries = append(leftSibling.Entries, node.Parent.Entries[leftSiblingIndex])
+		leftSibling.Entries = append(leftSibling.Entries, node.Entries...)
 		deletedKey = node.Parent.Entries[leftSiblingIndex].Key
 		tree.deleteEntry(node.Parent, leftSiblingIndex)
-		tree.prependChildren(node.Parent.Children[leftSiblingIndex], node)
-		tree.deleteChild(node.Parent, leftSiblingIndex)
+		tree.appendChildren(node, leftSibling)
+		tree.deleteChild(node.Parent, leftSiblingIndex+1)
+		tree.freeNode(node)
+		node = leftSibling
 	}
 
 	// make the merged node the root if its parent was the root and the root is empty
 	if node.Parent == tree.Root && len(tree.Root.Entries) == 0 {
+		tree.freeNode(tree.Root)
 		tree.Root = node
 		node.Parent = nil
 		return
@@ -550,12 +592,6 @@ func (tree *Tree) rebalance(node *Node, deletedKey interface{}) {
 	tree.rebalance(node.Parent, deletedKey)
 }
 
-func (tree *Tree) prependChildren(fromNode *Node, toNode *Node) {
-	children := append([]*Node(nil), fromNode.Children...)
-	toNode.Children = append(children, toNode.Children...)
-	setParent(fromNode.Children, toNode)
-}
-
 func (tree *Tree) appendChildren(fromNode *Node, toNode *Node) {
 	toNode.Children = append(toNode.Children, fromNode.Children...)
 	setParent(fromNode.Children, toNode)
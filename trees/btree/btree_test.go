@@ -5,7 +5,11 @@
 package btree
 
 import (
+	"bytes"
 	"fmt"
+	"github.com/dairongpeng/gds/containers"
+	"github.com/dairongpeng/gds/utils"
+	"math/rand"
 	"testing"
 )
 
@@ -1074,6 +1078,103 @@ func assertValidTreeNode(t *testing.T, node *Node, expectedEntries int, expected
 	}
 }
 
+func TestBTreeIteratorRemove(t *testing.T) {
+	for order := 3; order <= 6; order++ {
+		tree := NewWithIntComparator(order)
+		for _, key := range rand.Perm(200) {
+			tree.Put(key, key)
+		}
+		it := tree.Iterator()
+		for it.Next() {
+			if it.Key().(int)%3 != 0 {
+				it.Remove()
+				if err := tree.Validate(); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+		for it.Prev() {
+			if it.Key().(int)%2 != 0 {
+				it.Remove()
+				if err := tree.Validate(); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+		keys := []interface{}{}
+		for key := 0; key < 200; key += 6 {
+			keys = append(keys, key)
+		}
+		if actualValue, expectedValue := fmt.Sprint(tree.Keys()), fmt.Sprint(keys); actualValue != expectedValue {
+			t.Errorf("Got %v expected %v", actualValue, expectedValue)
+		}
+	}
+
+	tree := NewWithIntComparator(3)
+	tree.Put(1, "a")
+	tree.Put(2, "b")
+	tree.Put(3, "c")
+	it := tree.Iterator()
+	it.Next()
+	it.Next()
+	it.Remove()
+	if !it.Prev() || it.Key() != 1 {
+		t.Errorf("Got %v expected %v", it.Key(), 1)
+	}
+	it.Remove()
+	if it.Prev() {
+		t.Errorf("Shouldn't iterate before the beginning")
+	}
+	if !it.Next() || it.Key() != 3 {
+		t.Errorf("Got %v expected %v", it.Key(), 3)
+	}
+	it.Remove()
+	if it.Next() {
+		t.Errorf("Shouldn't iterate past the end")
+	}
+	if actualValue, expectedValue := tree.Size(), 0; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+}
+
+// expectPanic checks that f panics with the expected error.
+func expectPanic(t *testing.T, expected error, f func()) {
+	t.Helper()
+	defer func() {
+		if r := recover(); r != expected {
+			t.Errorf("Got %v expected %v", r, expected)
+		}
+	}()
+	f()
+}
+
+func TestBTreeIteratorConcurrentModification(t *testing.T) {
+	tree := NewWithIntComparator(3)
+	tree.Put(1, "a")
+	tree.Put(2, "b")
+	it := tree.Iterator()
+	it.Next()
+	tree.Put(3, "c")
+	expectPanic(t, containers.ErrConcurrentModification, func() { it.Next() })
+	expectPanic(t, containers.ErrConcurrentModification, func() { it.Prev() })
+	expectPanic(t, containers.ErrConcurrentModification, func() { it.Remove() })
+
+	// updating a value is not a structural modification
+	it.Begin()
+	it.Next()
+	tree.Put(1, "z")
+	tree.Remove(4)
+	if !it.Next() || it.Key() != 2 {
+		t.Errorf("Got %v expected %v", it.Key(), 2)
+	}
+
+	it.End()
+	expectPanic(t, containers.ErrIteratorNotOnElement, func() { it.Remove() })
+	it.Prev()
+	it.Remove()
+	expectPanic(t, containers.ErrIteratorNotOnElement, func() { it.Remove() })
+}
+
 func TestBTreeSerialization(t *testing.T) {
 	tree := NewWithStringComparator(3)
 	tree.Put("c", "3")
@@ -1105,6 +1206,101 @@ func TestBTreeSerialization(t *testing.T) {
 	assert()
 }
 
+func TestBTreePool(t *testing.T) {
+	for order := 3; order <= 6; order++ {
+		tree := NewWithPool(order, utils.IntComparator, 16)
+		expected := map[int]int{}
+		for i := 0; i < 2000; i++ {
+			key := rand.Intn(200)
+			if rand.Intn(3) == 0 {
+				tree.Remove(key)
+				delete(expected, key)
+			} else {
+				tree.Put(key, i)
+				expected[key] = i
+			}
+		}
+		if err := tree.Validate(); err != nil {
+			t.Errorf("Got %v expected %v", err, nil)
+		}
+		if actualValue, expectedValue := tree.Size(), len(expected); actualValue != expectedValue {
+			t.Errorf("Got %v expected %v", actualValue, expectedValue)
+		}
+		for key, value := range expected {
+			if actualValue, found := tree.Get(key); actualValue != value || !found {
+				t.Errorf("Got %v expected %v", actualValue, value)
+			}
+		}
+
+		// the nodes and entries of removed keys are reused
+		for key := range expected {
+			tree.Remove(key)
+		}
+		allocs := testing.AllocsPerRun(10, func() {
+			for n := 0; n < 100; n++ {
+				tree.Put(n, n)
+			}
+			for n := 0; n < 100; n++ {
+				tree.Remove(n)
+			}
+		})
+		if allocs != 0 {
+			t.Errorf("Got %v expected %v", allocs, 0)
+		}
+	}
+}
+
+func TestBTreeStats(t *testing.T) {
+	tree := NewWithIntComparator(3)
+	if actualValue, expectedValue := fmt.Sprintf("%+v", tree.Stats()), "{Height:0 Nodes:0 AverageDepth:0 Rotations:0 Splits:0 Merges:0 Comparisons:0}"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	for i := 1; i <= 7; i++ {
+		tree.Put(i, i)
+	}
+	// BTree
+	//         1
+	//     2
+	//         3
+	// 4
+	//         5
+	//     6
+	//         7
+	if actualValue, expectedValue := fmt.Sprintf("%+v", tree.Stats()), "{Height:3 Nodes:7 AverageDepth:1.4285714285714286 Rotations:0 Splits:4 Merges:0 Comparisons:18}"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+
+	// the leaf of 1 merges with its sibling, which leaves their parent to merge with its sibling
+	tree.Remove(1)
+	tree.Remove(7)
+	tree.Remove(6)
+	// BTree
+	//     2
+	//     3
+	// 4
+	//     5
+	stats := tree.Stats()
+	if actualValue, expectedValue := fmt.Sprint(stats.Height, stats.Nodes, stats.AverageDepth), "2 3 0.75"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue, expectedValue := fmt.Sprint(stats.Rotations, stats.Splits, stats.Merges), "0 4 3"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+
+	// the empty leaf of 5 borrows 3 through the parent
+	tree.Remove(5)
+	stats = tree.Stats()
+	if actualValue, expectedValue := fmt.Sprint(stats.Rotations, stats.Splits, stats.Merges), "1 4 3"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	// 4 is compared with the root 3 and found in the right leaf
+	comparisons := stats.Comparisons
+	tree.Get(4)
+	if actualValue, expectedValue := tree.Stats().Comparisons, comparisons+2; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+}
+
 func benchmarkGet(b *testing.B, tree *Tree, size int) {
 	for i := 0; i < b.N; i++ {
 		for n := 0; n < size; n++ {
@@ -1129,6 +1325,125 @@ func benchmarkRemove(b *testing.B, tree *Tree, size int) {
 	}
 }
 
+// benchmarkChurn inserts and removes all keys in every round, the keys are boxed up front
+// so that the reported allocations are those of the tree. A pooled tree reuses the nodes and entries of the
+// removed keys and should report hardly any allocations.
+func benchmarkChurn(b *testing.B, tree *Tree, size int) {
+	keys := make([]interface{}, size)
+	for n := range keys {
+		keys[n] = n
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, key := range keys {
+			tree.Put(key, struct{}{})
+		}
+		for _, key := range keys {
+			tree.Remove(key)
+		}
+	}
+}
+
+func TestBTreeValidate(t *testing.T) {
+	for _, order := range []int{3, 4, 5, 10} {
+		tree := NewWithIntComparator(order)
+		if err := tree.Validate(); err != nil {
+			t.Errorf("Got %v expected %v", err, nil)
+		}
+		r := rand.New(rand.NewSource(1))
+		for i := 0; i < 2000; i++ {
+			if r.Intn(3) == 0 {
+				tree.Remove(r.Intn(200))
+			} else {
+				tree.Put(r.Intn(200), i)
+			}
+			if i%50 == 0 {
+				if err := tree.Validate(); err != nil {
+					t.Fatalf("Got %v expected %v", err, nil)
+				}
+			}
+		}
+	}
+
+	corruptions := []struct {
+		corrupt  func(tree *Tree)
+		expected string
+	}{
+		{func(tree *Tree) { tree.Root.Entries[0].Key = 20 }, "key 12 is not after 20"},
+		{func(tree *Tree) { tree.Root.Children[0].Entries[0].Key = 5 }, "key 5 is not after 5"},
+		{func(tree *Tree) { tree.Root.Children[0].Children[0].Entries = nil }, "node at depth 2 has no entries"},
+		{func(tree *Tree) {
+			leaf := tree.Root.Children[1].Children[0].Children[1]
+			leaf.Entries = append(leaf.Entries, &Entry{Key: 12}, &Entry{Key: 13})
+		}, "node of key 11 has 3 entries, more than 2"},
+		{func(tree *Tree) { tree.Root.Children[1].Children[1].Parent = nil }, "child 1 of the node of key 12 does not link to its parent"},
+		{func(tree *Tree) { tree.Root.Children[1].Children[1].Children = nil }, "leaf of key 14 is at depth 2, expected 3"},
+		{func(tree *Tree) { tree.Root.Children = tree.Root.Children[:1] }, "node of key 8 has 1 entries but 1 children"},
+		{func(tree *Tree) { tree.size = 0 }, "size is 0 but tree holds 15 entries"},
+	}
+	for _, test := range corruptions {
+		tree := NewWithIntComparator(3)
+		for i := 1; i <= 15; i++ {
+			tree.Put(i, nil)
+		}
+		if err := tree.Validate(); err != nil {
+			t.Fatalf("Got %v expected %v", err, nil)
+		}
+		test.corrupt(tree)
+		if err := tree.Validate(); err == nil || err.Error() != "btree: "+test.expected {
+			t.Errorf("Got %v expected %v", err, test.expected)
+		}
+	}
+}
+
+func TestBTreeWriteDOT(t *testing.T) {
+	tree := NewWithIntComparator(3)
+	for i := 1; i <= 7; i++ {
+		tree.Put(i, fmt.Sprintf("v%d", i))
+	}
+	tree.Put(8, "<|>")
+	var buffer bytes.Buffer
+	if err := tree.WriteDOT(&buffer, containers.DOTOptions{Values: true}); err != nil {
+		t.Errorf("Got %v expected %v", err, nil)
+	}
+	expectedValue := `digraph G {
+	node [shape=record];
+	n0 [label="<c0>|4\nv4|<c1>"];
+	n1 [label="<c0>|2\nv2|<c1>"];
+	n2 [label="1\nv1"];
+	n1:c0 -> n2;
+	n3 [label="3\nv3"];
+	n1:c1 -> n3;
+	n0:c0 -> n1;
+	n4 [label="<c0>|6\nv6|<c1>"];
+	n5 [label="5\nv5"];
+	n4:c0 -> n5;
+	n6 [label="7\nv7|8\n\<\|\>"];
+	n4:c1 -> n6;
+	n0:c1 -> n4;
+}
+`
+	if actualValue := buffer.String(); actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+
+	buffer.Reset()
+	tree.WriteDOT(&buffer, containers.DOTOptions{MaxDepth: 1})
+	expectedValue = `digraph G {
+	node [shape=record];
+	n0 [label="<c0>|4|<c1>"];
+	n1 [label="...", shape=plaintext];
+	n0:c0 -> n1;
+	n2 [label="...", shape=plaintext];
+	n0:c1 -> n2;
+}
+`
+	if actualValue := buffer.String(); actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+}
+
 func BenchmarkBTreeGet100(b *testing.B) {
 	b.StopTimer()
 	size := 100
@@ -1257,3 +1572,19 @@ func BenchmarkBTreeRemove100000(b *testing.B) {
 	b.StartTimer()
 	benchmarkRemove(b, tree, size)
 }
+
+func BenchmarkBTreeChurn1000(b *testing.B) {
+	benchmarkChurn(b, NewWithIntComparator(128), 1000)
+}
+
+func BenchmarkBTreeChurn10000(b *testing.B) {
+	benchmarkChurn(b, NewWithIntComparator(128), 10000)
+}
+
+func BenchmarkBTreePooledChurn1000(b *testing.B) {
+	benchmarkChurn(b, NewWithPool(128, utils.IntComparator, 256), 1000)
+}
+
+func BenchmarkBTreePooledChurn10000(b *testing.B) {
+	benchmarkChurn(b, NewWithPool(128, utils.IntComparator, 256), 10000)
+}
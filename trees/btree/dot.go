@@ -0,0 +1,65 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package btree
+
+import (
+	"fmt"
+	"github.com/dairongpeng/gds/containers"
+	"github.com/dairongpeng/gds/utils"
+	"io"
+	"strings"
+)
+
+func assertDOTImplementation() {
+	var _ containers.DOTWriter = (*Tree)(nil)
+}
+
+var recordEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`,
+	`{`, `\{`, `}`, `\}`, `|`, `\|`, `<`, `\<`, `>`, `\>`)
+
+// WriteDOT writes the tree as a Graphviz DOT digraph with every node drawn as a record of its entries.
+// Inner nodes have a port between every two entries and at both ends, the edges to the children start at these ports.
+// Subtrees beyond the maximum depth of the options are drawn as "...".
+func (tree *Tree) WriteDOT(w io.Writer, options containers.DOTOptions) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph %s {\n", options.GraphName())
+	b.WriteString("\tnode [shape=record];\n")
+	id := 0
+	var write func(node *Node, depth int) string
+	write = func(node *Node, depth int) string {
+		name := fmt.Sprintf("n%d", id)
+		id++
+		if options.Truncated(depth) {
+			fmt.Fprintf(&b, "\t%s [label=\"...\", shape=plaintext];\n", name)
+			return name
+		}
+		leaf := tree.isLeaf(node)
+		fields := []string{}
+		for i, entry := range node.Entries {
+			if !leaf {
+				fields = append(fields, fmt.Sprintf("<c%d>", i))
+			}
+			field := recordEscaper.Replace(utils.ToString(entry.Key))
+			if options.Values {
+				field += `\n` + recordEscaper.Replace(utils.ToString(entry.Value))
+			}
+			fields = append(fields, field)
+		}
+		if !leaf {
+			fields = append(fields, fmt.Sprintf("<c%d>", len(node.Entries)))
+		}
+		fmt.Fprintf(&b, "\t%s [label=\"%s\"];\n", name, strings.Join(fields, "|"))
+		for i, child := range node.Children {
+			fmt.Fprintf(&b, "\t%s:c%d -> %s;\n", name, i, write(child, depth+1))
+		}
+		return name
+	}
+	if tree.Root != nil {
+		write(tree.Root, 0)
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
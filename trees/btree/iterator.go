@@ -8,6 +8,7 @@ import "github.com/dairongpeng/gds/containers"
 
 func assertIteratorImplementation() {
 	var _ containers.ReverseIteratorWithKey = (*Iterator)(nil)
+	var _ containers.IteratorWithRemove = (*Iterator)(nil)
 }
 
 // Iterator holding the iterator's state
@@ -16,6 +17,8 @@ type Iterator struct {
 	node     *Node
 	entry    *Entry
 	position position
+	modCount int
+	removed  bool // entry was removed, the iterator finds its neighbours by its key
 }
 
 type position byte
@@ -26,7 +29,7 @@ const (
 
 // Iterator returns a stateful iterator whose elements are key/value pairs.
 func (tree *Tree) Iterator() Iterator {
-	return Iterator{tree: tree, node: nil, position: begin}
+	return Iterator{tree: tree, node: nil, position: begin, modCount: tree.modCount}
 }
 
 // Next moves the iterator to the next element and returns true if there was a next element in the container.
@@ -34,6 +37,10 @@ func (tree *Tree) Iterator() Iterator {
 // If Next() was called for the first time, then it will point the iterator to the first element if it exists.
 // Modifies the state of the iterator.
 func (iterator *Iterator) Next() bool {
+	iterator.checkModification()
+	if iterator.removed {
+		return iterator.seek(iterator.entry.Key, true)
+	}
 	// If already at end, go to end
 	if iterator.position == end {
 		goto end
@@ -93,6 +100,10 @@ between:
 // If Prev() returns true, then previous element's key and value can be retrieved by Key() and Value().
 // Modifies the state of the iterator.
 func (iterator *Iterator) Prev() bool {
+	iterator.checkModification()
+	if iterator.removed {
+		return iterator.seek(iterator.entry.Key, false)
+	}
 	// If already at beginning, go to begin
 	if iterator.position == begin {
 		goto begin
@@ -166,6 +177,8 @@ func (iterator *Iterator) Begin() {
 	iterator.node = nil
 	iterator.position = begin
 	iterator.entry = nil
+	iterator.removed = false
+	iterator.modCount = iterator.tree.modCount
 }
 
 // End moves the iterator past the last element (one-past-the-end).
@@ -174,6 +187,8 @@ func (iterator *Iterator) End() {
 	iterator.node = nil
 	iterator.position = end
 	iterator.entry = nil
+	iterator.removed = false
+	iterator.modCount = iterator.tree.modCount
 }
 
 // First moves the iterator to the first element and returns true if there was a first element in the container.
@@ -191,3 +206,66 @@ func (iterator *Iterator) Last() bool {
 	iterator.End()
 	return iterator.Prev()
 }
+
+// Remove removes the current entry from the tree.
+// Next() moves the iterator to the entry after the removed one and Prev() to the entry before it.
+// Panics with containers.ErrIteratorNotOnElement if the iterator is not on an entry.
+func (iterator *Iterator) Remove() {
+	iterator.checkModification()
+	if iterator.removed || iterator.position != between {
+		panic(containers.ErrIteratorNotOnElement)
+	}
+	// the tree may reuse the removed entry, the iterator keeps a copy to find the neighbours by its key
+	entry := *iterator.entry
+	iterator.tree.Remove(entry.Key)
+	iterator.entry = &entry
+	iterator.modCount = iterator.tree.modCount
+	iterator.removed = true
+}
+
+// seek moves the iterator to the first entry after the key (forward) or to the last entry before the key.
+// Removing an entry merges and rebalances nodes, so the neighbours of a removed entry are searched from the root.
+func (iterator *Iterator) seek(key interface{}, forward bool) bool {
+	var node *Node
+	var entry *Entry
+	for n := iterator.tree.Root; n != nil; {
+		i, found := iterator.tree.search(n, key)
+		if forward {
+			if found {
+				i++
+			}
+			if i < len(n.Entries) {
+				node, entry = n, n.Entries[i]
+			}
+		} else if i > 0 {
+			node, entry = n, n.Entries[i-1]
+		}
+		if len(n.Children) == 0 {
+			break
+		}
+		n = n.Children[i]
+	}
+	if entry == nil {
+		if forward {
+			iterator.End()
+		} else {
+			iterator.Begin()
+		}
+		return false
+	}
+	iterator.node = node
+	iterator.entry = entry
+	iterator.position = between
+	iterator.removed = false
+	return true
+}
+
+// checkModification panics if the tree was structurally modified other than through the iterator.
+func (iterator *Iterator) checkModification() {
+	if iterator.modCount != iterator.tree.modCount {
+		if iterator.position != begin {
+			panic(containers.ErrConcurrentModification)
+		}
+		iterator.modCount = iterator.tree.modCount
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package btree
+
+// pool hands out the nodes and entries of a tree from slabs and keeps removed ones in free lists for reuse.
+// The entries and children of the nodes are carved out of slabs as well, with room for an overflowing node,
+// so that they never grow while the node is in use.
+type pool struct {
+	nodes       []Node
+	entries     []Entry
+	entrySlots  []*Entry
+	childSlots  []*Node
+	freeNodes   *Node // removed nodes, linked through their Parent field
+	freeEntries []*Entry
+	slabSize    int
+	order       int
+}
+
+func newPool(slabSize int, order int) *pool {
+	if slabSize < 1 {
+		slabSize = 1
+	}
+	return &pool{slabSize: slabSize, order: order}
+}
+
+// getNode returns a node without entries and children, reusing a removed node if there is one.
+func (p *pool) getNode() *Node {
+	if node := p.freeNodes; node != nil {
+		p.freeNodes = node.Parent
+		node.Parent = nil
+		return node
+	}
+	if len(p.nodes) == 0 {
+		p.nodes = make([]Node, p.slabSize)
+		p.entrySlots = make([]*Entry, p.slabSize*p.order)
+		p.childSlots = make([]*Node, p.slabSize*(p.order+1))
+	}
+	node := &p.nodes[0]
+	p.nodes = p.nodes[1:]
+	node.Entries = p.entrySlots[:0:p.order]
+	p.entrySlots = p.entrySlots[p.order:]
+	node.Children = p.childSlots[: 0 : p.order+1]
+	p.childSlots = p.childSlots[p.order+1:]
+	return node
+}
+
+// putNode clears the node, keeping the room for its entries and children, and adds it to the free list.
+func (p *pool) putNode(node *Node) {
+	for i := range node.Entries {
+		node.Entries[i] = nil
+	}
+	for i := range node.Children {
+		node.Children[i] = nil
+	}
+	*node = Node{Parent: p.freeNodes, Entries: node.Entries[:0], Children: node.Children[:0]}
+	p.freeNodes = node
+}
+
+// getEntry returns a zeroed entry, reusing a removed entry if there is one.
+func (p *pool) getEntry() *Entry {
+	if n := len(p.freeEntries); n > 0 {
+		entry := p.freeEntries[n-1]
+		p.freeEntries[n-1] = nil
+		p.freeEntries = p.freeEntries[:n-1]
+		return entry
+	}
+	if len(p.entries) == 0 {
+		p.entries = make([]Entry, p.slabSize)
+	}
+	entry := &p.entries[0]
+	p.entries = p.entries[1:]
+	return entry
+}
+
+// putEntry clears the entry, so that its key and value can be garbage collected, and adds it to the free list.
+func (p *pool) putEntry(entry *Entry) {
+	*entry = Entry{}
+	p.freeEntries = append(p.freeEntries, entry)
+}
+
+// newNode returns an empty node below the parent, taken from the pool if the tree has one.
+func (tree *Tree) newNode(parent *Node) *Node {
+	if tree.pool == nil {
+		return &Node{Parent: parent}
+	}
+	node := tree.pool.getNode()
+	node.Parent = parent
+	return node
+}
+
+// freeNode returns the node unlinked from the tree to the pool if the tree has one.
+func (tree *Tree) freeNode(node *Node) {
+	if tree.pool != nil {
+		tree.pool.putNode(node)
+	}
+}
+
+// newEntry returns an entry holding the key and value, taken from the pool if the tree has one.
+func (tree *Tree) newEntry(key interface{}, value interface{}) *Entry {
+	if tree.pool == nil {
+		return &Entry{Key: key, Value: value}
+	}
+	entry := tree.pool.getEntry()
+	entry.Key = key
+	entry.Value = value
+	return entry
+}
+
+// freeEntry returns the entry removed from the tree to the pool if the tree has one.
+func (tree *Tree) freeEntry(entry *Entry) {
+	if tree.pool != nil {
+		tree.pool.putEntry(entry)
+	}
+}
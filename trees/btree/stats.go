@@ -0,0 +1,50 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package btree
+
+import "github.com/dairongpeng/gds/trees"
+
+// stats counts the work done by the tree since it was created.
+type stats struct {
+	rotations   int
+	splits      int
+	merges      int
+	comparisons int
+}
+
+// Stats returns the shape of the tree and the number of rotations (entries borrowed from a sibling through the
+// parent), splits, merges and comparator calls since it was created.
+// The shape is measured by walking the tree in O(n).
+func (tree *Tree) Stats() trees.Stats {
+	s := trees.Stats{
+		Height:      tree.Height(),
+		Rotations:   tree.stats.rotations,
+		Splits:      tree.stats.splits,
+		Merges:      tree.stats.merges,
+		Comparisons: tree.stats.comparisons,
+	}
+	depths := 0
+	var walk func(node *Node, depth int)
+	walk = func(node *Node, depth int) {
+		s.Nodes++
+		depths += depth * len(node.Entries)
+		for _, child := range node.Children {
+			walk(child, depth+1)
+		}
+	}
+	if tree.Root != nil {
+		walk(tree.Root, 0)
+	}
+	if tree.size > 0 {
+		s.AverageDepth = float64(depths) / float64(tree.size)
+	}
+	return s
+}
+
+// compare calls the comparator and counts the call.
+func (tree *Tree) compare(a interface{}, b interface{}) int {
+	tree.stats.comparisons++
+	return tree.Comparator(a, b)
+}
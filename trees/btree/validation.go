@@ -0,0 +1,86 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package btree
+
+import "fmt"
+
+// Validate checks the invariants of the tree and returns an error naming the offending key of the first violation, or nil.
+// It checks that keys are in order under the comparator, that every child links to its parent, that the size matches
+// the number of entries, that every node holds between the minimum and maximum number of entries (the root at least one),
+// that inner nodes have one child more than entries and that all leaves are on the same level.
+// Validate visits every node, it is meant for tests of code that extends the tree.
+func (tree *Tree) Validate() error {
+	if tree.Root == nil {
+		if tree.size != 0 {
+			return fmt.Errorf("btree: size is %d but tree is empty", tree.size)
+		}
+		return nil
+	}
+	if tree.Root.Parent != nil {
+		return fmt.Errorf("btree: root has a parent")
+	}
+	count, leafDepth := 0, -1
+	if err := tree.validate(tree.Root, nil, nil, 0, &leafDepth, &count); err != nil {
+		return err
+	}
+	if count != tree.size {
+		return fmt.Errorf("btree: size is %d but tree holds %d entries", tree.size, count)
+	}
+	return nil
+}
+
+// validate counts the entries of the subtree and records the depth of the first leaf in leafDepth.
+// Keys of the subtree have to lie between the keys of lower and upper, which are nil if unbounded.
+func (tree *Tree) validate(node *Node, lower *Entry, upper *Entry, depth int, leafDepth *int, count *int) error {
+	if len(node.Entries) == 0 {
+		return fmt.Errorf("btree: node at depth %d has no entries", depth)
+	}
+	first := node.Entries[0].Key
+	if len(node.Entries) > tree.maxEntries() {
+		return fmt.Errorf("btree: node of key %v has %d entries, more than %d", first, len(node.Entries), tree.maxEntries())
+	}
+	if node != tree.Root && len(node.Entries) < tree.minEntries() {
+		return fmt.Errorf("btree: node of key %v has %d entries, fewer than %d", first, len(node.Entries), tree.minEntries())
+	}
+	previous := lower
+	for _, entry := range node.Entries {
+		if previous != nil && tree.Comparator(entry.Key, previous.Key) <= 0 {
+			return fmt.Errorf("btree: key %v is not after %v", entry.Key, previous.Key)
+		}
+		previous = entry
+	}
+	if upper != nil && tree.Comparator(previous.Key, upper.Key) >= 0 {
+		return fmt.Errorf("btree: key %v is not before %v", previous.Key, upper.Key)
+	}
+	*count += len(node.Entries)
+
+	if tree.isLeaf(node) {
+		if *leafDepth == -1 {
+			*leafDepth = depth
+		} else if *leafDepth != depth {
+			return fmt.Errorf("btree: leaf of key %v is at depth %d, expected %d", first, depth, *leafDepth)
+		}
+		return nil
+	}
+	if len(node.Children) != len(node.Entries)+1 {
+		return fmt.Errorf("btree: node of key %v has %d entries but %d children", first, len(node.Entries), len(node.Children))
+	}
+	for i, child := range node.Children {
+		if child.Parent != node {
+			return fmt.Errorf("btree: child %d of the node of key %v does not link to its parent", i, first)
+		}
+		childLower, childUpper := lower, upper
+		if i > 0 {
+			childLower = node.Entries[i-1]
+		}
+		if i < len(node.Entries) {
+			childUpper = node.Entries[i]
+		}
+		if err := tree.validate(child, childLower, childUpper, depth+1, leafDepth, count); err != nil {
+			return err
+		}
+	}
+	return nil
+}
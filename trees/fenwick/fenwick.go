@@ -0,0 +1,181 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package fenwick implements Fenwick trees (binary indexed trees) over int counters in one and two dimensions.
+//
+// Point updates and prefix sums take O(log n). Used as a frequency table over a bounded integer domain,
+// i.e. Add(x, 1) for every occurrence of x, PrefixSum(x) counts the values smaller than or equal to x
+// and FindByPrefix(k) finds the k-th smallest value.
+//
+// Indexes are zero-based and ranges are inclusive on both ends, i.e. [from, to].
+//
+// Structure is not thread safe.
+//
+// Reference: https://en.wikipedia.org/wiki/Fenwick_tree
+package fenwick
+
+import (
+	"fmt"
+	"github.com/dairongpeng/gds/trees"
+	"math/bits"
+	"strings"
+)
+
+func assertTreeImplementation() {
+	var _ trees.Tree = (*Tree)(nil)
+}
+
+// Tree holds the partial sums of the counters.
+// Tree 树状数组，tree[i]保存区间(i-lowbit(i), i]的和（下标从1开始）
+type Tree struct {
+	tree []int // 1-indexed partial sums, tree[0] is unused
+	size int
+}
+
+// New instantiates a Fenwick tree of the given size with all counters set to zero.
+func New(size int) *Tree {
+	return &Tree{tree: make([]int, size+1), size: size}
+}
+
+// NewFrom instantiates a Fenwick tree whose counters are initialised to the values in O(n).
+func NewFrom(values ...int) *Tree {
+	tree := New(len(values))
+	tree.build(values)
+	return tree
+}
+
+// Add adds delta to the counter at index.
+// Does not do anything if index is out of bounds.
+func (tree *Tree) Add(index int, delta int) {
+	if !tree.withinRange(index) {
+		return
+	}
+	for i := index + 1; i <= tree.size; i += i & -i {
+		tree.tree[i] += delta
+	}
+}
+
+// Set replaces the counter at index with the value.
+// Does not do anything if index is out of bounds.
+func (tree *Tree) Set(index int, value int) {
+	tree.Add(index, value-tree.Get(index))
+}
+
+// Get returns the counter at index or 0 if index is out of bounds.
+func (tree *Tree) Get(index int) int {
+	return tree.RangeSum(index, index)
+}
+
+// PrefixSum returns the sum of the counters within [0, index].
+// Index is clamped to the bounds of the tree, so it returns 0 for negative indexes.
+// PrefixSum 返回区间[0, index]的前缀和
+func (tree *Tree) PrefixSum(index int) int {
+	if index >= tree.size {
+		index = tree.size - 1
+	}
+	sum := 0
+	for i := index + 1; i > 0; i -= i & -i {
+		sum += tree.tree[i]
+	}
+	return sum
+}
+
+// RangeSum returns the sum of the counters within [from, to], or 0 if the range is empty.
+func (tree *Tree) RangeSum(from int, to int) int {
+	if from > to {
+		return 0
+	}
+	return tree.PrefixSum(to) - tree.PrefixSum(from-1)
+}
+
+// FindByPrefix returns the smallest index whose prefix sum is greater than or equal to target in O(log n).
+// Second return parameter is false if the total sum is smaller than target.
+// Requires all counters to be non-negative, so that prefix sums are non-decreasing.
+// FindByPrefix 通过二进制倍增找到前缀和不小于target的最小下标
+func (tree *Tree) FindByPrefix(target int) (index int, found bool) {
+	if target <= 0 {
+		return 0, tree.size > 0
+	}
+	position := 0
+	for step := highestPowerOfTwo(tree.size); step > 0; step >>= 1 {
+		if next := position + step; next <= tree.size && tree.tree[next] < target {
+			position = next
+			target -= tree.tree[next]
+		}
+	}
+	if position >= tree.size {
+		return -1, false
+	}
+	return position, true
+}
+
+// Empty returns true if tree does not contain any counters.
+func (tree *Tree) Empty() bool {
+	return tree.size == 0
+}
+
+// Size returns number of counters in the tree.
+func (tree *Tree) Size() int {
+	return tree.size
+}
+
+// Clear removes all counters from the tree.
+func (tree *Tree) Clear() {
+	tree.tree = make([]int, 1)
+	tree.size = 0
+}
+
+// Values returns all counters in index order.
+func (tree *Tree) Values() []interface{} {
+	values := make([]interface{}, tree.size)
+	for i, value := range tree.counters() {
+		values[i] = value
+	}
+	return values
+}
+
+// String returns a string representation of container
+func (tree *Tree) String() string {
+	str := "FenwickTree\n"
+	values := []string{}
+	for _, value := range tree.counters() {
+		values = append(values, fmt.Sprintf("%v", value))
+	}
+	str += strings.Join(values, ", ")
+	return str
+}
+
+// build initialises the partial sums from the values by pushing each node's sum to its parent.
+func (tree *Tree) build(values []int) {
+	copy(tree.tree[1:], values)
+	for i := 1; i <= tree.size; i++ {
+		if parent := i + i&-i; parent <= tree.size {
+			tree.tree[parent] += tree.tree[i]
+		}
+	}
+}
+
+// counters recovers the individual counters from the partial sums in O(n).
+func (tree *Tree) counters() []int {
+	counters := make([]int, tree.size)
+	copy(counters, tree.tree[1:])
+	for i := tree.size; i >= 1; i-- {
+		if parent := i + i&-i; parent <= tree.size {
+			counters[parent-1] -= counters[i-1]
+		}
+	}
+	return counters
+}
+
+// Check that the index is within bounds of the tree
+func (tree *Tree) withinRange(index int) bool {
+	return index >= 0 && index < tree.size
+}
+
+func highestPowerOfTwo(n int) int {
+	if n <= 0 {
+		return 0
+	}
+	return 1 << (bits.Len(uint(n)) - 1)
+}
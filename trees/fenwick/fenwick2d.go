@@ -0,0 +1,155 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fenwick
+
+import (
+	"fmt"
+	"github.com/dairongpeng/gds/trees"
+	"strings"
+)
+
+func assertTree2DImplementation() {
+	var _ trees.Tree = (*Tree2D)(nil)
+}
+
+// Tree2D holds the partial sums of a rows x columns grid of counters.
+// Tree2D 二维树状数组，支持单点更新与子矩阵求和
+type Tree2D struct {
+	tree    [][]int // 1-indexed partial sums
+	rows    int
+	columns int
+}
+
+// New2D instantiates a two-dimensional Fenwick tree with all counters set to zero.
+func New2D(rows int, columns int) *Tree2D {
+	tree := &Tree2D{rows: rows, columns: columns}
+	tree.tree = make([][]int, rows+1)
+	for i := range tree.tree {
+		tree.tree[i] = make([]int, columns+1)
+	}
+	return tree
+}
+
+// Add adds delta to the counter at (row, column).
+// Does not do anything if the cell is out of bounds.
+func (tree *Tree2D) Add(row int, column int, delta int) {
+	if !tree.withinRange(row, column) {
+		return
+	}
+	for i := row + 1; i <= tree.rows; i += i & -i {
+		for j := column + 1; j <= tree.columns; j += j & -j {
+			tree.tree[i][j] += delta
+		}
+	}
+}
+
+// Set replaces the counter at (row, column) with the value.
+// Does not do anything if the cell is out of bounds.
+func (tree *Tree2D) Set(row int, column int, value int) {
+	tree.Add(row, column, value-tree.Get(row, column))
+}
+
+// Get returns the counter at (row, column) or 0 if the cell is out of bounds.
+func (tree *Tree2D) Get(row int, column int) int {
+	return tree.RangeSum(row, column, row, column)
+}
+
+// PrefixSum returns the sum of the counters within rows [0, row] and columns [0, column].
+// Indexes are clamped to the bounds of the tree.
+func (tree *Tree2D) PrefixSum(row int, column int) int {
+	if row >= tree.rows {
+		row = tree.rows - 1
+	}
+	if column >= tree.columns {
+		column = tree.columns - 1
+	}
+	sum := 0
+	for i := row + 1; i > 0; i -= i & -i {
+		for j := column + 1; j > 0; j -= j & -j {
+			sum += tree.tree[i][j]
+		}
+	}
+	return sum
+}
+
+// RangeSum returns the sum of the counters within rows [fromRow, toRow] and columns [fromColumn, toColumn],
+// or 0 if the rectangle is empty.
+func (tree *Tree2D) RangeSum(fromRow int, fromColumn int, toRow int, toColumn int) int {
+	if fromRow > toRow || fromColumn > toColumn {
+		return 0
+	}
+	return tree.PrefixSum(toRow, toColumn) -
+		tree.PrefixSum(fromRow-1, toColumn) -
+		tree.PrefixSum(toRow, fromColumn-1) +
+		tree.PrefixSum(fromRow-1, fromColumn-1)
+}
+
+// Rows returns the number of rows of the grid.
+func (tree *Tree2D) Rows() int {
+	return tree.rows
+}
+
+// Columns returns the number of columns of the grid.
+func (tree *Tree2D) Columns() int {
+	return tree.columns
+}
+
+// Empty returns true if tree does not contain any counters.
+func (tree *Tree2D) Empty() bool {
+	return tree.Size() == 0
+}
+
+// Size returns number of counters in the tree, i.e. rows * columns.
+func (tree *Tree2D) Size() int {
+	return tree.rows * tree.columns
+}
+
+// Clear removes all counters from the tree.
+func (tree *Tree2D) Clear() {
+	*tree = *New2D(0, 0)
+}
+
+// Values returns all counters in row-major order.
+func (tree *Tree2D) Values() []interface{} {
+	values := make([]interface{}, 0, tree.Size())
+	for _, row := range tree.grid() {
+		for _, value := range row {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// String returns a string representation of container
+func (tree *Tree2D) String() string {
+	str := "FenwickTree2D\n"
+	rows := []string{}
+	for _, row := range tree.grid() {
+		values := []string{}
+		for _, value := range row {
+			values = append(values, fmt.Sprintf("%v", value))
+		}
+		rows = append(rows, strings.Join(values, ", "))
+	}
+	str += strings.Join(rows, "\n")
+	return str
+}
+
+// grid recovers the individual counters row by row.
+func (tree *Tree2D) grid() [][]int {
+	grid := make([][]int, tree.rows)
+	for i := range grid {
+		grid[i] = make([]int, tree.columns)
+		for j := range grid[i] {
+			grid[i][j] = tree.Get(i, j)
+		}
+	}
+	return grid
+}
+
+// Check that the cell is within bounds of the tree
+func (tree *Tree2D) withinRange(row int, column int) bool {
+	return row >= 0 && row < tree.rows && column >= 0 && column < tree.columns
+}
@@ -0,0 +1,242 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fenwick
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+func TestFenwickSums(t *testing.T) {
+	tree := NewFrom(3, 2, -1, 6, 5, 4, -3, 3, 7, 2, 3)
+
+	if actualValue := tree.Size(); actualValue != 11 {
+		t.Errorf("Got %v expected %v", actualValue, 11)
+	}
+	tests := [][]int{
+		// from, to, expected sum
+		{0, 0, 3},
+		{0, 10, 31},
+		{2, 5, 14},
+		{6, 6, -3},
+		{5, 4, 0},
+		{-5, 1, 5},
+		{9, 20, 5},
+	}
+	for _, test := range tests {
+		if actualValue := tree.RangeSum(test[0], test[1]); actualValue != test[2] {
+			t.Errorf("Got %v expected %v for [%v,%v]", actualValue, test[2], test[0], test[1])
+		}
+	}
+	if actualValue := tree.PrefixSum(3); actualValue != 10 {
+		t.Errorf("Got %v expected %v", actualValue, 10)
+	}
+
+	tree.Add(2, 5)
+	tree.Set(0, 0)
+	tree.Add(11, 100) // out of range
+	if actualValue, expectedValue := fmt.Sprintf("%v", tree.Values()), "[0 2 4 6 5 4 -3 3 7 2 3]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue := tree.Get(2); actualValue != 4 {
+		t.Errorf("Got %v expected %v", actualValue, 4)
+	}
+}
+
+func TestFenwickFindByPrefix(t *testing.T) {
+	// frequency table of values in the domain [0, 10)
+	tree := New(10)
+	for _, value := range []int{7, 2, 2, 9, 4, 7, 7} {
+		tree.Add(value, 1)
+	}
+	if actualValue := tree.PrefixSum(6); actualValue != 3 {
+		t.Errorf("Got %v expected %v", actualValue, 3)
+	}
+	// k-th smallest value
+	tests := [][]interface{}{
+		{1, 2, true},
+		{2, 2, true},
+		{3, 4, true},
+		{4, 7, true},
+		{6, 7, true},
+		{7, 9, true},
+		{8, -1, false},
+		{0, 0, true},
+	}
+	for _, test := range tests {
+		index, found := tree.FindByPrefix(test[0].(int))
+		if index != test[1] || found != test[2] {
+			t.Errorf("Got %v,%v expected %v,%v for %v", index, found, test[1], test[2], test[0])
+		}
+	}
+	if _, found := New(0).FindByPrefix(1); found {
+		t.Errorf("Got %v expected %v", found, false)
+	}
+}
+
+func TestFenwickRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, size := range []int{1, 2, 7, 64, 100} {
+		expected := make([]int, size)
+		tree := New(size)
+		for i := 0; i < 500; i++ {
+			index := r.Intn(size)
+			delta := r.Intn(10)
+			expected[index] += delta
+			tree.Add(index, delta)
+
+			from := r.Intn(size)
+			to := from + r.Intn(size-from)
+			sum := 0
+			for j := from; j <= to; j++ {
+				sum += expected[j]
+			}
+			if actualValue := tree.RangeSum(from, to); actualValue != sum {
+				t.Errorf("Got %v expected %v", actualValue, sum)
+			}
+
+			target := r.Intn(sum + 1)
+			prefix, expectedIndex := 0, -1
+			for j := 0; j < size; j++ {
+				prefix += expected[j]
+				if prefix >= target {
+					expectedIndex = j
+					break
+				}
+			}
+			if actualValue, _ := tree.FindByPrefix(target); target > 0 && actualValue != expectedIndex {
+				t.Errorf("Got %v expected %v for target %v", actualValue, expectedIndex, target)
+			}
+		}
+		if actualValue, expectedValue := fmt.Sprintf("%v", tree.Values()), fmt.Sprintf("%v", expected); actualValue != expectedValue {
+			t.Errorf("Got %v expected %v", actualValue, expectedValue)
+		}
+	}
+}
+
+func TestFenwick2D(t *testing.T) {
+	tree := New2D(3, 4)
+	tree.Add(0, 0, 1)
+	tree.Add(1, 2, 5)
+	tree.Add(2, 3, 2)
+	tree.Add(2, 1, 4)
+	tree.Set(0, 0, 3)
+	tree.Add(3, 0, 100) // out of range
+
+	if actualValue := tree.Size(); actualValue != 12 {
+		t.Errorf("Got %v expected %v", actualValue, 12)
+	}
+	tests := [][]int{
+		// fromRow, fromColumn, toRow, toColumn, expected sum
+		{0, 0, 2, 3, 14},
+		{1, 1, 2, 2, 9},
+		{0, 0, 0, 0, 3},
+		{2, 2, 1, 1, 0},
+		{1, 2, 5, 5, 7},
+	}
+	for _, test := range tests {
+		if actualValue := tree.RangeSum(test[0], test[1], test[2], test[3]); actualValue != test[4] {
+			t.Errorf("Got %v expected %v for %v", actualValue, test[4], test[:4])
+		}
+	}
+	if actualValue := tree.PrefixSum(1, 2); actualValue != 8 {
+		t.Errorf("Got %v expected %v", actualValue, 8)
+	}
+	if actualValue, expectedValue := fmt.Sprintf("%v", tree.Values()), "[3 0 0 0 0 0 5 0 0 4 0 2]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	tree.Clear()
+	if actualValue := tree.Empty(); actualValue != true {
+		t.Errorf("Got %v expected %v", actualValue, true)
+	}
+}
+
+func TestFenwickSerialization(t *testing.T) {
+	tree := NewFrom(1, 2, 3)
+	json, err := tree.ToJSON()
+	if err != nil {
+		t.Errorf("Got error %v", err)
+	}
+	if actualValue, expectedValue := string(json), "[1,2,3]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	deserialized := New(0)
+	if err := deserialized.FromJSON(json); err != nil {
+		t.Errorf("Got error %v", err)
+	}
+	if actualValue := deserialized.PrefixSum(2); actualValue != 6 {
+		t.Errorf("Got %v expected %v", actualValue, 6)
+	}
+
+	grid := New2D(2, 2)
+	grid.Add(0, 1, 5)
+	grid.Add(1, 0, 7)
+	json, err = grid.ToJSON()
+	if err != nil {
+		t.Errorf("Got error %v", err)
+	}
+	if actualValue, expectedValue := string(json), "[[0,5],[7,0]]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	deserialized2D := New2D(0, 0)
+	if err := deserialized2D.FromJSON(json); err != nil {
+		t.Errorf("Got error %v", err)
+	}
+	if actualValue := deserialized2D.RangeSum(0, 0, 1, 1); actualValue != 12 {
+		t.Errorf("Got %v expected %v", actualValue, 12)
+	}
+	if err := deserialized2D.FromJSON([]byte("[[1,2],[3]]")); err == nil {
+		t.Errorf("Expected error for rows of different length")
+	}
+}
+
+func TestFenwickStats(t *testing.T) {
+	tree := New(0)
+	if actualValue, expectedValue := fmt.Sprintf("%+v", tree.Stats()), "{Height:0 Nodes:0 AverageDepth:0 Rotations:0 Splits:0 Merges:0 Comparisons:0}"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	// 1, 2 and 4 are roots, 3, 5 and 6 their children and 7 a grandchild
+	tree = New(7)
+	if actualValue, expectedValue := fmt.Sprintf("%+v", tree.Stats()), "{Height:3 Nodes:7 AverageDepth:0.7142857142857143 Rotations:0 Splits:0 Merges:0 Comparisons:0}"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	grid := New2D(7, 2)
+	if actualValue, expectedValue := fmt.Sprintf("%+v", grid.Stats()), "{Height:3 Nodes:14 AverageDepth:0.7142857142857143 Rotations:0 Splits:0 Merges:0 Comparisons:0}"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+}
+
+func benchmarkAdd(b *testing.B, tree *Tree, size int) {
+	for i := 0; i < b.N; i++ {
+		for n := 0; n < size; n++ {
+			tree.Add(n, 1)
+		}
+	}
+}
+
+func benchmarkPrefixSum(b *testing.B, tree *Tree, size int) {
+	for i := 0; i < b.N; i++ {
+		for n := 0; n < size; n++ {
+			tree.PrefixSum(n)
+		}
+	}
+}
+
+func BenchmarkFenwickAdd100000(b *testing.B) {
+	b.StopTimer()
+	size := 100000
+	tree := New(size)
+	b.StartTimer()
+	benchmarkAdd(b, tree, size)
+}
+
+func BenchmarkFenwickPrefixSum100000(b *testing.B) {
+	b.StopTimer()
+	size := 100000
+	tree := New(size)
+	b.StartTimer()
+	benchmarkPrefixSum(b, tree, size)
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fenwick
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/dairongpeng/gds/containers"
+)
+
+func assertSerializationImplementation() {
+	var _ containers.JSONSerializer = (*Tree)(nil)
+	var _ containers.JSONDeserializer = (*Tree)(nil)
+	var _ containers.JSONSerializer = (*Tree2D)(nil)
+	var _ containers.JSONDeserializer = (*Tree2D)(nil)
+}
+
+// ToJSON outputs the JSON representation of the counters as an array.
+func (tree *Tree) ToJSON() ([]byte, error) {
+	return json.Marshal(tree.counters())
+}
+
+// FromJSON populates the tree from the input JSON representation.
+func (tree *Tree) FromJSON(data []byte) error {
+	var values []int
+	err := json.Unmarshal(data, &values)
+	if err == nil {
+		*tree = *NewFrom(values...)
+	}
+	return err
+}
+
+// ToJSON outputs the JSON representation of the counters as an array of rows.
+func (tree *Tree2D) ToJSON() ([]byte, error) {
+	return json.Marshal(tree.grid())
+}
+
+// FromJSON populates the tree from the input JSON representation.
+// All rows must have the same length.
+func (tree *Tree2D) FromJSON(data []byte) error {
+	var grid [][]int
+	if err := json.Unmarshal(data, &grid); err != nil {
+		return err
+	}
+	columns := 0
+	if len(grid) > 0 {
+		columns = len(grid[0])
+	}
+	for _, row := range grid {
+		if len(row) != columns {
+			return errors.New("fenwick: rows of different length")
+		}
+	}
+	*tree = *New2D(len(grid), columns)
+	for i, row := range grid {
+		for j, value := range row {
+			tree.Add(i, j, value)
+		}
+	}
+	return nil
+}
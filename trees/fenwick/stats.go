@@ -0,0 +1,52 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fenwick
+
+import (
+	"github.com/dairongpeng/gds/trees"
+	"math/bits"
+)
+
+// Stats returns the shape of the tree of partial sums, in which the parent of index i is i minus its lowest set bit
+// and powers of two are roots. The depth of an index is the number of partial sums a prefix sum up to it adds
+// beyond the first one. The tree has a fixed shape and does not use a comparator, so the counters stay zero.
+// The shape is measured in O(n).
+func (tree *Tree) Stats() trees.Stats {
+	s := trees.Stats{Nodes: tree.size}
+	height, depths := shape(tree.size)
+	s.Height = height
+	if tree.size > 0 {
+		s.AverageDepth = float64(depths) / float64(tree.size)
+	}
+	return s
+}
+
+// Stats returns the shape of the product of the trees of the rows and of the columns (see Tree.Stats), in which
+// the depth of a cell is the sum of the depths of its row and its column.
+// The counters stay zero. The shape is measured in O(rows+columns).
+func (tree *Tree2D) Stats() trees.Stats {
+	var s trees.Stats
+	if tree.rows == 0 || tree.columns == 0 {
+		return s
+	}
+	rowHeight, rowDepths := shape(tree.rows)
+	columnHeight, columnDepths := shape(tree.columns)
+	s.Nodes = tree.rows * tree.columns
+	s.Height = rowHeight + columnHeight - 1
+	s.AverageDepth = float64(rowDepths)/float64(tree.rows) + float64(columnDepths)/float64(tree.columns)
+	return s
+}
+
+// shape returns the height of the tree of partial sums of size counters and the sum of the depths of its indices.
+func shape(size int) (height int, depths int) {
+	for i := 1; i <= size; i++ {
+		depth := bits.OnesCount(uint(i)) - 1
+		depths += depth
+		if depth+1 > height {
+			height = depth + 1
+		}
+	}
+	return height, depths
+}
@@ -0,0 +1,204 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package intervaltree implements an interval tree backed by an augmented red-black tree.
+//
+// Intervals are closed, i.e. [low, high] contains both endpoints, and are identified by their endpoints:
+// inserting an interval that is already present replaces its value.
+// Every node keeps the maximum high endpoint of its subtree, which lets queries skip subtrees that cannot overlap.
+//
+// Structure is not thread safe.
+//
+// References: https://en.wikipedia.org/wiki/Interval_tree#Augmented_tree
+package intervaltree
+
+import (
+	"fmt"
+	"github.com/dairongpeng/gds/trees"
+	rbt "github.com/dairongpeng/gds/trees/redblacktree"
+	"github.com/dairongpeng/gds/utils"
+	"strings"
+)
+
+func assertTreeImplementation() {
+	var _ trees.Tree = (*Tree)(nil)
+}
+
+// Tree holds the intervals ordered by their low (then high) endpoint.
+// Tree 区间树，基于红黑树实现，每个节点维护以自身为头的子树中最大的右端点
+type Tree struct {
+	tree        *rbt.Tree
+	Comparator  utils.Comparator // Endpoint comparator
+	comparisons int              // Calls of the comparator
+}
+
+// Interval is a closed interval [Low, High] with its attached value.
+type Interval struct {
+	Low   interface{}
+	High  interface{}
+	Value interface{}
+}
+
+// endpoints is the key of an interval within the red-black tree.
+type endpoints struct {
+	low  interface{}
+	high interface{}
+}
+
+// NewWith instantiates an interval tree with the custom endpoint comparator.
+func NewWith(comparator utils.Comparator) *Tree {
+	t := &Tree{Comparator: comparator}
+	t.tree = rbt.NewWithAugmenter(t.compareEndpoints, t.maxHigh)
+	return t
+}
+
+// NewWithIntComparator instantiates an interval tree with the IntComparator, i.e. endpoints are of type int.
+func NewWithIntComparator() *Tree {
+	return NewWith(utils.IntComparator)
+}
+
+// NewWithStringComparator instantiates an interval tree with the StringComparator, i.e. endpoints are of type string.
+func NewWithStringComparator() *Tree {
+	return NewWith(utils.StringComparator)
+}
+
+// UsePool makes the tree allocate its nodes slabSize at a time and reuse the nodes of deleted intervals,
+// see redblacktree.Tree.UsePool.
+func (t *Tree) UsePool(slabSize int) {
+	t.tree.UsePool(slabSize)
+}
+
+// Insert adds the interval [low, high] with the value to the tree, replacing the value if the interval is already present.
+// Endpoints should adhere to the comparator's type assertion and low must not be greater than high, otherwise method panics.
+func (t *Tree) Insert(low interface{}, high interface{}, value interface{}) {
+	if t.compare(low, high) > 0 {
+		panic(fmt.Sprintf("Invalid interval [%v, %v], low is greater than high", low, high))
+	}
+	t.tree.Put(endpoints{low: low, high: high}, value)
+}
+
+// Delete removes the interval [low, high] from the tree.
+// Endpoints should adhere to the comparator's type assertion, otherwise method panics.
+func (t *Tree) Delete(low interface{}, high interface{}) {
+	t.tree.Remove(endpoints{low: low, high: high})
+}
+
+// Get returns the value of the interval [low, high] or nil if the interval is not in the tree.
+// Second return parameter is true if the interval was found, otherwise false.
+func (t *Tree) Get(low interface{}, high interface{}) (value interface{}, found bool) {
+	return t.tree.Get(endpoints{low: low, high: high})
+}
+
+// Overlapping returns all intervals that share at least one point with [low, high], ordered by their endpoints.
+// Subtrees whose maximum high endpoint lies before low are skipped, so the cost depends on the number of reported intervals.
+// Overlapping 返回所有与[low, high]有交集的区间
+func (t *Tree) Overlapping(low interface{}, high interface{}) []Interval {
+	var intervals []Interval
+	t.overlapping(t.tree.Root, low, high, &intervals)
+	return intervals
+}
+
+// Containing returns all intervals that contain the point, ordered by their endpoints.
+func (t *Tree) Containing(point interface{}) []Interval {
+	return t.Overlapping(point, point)
+}
+
+// Any returns some interval overlapping [low, high] in O(log n).
+// Second return parameter is true if such an interval exists, otherwise false.
+func (t *Tree) Any(low interface{}, high interface{}) (interval Interval, found bool) {
+	node := t.tree.Root
+	for node != nil {
+		key := node.Key.(endpoints)
+		if t.compare(key.low, high) <= 0 && t.compare(low, key.high) <= 0 {
+			return Interval{Low: key.low, High: key.high, Value: node.Value}, true
+		}
+		if node.Left != nil && t.compare(node.Left.Aggregate, low) >= 0 {
+			node = node.Left
+		} else {
+			node = node.Right
+		}
+	}
+	return Interval{}, false
+}
+
+// Empty returns true if tree does not contain any intervals
+func (t *Tree) Empty() bool {
+	return t.tree.Empty()
+}
+
+// Size returns number of intervals in the tree.
+func (t *Tree) Size() int {
+	return t.tree.Size()
+}
+
+// Intervals returns all intervals ordered by their endpoints.
+func (t *Tree) Intervals() []Interval {
+	intervals := make([]Interval, 0, t.Size())
+	it := t.Iterator()
+	for it.Next() {
+		intervals = append(intervals, it.Interval())
+	}
+	return intervals
+}
+
+// Values returns all values ordered by the endpoints of their intervals.
+func (t *Tree) Values() []interface{} {
+	return t.tree.Values()
+}
+
+// Clear removes all intervals from the tree.
+func (t *Tree) Clear() {
+	t.tree.Clear()
+}
+
+// String returns a string representation of container
+func (t *Tree) String() string {
+	str := "IntervalTree\n"
+	values := []string{}
+	it := t.Iterator()
+	for it.Next() {
+		interval := it.Interval()
+		values = append(values, fmt.Sprintf("[%v, %v]:%v", interval.Low, interval.High, interval.Value))
+	}
+	str += strings.Join(values, ", ")
+	return str
+}
+
+func (t *Tree) overlapping(node *rbt.Node, low interface{}, high interface{}, intervals *[]Interval) {
+	if node == nil || t.compare(node.Aggregate, low) < 0 {
+		return
+	}
+	t.overlapping(node.Left, low, high, intervals)
+	key := node.Key.(endpoints)
+	if t.compare(key.low, high) > 0 {
+		// this node and everything to its right starts after the query interval
+		return
+	}
+	if t.compare(low, key.high) <= 0 {
+		*intervals = append(*intervals, Interval{Low: key.low, High: key.high, Value: node.Value})
+	}
+	t.overlapping(node.Right, low, high, intervals)
+}
+
+// compareEndpoints orders intervals by their low endpoint, then by their high endpoint.
+func (t *Tree) compareEndpoints(a, b interface{}) int {
+	e1 := a.(endpoints)
+	e2 := b.(endpoints)
+	if c := t.compare(e1.low, e2.low); c != 0 {
+		return c
+	}
+	return t.compare(e1.high, e2.high)
+}
+
+// maxHigh computes the largest high endpoint within the subtree of the node.
+func (t *Tree) maxHigh(node *rbt.Node) interface{} {
+	max := node.Key.(endpoints).high
+	if node.Left != nil && t.compare(node.Left.Aggregate, max) > 0 {
+		max = node.Left.Aggregate
+	}
+	if node.Right != nil && t.compare(node.Right.Aggregate, max) > 0 {
+		max = node.Right.Aggregate
+	}
+	return max
+}
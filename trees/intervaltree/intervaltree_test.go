@@ -0,0 +1,295 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intervaltree
+
+import (
+	"fmt"
+	"github.com/dairongpeng/gds/containers"
+	"github.com/dairongpeng/gds/utils"
+	"math/rand"
+	"testing"
+)
+
+func intervalsString(intervals []Interval) string {
+	str := ""
+	for _, interval := range intervals {
+		str += fmt.Sprintf("[%v,%v]", interval.Low, interval.High)
+	}
+	return str
+}
+
+func TestIntervalTreeInsert(t *testing.T) {
+	tree := NewWithIntComparator()
+	tree.Insert(5, 10, "a")
+	tree.Insert(1, 3, "b")
+	tree.Insert(5, 7, "c")
+	tree.Insert(15, 20, "d")
+	tree.Insert(5, 10, "e") //overwrite
+
+	if actualValue := tree.Size(); actualValue != 4 {
+		t.Errorf("Got %v expected %v", actualValue, 4)
+	}
+	if actualValue, expectedValue := intervalsString(tree.Intervals()), "[1,3][5,7][5,10][15,20]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue, expectedValue := fmt.Sprintf("%v", tree.Values()), "[b c e d]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue, found := tree.Get(5, 10); actualValue != "e" || !found {
+		t.Errorf("Got %v expected %v", actualValue, "e")
+	}
+	if actualValue, found := tree.Get(5, 11); actualValue != nil || found {
+		t.Errorf("Got %v expected %v", actualValue, nil)
+	}
+
+	tree.Delete(5, 7)
+	tree.Delete(5, 11)
+	if actualValue, expectedValue := intervalsString(tree.Intervals()), "[1,3][5,10][15,20]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+}
+
+func TestIntervalTreeInsertInvalid(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Expected panic for an interval with low greater than high")
+		}
+	}()
+	NewWithIntComparator().Insert(3, 1, nil)
+}
+
+func TestIntervalTreeOverlapping(t *testing.T) {
+	tree := NewWithIntComparator()
+	tree.Insert(15, 20, nil)
+	tree.Insert(10, 30, nil)
+	tree.Insert(17, 19, nil)
+	tree.Insert(5, 20, nil)
+	tree.Insert(12, 15, nil)
+	tree.Insert(30, 40, nil)
+
+	tests := [][]interface{}{
+		{6, 7, "[5,20]"},
+		{0, 4, ""},
+		{20, 20, "[5,20][10,30][15,20]"},
+		{31, 50, "[30,40]"},
+		{41, 50, ""},
+		{13, 16, "[5,20][10,30][12,15][15,20]"},
+	}
+	for _, test := range tests {
+		if actualValue := intervalsString(tree.Overlapping(test[0], test[1])); actualValue != test[2] {
+			t.Errorf("Got %v expected %v for [%v,%v]", actualValue, test[2], test[0], test[1])
+		}
+		interval, found := tree.Any(test[0], test[1])
+		if found != (test[2] != "") {
+			t.Errorf("Got %v expected %v for [%v,%v]", found, test[2] != "", test[0], test[1])
+		}
+		if found && (interval.Low.(int) > test[1].(int) || interval.High.(int) < test[0].(int)) {
+			t.Errorf("Got [%v,%v] which does not overlap [%v,%v]", interval.Low, interval.High, test[0], test[1])
+		}
+	}
+
+	if actualValue, expectedValue := intervalsString(tree.Containing(30)), "[10,30][30,40]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+}
+
+func TestIntervalTreeRandom(t *testing.T) {
+	tree := NewWithIntComparator()
+	r := rand.New(rand.NewSource(1))
+	expected := make(map[[2]int]bool)
+	for i := 0; i < 3000; i++ {
+		low := r.Intn(500)
+		high := low + r.Intn(40)
+		if r.Intn(4) == 0 {
+			tree.Delete(low, high)
+			delete(expected, [2]int{low, high})
+		} else {
+			tree.Insert(low, high, i)
+			expected[[2]int{low, high}] = true
+		}
+	}
+	for i := 0; i < 200; i++ {
+		low := r.Intn(550)
+		high := low + r.Intn(20)
+		count := 0
+		for interval := range expected {
+			if interval[0] <= high && low <= interval[1] {
+				count++
+			}
+		}
+		overlapping := tree.Overlapping(low, high)
+		if actualValue := len(overlapping); actualValue != count {
+			t.Errorf("Got %v expected %v for [%v,%v]", actualValue, count, low, high)
+		}
+		for _, interval := range overlapping {
+			if !expected[[2]int{interval.Low.(int), interval.High.(int)}] {
+				t.Errorf("Got unexpected interval [%v,%v]", interval.Low, interval.High)
+			}
+		}
+		if _, found := tree.Any(low, high); found != (count > 0) {
+			t.Errorf("Got %v expected %v for [%v,%v]", found, count > 0, low, high)
+		}
+	}
+}
+
+func TestIntervalTreeUsePool(t *testing.T) {
+	tree := NewWithIntComparator()
+	pooled := NewWithIntComparator()
+	pooled.UsePool(16)
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 3000; i++ {
+		low := r.Intn(500)
+		high := low + r.Intn(40)
+		if r.Intn(4) == 0 {
+			tree.Delete(low, high)
+			pooled.Delete(low, high)
+		} else {
+			tree.Insert(low, high, i)
+			pooled.Insert(low, high, i)
+		}
+	}
+	if actualValue, expectedValue := fmt.Sprint(pooled.Intervals()), fmt.Sprint(tree.Intervals()); actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	for low := 0; low < 550; low += 25 {
+		if actualValue, expectedValue := fmt.Sprint(pooled.Overlapping(low, low+10)), fmt.Sprint(tree.Overlapping(low, low+10)); actualValue != expectedValue {
+			t.Errorf("Got %v expected %v for [%v,%v]", actualValue, expectedValue, low, low+10)
+		}
+	}
+}
+
+func TestIntervalTreeIterator(t *testing.T) {
+	tree := NewWithIntComparator()
+	tree.Insert(3, 4, "c")
+	tree.Insert(1, 2, "a")
+	tree.Insert(2, 5, "b")
+
+	it := tree.Iterator()
+	count := 0
+	for it.Next() {
+		count++
+		interval := it.Key().(Interval)
+		switch count {
+		case 1:
+			if interval.Low != 1 || interval.High != 2 || it.Value() != "a" {
+				t.Errorf("Got %v expected %v", interval, "[1,2]:a")
+			}
+		case 3:
+			if interval.Low != 3 || interval.High != 4 || it.Value() != "c" {
+				t.Errorf("Got %v expected %v", interval, "[3,4]:c")
+			}
+		}
+	}
+	if actualValue, expectedValue := count, 3; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if !it.Last() || it.Interval().Low != 3 {
+		t.Errorf("Got %v expected %v", it.Interval().Low, 3)
+	}
+	if !it.Prev() || it.Interval().Value != "b" {
+		t.Errorf("Got %v expected %v", it.Interval().Value, "b")
+	}
+}
+
+func TestIntervalTreeIteratorRemove(t *testing.T) {
+	tree := NewWithIntComparator()
+	for _, i := range rand.Perm(50) {
+		tree.Insert(i, i+10, i)
+	}
+	it := tree.Iterator()
+	for it.Next() {
+		if it.Interval().Low.(int)%2 == 0 {
+			it.Remove()
+		}
+	}
+	if actualValue, expectedValue := tree.Size(), 25; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	// the maximum endpoints of the subtrees follow the removals
+	if actualValue, expectedValue := fmt.Sprint(tree.Containing(10)), "[{1 11 1} {3 13 3} {5 15 5} {7 17 7} {9 19 9}]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	func() {
+		defer func() {
+			if r := recover(); r != containers.ErrConcurrentModification {
+				t.Errorf("Got %v expected %v", r, containers.ErrConcurrentModification)
+			}
+		}()
+		it.Last()
+		tree.Delete(49, 59)
+		it.Prev()
+	}()
+}
+
+func TestIntervalTreeStats(t *testing.T) {
+	tree := NewWithIntComparator()
+	for i := 1; i <= 7; i++ {
+		tree.Insert(i, i+1, i)
+	}
+	stats := tree.Stats()
+	if actualValue, expectedValue := fmt.Sprint(stats.Height, stats.Nodes, stats.Rotations), "4 7 3"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+
+	// the low endpoint of [3, 4] tells it apart from the root [2, 3] and its right child [4, 5],
+	// both endpoints are compared with the left child [3, 4] of the latter
+	comparisons := stats.Comparisons
+	tree.Get(3, 4)
+	if actualValue, expectedValue := tree.Stats().Comparisons, comparisons+4; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+}
+
+func TestIntervalTreeSerialization(t *testing.T) {
+	tree := NewWith(utils.Float64Comparator)
+	tree.Insert(1.0, 2.5, "a")
+	tree.Insert(0.5, 4.0, "b")
+
+	json, err := tree.ToJSON()
+	if err != nil {
+		t.Errorf("Got error %v", err)
+	}
+	if actualValue, expectedValue := string(json), `[{"low":0.5,"high":4,"value":"b"},{"low":1,"high":2.5,"value":"a"}]`; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+
+	deserialized := NewWith(utils.Float64Comparator)
+	if err := deserialized.FromJSON(json); err != nil {
+		t.Errorf("Got error %v", err)
+	}
+	if actualValue, expectedValue := intervalsString(deserialized.Containing(2.0)), "[0.5,4][1,2.5]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+}
+
+func benchmarkOverlapping(b *testing.B, tree *Tree, size int) {
+	for i := 0; i < b.N; i++ {
+		for n := 0; n < size; n++ {
+			tree.Overlapping(n, n+5)
+		}
+	}
+}
+
+func BenchmarkIntervalTreeOverlapping1000(b *testing.B) {
+	b.StopTimer()
+	size := 1000
+	tree := NewWithIntComparator()
+	for n := 0; n < size; n++ {
+		tree.Insert(n, n+10, struct{}{})
+	}
+	b.StartTimer()
+	benchmarkOverlapping(b, tree, size)
+}
+
+func BenchmarkIntervalTreeOverlapping100000(b *testing.B) {
+	b.StopTimer()
+	size := 100000
+	tree := NewWithIntComparator()
+	for n := 0; n < size; n++ {
+		tree.Insert(n, n+10, struct{}{})
+	}
+	b.StartTimer()
+	benchmarkOverlapping(b, tree, size)
+}
@@ -0,0 +1,46 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intervaltree
+
+import (
+	"encoding/json"
+	"github.com/dairongpeng/gds/containers"
+)
+
+func assertSerializationImplementation() {
+	var _ containers.JSONSerializer = (*Tree)(nil)
+	var _ containers.JSONDeserializer = (*Tree)(nil)
+}
+
+type jsonInterval struct {
+	Low   interface{} `json:"low"`
+	High  interface{} `json:"high"`
+	Value interface{} `json:"value"`
+}
+
+// ToJSON outputs the JSON representation of the tree as an array of {"low", "high", "value"} objects.
+func (t *Tree) ToJSON() ([]byte, error) {
+	elements := make([]jsonInterval, 0, t.Size())
+	it := t.Iterator()
+	for it.Next() {
+		interval := it.Interval()
+		elements = append(elements, jsonInterval{Low: interval.Low, High: interval.High, Value: interval.Value})
+	}
+	return json.Marshal(&elements)
+}
+
+// FromJSON populates the tree from the input JSON representation.
+// Decoded endpoints must adhere to the comparator's type assertion (e.g. numbers are decoded as float64).
+func (t *Tree) FromJSON(data []byte) error {
+	var elements []jsonInterval
+	err := json.Unmarshal(data, &elements)
+	if err == nil {
+		t.Clear()
+		for _, element := range elements {
+			t.Insert(element.Low, element.High, element.Value)
+		}
+	}
+	return err
+}
@@ -0,0 +1,21 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intervaltree
+
+import "github.com/dairongpeng/gds/trees"
+
+// Stats returns the shape of the underlying red-black tree, the number of rotations and the number of endpoint
+// comparator calls since the tree was created.
+func (t *Tree) Stats() trees.Stats {
+	s := t.tree.Stats()
+	s.Comparisons = t.comparisons
+	return s
+}
+
+// compare calls the endpoint comparator and counts the call.
+func (t *Tree) compare(a interface{}, b interface{}) int {
+	t.comparisons++
+	return t.Comparator(a, b)
+}
@@ -0,0 +1,465 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package kdtree implements a k-d tree, a binary space partitioning tree over points in k dimensions.
+//
+// Every node splits the space along one axis, cycling through the axes by depth, so that searches can skip the
+// half of a subtree that is too far away. Nearest neighbour, radius and range searches take O(log n) time on
+// average for well distributed points. Build creates a balanced tree; Insert and Delete do not rebalance it.
+//
+// The distance function is pluggable but has to be a metric that never grows when one coordinate gets closer to
+// the query, such as the Euclidean or Manhattan distance, since it is also used to bound the distance to splitting planes.
+//
+// Structure is not thread safe.
+//
+// Reference: https://en.wikipedia.org/wiki/K-d_tree
+package kdtree
+
+import (
+	"fmt"
+	"github.com/dairongpeng/gds/trees"
+	"github.com/dairongpeng/gds/trees/binaryheap"
+	"math"
+	"sort"
+	"strings"
+)
+
+func assertTreeImplementation() {
+	var _ trees.Tree = (*Tree)(nil)
+}
+
+// Point is a position in k-dimensional space
+type Point []float64
+
+// DistanceFunc returns the distance between two points of the same dimensionality
+type DistanceFunc func(a, b Point) float64
+
+// Entry is a point with its attached value
+type Entry struct {
+	Point Point
+	Value interface{}
+}
+
+// Tree holds elements of the k-d tree
+// Tree k-d树，每一层按照一个坐标轴划分空间
+type Tree struct {
+	Root       *Node
+	dimensions int
+	distance   DistanceFunc
+	size       int
+}
+
+// Node is a single element within the tree, which splits its subtree along Axis at its point
+type Node struct {
+	Entry
+	Axis  int
+	Left  *Node // points smaller than the point on the axis
+	Right *Node // points larger than or equal to the point on the axis
+}
+
+// EuclideanDistance returns the straight-line distance between the points.
+func EuclideanDistance(a, b Point) float64 {
+	sum := 0.0
+	for i := range a {
+		d := a[i] - b[i]
+		sum += d * d
+	}
+	return math.Sqrt(sum)
+}
+
+// ManhattanDistance returns the sum of the absolute differences of the coordinates of the points.
+func ManhattanDistance(a, b Point) float64 {
+	sum := 0.0
+	for i := range a {
+		sum += math.Abs(a[i] - b[i])
+	}
+	return sum
+}
+
+// New instantiates a k-d tree of the given dimensionality using the Euclidean distance.
+func New(dimensions int) *Tree {
+	return NewWith(dimensions, EuclideanDistance)
+}
+
+// NewWith instantiates a k-d tree of the given dimensionality using the custom distance function.
+func NewWith(dimensions int, distance DistanceFunc) *Tree {
+	return &Tree{dimensions: dimensions, distance: distance}
+}
+
+// Build replaces the content of the tree with a balanced tree of the entries, splitting at the median on every level.
+// Points should have the dimensionality of the tree, otherwise method panics.
+func (tree *Tree) Build(entries ...Entry) {
+	for _, entry := range entries {
+		tree.check(entry.Point)
+	}
+	sorted := make([]Entry, len(entries))
+	copy(sorted, entries)
+	tree.Root = tree.build(sorted, 0)
+	tree.size = len(entries)
+}
+
+// Insert adds the point with the attached value to the tree. Equal points are kept as separate entries.
+// Point should have the dimensionality of the tree, otherwise method panics.
+func (tree *Tree) Insert(point Point, value interface{}) {
+	tree.check(point)
+	node := &Node{Entry: Entry{Point: point, Value: value}}
+	if tree.Root == nil {
+		tree.Root = node
+		tree.size++
+		return
+	}
+	current := tree.Root
+	for {
+		node.Axis = (current.Axis + 1) % tree.dimensions
+		if point[current.Axis] < current.Point[current.Axis] {
+			if current.Left == nil {
+				current.Left = node
+				break
+			}
+			current = current.Left
+		} else {
+			if current.Right == nil {
+				current.Right = node
+				break
+			}
+			current = current.Right
+		}
+	}
+	tree.size++
+}
+
+// Get returns the value of an entry at the point or nil if there is none.
+// Second return parameter is true if an entry was found, otherwise false.
+// Point should have the dimensionality of the tree, otherwise method panics.
+func (tree *Tree) Get(point Point) (value interface{}, found bool) {
+	tree.check(point)
+	if node := tree.lookup(tree.Root, point); node != nil {
+		return node.Value, true
+	}
+	return nil, false
+}
+
+// Delete removes one entry at the point from the tree, if there is any.
+// Point should have the dimensionality of the tree, otherwise method panics.
+func (tree *Tree) Delete(point Point) {
+	tree.check(point)
+	var deleted bool
+	tree.Root, deleted = tree.delete(tree.Root, point, nil)
+	if deleted {
+		tree.size--
+	}
+}
+
+// Nearest returns the entry closest to the point.
+// Second return parameter is false if the tree is empty.
+// Point should have the dimensionality of the tree, otherwise method panics.
+func (tree *Tree) Nearest(point Point) (entry Entry, found bool) {
+	if entries := tree.KNearest(point, 1); len(entries) > 0 {
+		return entries[0], true
+	}
+	return Entry{}, false
+}
+
+// KNearest returns the k entries closest to the point, from the closest to the farthest.
+// Point should have the dimensionality of the tree, otherwise method panics.
+// KNearest 返回距离point最近的k个元素，使用大小为k的大根堆保存候选集
+func (tree *Tree) KNearest(point Point, k int) []Entry {
+	tree.check(point)
+	if k <= 0 {
+		return []Entry{}
+	}
+	// max-heap on the distance, so the farthest candidate is evicted first
+	heap := binaryheap.NewWith(func(a, b interface{}) int {
+		return compareDistance(b.(candidate).distance, a.(candidate).distance)
+	})
+	scratch := make(Point, len(point))
+	copy(scratch, point)
+	tree.nearest(tree.Root, point, scratch, k, heap)
+
+	entries := make([]Entry, heap.Size())
+	for i := len(entries) - 1; i >= 0; i-- {
+		value, _ := heap.Pop()
+		entries[i] = value.(candidate).entry
+	}
+	return entries
+}
+
+// Radius returns all entries within the distance of the point, from the closest to the farthest.
+// Point should have the dimensionality of the tree, otherwise method panics.
+func (tree *Tree) Radius(point Point, radius float64) []Entry {
+	tree.check(point)
+	candidates := []candidate{}
+	scratch := make(Point, len(point))
+	copy(scratch, point)
+	tree.radius(tree.Root, point, scratch, radius, &candidates)
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].distance < candidates[j].distance
+	})
+	entries := make([]Entry, len(candidates))
+	for i, c := range candidates {
+		entries[i] = c.entry
+	}
+	return entries
+}
+
+// Range returns all entries within the axis-aligned box between min and max, bounds included.
+// Points should have the dimensionality of the tree, otherwise method panics.
+func (tree *Tree) Range(min Point, max Point) []Entry {
+	tree.check(min)
+	tree.check(max)
+	entries := []Entry{}
+	tree.inRange(tree.Root, min, max, &entries)
+	return entries
+}
+
+// Dimensions returns the dimensionality of the points in the tree.
+func (tree *Tree) Dimensions() int {
+	return tree.dimensions
+}
+
+// Empty returns true if tree does not contain any entries
+func (tree *Tree) Empty() bool {
+	return tree.size == 0
+}
+
+// Size returns number of entries in the tree.
+func (tree *Tree) Size() int {
+	return tree.size
+}
+
+// Clear removes all entries from the tree.
+func (tree *Tree) Clear() {
+	tree.Root = nil
+	tree.size = 0
+}
+
+// Entries returns all entries in pre-order.
+func (tree *Tree) Entries() []Entry {
+	entries := make([]Entry, 0, tree.size)
+	var collect func(node *Node)
+	collect = func(node *Node) {
+		if node == nil {
+			return
+		}
+		entries = append(entries, node.Entry)
+		collect(node.Left)
+		collect(node.Right)
+	}
+	collect(tree.Root)
+	return entries
+}
+
+// Values returns the values of all entries in pre-order.
+func (tree *Tree) Values() []interface{} {
+	values := make([]interface{}, 0, tree.size)
+	for _, entry := range tree.Entries() {
+		values = append(values, entry.Value)
+	}
+	return values
+}
+
+// String returns a string representation of container
+func (tree *Tree) String() string {
+	str := "KDTree\n"
+	entries := []string{}
+	for _, entry := range tree.Entries() {
+		entries = append(entries, fmt.Sprintf("%v:%v", entry.Point, entry.Value))
+	}
+	str += strings.Join(entries, ", ")
+	return str
+}
+
+type candidate struct {
+	entry    Entry
+	distance float64
+}
+
+func (tree *Tree) build(entries []Entry, axis int) *Node {
+	if len(entries) == 0 {
+		return nil
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Point[axis] < entries[j].Point[axis]
+	})
+	median := len(entries) / 2
+	// equal coordinates belong to the right subtree
+	for median > 0 && entries[median-1].Point[axis] == entries[median].Point[axis] {
+		median--
+	}
+	next := (axis + 1) % tree.dimensions
+	return &Node{
+		Entry: entries[median],
+		Axis:  axis,
+		Left:  tree.build(entries[:median], next),
+		Right: tree.build(entries[median+1:], next),
+	}
+}
+
+func (tree *Tree) lookup(node *Node, point Point) *Node {
+	for node != nil {
+		if equal(node.Point, point) {
+			return node
+		}
+		if point[node.Axis] < node.Point[node.Axis] {
+			node = node.Left
+		} else {
+			node = node.Right
+		}
+	}
+	return nil
+}
+
+// delete removes the target node, or any node at the point if target is nil, from the subtree
+// and returns the new root of the subtree. Every node is found by following its own point from the root.
+// A deleted inner node is replaced by the entry with the smallest coordinate on its axis from the right subtree,
+// or from the left subtree which then becomes the right one, keeping equal coordinates on the right.
+func (tree *Tree) delete(node *Node, point Point, target *Node) (*Node, bool) {
+	if node == nil {
+		return nil, false
+	}
+	if node == target || (target == nil && equal(node.Point, point)) {
+		var replacement *Node
+		switch {
+		case node.Right != nil:
+			replacement = tree.minimum(node.Right, node.Axis)
+		case node.Left != nil:
+			replacement = tree.minimum(node.Left, node.Axis)
+			node.Right, node.Left = node.Left, nil
+		default:
+			return nil, true
+		}
+		node.Entry = replacement.Entry
+		node.Right, _ = tree.delete(node.Right, replacement.Point, replacement)
+		return node, true
+	}
+	var deleted bool
+	if point[node.Axis] < node.Point[node.Axis] {
+		node.Left, deleted = tree.delete(node.Left, point, target)
+	} else {
+		node.Right, deleted = tree.delete(node.Right, point, target)
+	}
+	return node, deleted
+}
+
+// minimum returns the node with the smallest coordinate on the axis in the subtree.
+func (tree *Tree) minimum(node *Node, axis int) *Node {
+	if node == nil {
+		return nil
+	}
+	if node.Axis == axis {
+		if node.Left == nil {
+			return node
+		}
+		return tree.minimum(node.Left, axis)
+	}
+	best := node
+	for _, child := range []*Node{tree.minimum(node.Left, axis), tree.minimum(node.Right, axis)} {
+		if child != nil && child.Point[axis] < best.Point[axis] {
+			best = child
+		}
+	}
+	return best
+}
+
+// nearest collects the k closest entries of the subtree in the heap.
+// scratch equals the query and is used to compute the distance to splitting planes.
+func (tree *Tree) nearest(node *Node, point Point, scratch Point, k int, heap *binaryheap.Heap) {
+	if node == nil {
+		return
+	}
+	distance := tree.distance(point, node.Point)
+	if heap.Size() < k {
+		heap.Push(candidate{entry: node.Entry, distance: distance})
+	} else if farthest, _ := heap.Peek(); distance < farthest.(candidate).distance {
+		heap.Pop()
+		heap.Push(candidate{entry: node.Entry, distance: distance})
+	}
+
+	near, far := node.Left, node.Right
+	if point[node.Axis] >= node.Point[node.Axis] {
+		near, far = far, near
+	}
+	tree.nearest(near, point, scratch, k, heap)
+	if far == nil {
+		return
+	}
+	if heap.Size() == k {
+		// the far side can only hold closer points if the splitting plane is closer than the farthest candidate
+		scratch[node.Axis] = node.Point[node.Axis]
+		bound := tree.distance(point, scratch)
+		scratch[node.Axis] = point[node.Axis]
+		if farthest, _ := heap.Peek(); bound >= farthest.(candidate).distance {
+			return
+		}
+	}
+	tree.nearest(far, point, scratch, k, heap)
+}
+
+func (tree *Tree) radius(node *Node, point Point, scratch Point, radius float64, candidates *[]candidate) {
+	if node == nil {
+		return
+	}
+	if distance := tree.distance(point, node.Point); distance <= radius {
+		*candidates = append(*candidates, candidate{entry: node.Entry, distance: distance})
+	}
+	near, far := node.Left, node.Right
+	if point[node.Axis] >= node.Point[node.Axis] {
+		near, far = far, near
+	}
+	tree.radius(near, point, scratch, radius, candidates)
+	scratch[node.Axis] = node.Point[node.Axis]
+	bound := tree.distance(point, scratch)
+	scratch[node.Axis] = point[node.Axis]
+	if bound <= radius {
+		tree.radius(far, point, scratch, radius, candidates)
+	}
+}
+
+func (tree *Tree) inRange(node *Node, min Point, max Point, entries *[]Entry) {
+	if node == nil {
+		return
+	}
+	inside := true
+	for i, coordinate := range node.Point {
+		if coordinate < min[i] || coordinate > max[i] {
+			inside = false
+			break
+		}
+	}
+	if inside {
+		*entries = append(*entries, node.Entry)
+	}
+	if min[node.Axis] < node.Point[node.Axis] {
+		tree.inRange(node.Left, min, max, entries)
+	}
+	if max[node.Axis] >= node.Point[node.Axis] {
+		tree.inRange(node.Right, min, max, entries)
+	}
+}
+
+func (tree *Tree) check(point Point) {
+	if len(point) != tree.dimensions {
+		panic(fmt.Sprintf("Point %v does not have %d dimensions", point, tree.dimensions))
+	}
+}
+
+func equal(a, b Point) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func compareDistance(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
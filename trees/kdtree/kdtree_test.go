@@ -0,0 +1,351 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+// assertPartition checks that every node is on the correct side of all its ancestors.
+func assertPartition(t *testing.T, tree *Tree) {
+	var check func(node *Node, depth int, ancestors []*Node, left []bool) int
+	check = func(node *Node, depth int, ancestors []*Node, left []bool) int {
+		if node == nil {
+			return 0
+		}
+		for i, ancestor := range ancestors {
+			if smaller := node.Point[ancestor.Axis] < ancestor.Point[ancestor.Axis]; smaller != left[i] {
+				t.Errorf("Point %v is on the wrong side of %v", node.Point, ancestor.Point)
+			}
+		}
+		ancestors = append(ancestors, node)
+		return 1 + check(node.Left, depth+1, ancestors, append(left, true)) +
+			check(node.Right, depth+1, ancestors, append(left[:len(left):len(left)], false))
+	}
+	if actualValue := check(tree.Root, 0, nil, nil); actualValue != tree.Size() {
+		t.Errorf("Got %v expected %v", actualValue, tree.Size())
+	}
+}
+
+func randomEntries(r *rand.Rand, count int, dimensions int) []Entry {
+	entries := make([]Entry, count)
+	for i := range entries {
+		point := make(Point, dimensions)
+		for j := range point {
+			// coarse coordinates, so that equal coordinates and points occur
+			point[j] = float64(r.Intn(50))
+		}
+		entries[i] = Entry{Point: point, Value: i}
+	}
+	return entries
+}
+
+func bruteForce(entries []Entry, point Point, distance DistanceFunc) []float64 {
+	distances := make([]float64, len(entries))
+	for i, entry := range entries {
+		distances[i] = distance(point, entry.Point)
+	}
+	sort.Float64s(distances)
+	return distances
+}
+
+func TestKDTreeInsertGet(t *testing.T) {
+	tree := New(2)
+	tree.Insert(Point{3, 6}, "a")
+	tree.Insert(Point{17, 15}, "b")
+	tree.Insert(Point{13, 15}, "c")
+	tree.Insert(Point{6, 12}, "d")
+	tree.Insert(Point{9, 1}, "e")
+	tree.Insert(Point{2, 7}, "f")
+	tree.Insert(Point{10, 19}, "g")
+	assertPartition(t, tree)
+
+	if actualValue := tree.Size(); actualValue != 7 {
+		t.Errorf("Got %v expected %v", actualValue, 7)
+	}
+	tests := [][]interface{}{
+		{Point{3, 6}, "a", true},
+		{Point{10, 19}, "g", true},
+		{Point{9, 1}, "e", true},
+		{Point{9, 2}, nil, false},
+	}
+	for _, test := range tests {
+		actualValue, actualFound := tree.Get(test[0].(Point))
+		if actualValue != test[1] || actualFound != test[2] {
+			t.Errorf("Got %v,%v expected %v,%v", actualValue, actualFound, test[1], test[2])
+		}
+	}
+	if actualValue, expectedValue := fmt.Sprintf("%v", tree.Values()), "[a f b d e c g]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+}
+
+func TestKDTreeBuild(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	entries := randomEntries(r, 1000, 3)
+	tree := New(3)
+	tree.Build(entries...)
+	assertPartition(t, tree)
+
+	if actualValue := tree.Size(); actualValue != 1000 {
+		t.Errorf("Got %v expected %v", actualValue, 1000)
+	}
+	var height func(node *Node) int
+	height = func(node *Node) int {
+		if node == nil {
+			return 0
+		}
+		left, right := height(node.Left), height(node.Right)
+		if left > right {
+			return left + 1
+		}
+		return right + 1
+	}
+	if actualValue := height(tree.Root); actualValue > 20 {
+		t.Errorf("Got height %v expected a balanced tree", actualValue)
+	}
+	for _, entry := range entries {
+		if _, found := tree.Get(entry.Point); !found {
+			t.Errorf("Point %v not found", entry.Point)
+		}
+	}
+}
+
+func TestKDTreeDelete(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	entries := randomEntries(r, 500, 2)
+	tree := New(2)
+	tree.Build(entries[:250]...)
+	for _, entry := range entries[250:] {
+		tree.Insert(entry.Point, entry.Value)
+	}
+
+	remaining := map[string]int{}
+	for _, entry := range entries {
+		remaining[fmt.Sprint(entry.Point)]++
+	}
+	for i, entry := range entries {
+		if i%2 == 0 {
+			continue
+		}
+		tree.Delete(entry.Point)
+		remaining[fmt.Sprint(entry.Point)]--
+	}
+	tree.Delete(Point{-1, -1}) // not in tree
+	assertPartition(t, tree)
+
+	if actualValue := tree.Size(); actualValue != 250 {
+		t.Errorf("Got %v expected %v", actualValue, 250)
+	}
+	counts := map[string]int{}
+	for _, entry := range tree.Entries() {
+		counts[fmt.Sprint(entry.Point)]++
+	}
+	for point, count := range remaining {
+		if counts[point] != count {
+			t.Errorf("Got %v expected %v entries at %v", counts[point], count, point)
+		}
+	}
+
+	for _, entry := range tree.Entries() {
+		tree.Delete(entry.Point)
+	}
+	if actualValue := tree.Empty(); actualValue != true {
+		t.Errorf("Got %v expected %v", actualValue, true)
+	}
+}
+
+func TestKDTreeKNearest(t *testing.T) {
+	tree := New(2)
+	if _, found := tree.Nearest(Point{0, 0}); found {
+		t.Errorf("Got %v expected %v", found, false)
+	}
+	tree.Build(
+		Entry{Point{2, 3}, "a"},
+		Entry{Point{5, 4}, "b"},
+		Entry{Point{9, 6}, "c"},
+		Entry{Point{4, 7}, "d"},
+		Entry{Point{8, 1}, "e"},
+		Entry{Point{7, 2}, "f"},
+	)
+	if entry, found := tree.Nearest(Point{9, 2}); entry.Value != "e" || !found {
+		t.Errorf("Got %v expected %v", entry.Value, "e")
+	}
+	nearest := tree.KNearest(Point{5, 6}, 3)
+	if actualValue, expectedValue := fmt.Sprintf("%v %v %v", nearest[0].Value, nearest[1].Value, nearest[2].Value), "d b c"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue := len(tree.KNearest(Point{5, 5}, 10)); actualValue != 6 {
+		t.Errorf("Got %v expected %v", actualValue, 6)
+	}
+	if actualValue := len(tree.KNearest(Point{5, 5}, 0)); actualValue != 0 {
+		t.Errorf("Got %v expected %v", actualValue, 0)
+	}
+
+	r := rand.New(rand.NewSource(1))
+	for _, distance := range []DistanceFunc{EuclideanDistance, ManhattanDistance} {
+		entries := randomEntries(r, 500, 3)
+		tree := NewWith(3, distance)
+		tree.Build(entries...)
+		for i := 0; i < 50; i++ {
+			query := randomEntries(r, 1, 3)[0].Point
+			expected := bruteForce(entries, query, distance)
+			for j, entry := range tree.KNearest(query, 10) {
+				if actualValue := distance(query, entry.Point); actualValue != expected[j] {
+					t.Errorf("Got %v expected %v for neighbour %v", actualValue, expected[j], j)
+				}
+			}
+		}
+	}
+}
+
+func TestKDTreeRadius(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	entries := randomEntries(r, 500, 2)
+	tree := New(2)
+	for _, entry := range entries {
+		tree.Insert(entry.Point, entry.Value)
+	}
+	for i := 0; i < 50; i++ {
+		query := randomEntries(r, 1, 2)[0].Point
+		radius := float64(r.Intn(10))
+		expected := 0
+		for _, distance := range bruteForce(entries, query, EuclideanDistance) {
+			if distance <= radius {
+				expected++
+			}
+		}
+		found := tree.Radius(query, radius)
+		if actualValue := len(found); actualValue != expected {
+			t.Errorf("Got %v expected %v", actualValue, expected)
+		}
+		for j := 1; j < len(found); j++ {
+			if EuclideanDistance(query, found[j-1].Point) > EuclideanDistance(query, found[j].Point) {
+				t.Errorf("Entries are not sorted by distance")
+			}
+		}
+	}
+}
+
+func TestKDTreeRange(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	entries := randomEntries(r, 500, 3)
+	tree := New(3)
+	tree.Build(entries...)
+	for i := 0; i < 50; i++ {
+		a, b := randomEntries(r, 1, 3)[0].Point, randomEntries(r, 1, 3)[0].Point
+		min, max := make(Point, 3), make(Point, 3)
+		for j := range a {
+			min[j], max[j] = a[j], b[j]
+			if min[j] > max[j] {
+				min[j], max[j] = max[j], min[j]
+			}
+		}
+		expected := 0
+		for _, entry := range entries {
+			inside := true
+			for j := range entry.Point {
+				inside = inside && entry.Point[j] >= min[j] && entry.Point[j] <= max[j]
+			}
+			if inside {
+				expected++
+			}
+		}
+		if actualValue := len(tree.Range(min, max)); actualValue != expected {
+			t.Errorf("Got %v expected %v", actualValue, expected)
+		}
+	}
+}
+
+func TestKDTreeDimensionMismatch(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Expected panic for a point of the wrong dimensionality")
+		}
+	}()
+	New(2).Insert(Point{1, 2, 3}, nil)
+}
+
+func TestKDTreeClear(t *testing.T) {
+	tree := New(1)
+	tree.Insert(Point{1}, 1)
+	tree.Clear()
+	if actualValue := tree.Empty(); actualValue != true {
+		t.Errorf("Got %v expected %v", actualValue, true)
+	}
+	if actualValue, expectedValue := tree.String(), "KDTree\n"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+}
+
+func TestKDTreeStats(t *testing.T) {
+	tree := New(1)
+	for i := 1; i <= 4; i++ {
+		tree.Insert(Point{float64(i)}, i)
+	}
+	if actualValue, expectedValue := fmt.Sprintf("%+v", tree.Stats()), "{Height:4 Nodes:4 AverageDepth:1.5 Rotations:0 Splits:0 Merges:0 Comparisons:0}"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	entries := []Entry{}
+	for i := 1; i <= 7; i++ {
+		entries = append(entries, Entry{Point: Point{float64(i)}, Value: i})
+	}
+	tree.Build(entries...)
+	if actualValue, expectedValue := fmt.Sprintf("%+v", tree.Stats()), "{Height:3 Nodes:7 AverageDepth:1.4285714285714286 Rotations:0 Splits:0 Merges:0 Comparisons:0}"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	tree.Clear()
+	if actualValue, expectedValue := fmt.Sprintf("%+v", tree.Stats()), "{Height:0 Nodes:0 AverageDepth:0 Rotations:0 Splits:0 Merges:0 Comparisons:0}"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+}
+
+func benchmarkEntries(size int) []Entry {
+	r := rand.New(rand.NewSource(1))
+	entries := make([]Entry, size)
+	for i := range entries {
+		entries[i] = Entry{Point: Point{r.Float64(), r.Float64()}}
+	}
+	return entries
+}
+
+func BenchmarkKDTreeKNearest100000(b *testing.B) {
+	b.StopTimer()
+	entries := benchmarkEntries(100000)
+	tree := New(2)
+	tree.Build(entries...)
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		for n := 0; n < 1000; n++ {
+			tree.KNearest(entries[n].Point, 10)
+		}
+	}
+}
+
+func BenchmarkBruteForceKNearest100000(b *testing.B) {
+	b.StopTimer()
+	entries := benchmarkEntries(100000)
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		for n := 0; n < 10; n++ {
+			bruteForce(entries, entries[n].Point, EuclideanDistance)
+		}
+	}
+}
+
+func BenchmarkKDTreeInsert100000(b *testing.B) {
+	b.StopTimer()
+	entries := benchmarkEntries(100000)
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		tree := New(2)
+		for _, entry := range entries {
+			tree.Insert(entry.Point, entry.Value)
+		}
+	}
+}
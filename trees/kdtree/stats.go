@@ -0,0 +1,32 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdtree
+
+import "github.com/dairongpeng/gds/trees"
+
+// Stats returns the shape of the tree.
+// The tree is not rebalanced and compares coordinates directly instead of calling a comparator,
+// so the counters stay zero. The shape is measured by walking the tree in O(n).
+func (tree *Tree) Stats() trees.Stats {
+	s := trees.Stats{Nodes: tree.size}
+	depths := 0
+	var walk func(node *Node, depth int)
+	walk = func(node *Node, depth int) {
+		if node == nil {
+			return
+		}
+		depths += depth
+		if depth+1 > s.Height {
+			s.Height = depth + 1
+		}
+		walk(node.Left, depth+1)
+		walk(node.Right, depth+1)
+	}
+	walk(tree.Root, 0)
+	if tree.size > 0 {
+		s.AverageDepth = float64(depths) / float64(tree.size)
+	}
+	return s
+}
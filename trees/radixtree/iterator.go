@@ -0,0 +1,159 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package radixtree
+
+import "github.com/dairongpeng/gds/containers"
+
+func assertIteratorImplementation() {
+	var _ containers.ReverseIteratorWithKey = (*Iterator)(nil)
+	var _ containers.IteratorWithRemove = (*Iterator)(nil)
+}
+
+// Iterator holding the iterator's state.
+// Nodes have no parent pointers, so every step searches the successor (or predecessor) of the current key,
+// which takes time proportional to the length of the key.
+type Iterator struct {
+	tree     *Tree
+	leaf     *leaf
+	position position
+	modCount int
+	removed  bool // the current leaf was removed, its key still finds its neighbours
+}
+
+type position byte
+
+const (
+	begin, between, end position = 0, 1, 2
+)
+
+// Iterator returns a stateful iterator whose elements are key/value pairs in key order.
+func (tree *Tree) Iterator() *Iterator {
+	return &Iterator{tree: tree, leaf: nil, position: begin, modCount: tree.modCount}
+}
+
+// Next moves the iterator to the next element and returns true if there was a next element in the container.
+// If Next() returns true, then next element's key and value can be retrieved by Key() and Value().
+// If Next() was called for the first time, then it will point the iterator to the first element if it exists.
+// Modifies the state of the iterator.
+func (iterator *Iterator) Next() bool {
+	iterator.checkModification()
+	iterator.removed = false
+	switch iterator.position {
+	case begin:
+		iterator.position = between
+		iterator.leaf = nil
+		if iterator.tree.root != nil {
+			iterator.leaf = iterator.tree.root.minimum()
+		}
+	case between:
+		iterator.leaf = ceiling(iterator.tree.root, iterator.leaf.key, 0, false)
+	}
+
+	if iterator.leaf == nil {
+		iterator.position = end
+		return false
+	}
+	return true
+}
+
+// Prev moves the iterator to the previous element and returns true if there was a previous element in the container.
+// If Prev() returns true, then previous element's key and value can be retrieved by Key() and Value().
+// Modifies the state of the iterator.
+func (iterator *Iterator) Prev() bool {
+	iterator.checkModification()
+	iterator.removed = false
+	switch iterator.position {
+	case end:
+		iterator.position = between
+		iterator.leaf = nil
+		if iterator.tree.root != nil {
+			iterator.leaf = iterator.tree.root.maximum()
+		}
+	case between:
+		iterator.leaf = floor(iterator.tree.root, iterator.leaf.key, 0, false)
+	}
+
+	if iterator.leaf == nil {
+		iterator.position = begin
+		return false
+	}
+	return true
+}
+
+// Value returns the current element's value.
+// Does not modify the state of the iterator.
+func (iterator *Iterator) Value() interface{} {
+	if iterator.leaf == nil {
+		return nil
+	}
+	return iterator.leaf.value
+}
+
+// Key returns the current element's key as a string.
+// Does not modify the state of the iterator.
+func (iterator *Iterator) Key() interface{} {
+	if iterator.leaf == nil {
+		return nil
+	}
+	return iterator.leaf.key
+}
+
+// Begin resets the iterator to its initial state (one-before-first)
+// Call Next() to fetch the first element if any.
+func (iterator *Iterator) Begin() {
+	iterator.leaf = nil
+	iterator.position = begin
+	iterator.removed = false
+	iterator.modCount = iterator.tree.modCount
+}
+
+// End moves the iterator past the last element (one-past-the-end).
+// Call Prev() to fetch the last element if any.
+func (iterator *Iterator) End() {
+	iterator.leaf = nil
+	iterator.position = end
+	iterator.removed = false
+	iterator.modCount = iterator.tree.modCount
+}
+
+// First moves the iterator to the first element and returns true if there was a first element in the container.
+// If First() returns true, then first element's key and value can be retrieved by Key() and Value().
+// Modifies the state of the iterator
+func (iterator *Iterator) First() bool {
+	iterator.Begin()
+	return iterator.Next()
+}
+
+// Last moves the iterator to the last element and returns true if there was a last element in the container.
+// If Last() returns true, then last element's key and value can be retrieved by Key() and Value().
+// Modifies the state of the iterator.
+func (iterator *Iterator) Last() bool {
+	iterator.End()
+	return iterator.Prev()
+}
+
+// Remove removes the current element from the tree.
+// Next() moves the iterator to the element after the removed one and Prev() to the element before it,
+// until then Key() and Value() return the removed element.
+// Panics with containers.ErrIteratorNotOnElement if the iterator is not on an element.
+func (iterator *Iterator) Remove() {
+	iterator.checkModification()
+	if iterator.removed || iterator.position != between {
+		panic(containers.ErrIteratorNotOnElement)
+	}
+	iterator.tree.Remove(iterator.leaf.key)
+	iterator.modCount = iterator.tree.modCount
+	iterator.removed = true
+}
+
+// checkModification panics if the tree was structurally modified other than through the iterator.
+func (iterator *Iterator) checkModification() {
+	if iterator.modCount != iterator.tree.modCount {
+		if iterator.position != begin {
+			panic(containers.ErrConcurrentModification)
+		}
+		iterator.modCount = iterator.tree.modCount
+	}
+}
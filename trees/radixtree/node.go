@@ -0,0 +1,276 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package radixtree
+
+import "sort"
+
+// kind is the size class of a node's children, which grows and shrinks with the number of children.
+type kind byte
+
+const (
+	node4   kind = iota // up to 4 children, sorted labels
+	node16              // up to 16 children, sorted labels
+	node48              // up to 48 children, indexed through a 256 byte table
+	node256             // up to 256 children, indexed directly by label
+)
+
+// Thresholds at which a node shrinks to the next smaller kind, leaving some slack to avoid growing right back.
+const (
+	shrink16  = 3
+	shrink48  = 12
+	shrink256 = 37
+)
+
+// node is an inner node of the tree, whose path from the parent is compressed into the prefix.
+// A key ending at the node is stored in its leaf, so a node without children is a plain leaf.
+type node struct {
+	prefix   string      // bytes following the label of the node in its parent
+	leaf     *leaf       // key ending at this node, or nil
+	kind     kind        // size class of the children
+	count    int         // number of children
+	keys     []byte      // labels of the children in node4 and node16, sorted
+	children []*node     // children parallel to keys in node4 and node16, slots in node48, by label in node256
+	index    *[256]uint8 // slot+1 of the child with each label in node48, 0 if there is none
+}
+
+type leaf struct {
+	key   string
+	value interface{}
+}
+
+// findChild returns a reference to the child slot with the label, or nil if there is no such child.
+func (n *node) findChild(label byte) **node {
+	switch n.kind {
+	case node4, node16:
+		if i := n.search(label); i < n.count && n.keys[i] == label {
+			return &n.children[i]
+		}
+	case node48:
+		if slot := n.index[label]; slot > 0 {
+			return &n.children[slot-1]
+		}
+	case node256:
+		if n.children[label] != nil {
+			return &n.children[label]
+		}
+	}
+	return nil
+}
+
+// nextChild returns the child with the smallest label larger than or equal to from, or nil if there is none.
+func (n *node) nextChild(from int) (label byte, child *node) {
+	switch n.kind {
+	case node4, node16:
+		if from > 255 {
+			return 0, nil
+		}
+		if i := n.search(byte(from)); i < n.count {
+			return n.keys[i], n.children[i]
+		}
+	case node48:
+		for b := from; b < 256; b++ {
+			if slot := n.index[b]; slot > 0 {
+				return byte(b), n.children[slot-1]
+			}
+		}
+	case node256:
+		for b := from; b < 256; b++ {
+			if n.children[b] != nil {
+				return byte(b), n.children[b]
+			}
+		}
+	}
+	return 0, nil
+}
+
+// prevChild returns the child with the largest label smaller than or equal to to, or nil if there is none.
+func (n *node) prevChild(to int) (label byte, child *node) {
+	switch n.kind {
+	case node4, node16:
+		if to < 0 {
+			return 0, nil
+		}
+		i := n.search(byte(to))
+		if i < n.count && n.keys[i] == byte(to) {
+			return n.keys[i], n.children[i]
+		}
+		if i > 0 {
+			return n.keys[i-1], n.children[i-1]
+		}
+	case node48:
+		for b := to; b >= 0; b-- {
+			if slot := n.index[b]; slot > 0 {
+				return byte(b), n.children[slot-1]
+			}
+		}
+	case node256:
+		for b := to; b >= 0; b-- {
+			if n.children[b] != nil {
+				return byte(b), n.children[b]
+			}
+		}
+	}
+	return 0, nil
+}
+
+// addChild adds the child with the label, which must not be in the node yet, growing the node if it is full.
+func (n *node) addChild(label byte, child *node) {
+	switch n.kind {
+	case node4, node16:
+		if (n.kind == node4 && n.count == 4) || (n.kind == node16 && n.count == 16) {
+			n.grow()
+			n.addChild(label, child)
+			return
+		}
+		if n.keys == nil {
+			n.keys = make([]byte, 0, 4)
+			n.children = make([]*node, 0, 4)
+		}
+		i := n.search(label)
+		n.keys = append(n.keys, 0)
+		n.children = append(n.children, nil)
+		copy(n.keys[i+1:], n.keys[i:])
+		copy(n.children[i+1:], n.children[i:])
+		n.keys[i] = label
+		n.children[i] = child
+	case node48:
+		if n.count == 48 {
+			n.grow()
+			n.addChild(label, child)
+			return
+		}
+		slot := 0
+		for n.children[slot] != nil {
+			slot++
+		}
+		n.children[slot] = child
+		n.index[label] = uint8(slot + 1)
+	case node256:
+		n.children[label] = child
+	}
+	n.count++
+}
+
+// removeChild removes the child with the label, which must be in the node, shrinking the node if it gets sparse.
+func (n *node) removeChild(label byte) {
+	switch n.kind {
+	case node4, node16:
+		i := n.search(label)
+		copy(n.keys[i:], n.keys[i+1:])
+		copy(n.children[i:], n.children[i+1:])
+		n.children[len(n.children)-1] = nil
+		n.keys = n.keys[:len(n.keys)-1]
+		n.children = n.children[:len(n.children)-1]
+		n.count--
+		if n.kind == node16 && n.count <= shrink16 {
+			n.shrink()
+		}
+	case node48:
+		n.children[n.index[label]-1] = nil
+		n.index[label] = 0
+		n.count--
+		if n.count <= shrink48 {
+			n.shrink()
+		}
+	case node256:
+		n.children[label] = nil
+		n.count--
+		if n.count <= shrink256 {
+			n.shrink()
+		}
+	}
+}
+
+// grow moves the children into the next larger kind.
+func (n *node) grow() {
+	switch n.kind {
+	case node4:
+		keys := make([]byte, n.count, 16)
+		children := make([]*node, n.count, 16)
+		copy(keys, n.keys)
+		copy(children, n.children)
+		n.keys, n.children, n.kind = keys, children, node16
+	case node16:
+		index := new([256]uint8)
+		children := make([]*node, 48)
+		for i := 0; i < n.count; i++ {
+			children[i] = n.children[i]
+			index[n.keys[i]] = uint8(i + 1)
+		}
+		n.keys, n.children, n.index, n.kind = nil, children, index, node48
+	case node48:
+		children := make([]*node, 256)
+		for b, slot := range n.index {
+			if slot > 0 {
+				children[b] = n.children[slot-1]
+			}
+		}
+		n.children, n.index, n.kind = children, nil, node256
+	}
+}
+
+// shrink moves the children into the next smaller kind.
+func (n *node) shrink() {
+	switch n.kind {
+	case node16:
+		keys := make([]byte, n.count, 4)
+		children := make([]*node, n.count, 4)
+		copy(keys, n.keys)
+		copy(children, n.children)
+		n.keys, n.children, n.kind = keys, children, node4
+	case node48:
+		keys := make([]byte, 0, 16)
+		children := make([]*node, 0, 16)
+		for b, slot := range n.index {
+			if slot > 0 {
+				keys = append(keys, byte(b))
+				children = append(children, n.children[slot-1])
+			}
+		}
+		n.keys, n.children, n.index, n.kind = keys, children, nil, node16
+	case node256:
+		index := new([256]uint8)
+		children := make([]*node, 48)
+		slot := 0
+		for b, child := range n.children {
+			if child != nil {
+				children[slot] = child
+				index[b] = uint8(slot + 1)
+				slot++
+			}
+		}
+		n.children, n.index, n.kind = children, index, node48
+	}
+}
+
+// search returns the position of the label among the sorted labels of a node4 or node16.
+func (n *node) search(label byte) int {
+	if n.kind == node4 {
+		i := 0
+		for i < n.count && n.keys[i] < label {
+			i++
+		}
+		return i
+	}
+	return sort.Search(n.count, func(i int) bool {
+		return n.keys[i] >= label
+	})
+}
+
+// minimum returns the leaf with the smallest key in the subtree.
+func (n *node) minimum() *leaf {
+	for n.leaf == nil {
+		_, n = n.nextChild(0)
+	}
+	return n.leaf
+}
+
+// maximum returns the leaf with the largest key in the subtree.
+func (n *node) maximum() *leaf {
+	for n.count > 0 {
+		_, n = n.prevChild(255)
+	}
+	return n.leaf
+}
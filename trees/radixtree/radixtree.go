@@ -0,0 +1,416 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package radixtree implements an adaptive radix tree that maps string or []byte keys to values.
+//
+// Like a trie, every node branches on one byte of the keys, but chains of nodes with a single child are compressed
+// into a prefix of the node below them. Nodes adapt the storage of their children to how many they have, using
+// sorted arrays of 4 or 16 labels, a 256 byte index into 48 slots or a full table of 256 children, and shrink again
+// when children are removed. This keeps the tree compact for long shared prefixes as well as for dense key sets.
+//
+// Keys are ordered byte-wise, as strings are compared in Go, and returned as strings.
+//
+// Structure is not thread safe.
+//
+// References: https://db.in.tum.de/~leis/papers/ART.pdf
+package radixtree
+
+import (
+	"fmt"
+	"github.com/dairongpeng/gds/maps"
+	"github.com/dairongpeng/gds/trees"
+	"strings"
+)
+
+func assertTreeImplementation() {
+	var _ trees.Tree = (*Tree)(nil)
+	var _ maps.Map = (*Tree)(nil)
+}
+
+// Tree holds elements of the radix tree
+// Tree 自适应基数树，压缩单分支路径，并按照子节点数量调整节点大小
+type Tree struct {
+	root     *node
+	size     int
+	modCount int   // number of inserted and removed keys, checked by iterators
+	stats    stats // Counts the work done by the tree
+}
+
+// New instantiates an empty radix tree.
+func New() *Tree {
+	return &Tree{}
+}
+
+// Put inserts the key-value pair into the tree.
+// Key should be a string or a []byte, otherwise method panics.
+func (tree *Tree) Put(key interface{}, value interface{}) {
+	if tree.insert(&tree.root, toString(key), 0, value) {
+		tree.size++
+		tree.modCount++
+	}
+}
+
+// Get searches the element in the tree by key and returns its value or nil if key is not found in tree.
+// Second return parameter is true if key was found, otherwise false.
+// Key should be a string or a []byte, otherwise method panics.
+func (tree *Tree) Get(key interface{}) (value interface{}, found bool) {
+	k := toString(key)
+	current, depth := tree.root, 0
+	for current != nil {
+		if !strings.HasPrefix(k[depth:], current.prefix) {
+			return nil, false
+		}
+		depth += len(current.prefix)
+		if depth == len(k) {
+			if current.leaf != nil {
+				return current.leaf.value, true
+			}
+			return nil, false
+		}
+		child := current.findChild(k[depth])
+		if child == nil {
+			return nil, false
+		}
+		current = *child
+		depth++
+	}
+	return nil, false
+}
+
+// Remove removes the element from the tree by key, merging and shrinking the nodes left behind.
+// Key should be a string or a []byte, otherwise method panics.
+func (tree *Tree) Remove(key interface{}) {
+	if tree.delete(&tree.root, toString(key), 0) {
+		tree.size--
+		tree.modCount++
+	}
+}
+
+// WalkPrefix calls the function with all key-value pairs whose key starts with the prefix in key order,
+// until the function returns false.
+// Prefix should be a string or a []byte, otherwise method panics.
+// WalkPrefix 按照key的顺序遍历所有以prefix开头的元素
+func (tree *Tree) WalkPrefix(prefix interface{}, f func(key string, value interface{}) bool) {
+	p := toString(prefix)
+	current, depth := tree.root, 0
+	for current != nil {
+		rest := p[depth:]
+		if len(rest) <= len(current.prefix) {
+			// the prefix ends within this node, so either all keys below it match or none does
+			if strings.HasPrefix(current.prefix, rest) {
+				walk(current, f)
+			}
+			return
+		}
+		if !strings.HasPrefix(rest, current.prefix) {
+			return
+		}
+		depth += len(current.prefix)
+		child := current.findChild(p[depth])
+		if child == nil {
+			return
+		}
+		current = *child
+		depth++
+	}
+}
+
+// Empty returns true if tree does not contain any keys
+func (tree *Tree) Empty() bool {
+	return tree.size == 0
+}
+
+// Size returns number of keys in the tree.
+func (tree *Tree) Size() int {
+	return tree.size
+}
+
+// Keys returns all keys in order.
+func (tree *Tree) Keys() []interface{} {
+	keys := make([]interface{}, 0, tree.size)
+	if tree.root != nil {
+		walk(tree.root, func(key string, value interface{}) bool {
+			keys = append(keys, key)
+			return true
+		})
+	}
+	return keys
+}
+
+// Values returns all values in order of their keys.
+func (tree *Tree) Values() []interface{} {
+	values := make([]interface{}, 0, tree.size)
+	if tree.root != nil {
+		walk(tree.root, func(key string, value interface{}) bool {
+			values = append(values, value)
+			return true
+		})
+	}
+	return values
+}
+
+// Clear removes all keys from the tree.
+func (tree *Tree) Clear() {
+	tree.root = nil
+	tree.size = 0
+	tree.modCount++
+}
+
+// Min returns the minimum key and its value from the tree.
+// Returns nil, nil if tree is empty.
+func (tree *Tree) Min() (key interface{}, value interface{}) {
+	if tree.root == nil {
+		return nil, nil
+	}
+	l := tree.root.minimum()
+	return l.key, l.value
+}
+
+// Max returns the maximum key and its value from the tree.
+// Returns nil, nil if tree is empty.
+func (tree *Tree) Max() (key interface{}, value interface{}) {
+	if tree.root == nil {
+		return nil, nil
+	}
+	l := tree.root.maximum()
+	return l.key, l.value
+}
+
+// Floor finds the floor key-value pair for the input key.
+// In case that no floor is found, then both returned values will be nil.
+// It's generally enough to check the first value (key) for nil, which determines if floor was found.
+//
+// Floor key is defined as the largest key that is smaller than or equal to the given key.
+// A floor key may not be found, either because the tree is empty, or because
+// all keys in the tree are larger than the given key.
+//
+// Key should be a string or a []byte, otherwise method panics.
+func (tree *Tree) Floor(key interface{}) (foundKey interface{}, foundValue interface{}) {
+	if l := floor(tree.root, toString(key), 0, true); l != nil {
+		return l.key, l.value
+	}
+	return nil, nil
+}
+
+// Ceiling finds the ceiling key-value pair for the input key.
+// In case that no ceiling is found, then both returned values will be nil.
+// It's generally enough to check the first value (key) for nil, which determines if ceiling was found.
+//
+// Ceiling key is defined as the smallest key that is larger than or equal to the given key.
+// A ceiling key may not be found, either because the tree is empty, or because
+// all keys in the tree are smaller than the given key.
+//
+// Key should be a string or a []byte, otherwise method panics.
+func (tree *Tree) Ceiling(key interface{}) (foundKey interface{}, foundValue interface{}) {
+	if l := ceiling(tree.root, toString(key), 0, true); l != nil {
+		return l.key, l.value
+	}
+	return nil, nil
+}
+
+// String returns a string representation of container
+func (tree *Tree) String() string {
+	str := "RadixTree\nmap["
+	if tree.root != nil {
+		walk(tree.root, func(key string, value interface{}) bool {
+			str += fmt.Sprintf("%v:%v ", key, value)
+			return true
+		})
+	}
+	return strings.TrimRight(str, " ") + "]"
+}
+
+// insert adds the key below the referenced node, whose prefix starts at depth of the key.
+// Returns true if the key was not in the tree yet.
+func (tree *Tree) insert(ref **node, key string, depth int, value interface{}) bool {
+	current := *ref
+	if current == nil {
+		*ref = &node{prefix: key[depth:], leaf: &leaf{key: key, value: value}}
+		return true
+	}
+	common := commonPrefix(current.prefix, key[depth:])
+	if common < len(current.prefix) {
+		// the key leaves the compressed path, split it at the first differing byte
+		tree.stats.splits++
+		parent := &node{prefix: current.prefix[:common]}
+		parent.addChild(current.prefix[common], current)
+		current.prefix = current.prefix[common+1:]
+		if depth+common == len(key) {
+			parent.leaf = &leaf{key: key, value: value}
+		} else {
+			parent.addChild(key[depth+common], &node{prefix: key[depth+common+1:], leaf: &leaf{key: key, value: value}})
+		}
+		*ref = parent
+		return true
+	}
+	depth += common
+	if depth == len(key) {
+		if current.leaf != nil {
+			current.leaf.value = value
+			return false
+		}
+		current.leaf = &leaf{key: key, value: value}
+		return true
+	}
+	if child := current.findChild(key[depth]); child != nil {
+		return tree.insert(child, key, depth+1, value)
+	}
+	current.addChild(key[depth], &node{prefix: key[depth+1:], leaf: &leaf{key: key, value: value}})
+	return true
+}
+
+// delete removes the key below the referenced node, whose prefix starts at depth of the key.
+// Returns true if the key was found.
+func (tree *Tree) delete(ref **node, key string, depth int) bool {
+	current := *ref
+	if current == nil || !strings.HasPrefix(key[depth:], current.prefix) {
+		return false
+	}
+	depth += len(current.prefix)
+	if depth == len(key) {
+		if current.leaf == nil {
+			return false
+		}
+		current.leaf = nil
+	} else {
+		child := current.findChild(key[depth])
+		if child == nil || !tree.delete(child, key, depth+1) {
+			return false
+		}
+		if *child == nil {
+			current.removeChild(key[depth])
+		}
+	}
+	tree.compact(ref)
+	return true
+}
+
+// compact removes the referenced node if it is empty, or merges it into its only child if it holds no key.
+func (tree *Tree) compact(ref **node) {
+	current := *ref
+	if current.leaf != nil || current.count > 1 {
+		return
+	}
+	if current.count == 0 {
+		*ref = nil
+		return
+	}
+	tree.stats.merges++
+	label, child := current.nextChild(0)
+	child.prefix = current.prefix + string([]byte{label}) + child.prefix
+	*ref = child
+}
+
+// ceiling returns the leaf with the smallest key larger than (or equal to, if inclusive) the key in the subtree
+// of the node, whose prefix starts at depth of the key.
+func ceiling(current *node, key string, depth int, inclusive bool) *leaf {
+	if current == nil {
+		return nil
+	}
+	rest := key[depth:]
+	if len(rest) < len(current.prefix) {
+		if current.prefix[:len(rest)] < rest {
+			return nil
+		}
+		// the key is smaller than every key of the subtree
+		return current.minimum()
+	}
+	if compare := strings.Compare(current.prefix, rest[:len(current.prefix)]); compare != 0 {
+		if compare < 0 {
+			return nil
+		}
+		return current.minimum()
+	}
+	depth += len(current.prefix)
+	if depth == len(key) {
+		if inclusive && current.leaf != nil {
+			return current.leaf
+		}
+		if _, child := current.nextChild(0); child != nil {
+			return child.minimum()
+		}
+		return nil
+	}
+	label := key[depth]
+	if child := current.findChild(label); child != nil {
+		if l := ceiling(*child, key, depth+1, inclusive); l != nil {
+			return l
+		}
+	}
+	if _, child := current.nextChild(int(label) + 1); child != nil {
+		return child.minimum()
+	}
+	return nil
+}
+
+// floor returns the leaf with the largest key smaller than (or equal to, if inclusive) the key in the subtree
+// of the node, whose prefix starts at depth of the key.
+func floor(current *node, key string, depth int, inclusive bool) *leaf {
+	if current == nil {
+		return nil
+	}
+	rest := key[depth:]
+	if len(rest) < len(current.prefix) {
+		if current.prefix[:len(rest)] > rest {
+			return nil
+		}
+		if current.prefix[:len(rest)] < rest {
+			return current.maximum()
+		}
+		// the key is a proper prefix of every key of the subtree, so they are all larger
+		return nil
+	}
+	if compare := strings.Compare(current.prefix, rest[:len(current.prefix)]); compare != 0 {
+		if compare > 0 {
+			return nil
+		}
+		return current.maximum()
+	}
+	depth += len(current.prefix)
+	if depth == len(key) {
+		if inclusive {
+			return current.leaf
+		}
+		return nil
+	}
+	label := key[depth]
+	if child := current.findChild(label); child != nil {
+		if l := floor(*child, key, depth+1, inclusive); l != nil {
+			return l
+		}
+	}
+	if _, child := current.prevChild(int(label) - 1); child != nil {
+		return child.maximum()
+	}
+	// the key of this node is a proper prefix of the key
+	return current.leaf
+}
+
+// walk calls the function with all key-value pairs of the subtree in key order until the function returns false.
+func walk(current *node, f func(key string, value interface{}) bool) bool {
+	if current.leaf != nil && !f(current.leaf.key, current.leaf.value) {
+		return false
+	}
+	for label, child := current.nextChild(0); child != nil; label, child = current.nextChild(int(label) + 1) {
+		if !walk(child, f) {
+			return false
+		}
+	}
+	return true
+}
+
+func commonPrefix(a string, b string) int {
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+	return i
+}
+
+func toString(key interface{}) string {
+	if bytes, ok := key.([]byte); ok {
+		return string(bytes)
+	}
+	return key.(string)
+}
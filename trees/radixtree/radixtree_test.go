@@ -0,0 +1,600 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package radixtree
+
+import (
+	"fmt"
+	"github.com/dairongpeng/gds/containers"
+	"github.com/dairongpeng/gds/maps/treemap"
+	"math/rand"
+	"testing"
+)
+
+// assertStructure checks that no node could have been merged or shrunk and that the counts match the children.
+func assertStructure(t *testing.T, tree *Tree) {
+	var check func(current *node) int
+	check = func(current *node) int {
+		children, keys := 0, 0
+		if current.leaf != nil {
+			keys++
+		}
+		for label, child := current.nextChild(0); child != nil; label, child = current.nextChild(int(label) + 1) {
+			children++
+			keys += check(child)
+		}
+		if children != current.count {
+			t.Errorf("Got %v children expected %v", children, current.count)
+		}
+		if current.leaf == nil && children < 2 {
+			t.Errorf("Got a node without key and %v children", children)
+		}
+		if capacity := map[kind]int{node4: 4, node16: 16, node48: 48, node256: 256}[current.kind]; current.count > capacity {
+			t.Errorf("Got %v children in a node of kind %v", current.count, current.kind)
+		}
+		return keys
+	}
+	if tree.root == nil {
+		if tree.size != 0 {
+			t.Errorf("Got %v expected %v", tree.size, 0)
+		}
+		return
+	}
+	if actualValue := check(tree.root); actualValue != tree.size {
+		t.Errorf("Got %v expected %v", actualValue, tree.size)
+	}
+}
+
+func TestRadixTreePutGet(t *testing.T) {
+	tree := New()
+	tree.Put("romane", 1)
+	tree.Put("romanus", 2)
+	tree.Put("romulus", 3)
+	tree.Put("rubens", 4)
+	tree.Put("ruber", 5)
+	tree.Put("rubicon", 6)
+	tree.Put("rubicundus", 7)
+	tree.Put([]byte("rom"), 8)
+	tree.Put("romane", 9) // overwrite
+	assertStructure(t, tree)
+
+	if actualValue := tree.Size(); actualValue != 8 {
+		t.Errorf("Got %v expected %v", actualValue, 8)
+	}
+	if actualValue, expectedValue := fmt.Sprintf("%v", tree.Keys()), "[rom romane romanus romulus rubens ruber rubicon rubicundus]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue, expectedValue := fmt.Sprintf("%v", tree.Values()), "[8 9 2 3 4 5 6 7]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+
+	tests := [][]interface{}{
+		{"romane", 9, true},
+		{"rom", 8, true},
+		{[]byte("rubicon"), 6, true},
+		{"ro", nil, false},
+		{"roman", nil, false},
+		{"romanes", nil, false},
+		{"x", nil, false},
+		{"", nil, false},
+	}
+	for _, test := range tests {
+		actualValue, actualFound := tree.Get(test[0])
+		if actualValue != test[1] || actualFound != test[2] {
+			t.Errorf("Got %v,%v expected %v,%v for %s", actualValue, actualFound, test[1], test[2], test[0])
+		}
+	}
+
+	tree.Put("", 0)
+	if actualValue, found := tree.Get(""); actualValue != 0 || !found {
+		t.Errorf("Got %v expected %v", actualValue, 0)
+	}
+	if key, _ := tree.Min(); key != "" {
+		t.Errorf("Got %q expected %q", key, "")
+	}
+	if key, _ := tree.Max(); key != "rubicundus" {
+		t.Errorf("Got %v expected %v", key, "rubicundus")
+	}
+}
+
+func TestRadixTreeRemove(t *testing.T) {
+	tree := New()
+	for i, key := range []string{"romane", "romanus", "romulus", "rubens", "ruber", "rubicon", "rubicundus"} {
+		tree.Put(key, i)
+	}
+
+	tree.Remove("romanus")
+	tree.Remove("roman") // not a key
+	tree.Remove("rubicundusx")
+	tree.Remove("ruber")
+	assertStructure(t, tree)
+
+	if actualValue, expectedValue := fmt.Sprintf("%v", tree.Keys()), "[romane romulus rubens rubicon rubicundus]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue := tree.Size(); actualValue != 5 {
+		t.Errorf("Got %v expected %v", actualValue, 5)
+	}
+	if actualValue, found := tree.Get("romane"); actualValue != 0 || !found {
+		t.Errorf("Got %v expected %v", actualValue, 0)
+	}
+
+	for _, key := range []string{"romane", "romulus", "rubens", "rubicon", "rubicundus"} {
+		tree.Remove(key)
+		assertStructure(t, tree)
+	}
+	if actualValue := tree.Empty(); actualValue != true {
+		t.Errorf("Got %v expected %v", actualValue, true)
+	}
+	if tree.root != nil {
+		t.Errorf("Got %v expected %v", tree.root, nil)
+	}
+}
+
+func TestRadixTreeGrowAndShrink(t *testing.T) {
+	tree := New()
+	tree.Put("k", -1)
+	tests := []struct {
+		children int
+		kind     kind
+	}{
+		{4, node4},
+		{5, node16},
+		{16, node16},
+		{17, node48},
+		{48, node48},
+		{49, node256},
+		{256, node256},
+	}
+	added := 0
+	for _, test := range tests {
+		for ; added < test.children; added++ {
+			tree.Put(string([]byte{'k', byte(added)}), added)
+		}
+		assertStructure(t, tree)
+		if actualValue := tree.root.kind; actualValue != test.kind {
+			t.Errorf("Got %v expected %v for %v children", actualValue, test.kind, test.children)
+		}
+	}
+	for i := 0; i < 256; i++ {
+		if actualValue, found := tree.Get(string([]byte{'k', byte(i)})); actualValue != i || !found {
+			t.Errorf("Got %v expected %v", actualValue, i)
+		}
+	}
+
+	shrinks := []struct {
+		children int
+		kind     kind
+	}{
+		{38, node256},
+		{37, node48},
+		{13, node48},
+		{12, node16},
+		{4, node16},
+		{3, node4},
+	}
+	for _, test := range shrinks {
+		for ; added > test.children; added-- {
+			tree.Remove(string([]byte{'k', byte(added - 1)}))
+		}
+		assertStructure(t, tree)
+		if actualValue := tree.root.kind; actualValue != test.kind {
+			t.Errorf("Got %v expected %v for %v children", actualValue, test.kind, test.children)
+		}
+	}
+	if actualValue, expectedValue := fmt.Sprintf("%q", tree.Keys()), `["k" "k\x00" "k\x01" "k\x02"]`; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+}
+
+func TestRadixTreeFloorAndCeiling(t *testing.T) {
+	tree := New()
+	if key, _ := tree.Floor("a"); key != nil {
+		t.Errorf("Got %v expected %v", key, nil)
+	}
+	if key, _ := tree.Ceiling("a"); key != nil {
+		t.Errorf("Got %v expected %v", key, nil)
+	}
+
+	for _, key := range []string{"apple", "apply", "banana", "band", "bandana", "can"} {
+		tree.Put(key, key)
+	}
+	tests := [][]interface{}{
+		// key, floor, ceiling
+		{"apple", "apple", "apple"},
+		{"appl", nil, "apple"},
+		{"applz", "apply", "banana"},
+		{"b", "apply", "banana"},
+		{"banc", "banana", "band"},
+		{"banda", "band", "bandana"},
+		{"bandz", "bandana", "can"},
+		{"a", nil, "apple"},
+		{"", nil, "apple"},
+		{"cat", "can", nil},
+		{"ca", "bandana", "can"},
+	}
+	for _, test := range tests {
+		if key, _ := tree.Floor(test[0]); key != test[1] {
+			t.Errorf("Got %v expected %v for floor of %v", key, test[1], test[0])
+		}
+		if key, _ := tree.Ceiling(test[0]); key != test[2] {
+			t.Errorf("Got %v expected %v for ceiling of %v", key, test[2], test[0])
+		}
+	}
+}
+
+func TestRadixTreeWalkPrefix(t *testing.T) {
+	tree := New()
+	for _, key := range []string{"car", "cart", "carbon", "cat", "dog", "ca"} {
+		tree.Put(key, true)
+	}
+	tests := []struct {
+		prefix   string
+		expected string
+	}{
+		{"car", "[car carbon cart]"},
+		{"ca", "[ca car carbon cart cat]"},
+		{"carb", "[carbon]"},
+		{"cb", "[]"},
+		{"do", "[dog]"},
+		{"dogs", "[]"},
+		{"", "[ca car carbon cart cat dog]"},
+	}
+	for _, test := range tests {
+		keys := []string{}
+		tree.WalkPrefix(test.prefix, func(key string, value interface{}) bool {
+			keys = append(keys, key)
+			return true
+		})
+		if actualValue := fmt.Sprintf("%v", keys); actualValue != test.expected {
+			t.Errorf("Got %v expected %v for %q", actualValue, test.expected, test.prefix)
+		}
+	}
+
+	// stop early
+	keys := []string{}
+	tree.WalkPrefix("ca", func(key string, value interface{}) bool {
+		keys = append(keys, key)
+		return len(keys) < 2
+	})
+	if actualValue, expectedValue := fmt.Sprintf("%v", keys), "[ca car]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+}
+
+func TestRadixTreeRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	randomKey := func() string {
+		key := make([]byte, r.Intn(6))
+		for i := range key {
+			// a small alphabet for shared prefixes, plus the occasional byte from the whole range
+			if r.Intn(10) == 0 {
+				key[i] = byte(r.Intn(256))
+			} else {
+				key[i] = "abc"[r.Intn(3)]
+			}
+		}
+		return string(key)
+	}
+	tree := New()
+	expected := treemap.NewWithStringComparator()
+	for i := 0; i < 5000; i++ {
+		key := randomKey()
+		if r.Intn(3) == 0 {
+			tree.Remove(key)
+			expected.Remove(key)
+		} else {
+			tree.Put(key, i)
+			expected.Put(key, i)
+		}
+	}
+	assertStructure(t, tree)
+	if actualValue, expectedValue := fmt.Sprintf("%q", tree.Keys()), fmt.Sprintf("%q", expected.Keys()); actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue, expectedValue := fmt.Sprintf("%v", tree.Values()), fmt.Sprintf("%v", expected.Values()); actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	for i := 0; i < 1000; i++ {
+		key := randomKey()
+		actualKey, _ := tree.Floor(key)
+		expectedKey, _ := expected.Floor(key)
+		if actualKey != expectedKey {
+			t.Errorf("Got %q expected %q for floor of %q", actualKey, expectedKey, key)
+		}
+		actualKey, _ = tree.Ceiling(key)
+		expectedKey, _ = expected.Ceiling(key)
+		if actualKey != expectedKey {
+			t.Errorf("Got %q expected %q for ceiling of %q", actualKey, expectedKey, key)
+		}
+	}
+
+	// iteration in both directions
+	keys := expected.Keys()
+	it := tree.Iterator()
+	for i := 0; it.Next(); i++ {
+		if actualValue := it.Key(); actualValue != keys[i] {
+			t.Errorf("Got %q expected %q", actualValue, keys[i])
+		}
+	}
+	for i := len(keys) - 1; it.Prev(); i-- {
+		if actualValue := it.Key(); actualValue != keys[i] {
+			t.Errorf("Got %q expected %q", actualValue, keys[i])
+		}
+	}
+}
+
+func TestRadixTreeIteratorOnEmpty(t *testing.T) {
+	tree := New()
+	it := tree.Iterator()
+	for it.Next() {
+		t.Errorf("Shouldn't iterate on empty tree")
+	}
+	for it.Prev() {
+		t.Errorf("Shouldn't iterate on empty tree")
+	}
+}
+
+func TestRadixTreeIterator(t *testing.T) {
+	tree := New()
+	for i, key := range []string{"b", "a", "ab", "abc", "c"} {
+		tree.Put(key, i)
+	}
+	expected := []string{"a", "ab", "abc", "b", "c"}
+
+	it := tree.Iterator()
+	count := 0
+	for it.Next() {
+		if actualValue, expectedValue := it.Key(), expected[count]; actualValue != expectedValue {
+			t.Errorf("Got %v expected %v", actualValue, expectedValue)
+		}
+		count++
+	}
+	if actualValue, expectedValue := count, tree.Size(); actualValue != expectedValue {
+		t.Errorf("Size different. Got %v expected %v", actualValue, expectedValue)
+	}
+
+	for it.Prev() {
+		count--
+		if actualValue, expectedValue := it.Key(), expected[count]; actualValue != expectedValue {
+			t.Errorf("Got %v expected %v", actualValue, expectedValue)
+		}
+	}
+	if actualValue, expectedValue := count, 0; actualValue != expectedValue {
+		t.Errorf("Size different. Got %v expected %v", actualValue, expectedValue)
+	}
+
+	if actualValue := it.Last(); actualValue != true || it.Key() != "c" {
+		t.Errorf("Got %v expected %v", it.Key(), "c")
+	}
+	if actualValue := it.First(); actualValue != true || it.Key() != "a" {
+		t.Errorf("Got %v expected %v", it.Key(), "a")
+	}
+}
+
+func TestRadixTreeIteratorRemove(t *testing.T) {
+	tree := New()
+	for _, i := range rand.Perm(100) {
+		tree.Put(fmt.Sprintf("%02d", i), i)
+	}
+	it := tree.Iterator()
+	for it.Next() {
+		if it.Value().(int)%2 == 0 {
+			it.Remove()
+		}
+	}
+	for it.Prev() {
+		if it.Value().(int)%3 == 0 {
+			it.Remove()
+		}
+	}
+	keys := []interface{}{}
+	for i := 0; i < 100; i++ {
+		if i%2 != 0 && i%3 != 0 {
+			keys = append(keys, fmt.Sprintf("%02d", i))
+		}
+	}
+	if actualValue, expectedValue := fmt.Sprint(tree.Keys()), fmt.Sprint(keys); actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+
+	it.First()
+	it.Remove()
+	if it.Prev() {
+		t.Errorf("Shouldn't iterate before the beginning")
+	}
+	if !it.Next() || it.Key() != keys[1] {
+		t.Errorf("Got %v expected %v", it.Key(), keys[1])
+	}
+	it.Last()
+	it.Remove()
+	if it.Next() {
+		t.Errorf("Shouldn't iterate past the end")
+	}
+	if !it.Prev() || it.Key() != keys[len(keys)-2] {
+		t.Errorf("Got %v expected %v", it.Key(), keys[len(keys)-2])
+	}
+	if actualValue, expectedValue := tree.Size(), len(keys)-2; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+}
+
+// expectPanic checks that f panics with the expected error.
+func expectPanic(t *testing.T, expected error, f func()) {
+	t.Helper()
+	defer func() {
+		if r := recover(); r != expected {
+			t.Errorf("Got %v expected %v", r, expected)
+		}
+	}()
+	f()
+}
+
+func TestRadixTreeIteratorConcurrentModification(t *testing.T) {
+	tree := New()
+	tree.Put("a", 1)
+	tree.Put("ab", 2)
+	it := tree.Iterator()
+	it.Next()
+	tree.Remove("ab")
+	expectPanic(t, containers.ErrConcurrentModification, func() { it.Next() })
+	expectPanic(t, containers.ErrConcurrentModification, func() { it.Prev() })
+	expectPanic(t, containers.ErrConcurrentModification, func() { it.Remove() })
+
+	// updating a value is not a structural modification
+	it.Begin()
+	it.Next()
+	tree.Put("a", 3)
+	if it.Next() {
+		t.Errorf("Shouldn't iterate past the end")
+	}
+
+	it.Begin()
+	tree.Put("ab", 2)
+	if !it.Next() || it.Key() != "a" {
+		t.Errorf("Got %v expected %v", it.Key(), "a")
+	}
+	it.Remove()
+	expectPanic(t, containers.ErrIteratorNotOnElement, func() { it.Remove() })
+	it.End()
+	expectPanic(t, containers.ErrIteratorNotOnElement, func() { it.Remove() })
+}
+
+func TestRadixTreeSerialization(t *testing.T) {
+	tree := New()
+	tree.Put("b", "2")
+	tree.Put("a", "1")
+	tree.Put("ab", "3")
+
+	json, err := tree.ToJSON()
+	if err != nil {
+		t.Errorf("Got error %v", err)
+	}
+	if actualValue, expectedValue := string(json), `{"a":"1","ab":"3","b":"2"}`; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+
+	deserialized := New()
+	if err := deserialized.FromJSON(json); err != nil {
+		t.Errorf("Got error %v", err)
+	}
+	if actualValue, expectedValue := deserialized.String(), "RadixTree\nmap[a:1 ab:3 b:2]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+}
+
+func TestRadixTreeStats(t *testing.T) {
+	tree := New()
+	if actualValue, expectedValue := fmt.Sprintf("%+v", tree.Stats()), "{Height:0 Nodes:0 AverageDepth:0 Rotations:0 Splits:0 Merges:0 Comparisons:0}"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	tree.Put("romane", 1)
+	tree.Put("romanus", 2)
+	tree.Put("romulus", 3)
+	tree.Put("rubens", 4)
+	if actualValue, expectedValue := fmt.Sprintf("%+v", tree.Stats()), "{Height:4 Nodes:7 AverageDepth:2.25 Rotations:0 Splits:3 Merges:0 Comparisons:0}"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	tree.Remove("rubens")
+	tree.Remove("romulus")
+	if actualValue, expectedValue := fmt.Sprintf("%+v", tree.Stats()), "{Height:2 Nodes:3 AverageDepth:1 Rotations:0 Splits:3 Merges:2 Comparisons:0}"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+}
+
+func benchmarkKeys(size int) []string {
+	keys := make([]string, size)
+	for n := range keys {
+		keys[n] = fmt.Sprintf("user/%08d/profile", n*7919%size)
+	}
+	return keys
+}
+
+func BenchmarkRadixTreePut10000(b *testing.B) {
+	b.StopTimer()
+	keys := benchmarkKeys(10000)
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		tree := New()
+		for _, key := range keys {
+			tree.Put(key, struct{}{})
+		}
+	}
+}
+
+func BenchmarkTreeMapPut10000(b *testing.B) {
+	b.StopTimer()
+	keys := benchmarkKeys(10000)
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		m := treemap.NewWithStringComparator()
+		for _, key := range keys {
+			m.Put(key, struct{}{})
+		}
+	}
+}
+
+func BenchmarkRadixTreeGet10000(b *testing.B) {
+	b.StopTimer()
+	keys := benchmarkKeys(10000)
+	tree := New()
+	for _, key := range keys {
+		tree.Put(key, struct{}{})
+	}
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		for _, key := range keys {
+			tree.Get(key)
+		}
+	}
+}
+
+func BenchmarkTreeMapGet10000(b *testing.B) {
+	b.StopTimer()
+	keys := benchmarkKeys(10000)
+	m := treemap.NewWithStringComparator()
+	for _, key := range keys {
+		m.Put(key, struct{}{})
+	}
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		for _, key := range keys {
+			m.Get(key)
+		}
+	}
+}
+
+func BenchmarkRadixTreeRemove10000(b *testing.B) {
+	b.StopTimer()
+	keys := benchmarkKeys(10000)
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		tree := New()
+		for _, key := range keys {
+			tree.Put(key, struct{}{})
+		}
+		b.StartTimer()
+		for _, key := range keys {
+			tree.Remove(key)
+		}
+	}
+}
+
+func BenchmarkTreeMapRemove10000(b *testing.B) {
+	b.StopTimer()
+	keys := benchmarkKeys(10000)
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		m := treemap.NewWithStringComparator()
+		for _, key := range keys {
+			m.Put(key, struct{}{})
+		}
+		b.StartTimer()
+		for _, key := range keys {
+			m.Remove(key)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package radixtree
+
+import "github.com/dairongpeng/gds/trees"
+
+// stats counts the work done by the tree since it was created.
+type stats struct {
+	splits int // compressed paths split by an insertion
+	merges int // nodes without a key merged into their only child by a removal
+}
+
+// Stats returns the shape of the tree and the number of compressed paths split and nodes merged since it was created.
+// The tree does not rotate nodes or use a comparator, so these counters stay zero.
+// The shape is measured by walking the tree in O(n).
+func (tree *Tree) Stats() trees.Stats {
+	s := trees.Stats{
+		Splits: tree.stats.splits,
+		Merges: tree.stats.merges,
+	}
+	depths := 0
+	var walk func(current *node, depth int)
+	walk = func(current *node, depth int) {
+		s.Nodes++
+		if current.leaf != nil {
+			depths += depth
+		}
+		if depth+1 > s.Height {
+			s.Height = depth + 1
+		}
+		for label, child := current.nextChild(0); child != nil; label, child = current.nextChild(int(label) + 1) {
+			walk(child, depth+1)
+		}
+	}
+	if tree.root != nil {
+		walk(tree.root, 0)
+	}
+	if tree.size > 0 {
+		s.AverageDepth = float64(depths) / float64(tree.size)
+	}
+	return s
+}
@@ -0,0 +1,53 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package redblacktree
+
+import (
+	"fmt"
+	"github.com/dairongpeng/gds/containers"
+	"io"
+	"strings"
+)
+
+func assertDOTImplementation() {
+	var _ containers.DOTWriter = (*Tree)(nil)
+}
+
+// WriteDOT writes the tree as a Graphviz DOT digraph with nodes filled in their color.
+// Missing children are drawn as the small black NIL leaves of the red-black tree, so left and right children
+// can be told apart. Subtrees beyond the maximum depth of the options are drawn as "...".
+func (tree *Tree) WriteDOT(w io.Writer, options containers.DOTOptions) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph %s {\n", options.GraphName())
+	b.WriteString("\tnode [shape=circle, style=filled, fontcolor=white];\n")
+	id := 0
+	var write func(node *Node, depth int) string
+	write = func(node *Node, depth int) string {
+		name := fmt.Sprintf("n%d", id)
+		id++
+		switch {
+		case node == nil:
+			fmt.Fprintf(&b, "\t%s [shape=point, fillcolor=black];\n", name)
+		case options.Truncated(depth):
+			fmt.Fprintf(&b, "\t%s [label=\"...\", shape=plaintext, style=\"\", fontcolor=black];\n", name)
+		default:
+			color := "black"
+			if node.color == red {
+				color = "red"
+			}
+			fmt.Fprintf(&b, "\t%s [label=%s, fillcolor=%s];\n", name, options.Label(node.Key, node.Value), color)
+			for _, child := range []*Node{node.Left, node.Right} {
+				fmt.Fprintf(&b, "\t%s -> %s;\n", name, write(child, depth+1))
+			}
+		}
+		return name
+	}
+	if tree.Root != nil {
+		write(tree.Root, 0)
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -8,6 +8,7 @@ import "github.com/dairongpeng/gds/containers"
 
 func assertIteratorImplementation() {
 	var _ containers.ReverseIteratorWithKey = (*Iterator)(nil)
+	var _ containers.IteratorWithRemove = (*Iterator)(nil)
 }
 
 // Iterator holding the iterator's state
@@ -15,6 +16,8 @@ type Iterator struct {
 	tree     *Tree
 	node     *Node
 	position position
+	modCount int
+	removed  bool // the current node was removed, node is its successor
 }
 
 type position byte
@@ -25,12 +28,12 @@ const (
 
 // Iterator returns a stateful iterator whose elements are key/value pairs.
 func (tree *Tree) Iterator() Iterator {
-	return Iterator{tree: tree, node: nil, position: begin}
+	return Iterator{tree: tree, node: nil, position: begin, modCount: tree.modCount}
 }
 
 // IteratorAt returns a stateful iterator whose elements are key/value pairs that is initialised at a particular node.
 func (tree *Tree) IteratorAt(node *Node) Iterator {
-	return Iterator{tree: tree, node: node, position: between}
+	return Iterator{tree: tree, node: node, position: between, modCount: tree.modCount}
 }
 
 // Next moves the iterator to the next element and returns true if there was a next element in the container.
@@ -38,6 +41,14 @@ func (tree *Tree) IteratorAt(node *Node) Iterator {
 // If Next() was called for the first time, then it will point the iterator to the first element if it exists.
 // Modifies the state of the iterator.
 func (iterator *Iterator) Next() bool {
+	iterator.checkModification()
+	if iterator.removed {
+		iterator.removed = false
+		if iterator.node == nil {
+			goto end
+		}
+		goto between
+	}
 	if iterator.position == end {
 		goto end
 	}
@@ -49,22 +60,10 @@ func (iterator *Iterator) Next() bool {
 		iterator.node = left
 		goto between
 	}
-	if iterator.node.Right != nil {
-		iterator.node = iterator.node.Right
-		for iterator.node.Left != nil {
-			iterator.node = iterator.node.Left
-		}
+	// walk along the parent links rather than comparing keys, which may be equal in multi-key mode
+	if iterator.node = iterator.node.next(); iterator.node != nil {
 		goto between
 	}
-	if iterator.node.Parent != nil {
-		node := iterator.node
-		for iterator.node.Parent != nil {
-			iterator.node = iterator.node.Parent
-			if iterator.tree.Comparator(node.Key, iterator.node.Key) <= 0 {
-				goto between
-			}
-		}
-	}
 
 end:
 	iterator.node = nil
@@ -80,6 +79,19 @@ between:
 // If Prev() returns true, then previous element's key and value can be retrieved by Key() and Value().
 // Modifies the state of the iterator.
 func (iterator *Iterator) Prev() bool {
+	iterator.checkModification()
+	if iterator.removed {
+		iterator.removed = false
+		if iterator.node != nil {
+			iterator.node = iterator.node.prev()
+		} else {
+			iterator.node = iterator.tree.Right()
+		}
+		if iterator.node == nil {
+			goto begin
+		}
+		goto between
+	}
 	if iterator.position == begin {
 		goto begin
 	}
@@ -91,22 +103,9 @@ func (iterator *Iterator) Prev() bool {
 		iterator.node = right
 		goto between
 	}
-	if iterator.node.Left != nil {
-		iterator.node = iterator.node.Left
-		for iterator.node.Right != nil {
-			iterator.node = iterator.node.Right
-		}
+	if iterator.node = iterator.node.prev(); iterator.node != nil {
 		goto between
 	}
-	if iterator.node.Parent != nil {
-		node := iterator.node
-		for iterator.node.Parent != nil {
-			iterator.node = iterator.node.Parent
-			if iterator.tree.Comparator(node.Key, iterator.node.Key) >= 0 {
-				goto between
-			}
-		}
-	}
 
 begin:
 	iterator.node = nil
@@ -135,6 +134,8 @@ func (iterator *Iterator) Key() interface{} {
 func (iterator *Iterator) Begin() {
 	iterator.node = nil
 	iterator.position = begin
+	iterator.removed = false
+	iterator.modCount = iterator.tree.modCount
 }
 
 // End moves the iterator past the last element (one-past-the-end).
@@ -142,6 +143,8 @@ func (iterator *Iterator) Begin() {
 func (iterator *Iterator) End() {
 	iterator.node = nil
 	iterator.position = end
+	iterator.removed = false
+	iterator.modCount = iterator.tree.modCount
 }
 
 // First moves the iterator to the first element and returns true if there was a first element in the container.
@@ -159,3 +162,29 @@ func (iterator *Iterator) Last() bool {
 	iterator.End()
 	return iterator.Prev()
 }
+
+// Remove removes the current node from the tree.
+// Next() moves the iterator to the node after the removed one and Prev() to the node before it.
+// Panics with containers.ErrIteratorNotOnElement if the iterator is not on a node.
+func (iterator *Iterator) Remove() {
+	iterator.checkModification()
+	if iterator.removed || iterator.position != between {
+		panic(containers.ErrIteratorNotOnElement)
+	}
+	// removing a node with two children moves its predecessor into it, the successor stays in place
+	next := iterator.node.next()
+	iterator.tree.removeNode(iterator.node)
+	iterator.node = next
+	iterator.modCount = iterator.tree.modCount
+	iterator.removed = true
+}
+
+// checkModification panics if the tree was structurally modified other than through the iterator.
+func (iterator *Iterator) checkModification() {
+	if iterator.modCount != iterator.tree.modCount {
+		if iterator.position != begin || iterator.removed {
+			panic(containers.ErrConcurrentModification)
+		}
+		iterator.modCount = iterator.tree.modCount
+	}
+}
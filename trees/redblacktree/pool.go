@@ -0,0 +1,59 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package redblacktree
+
+// pool hands out the nodes of a tree from slabs and keeps removed nodes in a free list for reuse.
+type pool struct {
+	slab     []Node
+	free     *Node // removed nodes, linked through their Right field
+	slabSize int
+}
+
+func newPool(slabSize int) *pool {
+	if slabSize < 1 {
+		slabSize = 1
+	}
+	return &pool{slabSize: slabSize}
+}
+
+// get returns a zeroed node, reusing a removed node if there is one.
+func (p *pool) get() *Node {
+	if node := p.free; node != nil {
+		p.free = node.Right
+		node.Right = nil
+		return node
+	}
+	if len(p.slab) == 0 {
+		p.slab = make([]Node, p.slabSize)
+	}
+	node := &p.slab[0]
+	p.slab = p.slab[1:]
+	return node
+}
+
+// put clears the node, so that its key and value can be garbage collected, and adds it to the free list.
+func (p *pool) put(node *Node) {
+	*node = Node{Right: p.free}
+	p.free = node
+}
+
+// newNode returns a red node holding the key and value, taken from the pool if the tree has one.
+func (tree *Tree) newNode(key interface{}, value interface{}) *Node {
+	if tree.pool == nil {
+		return &Node{Key: key, Value: value, color: red}
+	}
+	node := tree.pool.get()
+	node.Key = key
+	node.Value = value
+	node.color = red
+	return node
+}
+
+// freeNode returns the node unlinked from the tree to the pool if the tree has one.
+func (tree *Tree) freeNode(node *Node) {
+	if tree.pool != nil {
+		tree.pool.put(node)
+	}
+}
@@ -6,6 +6,9 @@
 //
 // Used by TreeSet and TreeMap.
 //
+// A tree can allocate its nodes from a pool (NewWithPool, UsePool), which saves most allocations and garbage
+// collection work when keys are inserted and removed at a high rate.
+//
 // Structure is not thread safe.
 //
 // References: http://en.wikipedia.org/wiki/Red%E2%80%93black_tree
@@ -32,23 +35,49 @@ type Tree struct {
 	Root       *Node
 	size       int
 	Comparator utils.Comparator
+	augmenter  Augmenter
+	multi      bool // equal keys are kept as distinct nodes
+	modCount   int  // number of inserted and removed nodes, checked by iterators
+	pool       *pool
+	stats      stats
 }
 
 // Node is a single element within the tree
 type Node struct {
-	Key    interface{}
-	Value  interface{}
-	color  color
-	Left   *Node
-	Right  *Node
-	Parent *Node
+	Key       interface{}
+	Value     interface{}
+	Aggregate interface{} // Maintained by the tree's Augmenter, nil if the tree has none
+	color     color
+	Left      *Node
+	Right     *Node
+	Parent    *Node
 }
 
+// Augmenter computes the aggregate of a node from its key, value and the aggregates of its children.
+// The tree calls it bottom-up whenever the subtree rooted at the node changes (inserts, removals and rotations),
+// so the children passed along with the node always hold up-to-date aggregates.
+// Augmenter 根据节点自身的key、value以及左右孩子的聚合值，计算以该节点为头的子树的聚合值
+type Augmenter func(node *Node) interface{}
+
 // NewWith instantiates a red-black tree with the custom comparator.
 func NewWith(comparator utils.Comparator) *Tree {
 	return &Tree{Comparator: comparator}
 }
 
+// NewWithAugmenter instantiates a red-black tree with the custom comparator that maintains per-node aggregates with the augmenter.
+// The aggregates make it possible to answer queries over key ranges (sums, minimum and maximum of values, ...) in O(log n).
+func NewWithAugmenter(comparator utils.Comparator, augmenter Augmenter) *Tree {
+	return &Tree{Comparator: comparator, augmenter: augmenter}
+}
+
+// NewWithPool instantiates a red-black tree with the custom comparator whose nodes come from a pool, see UsePool.
+// NewWithPool 实例化一颗红黑树，节点按slabSize批量分配，删除的节点会被之后的插入复用
+func NewWithPool(comparator utils.Comparator, slabSize int) *Tree {
+	tree := NewWith(comparator)
+	tree.UsePool(slabSize)
+	return tree
+}
+
 // NewWithIntComparator instantiates a red-black tree with the IntComparator, i.e. keys are of type int.
 func NewWithIntComparator() *Tree {
 	return &Tree{Comparator: utils.IntComparator}
@@ -59,36 +88,65 @@ func NewWithStringComparator() *Tree {
 	return &Tree{Comparator: utils.StringComparator}
 }
 
+// NewMultiWith instantiates a red-black tree with the custom comparator in multi-key mode.
+// In multi-key mode Put keeps equal keys as distinct nodes, which are visited in insertion order.
+func NewMultiWith(comparator utils.Comparator) *Tree {
+	return &Tree{Comparator: comparator, multi: true}
+}
+
+// NewMultiWithIntComparator instantiates a red-black tree in multi-key mode with the IntComparator, i.e. keys are of type int.
+func NewMultiWithIntComparator() *Tree {
+	return NewMultiWith(utils.IntComparator)
+}
+
+// NewMultiWithStringComparator instantiates a red-black tree in multi-key mode with the StringComparator, i.e. keys are of type string.
+func NewMultiWithStringComparator() *Tree {
+	return NewMultiWith(utils.StringComparator)
+}
+
+// UsePool makes the tree allocate its nodes slabSize at a time and reuse the nodes of removed keys for later
+// insertions. A slabSize of 1 only reuses removed nodes. It combines with every constructor, e.g. NewWithAugmenter
+// and NewMultiWith, and is best called while the tree is empty, since nodes allocated before are only reused
+// once their keys are removed.
+// Nodes obtained from the tree (Left, Right, Floor, Ceiling) must not be used once their key was removed,
+// since the node may already hold another key, and a slab is kept in memory as long as any of its nodes is.
+// UsePool 让树的节点按slabSize批量分配，删除的节点会被之后的插入复用，可以和任意构造函数组合
+func (tree *Tree) UsePool(slabSize int) {
+	tree.pool = newPool(slabSize)
+}
+
 // Put inserts node into the tree.
+// In multi-key mode a key equal to existing keys is inserted after them, otherwise the node of the key is updated.
 // Key should adhere to the comparator's type assertion, otherwise method panics.
 func (tree *Tree) Put(key interface{}, value interface{}) {
 	var insertedNode *Node
 	if tree.Root == nil {
 		// Assert key is of comparator's type for initial tree
-		tree.Comparator(key, key)
-		tree.Root = &Node{Key: key, Value: value, color: red}
+		tree.compare(key, key)
+		tree.Root = tree.newNode(key, value)
 		insertedNode = tree.Root
 	} else {
 		node := tree.Root
 		loop := true
 		for loop {
-			compare := tree.Comparator(key, node.Key)
+			compare := tree.compare(key, node.Key)
 			switch {
-			case compare == 0:
+			case compare == 0 && !tree.multi:
 				node.Key = key
 				node.Value = value
+				tree.augmentPath(node)
 				return
 			case compare < 0:
 				if node.Left == nil {
-					node.Left = &Node{Key: key, Value: value, color: red}
+					node.Left = tree.newNode(key, value)
 					insertedNode = node.Left
 					loop = false
 				} else {
 					node = node.Left
 				}
-			case compare > 0:
+			default:
 				if node.Right == nil {
-					node.Right = &Node{Key: key, Value: value, color: red}
+					node.Right = tree.newNode(key, value)
 					insertedNode = node.Right
 					loop = false
 				} else {
@@ -98,11 +156,14 @@ func (tree *Tree) Put(key interface{}, value interface{}) {
 		}
 		insertedNode.Parent = node
 	}
+	tree.augmentPath(insertedNode)
 	tree.insertCase1(insertedNode)
 	tree.size++
+	tree.modCount++
 }
 
 // Get searches the node in the tree by key and returns its value or nil if key is not found in tree.
+// In multi-key mode it returns the value of the first node of the key.
 // Second return parameter is true if key was found, otherwise false.
 // Key should adhere to the comparator's type assertion, otherwise method panics.
 func (tree *Tree) Get(key interface{}) (value interface{}, found bool) {
@@ -114,35 +175,55 @@ func (tree *Tree) Get(key interface{}) (value interface{}, found bool) {
 }
 
 // Remove remove the node from the tree by key.
+// In multi-key mode it removes all nodes of the key, like RemoveAll.
 // Key should adhere to the comparator's type assertion, otherwise method panics.
 func (tree *Tree) Remove(key interface{}) {
-	var child *Node
-	node := tree.lookup(key)
-	if node == nil {
+	if tree.multi {
+		tree.RemoveAll(key)
 		return
 	}
-	if node.Left != nil && node.Right != nil {
-		pred := node.Left.maximumNode()
-		node.Key = pred.Key
-		node.Value = pred.Value
-		node = pred
+	tree.RemoveOne(key)
+}
+
+// RemoveOne removes the first node of the key from the tree, the one inserted first in multi-key mode.
+// Key should adhere to the comparator's type assertion, otherwise method panics.
+func (tree *Tree) RemoveOne(key interface{}) {
+	if node := tree.lookup(key); node != nil {
+		tree.removeNode(node)
 	}
-	if node.Left == nil || node.Right == nil {
-		if node.Right == nil {
-			child = node.Left
-		} else {
-			child = node.Right
-		}
-		if node.color == black {
-			node.color = nodeColor(child)
-			tree.deleteCase1(node)
-		}
-		tree.replaceNode(node, child)
-		if node.Parent == nil && child != nil {
-			child.color = black
-		}
+}
+
+// RemoveAll removes all nodes of the key from the tree.
+// Key should adhere to the comparator's type assertion, otherwise method panics.
+func (tree *Tree) RemoveAll(key interface{}) {
+	for node := tree.lookup(key); node != nil; node = tree.lookup(key) {
+		tree.removeNode(node)
 	}
-	tree.size--
+}
+
+// Count returns the number of nodes of the key, at most one unless in multi-key mode.
+// Key should adhere to the comparator's type assertion, otherwise method panics.
+func (tree *Tree) Count(key interface{}) int {
+	count := 0
+	for node := tree.lookup(key); node != nil && tree.compare(key, node.Key) == 0; node = node.next() {
+		count++
+	}
+	return count
+}
+
+// GetAll returns the values of all nodes of the key in insertion order, an empty slice if key is not found in tree.
+// Key should adhere to the comparator's type assertion, otherwise method panics.
+func (tree *Tree) GetAll(key interface{}) []interface{} {
+	values := []interface{}{}
+	for node := tree.lookup(key); node != nil && tree.compare(key, node.Key) == 0; node = node.next() {
+		values = append(values, node.Value)
+	}
+	return values
+}
+
+// Multi returns true if the tree keeps equal keys as distinct nodes.
+func (tree *Tree) Multi() bool {
+	return tree.multi
 }
 
 // Empty returns true if tree does not contain any nodes
@@ -201,6 +282,7 @@ func (tree *Tree) Right() *Node {
 // Second return parameter is true if floor was found, otherwise false.
 //
 // Floor node is defined as the largest node that is smaller than or equal to the given node.
+// In multi-key mode it is the last node of equal keys.
 // A floor node may not be found, either because the tree is empty, or because
 // all nodes in the tree are larger than the given node.
 //
@@ -209,13 +291,13 @@ func (tree *Tree) Floor(key interface{}) (floor *Node, found bool) {
 	found = false
 	node := tree.Root
 	for node != nil {
-		compare := tree.Comparator(key, node.Key)
+		compare := tree.compare(key, node.Key)
 		switch {
-		case compare == 0:
+		case compare == 0 && !tree.multi:
 			return node, true
 		case compare < 0:
 			node = node.Left
-		case compare > 0:
+		default:
 			floor, found = node, true
 			node = node.Right
 		}
@@ -230,6 +312,7 @@ func (tree *Tree) Floor(key interface{}) (floor *Node, found bool) {
 // Second return parameter is true if ceiling was found, otherwise false.
 //
 // Ceiling node is defined as the smallest node that is larger than or equal to the given node.
+// In multi-key mode it is the first node of equal keys.
 // A ceiling node may not be found, either because the tree is empty, or because
 // all nodes in the tree are smaller than the given node.
 //
@@ -238,15 +321,15 @@ func (tree *Tree) Ceiling(key interface{}) (ceiling *Node, found bool) {
 	found = false
 	node := tree.Root
 	for node != nil {
-		compare := tree.Comparator(key, node.Key)
+		compare := tree.compare(key, node.Key)
 		switch {
-		case compare == 0:
+		case compare == 0 && !tree.multi:
 			return node, true
-		case compare < 0:
-			ceiling, found = node, true
-			node = node.Left
 		case compare > 0:
 			node = node.Right
+		default:
+			ceiling, found = node, true
+			node = node.Left
 		}
 	}
 	if found {
@@ -259,6 +342,7 @@ func (tree *Tree) Ceiling(key interface{}) (ceiling *Node, found bool) {
 func (tree *Tree) Clear() {
 	tree.Root = nil
 	tree.size = 0
+	tree.modCount++
 }
 
 // String returns a string representation of container
@@ -302,20 +386,83 @@ func output(node *Node, prefix string, isTail bool, str *string) {
 	}
 }
 
+// removeNode removes the node from the tree and rebalances it.
+// A node with two children takes the key and value of its in-order predecessor, whose node is unlinked instead.
+func (tree *Tree) removeNode(node *Node) {
+	var child *Node
+	if node.Left != nil && node.Right != nil {
+		pred := node.Left.maximumNode()
+		node.Key = pred.Key
+		node.Value = pred.Value
+		node = pred
+	}
+	if node.Left == nil || node.Right == nil {
+		if node.Right == nil {
+			child = node.Left
+		} else {
+			child = node.Right
+		}
+		if node.color == black {
+			node.color = nodeColor(child)
+			tree.deleteCase1(node)
+		}
+		tree.replaceNode(node, child)
+		if node.Parent == nil && child != nil {
+			child.color = black
+		}
+		tree.augmentPath(node.Parent)
+		tree.freeNode(node)
+	}
+	tree.size--
+	tree.modCount++
+}
+
+// lookup returns the node of the key, the first one in multi-key mode, or nil if key is not found in tree.
 func (tree *Tree) lookup(key interface{}) *Node {
+	var found *Node
 	node := tree.Root
 	for node != nil {
-		compare := tree.Comparator(key, node.Key)
+		compare := tree.compare(key, node.Key)
 		switch {
 		case compare == 0:
-			return node
+			if !tree.multi {
+				return node
+			}
+			found = node
+			node = node.Left
 		case compare < 0:
 			node = node.Left
 		case compare > 0:
 			node = node.Right
 		}
 	}
-	return nil
+	return found
+}
+
+// next returns the in-order successor of the node or nil if it is the last node.
+func (node *Node) next() *Node {
+	if node.Right != nil {
+		node = node.Right
+		for node.Left != nil {
+			node = node.Left
+		}
+		return node
+	}
+	for node.Parent != nil && node == node.Parent.Right {
+		node = node.Parent
+	}
+	return node.Parent
+}
+
+// prev returns the in-order predecessor of the node or nil if it is the first node.
+func (node *Node) prev() *Node {
+	if node.Left != nil {
+		return node.Left.maximumNode()
+	}
+	for node.Parent != nil && node == node.Parent.Left {
+		node = node.Parent
+	}
+	return node.Parent
 }
 
 func (node *Node) grandparent() *Node {
@@ -343,6 +490,7 @@ func (node *Node) sibling() *Node {
 }
 
 func (tree *Tree) rotateLeft(node *Node) {
+	tree.stats.rotations++
 	right := node.Right
 	tree.replaceNode(node, right)
 	node.Right = right.Left
@@ -351,9 +499,12 @@ func (tree *Tree) rotateLeft(node *Node) {
 	}
 	right.Left = node
 	node.Parent = right
+	tree.augment(node)
+	tree.augment(right)
 }
 
 func (tree *Tree) rotateRight(node *Node) {
+	tree.stats.rotations++
 	left := node.Left
 	tree.replaceNode(node, left)
 	node.Left = left.Right
@@ -362,6 +513,8 @@ func (tree *Tree) rotateRight(node *Node) {
 	}
 	left.Right = node
 	node.Parent = left
+	tree.augment(node)
+	tree.augment(left)
 }
 
 func (tree *Tree) replaceNode(old *Node, new *Node) {
@@ -379,6 +532,23 @@ func (tree *Tree) replaceNode(old *Node, new *Node) {
 	}
 }
 
+// augment recomputes the aggregate of the node from its children.
+func (tree *Tree) augment(node *Node) {
+	if tree.augmenter != nil {
+		node.Aggregate = tree.augmenter(node)
+	}
+}
+
+// augmentPath recomputes the aggregates of the node and all its ancestors.
+func (tree *Tree) augmentPath(node *Node) {
+	if tree.augmenter == nil {
+		return
+	}
+	for ; node != nil; node = node.Parent {
+		node.Aggregate = tree.augmenter(node)
+	}
+}
+
 func (tree *Tree) insertCase1(node *Node) {
 	if node.Parent == nil {
 		node.color = black
@@ -5,7 +5,11 @@
 package redblacktree
 
 import (
+	"bytes"
 	"fmt"
+	"github.com/dairongpeng/gds/containers"
+	"github.com/dairongpeng/gds/utils"
+	"math/rand"
 	"testing"
 )
 
@@ -557,6 +561,119 @@ func TestRedBlackTreeIteratorLast(t *testing.T) {
 	}
 }
 
+func TestRedBlackTreeIteratorRemove(t *testing.T) {
+	tree := NewWithIntComparator()
+	for _, key := range rand.Perm(200) {
+		tree.Put(key, key)
+	}
+	it := tree.Iterator()
+	for it.Next() {
+		if it.Key().(int)%3 != 0 {
+			it.Remove()
+			if err := tree.Validate(); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	for it.Prev() {
+		if it.Key().(int)%2 != 0 {
+			it.Remove()
+			if err := tree.Validate(); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	keys := []interface{}{}
+	for key := 0; key < 200; key += 6 {
+		keys = append(keys, key)
+	}
+	if actualValue, expectedValue := fmt.Sprint(tree.Keys()), fmt.Sprint(keys); actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+
+	// neighbours of a removed node
+	tree = NewWithIntComparator()
+	tree.Put(2, "b")
+	tree.Put(1, "a")
+	tree.Put(3, "c")
+	it = tree.Iterator()
+	it.Next()
+	it.Next()
+	it.Remove()
+	if !it.Prev() || it.Key() != 1 {
+		t.Errorf("Got %v expected %v", it.Key(), 1)
+	}
+	it.Remove()
+	if it.Prev() {
+		t.Errorf("Shouldn't iterate before the beginning")
+	}
+	if !it.Next() || it.Key() != 3 {
+		t.Errorf("Got %v expected %v", it.Key(), 3)
+	}
+	it.Remove()
+	if it.Next() {
+		t.Errorf("Shouldn't iterate past the end")
+	}
+	if actualValue, expectedValue := tree.Size(), 0; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+
+	// removes the exact node among equal keys
+	tree = NewMultiWithIntComparator()
+	for i, key := range []int{1, 2, 2, 2, 3} {
+		tree.Put(key, i)
+	}
+	it = tree.Iterator()
+	for it.Next() {
+		if it.Value() == 2 {
+			it.Remove()
+		}
+	}
+	if actualValue, expectedValue := fmt.Sprint(tree.GetAll(2)), "[1 3]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+}
+
+// expectPanic checks that f panics with the expected error.
+func expectPanic(t *testing.T, expected error, f func()) {
+	t.Helper()
+	defer func() {
+		if r := recover(); r != expected {
+			t.Errorf("Got %v expected %v", r, expected)
+		}
+	}()
+	f()
+}
+
+func TestRedBlackTreeIteratorConcurrentModification(t *testing.T) {
+	tree := NewWithIntComparator()
+	tree.Put(1, "a")
+	tree.Put(2, "b")
+	it := tree.Iterator()
+	it.Next()
+	tree.Put(3, "c")
+	expectPanic(t, containers.ErrConcurrentModification, func() { it.Next() })
+	expectPanic(t, containers.ErrConcurrentModification, func() { it.Prev() })
+	expectPanic(t, containers.ErrConcurrentModification, func() { it.Remove() })
+
+	// updating a value is not a structural modification
+	it.Begin()
+	it.Next()
+	tree.Put(1, "z")
+	tree.Remove(4)
+	if !it.Next() || it.Key() != 2 {
+		t.Errorf("Got %v expected %v", it.Key(), 2)
+	}
+	tree.Remove(3)
+	expectPanic(t, containers.ErrConcurrentModification, func() { it.Next() })
+
+	it.End()
+	expectPanic(t, containers.ErrIteratorNotOnElement, func() { it.Remove() })
+	it.Prev()
+	it.Remove()
+	expectPanic(t, containers.ErrIteratorNotOnElement, func() { it.Remove() })
+}
+
 func TestRedBlackTreeSerialization(t *testing.T) {
 	tree := NewWithStringComparator()
 	tree.Put("c", "3")
@@ -588,6 +705,196 @@ func TestRedBlackTreeSerialization(t *testing.T) {
 	assert()
 }
 
+func sumAugmenter(node *Node) interface{} {
+	sum := node.Value.(int)
+	if node.Left != nil {
+		sum += node.Left.Aggregate.(int)
+	}
+	if node.Right != nil {
+		sum += node.Right.Aggregate.(int)
+	}
+	return sum
+}
+
+// sumLessThan returns the sum of the values whose keys are smaller than key in O(log n).
+func sumLessThan(tree *Tree, key int) int {
+	sum := 0
+	for node := tree.Root; node != nil; {
+		if key <= node.Key.(int) {
+			node = node.Left
+			continue
+		}
+		sum += node.Value.(int)
+		if node.Left != nil {
+			sum += node.Left.Aggregate.(int)
+		}
+		node = node.Right
+	}
+	return sum
+}
+
+func assertAggregates(t *testing.T, node *Node) int {
+	if node == nil {
+		return 0
+	}
+	sum := node.Value.(int) + assertAggregates(t, node.Left) + assertAggregates(t, node.Right)
+	if node.Aggregate != sum {
+		t.Errorf("Got %v expected %v at key %v", node.Aggregate, sum, node.Key)
+	}
+	return sum
+}
+
+func TestRedBlackTreeAugmenter(t *testing.T) {
+	tree := NewWithAugmenter(utils.IntComparator, sumAugmenter)
+	r := rand.New(rand.NewSource(1))
+	expected := make(map[int]int)
+	for i := 0; i < 2000; i++ {
+		key := r.Intn(300)
+		if r.Intn(3) == 0 {
+			tree.Remove(key)
+			delete(expected, key)
+		} else {
+			tree.Put(key, i)
+			expected[key] = i
+		}
+		assertAggregates(t, tree.Root)
+	}
+	for from := 0; from < 300; from += 37 {
+		to := from + 50
+		sum := 0
+		for key, value := range expected {
+			if key >= from && key < to {
+				sum += value
+			}
+		}
+		if actualValue := sumLessThan(tree, to) - sumLessThan(tree, from); actualValue != sum {
+			t.Errorf("Got %v expected %v for range [%v,%v)", actualValue, sum, from, to)
+		}
+	}
+}
+
+func TestRedBlackTreePool(t *testing.T) {
+	tree := NewWithPool(utils.IntComparator, 16)
+	expected := map[int]int{}
+	for i := 0; i < 2000; i++ {
+		key := rand.Intn(200)
+		if rand.Intn(3) == 0 {
+			tree.Remove(key)
+			delete(expected, key)
+		} else {
+			tree.Put(key, i)
+			expected[key] = i
+		}
+	}
+	if err := tree.Validate(); err != nil {
+		t.Errorf("Got %v expected %v", err, nil)
+	}
+	if actualValue, expectedValue := tree.Size(), len(expected); actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	for key, value := range expected {
+		if actualValue, found := tree.Get(key); actualValue != value || !found {
+			t.Errorf("Got %v expected %v", actualValue, value)
+		}
+	}
+
+	// the nodes of removed keys are reused
+	for key := range expected {
+		tree.Remove(key)
+	}
+	allocs := testing.AllocsPerRun(10, func() {
+		for n := 0; n < 100; n++ {
+			tree.Put(n, n)
+		}
+		for n := 0; n < 100; n++ {
+			tree.Remove(n)
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("Got %v expected %v", allocs, 0)
+	}
+}
+
+func TestRedBlackTreeUsePool(t *testing.T) {
+	tree := NewWithAugmenter(utils.IntComparator, sumAugmenter)
+	tree.UsePool(16)
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		key := r.Intn(200)
+		if r.Intn(3) == 0 {
+			tree.Remove(key)
+		} else {
+			tree.Put(key, i)
+		}
+		assertAggregates(t, tree.Root)
+	}
+	if err := tree.Validate(); err != nil {
+		t.Errorf("Got %v expected %v", err, nil)
+	}
+
+	// equal keys of a multi-key tree take a node each, all of them are reused
+	tree = NewMultiWith(utils.IntComparator)
+	tree.UsePool(16)
+	allocs := testing.AllocsPerRun(10, func() {
+		for n := 0; n < 100; n++ {
+			tree.Put(n%10, n)
+		}
+		if tree.Size() != 100 {
+			t.Errorf("Got %v expected %v", tree.Size(), 100)
+		}
+		for n := 0; n < 100; n++ {
+			tree.Remove(n % 10)
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("Got %v expected %v", allocs, 0)
+	}
+	if err := tree.Validate(); err != nil {
+		t.Errorf("Got %v expected %v", err, nil)
+	}
+}
+
+func TestRedBlackTreeStats(t *testing.T) {
+	tree := NewWithIntComparator()
+	if actualValue, expectedValue := fmt.Sprintf("%+v", tree.Stats()), "{Height:0 Nodes:0 AverageDepth:0 Rotations:0 Splits:0 Merges:0 Comparisons:0}"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	for i := 1; i <= 7; i++ {
+		tree.Put(i, i)
+	}
+	//
+	//  RedBlackTree
+	//  │           ┌── 7
+	//  │       ┌── 6
+	//  │       │   └── 5
+	//  │   ┌── 4
+	//  │   │   └── 3
+	//  └── 2
+	//      └── 1
+	//
+	stats := tree.Stats()
+	if actualValue, expectedValue := stats.Height, 4; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue, expectedValue := stats.Nodes, 7; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue, expectedValue := stats.AverageDepth, 12.0/7; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue, expectedValue := stats.Rotations, 3; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	// the first key is compared with itself, the others with the keys along their path
+	if actualValue, expectedValue := stats.Comparisons, 16; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	tree.Get(4)
+	if actualValue, expectedValue := tree.Stats().Comparisons, 18; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+}
+
 func benchmarkGet(b *testing.B, tree *Tree, size int) {
 	for i := 0; i < b.N; i++ {
 		for n := 0; n < size; n++ {
@@ -612,6 +919,280 @@ func benchmarkRemove(b *testing.B, tree *Tree, size int) {
 	}
 }
 
+// benchmarkChurn inserts and removes all keys in every round, the keys are boxed up front
+// so that the reported allocations are those of the tree.
+func benchmarkChurn(b *testing.B, tree *Tree, size int) {
+	keys := make([]interface{}, size)
+	for n := range keys {
+		keys[n] = n
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, key := range keys {
+			tree.Put(key, struct{}{})
+		}
+		for _, key := range keys {
+			tree.Remove(key)
+		}
+	}
+}
+
+func TestRedBlackTreeValidate(t *testing.T) {
+	tree := NewWithIntComparator()
+	if err := tree.Validate(); err != nil {
+		t.Errorf("Got %v expected %v", err, nil)
+	}
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		if r.Intn(3) == 0 {
+			tree.Remove(r.Intn(200))
+		} else {
+			tree.Put(r.Intn(200), i)
+		}
+		if i%50 == 0 {
+			if err := tree.Validate(); err != nil {
+				t.Fatalf("Got %v expected %v", err, nil)
+			}
+		}
+	}
+
+	corruptions := []struct {
+		corrupt  func(tree *Tree)
+		expected string
+	}{
+		{func(tree *Tree) { tree.Root.Key, tree.Root.Left.Key = tree.Root.Left.Key, tree.Root.Key }, "key 4 is not before 2"},
+		{func(tree *Tree) { tree.Root.color = red }, "root 4 is red"},
+		{func(tree *Tree) { tree.Root.Right.Right.color = red }, "red key 8 has a red child 10"},
+		{func(tree *Tree) { tree.Root.Right.Right.Right.color = black }, "key 10 has black heights 2 and 3"},
+		{func(tree *Tree) { tree.Root.Left.Left.Parent = tree.Root }, "key 1 does not link to its parent 2"},
+		{func(tree *Tree) { tree.size-- }, "size is 14 but tree holds 15 nodes"},
+	}
+	for _, test := range corruptions {
+		tree := NewWithIntComparator()
+		for i := 1; i <= 15; i++ {
+			tree.Put(i, nil)
+		}
+		if err := tree.Validate(); err != nil {
+			t.Fatalf("Got %v expected %v", err, nil)
+		}
+		test.corrupt(tree)
+		if err := tree.Validate(); err == nil || err.Error() != "redblacktree: "+test.expected {
+			t.Errorf("Got %v expected %v", err, test.expected)
+		}
+	}
+}
+
+func TestRedBlackTreeWriteDOT(t *testing.T) {
+	tree := NewWithIntComparator()
+	var buffer bytes.Buffer
+	if err := tree.WriteDOT(&buffer, containers.DOTOptions{}); err != nil {
+		t.Errorf("Got %v expected %v", err, nil)
+	}
+	if actualValue, expectedValue := buffer.String(), "digraph G {\n\tnode [shape=circle, style=filled, fontcolor=white];\n}\n"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+
+	tree.Put(2, "b")
+	tree.Put(1, "a")
+	tree.Put(3, "c")
+	tree.Put(4, "d")
+	buffer.Reset()
+	tree.WriteDOT(&buffer, containers.DOTOptions{Name: "rbt", Values: true})
+	expectedValue := `digraph "rbt" {
+	node [shape=circle, style=filled, fontcolor=white];
+	n0 [label="2\nb", fillcolor=black];
+	n1 [label="1\na", fillcolor=black];
+	n2 [shape=point, fillcolor=black];
+	n1 -> n2;
+	n3 [shape=point, fillcolor=black];
+	n1 -> n3;
+	n0 -> n1;
+	n4 [label="3\nc", fillcolor=black];
+	n5 [shape=point, fillcolor=black];
+	n4 -> n5;
+	n6 [label="4\nd", fillcolor=red];
+	n7 [shape=point, fillcolor=black];
+	n6 -> n7;
+	n8 [shape=point, fillcolor=black];
+	n6 -> n8;
+	n4 -> n6;
+	n0 -> n4;
+}
+`
+	if actualValue := buffer.String(); actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+
+	buffer.Reset()
+	tree.WriteDOT(&buffer, containers.DOTOptions{MaxDepth: 1})
+	expectedValue = `digraph G {
+	node [shape=circle, style=filled, fontcolor=white];
+	n0 [label="2", fillcolor=black];
+	n1 [label="...", shape=plaintext, style="", fontcolor=black];
+	n0 -> n1;
+	n2 [label="...", shape=plaintext, style="", fontcolor=black];
+	n0 -> n2;
+}
+`
+	if actualValue := buffer.String(); actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+}
+
+func TestRedBlackTreeMulti(t *testing.T) {
+	tree := NewMultiWithIntComparator()
+	tree.Put(2, "b1")
+	tree.Put(1, "a")
+	tree.Put(2, "b2")
+	tree.Put(3, "c")
+	tree.Put(2, "b3")
+
+	if actualValue := tree.Size(); actualValue != 5 {
+		t.Errorf("Got %v expected %v", actualValue, 5)
+	}
+	if actualValue, expectedValue := fmt.Sprintf("%v", tree.Keys()), "[1 2 2 2 3]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue, expectedValue := fmt.Sprintf("%v", tree.Values()), "[a b1 b2 b3 c]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue, expectedValue := fmt.Sprintf("%v", tree.GetAll(2)), "[b1 b2 b3]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue, expectedValue := fmt.Sprintf("%v", tree.GetAll(4)), "[]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue := tree.Count(2); actualValue != 3 {
+		t.Errorf("Got %v expected %v", actualValue, 3)
+	}
+	if actualValue, found := tree.Get(2); actualValue != "b1" || !found {
+		t.Errorf("Got %v expected %v", actualValue, "b1")
+	}
+	if node, found := tree.Floor(2); node.Value != "b3" || !found {
+		t.Errorf("Got %v expected %v", node.Value, "b3")
+	}
+	if node, found := tree.Ceiling(2); node.Value != "b1" || !found {
+		t.Errorf("Got %v expected %v", node.Value, "b1")
+	}
+
+	it := tree.Iterator()
+	values := []interface{}{}
+	for it.End(); it.Prev(); {
+		values = append(values, it.Value())
+	}
+	if actualValue, expectedValue := fmt.Sprintf("%v", values), "[c b3 b2 b1 a]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+
+	tree.RemoveOne(2)
+	if actualValue, expectedValue := fmt.Sprintf("%v", tree.GetAll(2)), "[b2 b3]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	tree.Put(2, "b4")
+	tree.RemoveAll(2)
+	if actualValue, expectedValue := fmt.Sprintf("%v", tree.Values()), "[a c]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	tree.Put(1, "a2")
+	tree.Remove(1)
+	if actualValue, expectedValue := fmt.Sprintf("%v", tree.Values()), "[c]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if err := tree.Validate(); err != nil {
+		t.Errorf("Got %v expected %v", err, nil)
+	}
+}
+
+func TestRedBlackTreeMultiRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	tree := NewMultiWithIntComparator()
+	expected := map[int][]interface{}{}
+	for i := 0; i < 3000; i++ {
+		key := r.Intn(20)
+		switch r.Intn(10) {
+		case 0:
+			tree.RemoveAll(key)
+			delete(expected, key)
+		case 1, 2, 3:
+			tree.RemoveOne(key)
+			if len(expected[key]) > 0 {
+				expected[key] = expected[key][1:]
+			}
+		default:
+			tree.Put(key, i)
+			expected[key] = append(expected[key], i)
+		}
+		if err := tree.Validate(); err != nil {
+			t.Fatalf("Got %v expected %v", err, nil)
+		}
+	}
+	size := 0
+	for key := 0; key < 20; key++ {
+		size += len(expected[key])
+		if actualValue, expectedValue := fmt.Sprintf("%v", tree.GetAll(key)), fmt.Sprintf("%v", expected[key]); actualValue != expectedValue {
+			t.Errorf("Got %v expected %v", actualValue, expectedValue)
+		}
+		if actualValue := tree.Count(key); actualValue != len(expected[key]) {
+			t.Errorf("Got %v expected %v", actualValue, len(expected[key]))
+		}
+	}
+	if actualValue := tree.Size(); actualValue != size {
+		t.Errorf("Got %v expected %v", actualValue, size)
+	}
+	it := tree.Iterator()
+	for key := 0; key < 20; key++ {
+		for _, value := range expected[key] {
+			if !it.Next() || it.Key() != key || it.Value() != value {
+				t.Fatalf("Got %v:%v expected %v:%v", it.Key(), it.Value(), key, value)
+			}
+		}
+	}
+	if it.Next() {
+		t.Errorf("Got %v expected %v", it.Key(), nil)
+	}
+}
+
+func TestRedBlackTreeUniqueCount(t *testing.T) {
+	tree := NewWithIntComparator()
+	tree.Put(1, "a")
+	tree.Put(1, "b")
+	if actualValue := tree.Count(1); actualValue != 1 {
+		t.Errorf("Got %v expected %v", actualValue, 1)
+	}
+	if actualValue, expectedValue := fmt.Sprintf("%v", tree.GetAll(1)), "[b]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue := tree.Multi(); actualValue != false {
+		t.Errorf("Got %v expected %v", actualValue, false)
+	}
+	tree.RemoveOne(1)
+	if actualValue := tree.Empty(); actualValue != true {
+		t.Errorf("Got %v expected %v", actualValue, true)
+	}
+}
+
+func TestRedBlackTreeMultiSerialization(t *testing.T) {
+	tree := NewMultiWithStringComparator()
+	tree.Put("a", 1.0)
+	tree.Put("b", 2.0)
+	tree.Put("a", 3.0)
+	json, err := tree.ToJSON()
+	if err != nil {
+		t.Errorf("Got error %v", err)
+	}
+	if actualValue, expectedValue := string(json), `{"a":[1,3],"b":[2]}`; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	other := NewMultiWithStringComparator()
+	if err := other.FromJSON(json); err != nil {
+		t.Errorf("Got error %v", err)
+	}
+	if actualValue, expectedValue := fmt.Sprintf("%v %v", other.Keys(), other.Values()), "[a a b] [1 3 2]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+}
+
 func BenchmarkRedBlackTreeGet100(b *testing.B) {
 	b.StopTimer()
 	size := 100
@@ -740,3 +1321,19 @@ func BenchmarkRedBlackTreeRemove100000(b *testing.B) {
 	b.StartTimer()
 	benchmarkRemove(b, tree, size)
 }
+
+func BenchmarkRedBlackTreeChurn1000(b *testing.B) {
+	benchmarkChurn(b, NewWithIntComparator(), 1000)
+}
+
+func BenchmarkRedBlackTreeChurn10000(b *testing.B) {
+	benchmarkChurn(b, NewWithIntComparator(), 10000)
+}
+
+func BenchmarkRedBlackTreePooledChurn1000(b *testing.B) {
+	benchmarkChurn(b, NewWithPool(utils.IntComparator, 256), 1000)
+}
+
+func BenchmarkRedBlackTreePooledChurn10000(b *testing.B) {
+	benchmarkChurn(b, NewWithPool(utils.IntComparator, 256), 10000)
+}
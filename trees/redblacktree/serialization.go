@@ -16,23 +16,38 @@ func assertSerializationImplementation() {
 }
 
 // ToJSON outputs the JSON representation of the tree.
+// In multi-key mode every key maps to the array of its values in insertion order.
 func (tree *Tree) ToJSON() ([]byte, error) {
 	elements := make(map[string]interface{})
 	it := tree.Iterator()
 	for it.Next() {
-		elements[utils.ToString(it.Key())] = it.Value()
+		key := utils.ToString(it.Key())
+		if !tree.multi {
+			elements[key] = it.Value()
+		} else if values, ok := elements[key]; ok {
+			elements[key] = append(values.([]interface{}), it.Value())
+		} else {
+			elements[key] = []interface{}{it.Value()}
+		}
 	}
 	return json.Marshal(&elements)
 }
 
 // FromJSON populates the tree from the input JSON representation.
+// In multi-key mode an array value is put as one node per element.
 func (tree *Tree) FromJSON(data []byte) error {
 	elements := make(map[string]interface{})
 	err := json.Unmarshal(data, &elements)
 	if err == nil {
 		tree.Clear()
 		for key, value := range elements {
-			tree.Put(key, value)
+			if values, ok := value.([]interface{}); ok && tree.multi {
+				for _, value := range values {
+					tree.Put(key, value)
+				}
+			} else {
+				tree.Put(key, value)
+			}
 		}
 	}
 	return err
@@ -0,0 +1,77 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package redblacktree
+
+import "fmt"
+
+// Validate checks the invariants of the tree and returns an error naming the offending key of the first violation, or nil.
+// It checks that keys are in order under the comparator (equal keys are allowed in multi-key mode), that every child
+// links to its parent, that the size matches the number of nodes, that the root is black, that red nodes only have
+// black children and that all paths from a node down to its leaves hold the same number of black nodes.
+// Validate visits every node, it is meant for tests of code that extends the tree.
+func (tree *Tree) Validate() error {
+	if tree.Root != nil {
+		if tree.Root.Parent != nil {
+			return fmt.Errorf("redblacktree: root %v has a parent", tree.Root.Key)
+		}
+		if tree.Root.color != black {
+			return fmt.Errorf("redblacktree: root %v is red", tree.Root.Key)
+		}
+	}
+	count := 0
+	if _, err := tree.validate(tree.Root, nil, nil, &count); err != nil {
+		return err
+	}
+	if count != tree.size {
+		return fmt.Errorf("redblacktree: size is %d but tree holds %d nodes", tree.size, count)
+	}
+	return nil
+}
+
+// validate counts the nodes of the subtree and returns its black height.
+// Keys of the subtree have to lie between the keys of lower and upper, which are nil if unbounded.
+func (tree *Tree) validate(node *Node, lower *Node, upper *Node, count *int) (int, error) {
+	if node == nil {
+		return 1, nil
+	}
+	*count++
+	// equal keys may be on both sides of a node in multi-key mode, rotations keep them in order but not in place
+	if lower != nil {
+		if c := tree.Comparator(node.Key, lower.Key); c < 0 || c == 0 && !tree.multi {
+			return 0, fmt.Errorf("redblacktree: key %v is not after %v", node.Key, lower.Key)
+		}
+	}
+	if upper != nil {
+		if c := tree.Comparator(node.Key, upper.Key); c > 0 || c == 0 && !tree.multi {
+			return 0, fmt.Errorf("redblacktree: key %v is not before %v", node.Key, upper.Key)
+		}
+	}
+	for _, child := range []*Node{node.Left, node.Right} {
+		if child == nil {
+			continue
+		}
+		if child.Parent != node {
+			return 0, fmt.Errorf("redblacktree: key %v does not link to its parent %v", child.Key, node.Key)
+		}
+		if node.color == red && child.color == red {
+			return 0, fmt.Errorf("redblacktree: red key %v has a red child %v", node.Key, child.Key)
+		}
+	}
+	left, err := tree.validate(node.Left, lower, node, count)
+	if err != nil {
+		return 0, err
+	}
+	right, err := tree.validate(node.Right, node, upper, count)
+	if err != nil {
+		return 0, err
+	}
+	if left != right {
+		return 0, fmt.Errorf("redblacktree: key %v has black heights %d and %d", node.Key, left, right)
+	}
+	if node.color == black {
+		left++
+	}
+	return left, nil
+}
@@ -0,0 +1,128 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rtree
+
+import (
+	"fmt"
+	"math"
+)
+
+// Rect is an axis-aligned bounding box, bounds included
+type Rect struct {
+	Min []float64
+	Max []float64
+}
+
+// NewRect instantiates a rectangle between the corners.
+// Corners should have the same dimensionality and min should not be larger than max on any axis, otherwise method panics.
+func NewRect(min []float64, max []float64) Rect {
+	if len(min) != len(max) {
+		panic(fmt.Sprintf("Corners %v and %v do not have the same dimensions", min, max))
+	}
+	for i := range min {
+		if min[i] > max[i] {
+			panic(fmt.Sprintf("Corner %v is larger than %v", min, max))
+		}
+	}
+	return Rect{Min: min, Max: max}
+}
+
+// NewPoint instantiates a rectangle of zero size at the point.
+func NewPoint(coordinates ...float64) Rect {
+	return Rect{Min: coordinates, Max: coordinates}
+}
+
+// Dimensions returns the dimensionality of the rectangle.
+func (rect Rect) Dimensions() int {
+	return len(rect.Min)
+}
+
+// Intersects returns true if the rectangles share at least one point.
+func (rect Rect) Intersects(other Rect) bool {
+	for i := range rect.Min {
+		if other.Max[i] < rect.Min[i] || other.Min[i] > rect.Max[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Contains returns true if the other rectangle lies completely within the rectangle.
+func (rect Rect) Contains(other Rect) bool {
+	for i := range rect.Min {
+		if other.Min[i] < rect.Min[i] || other.Max[i] > rect.Max[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Equal returns true if the rectangles have the same corners.
+func (rect Rect) Equal(other Rect) bool {
+	if len(rect.Min) != len(other.Min) {
+		return false
+	}
+	for i := range rect.Min {
+		if rect.Min[i] != other.Min[i] || rect.Max[i] != other.Max[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Area returns the product of the side lengths of the rectangle, its volume in more than two dimensions.
+func (rect Rect) Area() float64 {
+	area := 1.0
+	for i := range rect.Min {
+		area *= rect.Max[i] - rect.Min[i]
+	}
+	return area
+}
+
+// Union returns the smallest rectangle containing both rectangles.
+func (rect Rect) Union(other Rect) Rect {
+	union := Rect{Min: make([]float64, len(rect.Min)), Max: make([]float64, len(rect.Max))}
+	for i := range rect.Min {
+		union.Min[i] = math.Min(rect.Min[i], other.Min[i])
+		union.Max[i] = math.Max(rect.Max[i], other.Max[i])
+	}
+	return union
+}
+
+// Distance returns the Euclidean distance from the point to the closest point of the rectangle, zero if it is inside.
+func (rect Rect) Distance(point []float64) float64 {
+	sum := 0.0
+	for i, coordinate := range point {
+		var d float64
+		if coordinate < rect.Min[i] {
+			d = rect.Min[i] - coordinate
+		} else if coordinate > rect.Max[i] {
+			d = coordinate - rect.Max[i]
+		}
+		sum += d * d
+	}
+	return math.Sqrt(sum)
+}
+
+// String returns a string representation of the rectangle
+func (rect Rect) String() string {
+	return fmt.Sprintf("%v-%v", rect.Min, rect.Max)
+}
+
+// margin returns the sum of the side lengths of the rectangle.
+func (rect Rect) margin() float64 {
+	margin := 0.0
+	for i := range rect.Min {
+		margin += rect.Max[i] - rect.Min[i]
+	}
+	return margin
+}
+
+// enlargement returns the growth of the area and of the margin needed to include the other rectangle.
+// The margin tells apart rectangles of zero area, such as points.
+func (rect Rect) enlargement(other Rect) (area float64, margin float64) {
+	union := rect.Union(other)
+	return union.Area() - rect.Area(), union.margin() - rect.margin()
+}
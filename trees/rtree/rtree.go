@@ -0,0 +1,597 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package rtree implements an R-tree, a balanced search tree over axis-aligned bounding boxes in k dimensions.
+//
+// Every node holds the bounding box of its subtree, so that searches only descend into subtrees whose box can hold
+// a result. All leaves are on the same level and every node other than the root holds between the minimum and
+// maximum number of entries. Overflowing nodes are split with Guttman's quadratic split, underflowing nodes are
+// dissolved and their entries inserted again.
+//
+// BulkLoad packs entries into full nodes with the Sort-Tile-Recursive algorithm, which gives much better queries
+// than inserting the entries one by one.
+//
+// Structure is not thread safe.
+//
+// References: https://en.wikipedia.org/wiki/R-tree, https://en.wikipedia.org/wiki/R-tree#Bulk-loading
+package rtree
+
+import (
+	"fmt"
+	"github.com/dairongpeng/gds/trees"
+	"github.com/dairongpeng/gds/trees/binaryheap"
+	"math"
+	"sort"
+	"strings"
+)
+
+func assertTreeImplementation() {
+	var _ trees.Tree = (*Tree)(nil)
+}
+
+// DefaultMaxEntries is the maximum number of entries of a node used by New
+const DefaultMaxEntries = 16
+
+// Entry is a bounding box with its attached value
+type Entry struct {
+	Rect  Rect
+	Value interface{}
+}
+
+// Tree holds elements of the R-tree
+// Tree R树，每个节点保存其子树的最小外接矩形
+type Tree struct {
+	Root       *Node
+	dimensions int
+	minEntries int
+	maxEntries int
+	size       int
+	stats      stats // Counts the work done by the tree
+}
+
+// Node is a single element within the tree, either a leaf holding entries or an inner node holding children
+type Node struct {
+	Bounds   Rect // smallest rectangle containing the subtree, zero value in an empty root
+	Parent   *Node
+	Children []*Node // child nodes of an inner node
+	Entries  []Entry // entries of a leaf
+	leaf     bool
+}
+
+// New instantiates an R-tree of the given dimensionality with DefaultMaxEntries entries per node.
+func New(dimensions int) *Tree {
+	return NewWith(dimensions, DefaultMaxEntries)
+}
+
+// NewWith instantiates an R-tree of the given dimensionality with at most maxEntries entries per node.
+// Nodes are kept at least 40% full. MaxEntries should be at least 2, otherwise method panics.
+func NewWith(dimensions int, maxEntries int) *Tree {
+	if maxEntries < 2 {
+		panic("Invalid maximum number of entries, must be at least 2")
+	}
+	minEntries := maxEntries * 2 / 5
+	if minEntries < 1 {
+		minEntries = 1
+	}
+	return &Tree{Root: newLeaf(), dimensions: dimensions, minEntries: minEntries, maxEntries: maxEntries}
+}
+
+// Insert adds the rectangle with the attached value to the tree. Equal rectangles are kept as separate entries.
+// Rectangle should have the dimensionality of the tree, otherwise method panics.
+func (tree *Tree) Insert(rect Rect, value interface{}) {
+	tree.check(rect)
+	tree.insert(Entry{Rect: rect, Value: value})
+	tree.size++
+}
+
+// Delete removes one entry with an equal rectangle and value from the tree, if there is any.
+// Values are compared with ==, so they should be comparable, otherwise method panics.
+// Rectangle should have the dimensionality of the tree, otherwise method panics.
+func (tree *Tree) Delete(rect Rect, value interface{}) {
+	tree.check(rect)
+	leaf, index := tree.lookup(tree.Root, rect, value)
+	if leaf == nil {
+		return
+	}
+	leaf.Entries = append(leaf.Entries[:index], leaf.Entries[index+1:]...)
+	tree.condense(leaf)
+	tree.size--
+}
+
+// Search returns all entries whose rectangle intersects the rectangle, bounds included.
+// Rectangle should have the dimensionality of the tree, otherwise method panics.
+func (tree *Tree) Search(rect Rect) []Entry {
+	tree.check(rect)
+	entries := []Entry{}
+	if tree.size > 0 {
+		tree.search(tree.Root, rect, &entries)
+	}
+	return entries
+}
+
+// Contains returns all entries whose rectangle contains the rectangle, such as all fences around a point.
+// Rectangle should have the dimensionality of the tree, otherwise method panics.
+func (tree *Tree) Contains(rect Rect) []Entry {
+	tree.check(rect)
+	entries := []Entry{}
+	if tree.size > 0 {
+		tree.contains(tree.Root, rect, &entries)
+	}
+	return entries
+}
+
+// Nearest returns the k entries whose rectangles are closest to the point, from the closest to the farthest.
+// Entries containing the point have a distance of zero.
+// Point should have the dimensionality of the tree, otherwise method panics.
+// Nearest 按照距离从近到远返回k个元素，使用小根堆按最小距离依次展开节点
+func (tree *Tree) Nearest(point []float64, k int) []Entry {
+	tree.check(NewPoint(point...))
+	entries := []Entry{}
+	if k <= 0 || tree.size == 0 {
+		return entries
+	}
+	// best-first search, the distance to a node never exceeds the distance to anything in its subtree
+	heap := binaryheap.NewWith(func(a, b interface{}) int {
+		return compareDistance(a.(candidate).distance, b.(candidate).distance)
+	})
+	heap.Push(candidate{node: tree.Root})
+	for len(entries) < k {
+		value, ok := heap.Pop()
+		if !ok {
+			break
+		}
+		c := value.(candidate)
+		switch {
+		case c.node == nil:
+			entries = append(entries, c.entry)
+		case c.node.leaf:
+			for _, entry := range c.node.Entries {
+				heap.Push(candidate{entry: entry, distance: entry.Rect.Distance(point)})
+			}
+		default:
+			for _, child := range c.node.Children {
+				heap.Push(candidate{node: child, distance: child.Bounds.Distance(point)})
+			}
+		}
+	}
+	return entries
+}
+
+// BulkLoad replaces the content of the tree with the entries, packed into nodes with the Sort-Tile-Recursive algorithm.
+// Nodes are filled up to the maximum number of entries, only the last nodes of a level may hold fewer.
+// Rectangles should have the dimensionality of the tree, otherwise method panics.
+// BulkLoad 使用STR算法批量构建，按照各个坐标轴依次排序分片
+func (tree *Tree) BulkLoad(entries ...Entry) {
+	for _, entry := range entries {
+		tree.check(entry.Rect)
+	}
+	tree.Clear()
+	if len(entries) == 0 {
+		return
+	}
+	rects := make([]Rect, len(entries))
+	for i, entry := range entries {
+		rects[i] = entry.Rect
+	}
+	nodes := []*Node{}
+	for _, group := range tree.tile(rects, indices(len(rects)), 0, nil) {
+		leaf := newLeaf()
+		for _, i := range group {
+			leaf.Entries = append(leaf.Entries, entries[i])
+		}
+		leaf.updateBounds()
+		nodes = append(nodes, leaf)
+	}
+	for len(nodes) > 1 {
+		rects = make([]Rect, len(nodes))
+		for i, node := range nodes {
+			rects[i] = node.Bounds
+		}
+		parents := []*Node{}
+		for _, group := range tree.tile(rects, indices(len(rects)), 0, nil) {
+			parent := &Node{}
+			for _, i := range group {
+				nodes[i].Parent = parent
+				parent.Children = append(parent.Children, nodes[i])
+			}
+			parent.updateBounds()
+			parents = append(parents, parent)
+		}
+		nodes = parents
+	}
+	tree.Root = nodes[0]
+	tree.size = len(entries)
+}
+
+// Dimensions returns the dimensionality of the rectangles in the tree.
+func (tree *Tree) Dimensions() int {
+	return tree.dimensions
+}
+
+// Height returns the number of levels of the tree, one for a tree with a single leaf.
+func (tree *Tree) Height() int {
+	height := 1
+	for node := tree.Root; !node.leaf; node = node.Children[0] {
+		height++
+	}
+	return height
+}
+
+// Empty returns true if tree does not contain any entries
+func (tree *Tree) Empty() bool {
+	return tree.size == 0
+}
+
+// Size returns number of entries in the tree.
+func (tree *Tree) Size() int {
+	return tree.size
+}
+
+// Clear removes all entries from the tree.
+func (tree *Tree) Clear() {
+	tree.Root = newLeaf()
+	tree.size = 0
+}
+
+// Entries returns all entries, leaf by leaf from the left to the right.
+func (tree *Tree) Entries() []Entry {
+	entries := make([]Entry, 0, tree.size)
+	tree.Root.collect(&entries)
+	return entries
+}
+
+// Values returns the values of all entries, leaf by leaf from the left to the right.
+func (tree *Tree) Values() []interface{} {
+	values := make([]interface{}, 0, tree.size)
+	for _, entry := range tree.Entries() {
+		values = append(values, entry.Value)
+	}
+	return values
+}
+
+// String returns a string representation of container
+func (tree *Tree) String() string {
+	str := "RTree\n"
+	entries := []string{}
+	for _, entry := range tree.Entries() {
+		entries = append(entries, fmt.Sprintf("%v:%v", entry.Rect, entry.Value))
+	}
+	str += strings.Join(entries, ", ")
+	return str
+}
+
+// Leaf returns true if the node holds entries rather than child nodes.
+func (node *Node) Leaf() bool {
+	return node.leaf
+}
+
+type candidate struct {
+	node     *Node // nil for an entry
+	entry    Entry
+	distance float64
+}
+
+// insert adds the entry to the leaf whose bounds need the least enlargement and splits overflowing nodes up to the root.
+func (tree *Tree) insert(entry Entry) {
+	node := tree.Root
+	for !node.leaf {
+		best := node.Children[0]
+		bestArea, bestMargin := best.Bounds.enlargement(entry.Rect)
+		for _, child := range node.Children[1:] {
+			area, margin := child.Bounds.enlargement(entry.Rect)
+			if area < bestArea || (area == bestArea && (margin < bestMargin ||
+				(margin == bestMargin && child.Bounds.Area() < best.Bounds.Area()))) {
+				best, bestArea, bestMargin = child, area, margin
+			}
+		}
+		node = best
+	}
+	node.Entries = append(node.Entries, entry)
+	for ; node != nil; node = node.Parent {
+		if node.count() <= tree.maxEntries {
+			node.updateBounds()
+			continue
+		}
+		sibling := tree.split(node)
+		if node.Parent == nil {
+			root := &Node{Children: []*Node{node, sibling}}
+			node.Parent, sibling.Parent = root, root
+			tree.Root = root
+		} else {
+			node.Parent.Children = append(node.Parent.Children, sibling)
+		}
+	}
+}
+
+// split moves part of the entries of the overflowing node to a new sibling with the same parent.
+func (tree *Tree) split(node *Node) *Node {
+	tree.stats.splits++
+	first, second := tree.partition(node.rects())
+	sibling := &Node{Parent: node.Parent, leaf: node.leaf}
+	if node.leaf {
+		entries := node.Entries
+		node.Entries = make([]Entry, 0, len(first))
+		for _, i := range first {
+			node.Entries = append(node.Entries, entries[i])
+		}
+		for _, i := range second {
+			sibling.Entries = append(sibling.Entries, entries[i])
+		}
+	} else {
+		children := node.Children
+		node.Children = make([]*Node, 0, len(first))
+		for _, i := range first {
+			node.Children = append(node.Children, children[i])
+		}
+		for _, i := range second {
+			children[i].Parent = sibling
+			sibling.Children = append(sibling.Children, children[i])
+		}
+	}
+	node.updateBounds()
+	sibling.updateBounds()
+	return sibling
+}
+
+// partition divides the rectangles into two groups of at least the minimum size with Guttman's quadratic split.
+// The seeds are the two rectangles that would waste the most area in a common group, the other rectangles follow
+// one by one, the one with the strongest preference for a group first.
+func (tree *Tree) partition(rects []Rect) (first []int, second []int) {
+	seedFirst, seedSecond := 0, 1
+	worstArea, worstMargin := math.Inf(-1), math.Inf(-1)
+	for i := range rects {
+		for j := i + 1; j < len(rects); j++ {
+			union := rects[i].Union(rects[j])
+			area := union.Area() - rects[i].Area() - rects[j].Area()
+			margin := union.margin() - rects[i].margin() - rects[j].margin()
+			if area > worstArea || (area == worstArea && margin > worstMargin) {
+				seedFirst, seedSecond, worstArea, worstMargin = i, j, area, margin
+			}
+		}
+	}
+	first, second = []int{seedFirst}, []int{seedSecond}
+	boundsFirst, boundsSecond := rects[seedFirst], rects[seedSecond]
+	assigned := make([]bool, len(rects))
+	assigned[seedFirst], assigned[seedSecond] = true, true
+
+	for remaining := len(rects) - 2; remaining > 0; remaining-- {
+		// a group that needs all remaining rectangles to reach the minimum takes them
+		if len(first)+remaining == tree.minEntries || len(second)+remaining == tree.minEntries {
+			for i := range rects {
+				if assigned[i] {
+					continue
+				}
+				if len(first) < tree.minEntries {
+					first = append(first, i)
+				} else {
+					second = append(second, i)
+				}
+			}
+			break
+		}
+		next := -1
+		var preferenceArea, preferenceMargin float64
+		for i, rect := range rects {
+			if assigned[i] {
+				continue
+			}
+			areaFirst, marginFirst := boundsFirst.enlargement(rect)
+			areaSecond, marginSecond := boundsSecond.enlargement(rect)
+			area, margin := math.Abs(areaFirst-areaSecond), math.Abs(marginFirst-marginSecond)
+			if next == -1 || area > preferenceArea || (area == preferenceArea && margin > preferenceMargin) {
+				next, preferenceArea, preferenceMargin = i, area, margin
+			}
+		}
+		assigned[next] = true
+		areaFirst, marginFirst := boundsFirst.enlargement(rects[next])
+		areaSecond, marginSecond := boundsSecond.enlargement(rects[next])
+		var toFirst bool
+		switch {
+		case areaFirst != areaSecond:
+			toFirst = areaFirst < areaSecond
+		case marginFirst != marginSecond:
+			toFirst = marginFirst < marginSecond
+		case boundsFirst.Area() != boundsSecond.Area():
+			toFirst = boundsFirst.Area() < boundsSecond.Area()
+		default:
+			toFirst = len(first) <= len(second)
+		}
+		if toFirst {
+			first = append(first, next)
+			boundsFirst = boundsFirst.Union(rects[next])
+		} else {
+			second = append(second, next)
+			boundsSecond = boundsSecond.Union(rects[next])
+		}
+	}
+	return first, second
+}
+
+// lookup returns the leaf holding an entry with the rectangle and value and the index of the entry in the leaf.
+func (tree *Tree) lookup(node *Node, rect Rect, value interface{}) (*Node, int) {
+	if node.leaf {
+		for i, entry := range node.Entries {
+			if entry.Value == value && entry.Rect.Equal(rect) {
+				return node, i
+			}
+		}
+		return nil, -1
+	}
+	for _, child := range node.Children {
+		if child.Bounds.Contains(rect) {
+			if leaf, index := tree.lookup(child, rect, value); leaf != nil {
+				return leaf, index
+			}
+		}
+	}
+	return nil, -1
+}
+
+// condense walks up from the leaf that lost an entry, dissolves underflowing nodes and shrinks the bounds on the way.
+// The entries of dissolved nodes are inserted again, and a root with a single child is replaced by the child.
+func (tree *Tree) condense(node *Node) {
+	orphans := []Entry{}
+	for ; node.Parent != nil; node = node.Parent {
+		if node.count() >= tree.minEntries {
+			node.updateBounds()
+			continue
+		}
+		tree.stats.merges++
+		parent := node.Parent
+		for i, child := range parent.Children {
+			if child == node {
+				parent.Children = append(parent.Children[:i], parent.Children[i+1:]...)
+				break
+			}
+		}
+		node.collect(&orphans)
+	}
+	node.updateBounds()
+	for !tree.Root.leaf && len(tree.Root.Children) == 1 {
+		tree.Root = tree.Root.Children[0]
+		tree.Root.Parent = nil
+	}
+	if !tree.Root.leaf && len(tree.Root.Children) == 0 {
+		tree.Root = newLeaf()
+	}
+	for _, entry := range orphans {
+		tree.insert(entry)
+	}
+}
+
+func (tree *Tree) search(node *Node, rect Rect, entries *[]Entry) {
+	if node.leaf {
+		for _, entry := range node.Entries {
+			if entry.Rect.Intersects(rect) {
+				*entries = append(*entries, entry)
+			}
+		}
+		return
+	}
+	for _, child := range node.Children {
+		if child.Bounds.Intersects(rect) {
+			tree.search(child, rect, entries)
+		}
+	}
+}
+
+func (tree *Tree) contains(node *Node, rect Rect, entries *[]Entry) {
+	if node.leaf {
+		for _, entry := range node.Entries {
+			if entry.Rect.Contains(rect) {
+				*entries = append(*entries, entry)
+			}
+		}
+		return
+	}
+	for _, child := range node.Children {
+		if child.Bounds.Contains(rect) {
+			tree.contains(child, rect, entries)
+		}
+	}
+}
+
+// tile appends the groups of at most the maximum number of rectangles to groups. The rectangles are sorted by
+// their center on the axis and cut into slabs, which are tiled on the next axis, up to the last axis which is cut
+// into the groups.
+func (tree *Tree) tile(rects []Rect, order []int, axis int, groups [][]int) [][]int {
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := rects[order[i]], rects[order[j]]
+		return a.Min[axis]+a.Max[axis] < b.Min[axis]+b.Max[axis]
+	})
+	size := tree.maxEntries
+	if axis < tree.dimensions-1 {
+		// every slab holds the same number of nodes, about the root of the node count in the remaining axes
+		nodes := (len(order) + tree.maxEntries - 1) / tree.maxEntries
+		slabs := int(math.Ceil(math.Pow(float64(nodes), 1/float64(tree.dimensions-axis))))
+		size = tree.maxEntries * ((nodes + slabs - 1) / slabs)
+	}
+	for start := 0; start < len(order); start += size {
+		end := start + size
+		if end > len(order) {
+			end = len(order)
+		}
+		if axis < tree.dimensions-1 {
+			groups = tree.tile(rects, order[start:end], axis+1, groups)
+		} else {
+			groups = append(groups, order[start:end])
+		}
+	}
+	return groups
+}
+
+func (tree *Tree) check(rect Rect) {
+	if len(rect.Min) != tree.dimensions || len(rect.Max) != tree.dimensions {
+		panic(fmt.Sprintf("Rectangle %v does not have %d dimensions", rect, tree.dimensions))
+	}
+}
+
+func (node *Node) count() int {
+	if node.leaf {
+		return len(node.Entries)
+	}
+	return len(node.Children)
+}
+
+func (node *Node) rects() []Rect {
+	if node.leaf {
+		rects := make([]Rect, len(node.Entries))
+		for i, entry := range node.Entries {
+			rects[i] = entry.Rect
+		}
+		return rects
+	}
+	rects := make([]Rect, len(node.Children))
+	for i, child := range node.Children {
+		rects[i] = child.Bounds
+	}
+	return rects
+}
+
+func (node *Node) updateBounds() {
+	rects := node.rects()
+	if len(rects) == 0 {
+		node.Bounds = Rect{}
+		return
+	}
+	bounds := rects[0]
+	for _, rect := range rects[1:] {
+		bounds = bounds.Union(rect)
+	}
+	node.Bounds = bounds
+}
+
+// collect appends the entries of all leaves of the subtree.
+func (node *Node) collect(entries *[]Entry) {
+	if node.leaf {
+		*entries = append(*entries, node.Entries...)
+		return
+	}
+	for _, child := range node.Children {
+		child.collect(entries)
+	}
+}
+
+func newLeaf() *Node {
+	return &Node{leaf: true}
+}
+
+func indices(n int) []int {
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	return order
+}
+
+func compareDistance(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,401 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rtree
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+// assertValidTree checks the bounds, the parent links, the node sizes and that all leaves are on the same level.
+func assertValidTree(t *testing.T, tree *Tree, full bool) {
+	leafDepth := -1
+	var check func(node *Node, depth int) int
+	check = func(node *Node, depth int) int {
+		if node != tree.Root && node.count() > 0 {
+			if node.count() > tree.maxEntries || (full && node.count() < tree.minEntries) {
+				t.Errorf("Node has %v entries, expected between %v and %v", node.count(), tree.minEntries, tree.maxEntries)
+			}
+		}
+		rects := node.rects()
+		if len(rects) > 0 {
+			bounds := rects[0]
+			for _, rect := range rects[1:] {
+				bounds = bounds.Union(rect)
+			}
+			if !bounds.Equal(node.Bounds) {
+				t.Errorf("Got bounds %v expected %v", node.Bounds, bounds)
+			}
+		}
+		if node.Leaf() {
+			if leafDepth == -1 {
+				leafDepth = depth
+			} else if leafDepth != depth {
+				t.Errorf("Got leaf at depth %v expected %v", depth, leafDepth)
+			}
+			return len(node.Entries)
+		}
+		count := 0
+		for _, child := range node.Children {
+			if child.Parent != node {
+				t.Errorf("Child %v does not link to its parent", child.Bounds)
+			}
+			count += check(child, depth+1)
+		}
+		return count
+	}
+	if tree.Root.Parent != nil {
+		t.Errorf("Root has a parent")
+	}
+	if actualValue := check(tree.Root, 0); actualValue != tree.Size() {
+		t.Errorf("Got %v expected %v", actualValue, tree.Size())
+	}
+}
+
+func randomRect(r *rand.Rand, dimensions int, size float64) Rect {
+	min, max := make([]float64, dimensions), make([]float64, dimensions)
+	for i := range min {
+		min[i] = r.Float64() * 100
+		max[i] = min[i] + r.Float64()*size
+	}
+	return NewRect(min, max)
+}
+
+func randomEntries(r *rand.Rand, count int, dimensions int) []Entry {
+	entries := make([]Entry, count)
+	for i := range entries {
+		entries[i] = Entry{Rect: randomRect(r, dimensions, 10), Value: i}
+	}
+	return entries
+}
+
+func sortedValues(entries []Entry) []int {
+	values := make([]int, len(entries))
+	for i, entry := range entries {
+		values[i] = entry.Value.(int)
+	}
+	sort.Ints(values)
+	return values
+}
+
+func assertQueries(t *testing.T, r *rand.Rand, tree *Tree, entries []Entry) {
+	for i := 0; i < 50; i++ {
+		query := randomRect(r, tree.Dimensions(), 20)
+		intersecting, containing := []Entry{}, []Entry{}
+		for _, entry := range entries {
+			if entry.Rect.Intersects(query) {
+				intersecting = append(intersecting, entry)
+			}
+			if entry.Rect.Contains(NewPoint(query.Min...)) {
+				containing = append(containing, entry)
+			}
+		}
+		if actualValue, expectedValue := fmt.Sprint(sortedValues(tree.Search(query))), fmt.Sprint(sortedValues(intersecting)); actualValue != expectedValue {
+			t.Errorf("Got %v expected %v", actualValue, expectedValue)
+		}
+		if actualValue, expectedValue := fmt.Sprint(sortedValues(tree.Contains(NewPoint(query.Min...)))), fmt.Sprint(sortedValues(containing)); actualValue != expectedValue {
+			t.Errorf("Got %v expected %v", actualValue, expectedValue)
+		}
+	}
+}
+
+func TestRTreeInsertSearch(t *testing.T) {
+	tree := NewWith(2, 4)
+	tree.Insert(NewRect([]float64{0, 0}, []float64{10, 10}), "a")
+	tree.Insert(NewRect([]float64{5, 5}, []float64{15, 15}), "b")
+	tree.Insert(NewRect([]float64{20, 20}, []float64{30, 30}), "c")
+	tree.Insert(NewPoint(12, 12), "d")
+	tree.Insert(NewPoint(40, 0), "e")
+	tree.Insert(NewRect([]float64{-5, -5}, []float64{0, 0}), "f")
+	assertValidTree(t, tree, true)
+
+	if actualValue := tree.Size(); actualValue != 6 {
+		t.Errorf("Got %v expected %v", actualValue, 6)
+	}
+	if actualValue := tree.Height(); actualValue != 2 {
+		t.Errorf("Got %v expected %v", actualValue, 2)
+	}
+	tests := [][]interface{}{
+		{NewRect([]float64{9, 9}, []float64{11, 11}), "[a b]"},
+		{NewRect([]float64{10, 10}, []float64{20, 20}), "[a b c d]"},
+		{NewPoint(0, 0), "[a f]"},
+		{NewRect([]float64{31, 1}, []float64{39, 39}), "[]"},
+	}
+	for _, test := range tests {
+		values := []string{}
+		for _, entry := range tree.Search(test[0].(Rect)) {
+			values = append(values, entry.Value.(string))
+		}
+		sort.Strings(values)
+		if actualValue := fmt.Sprint(values); actualValue != test[1] {
+			t.Errorf("Got %v expected %v", actualValue, test[1])
+		}
+	}
+
+	contains := tree.Contains(NewRect([]float64{6, 6}, []float64{9, 9}))
+	if actualValue := len(contains); actualValue != 2 {
+		t.Errorf("Got %v expected %v", actualValue, 2)
+	}
+	if actualValue := len(tree.Contains(NewRect([]float64{6, 6}, []float64{11, 11}))); actualValue != 1 {
+		t.Errorf("Got %v expected %v", actualValue, 1)
+	}
+}
+
+func TestRTreeRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, dimensions := range []int{1, 2, 3} {
+		entries := randomEntries(r, 1000, dimensions)
+		tree := NewWith(dimensions, 8)
+		for _, entry := range entries {
+			tree.Insert(entry.Rect, entry.Value)
+		}
+		assertValidTree(t, tree, true)
+		assertQueries(t, r, tree, entries)
+	}
+}
+
+func TestRTreeDelete(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	entries := randomEntries(r, 1000, 2)
+	tree := NewWith(2, 6)
+	for _, entry := range entries {
+		tree.Insert(entry.Rect, entry.Value)
+	}
+	r.Shuffle(len(entries), func(i, j int) { entries[i], entries[j] = entries[j], entries[i] })
+	for _, entry := range entries[:700] {
+		tree.Delete(entry.Rect, entry.Value)
+	}
+	// not in tree
+	tree.Delete(entries[0].Rect, entries[0].Value)
+	tree.Delete(entries[800].Rect, -1)
+
+	assertValidTree(t, tree, true)
+	if actualValue := tree.Size(); actualValue != 300 {
+		t.Errorf("Got %v expected %v", actualValue, 300)
+	}
+	assertQueries(t, r, tree, entries[700:])
+
+	for _, entry := range entries[700:] {
+		tree.Delete(entry.Rect, entry.Value)
+	}
+	if actualValue := tree.Empty(); actualValue != true {
+		t.Errorf("Got %v expected %v", actualValue, true)
+	}
+	if actualValue := tree.Height(); actualValue != 1 {
+		t.Errorf("Got %v expected %v", actualValue, 1)
+	}
+	if actualValue := len(tree.Search(NewRect([]float64{0, 0}, []float64{100, 100}))); actualValue != 0 {
+		t.Errorf("Got %v expected %v", actualValue, 0)
+	}
+}
+
+func TestRTreeDeleteDuplicates(t *testing.T) {
+	tree := NewWith(2, 2)
+	for i := 0; i < 10; i++ {
+		tree.Insert(NewPoint(1, 1), i%2)
+	}
+	tree.Delete(NewPoint(1, 1), 0)
+	tree.Delete(NewPoint(1, 1), 0)
+	assertValidTree(t, tree, true)
+	if actualValue := fmt.Sprint(sortedValues(tree.Entries())); actualValue != "[0 0 0 1 1 1 1 1]" {
+		t.Errorf("Got %v expected %v", actualValue, "[0 0 0 1 1 1 1 1]")
+	}
+}
+
+func TestRTreeNearest(t *testing.T) {
+	tree := New(2)
+	if actualValue := len(tree.Nearest([]float64{0, 0}, 3)); actualValue != 0 {
+		t.Errorf("Got %v expected %v", actualValue, 0)
+	}
+	tree.Insert(NewRect([]float64{0, 0}, []float64{10, 10}), "a")
+	tree.Insert(NewPoint(13, 14), "b")
+	tree.Insert(NewRect([]float64{20, 0}, []float64{30, 5}), "c")
+	nearest := tree.Nearest([]float64{11, 3}, 3)
+	if actualValue, expectedValue := fmt.Sprintf("%v %v %v", nearest[0].Value, nearest[1].Value, nearest[2].Value), "a c b"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue := len(tree.Nearest([]float64{11, 3}, 0)); actualValue != 0 {
+		t.Errorf("Got %v expected %v", actualValue, 0)
+	}
+
+	r := rand.New(rand.NewSource(1))
+	entries := randomEntries(r, 1000, 3)
+	tree = NewWith(3, 8)
+	for _, entry := range entries {
+		tree.Insert(entry.Rect, entry.Value)
+	}
+	for i := 0; i < 50; i++ {
+		point := randomRect(r, 3, 0).Min
+		distances := make([]float64, len(entries))
+		for j, entry := range entries {
+			distances[j] = entry.Rect.Distance(point)
+		}
+		sort.Float64s(distances)
+		for j, entry := range tree.Nearest(point, 10) {
+			if actualValue := entry.Rect.Distance(point); actualValue != distances[j] {
+				t.Errorf("Got %v expected %v for neighbour %v", actualValue, distances[j], j)
+			}
+		}
+	}
+}
+
+func TestRTreeBulkLoad(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, dimensions := range []int{1, 2, 3} {
+		entries := randomEntries(r, 1000, dimensions)
+		tree := NewWith(dimensions, 10)
+		tree.Insert(NewPoint(make([]float64, dimensions)...), -1) // replaced by the bulk load
+		tree.BulkLoad(entries...)
+		assertValidTree(t, tree, false)
+		if actualValue := tree.Size(); actualValue != 1000 {
+			t.Errorf("Got %v expected %v", actualValue, 1000)
+		}
+		if actualValue := tree.Height(); actualValue != 3 {
+			t.Errorf("Got %v expected %v", actualValue, 3)
+		}
+		assertQueries(t, r, tree, entries)
+
+		// the packed tree keeps working with updates
+		for _, entry := range entries[:500] {
+			tree.Delete(entry.Rect, entry.Value)
+		}
+		for _, entry := range entries[:100] {
+			tree.Insert(entry.Rect, entry.Value)
+		}
+		assertValidTree(t, tree, false)
+		assertQueries(t, r, tree, append(entries[:100:100], entries[500:]...))
+	}
+
+	tree := New(2)
+	tree.BulkLoad()
+	if actualValue := tree.Empty(); actualValue != true {
+		t.Errorf("Got %v expected %v", actualValue, true)
+	}
+}
+
+func TestRTreeDimensionMismatch(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Expected panic for a rectangle of the wrong dimensionality")
+		}
+	}()
+	New(2).Insert(NewPoint(1, 2, 3), nil)
+}
+
+func TestRTreeInvalidRect(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Expected panic for a rectangle with min larger than max")
+		}
+	}()
+	NewRect([]float64{1, 2}, []float64{2, 1})
+}
+
+func TestRTreeClear(t *testing.T) {
+	tree := New(1)
+	tree.Insert(NewRect([]float64{1}, []float64{2}), 1)
+	if actualValue, expectedValue := tree.String(), "RTree\n[1]-[2]:1"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	tree.Clear()
+	if actualValue := tree.Empty(); actualValue != true {
+		t.Errorf("Got %v expected %v", actualValue, true)
+	}
+	if actualValue, expectedValue := tree.String(), "RTree\n"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+}
+
+func TestRTreeStats(t *testing.T) {
+	tree := NewWith(1, 4)
+	if actualValue, expectedValue := fmt.Sprintf("%+v", tree.Stats()), "{Height:0 Nodes:0 AverageDepth:0 Rotations:0 Splits:0 Merges:0 Comparisons:0}"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	for i := 0; i < 5; i++ {
+		tree.Insert(NewPoint(float64(i)), i)
+	}
+	if actualValue, expectedValue := fmt.Sprintf("%+v", tree.Stats()), "{Height:2 Nodes:3 AverageDepth:1 Rotations:0 Splits:1 Merges:0 Comparisons:0}"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	tree.Delete(NewPoint(3), 3)
+	tree.Delete(NewPoint(4), 4)
+	if actualValue, expectedValue := fmt.Sprintf("%+v", tree.Stats()), "{Height:1 Nodes:1 AverageDepth:0 Rotations:0 Splits:1 Merges:1 Comparisons:0}"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+}
+
+func benchmarkEntries(size int) []Entry {
+	r := rand.New(rand.NewSource(1))
+	entries := make([]Entry, size)
+	for i := range entries {
+		entries[i] = Entry{Rect: randomRect(r, 2, 1)}
+	}
+	return entries
+}
+
+func BenchmarkRTreeSearch100000(b *testing.B) {
+	b.StopTimer()
+	entries := benchmarkEntries(100000)
+	tree := New(2)
+	for _, entry := range entries {
+		tree.Insert(entry.Rect, entry.Value)
+	}
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		for n := 0; n < 1000; n++ {
+			tree.Contains(NewPoint(entries[n].Rect.Min...))
+		}
+	}
+}
+
+func BenchmarkRTreeBulkLoadedSearch100000(b *testing.B) {
+	b.StopTimer()
+	entries := benchmarkEntries(100000)
+	tree := New(2)
+	tree.BulkLoad(entries...)
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		for n := 0; n < 1000; n++ {
+			tree.Contains(NewPoint(entries[n].Rect.Min...))
+		}
+	}
+}
+
+func BenchmarkLinearScan100000(b *testing.B) {
+	b.StopTimer()
+	entries := benchmarkEntries(100000)
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		for n := 0; n < 10; n++ {
+			point := NewPoint(entries[n].Rect.Min...)
+			for _, entry := range entries {
+				entry.Rect.Contains(point)
+			}
+		}
+	}
+}
+
+func BenchmarkRTreeInsert100000(b *testing.B) {
+	b.StopTimer()
+	entries := benchmarkEntries(100000)
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		tree := New(2)
+		for _, entry := range entries {
+			tree.Insert(entry.Rect, entry.Value)
+		}
+	}
+}
+
+func BenchmarkRTreeBulkLoad100000(b *testing.B) {
+	b.StopTimer()
+	entries := benchmarkEntries(100000)
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		New(2).BulkLoad(entries...)
+	}
+}
@@ -0,0 +1,37 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rtree
+
+import "github.com/dairongpeng/gds/trees"
+
+// stats counts the work done by the tree since it was created.
+type stats struct {
+	splits int // overflowing nodes split by an insertion
+	merges int // underflowing nodes dissolved by a deletion, whose entries were inserted again
+}
+
+// Stats returns the shape of the tree and the number of nodes split and dissolved since it was created.
+// All entries are held by leaves at the same depth, which is the average depth. The tree does not rotate nodes
+// or use a comparator, so these counters stay zero. The shape is measured by walking the tree in O(n).
+func (tree *Tree) Stats() trees.Stats {
+	s := trees.Stats{
+		Splits: tree.stats.splits,
+		Merges: tree.stats.merges,
+	}
+	if tree.size == 0 {
+		return s
+	}
+	var walk func(node *Node)
+	walk = func(node *Node) {
+		s.Nodes++
+		for _, child := range node.Children {
+			walk(child)
+		}
+	}
+	walk(tree.Root)
+	s.Height = tree.Height()
+	s.AverageDepth = float64(s.Height - 1)
+	return s
+}
@@ -0,0 +1,44 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package segmenttree
+
+// Combine functions for elements of type int. Their identities are 0 for IntSum and IntGCD,
+// math.MaxInt for IntMin and math.MinInt for IntMax.
+
+// IntSum adds two ints.
+func IntSum(a, b interface{}) interface{} {
+	return a.(int) + b.(int)
+}
+
+// IntMin returns the smaller of two ints.
+func IntMin(a, b interface{}) interface{} {
+	if a.(int) < b.(int) {
+		return a
+	}
+	return b
+}
+
+// IntMax returns the larger of two ints.
+func IntMax(a, b interface{}) interface{} {
+	if a.(int) > b.(int) {
+		return a
+	}
+	return b
+}
+
+// IntGCD returns the greatest common divisor of two ints.
+func IntGCD(a, b interface{}) interface{} {
+	x, y := a.(int), b.(int)
+	if x < 0 {
+		x = -x
+	}
+	if y < 0 {
+		y = -y
+	}
+	for y != 0 {
+		x, y = y, x%y
+	}
+	return x
+}
@@ -0,0 +1,253 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package segmenttree implements a segment tree with lazy propagation over a fixed-size array.
+//
+// Aggregates of index ranges are computed with a user-supplied associative combine function and its identity
+// (sum, minimum, maximum, gcd, ...). Point updates and range queries take O(log n).
+// Trees created with NewWithLazy additionally support range updates in O(log n): updates are kept pending
+// at the highest nodes covering the range and only pushed towards the leaves when a query or update needs them.
+//
+// Ranges are inclusive on both ends, i.e. [from, to].
+//
+// Structure is not thread safe.
+//
+// Reference: https://en.wikipedia.org/wiki/Segment_tree
+package segmenttree
+
+import (
+	"fmt"
+	"github.com/dairongpeng/gds/trees"
+	"strings"
+)
+
+func assertTreeImplementation() {
+	var _ trees.Tree = (*Tree)(nil)
+}
+
+// CombineFunc merges the aggregates of two adjacent ranges. It must be associative.
+type CombineFunc func(a, b interface{}) interface{}
+
+// ApplyFunc returns the aggregate of a range of the given length after the update has been applied to each of its elements.
+// For example, adding a constant to every element changes a sum by update*length and a minimum by update.
+type ApplyFunc func(aggregate interface{}, update interface{}, length int) interface{}
+
+// ComposeFunc merges a newer update into an older pending one, so that applying the result
+// is the same as applying older and then newer.
+type ComposeFunc func(older, newer interface{}) interface{}
+
+// Tree holds the aggregates of the array's ranges in a complete binary tree stored in a slice.
+// Tree 线段树，节点i的左右孩子分别是2i和2i+1，每个节点保存其所覆盖区间的聚合值
+type Tree struct {
+	size       int
+	aggregates []interface{}
+	lazy       []interface{} // pending update for the children of a node
+	pending    []bool        // whether lazy holds an update
+	combine    CombineFunc
+	identity   interface{}
+	apply      ApplyFunc
+	compose    ComposeFunc
+}
+
+// New instantiates a segment tree over the values with the associative combine function and its identity element.
+// The tree supports point updates and range queries.
+func New(values []interface{}, combine CombineFunc, identity interface{}) *Tree {
+	tree := &Tree{combine: combine, identity: identity}
+	tree.build(values)
+	return tree
+}
+
+// NewWithLazy instantiates a segment tree over the values that additionally supports range updates,
+// which are described by the apply and compose functions.
+func NewWithLazy(values []interface{}, combine CombineFunc, identity interface{}, apply ApplyFunc, compose ComposeFunc) *Tree {
+	tree := &Tree{combine: combine, identity: identity, apply: apply, compose: compose}
+	tree.build(values)
+	return tree
+}
+
+// Get returns the element at index.
+// Second return parameter is true if index is within bounds of the array, otherwise false.
+func (tree *Tree) Get(index int) (interface{}, bool) {
+	return tree.Query(index, index)
+}
+
+// Set replaces the element at index with the value.
+// Does not do anything if index is out of bounds.
+func (tree *Tree) Set(index int, value interface{}) {
+	if !tree.withinRange(index) {
+		return
+	}
+	tree.set(1, 0, tree.size-1, index, value)
+}
+
+// Query returns the aggregate of the elements within [from, to].
+// Second return parameter is false, and the identity is returned, if the range is empty or out of bounds.
+// Query 查询区间[from, to]的聚合值
+func (tree *Tree) Query(from int, to int) (interface{}, bool) {
+	if !tree.withinRange(from) || !tree.withinRange(to) || from > to {
+		return tree.identity, false
+	}
+	return tree.query(1, 0, tree.size-1, from, to), true
+}
+
+// Update applies the update to every element within [from, to].
+// Does not do anything if the range is empty or out of bounds.
+// Panics if the tree was not instantiated with NewWithLazy.
+// Update 对区间[from, to]内的所有元素执行update，采用懒标记延迟下推
+func (tree *Tree) Update(from int, to int, update interface{}) {
+	if tree.apply == nil {
+		panic("Range updates require a tree instantiated with NewWithLazy")
+	}
+	if !tree.withinRange(from) || !tree.withinRange(to) || from > to {
+		return
+	}
+	tree.update(1, 0, tree.size-1, from, to, update)
+}
+
+// Empty returns true if the array does not contain any elements.
+func (tree *Tree) Empty() bool {
+	return tree.size == 0
+}
+
+// Size returns number of elements in the array.
+func (tree *Tree) Size() int {
+	return tree.size
+}
+
+// Clear removes all elements.
+func (tree *Tree) Clear() {
+	tree.build(nil)
+}
+
+// Values returns all elements of the array with pending updates applied.
+func (tree *Tree) Values() []interface{} {
+	values := make([]interface{}, tree.size)
+	if tree.size > 0 {
+		tree.collect(1, 0, tree.size-1, values)
+	}
+	return values
+}
+
+// String returns a string representation of container
+func (tree *Tree) String() string {
+	str := "SegmentTree\n"
+	values := []string{}
+	for _, value := range tree.Values() {
+		values = append(values, fmt.Sprintf("%v", value))
+	}
+	str += strings.Join(values, ", ")
+	return str
+}
+
+func (tree *Tree) build(values []interface{}) {
+	tree.size = len(values)
+	tree.aggregates = make([]interface{}, 4*tree.size)
+	if tree.apply != nil {
+		tree.lazy = make([]interface{}, 4*tree.size)
+		tree.pending = make([]bool, 4*tree.size)
+	}
+	if tree.size > 0 {
+		tree.buildNode(1, 0, tree.size-1, values)
+	}
+}
+
+func (tree *Tree) buildNode(node, left, right int, values []interface{}) {
+	if left == right {
+		tree.aggregates[node] = values[left]
+		return
+	}
+	middle := (left + right) / 2
+	tree.buildNode(2*node, left, middle, values)
+	tree.buildNode(2*node+1, middle+1, right, values)
+	tree.aggregates[node] = tree.combine(tree.aggregates[2*node], tree.aggregates[2*node+1])
+}
+
+func (tree *Tree) set(node, left, right, index int, value interface{}) {
+	if left == right {
+		tree.aggregates[node] = value
+		return
+	}
+	tree.push(node, left, right)
+	middle := (left + right) / 2
+	if index <= middle {
+		tree.set(2*node, left, middle, index, value)
+	} else {
+		tree.set(2*node+1, middle+1, right, index, value)
+	}
+	tree.aggregates[node] = tree.combine(tree.aggregates[2*node], tree.aggregates[2*node+1])
+}
+
+func (tree *Tree) query(node, left, right, from, to int) interface{} {
+	if from <= left && right <= to {
+		return tree.aggregates[node]
+	}
+	tree.push(node, left, right)
+	middle := (left + right) / 2
+	result := tree.identity
+	if from <= middle {
+		result = tree.combine(result, tree.query(2*node, left, middle, from, to))
+	}
+	if to > middle {
+		result = tree.combine(result, tree.query(2*node+1, middle+1, right, from, to))
+	}
+	return result
+}
+
+func (tree *Tree) update(node, left, right, from, to int, update interface{}) {
+	if from <= left && right <= to {
+		tree.applyTo(node, left, right, update)
+		return
+	}
+	tree.push(node, left, right)
+	middle := (left + right) / 2
+	if from <= middle {
+		tree.update(2*node, left, middle, from, to, update)
+	}
+	if to > middle {
+		tree.update(2*node+1, middle+1, right, from, to, update)
+	}
+	tree.aggregates[node] = tree.combine(tree.aggregates[2*node], tree.aggregates[2*node+1])
+}
+
+func (tree *Tree) collect(node, left, right int, values []interface{}) {
+	if left == right {
+		values[left] = tree.aggregates[node]
+		return
+	}
+	tree.push(node, left, right)
+	middle := (left + right) / 2
+	tree.collect(2*node, left, middle, values)
+	tree.collect(2*node+1, middle+1, right, values)
+}
+
+// applyTo applies the update to the aggregate of the node and records it as pending for its children.
+func (tree *Tree) applyTo(node, left, right int, update interface{}) {
+	tree.aggregates[node] = tree.apply(tree.aggregates[node], update, right-left+1)
+	if left == right {
+		return
+	}
+	if tree.pending[node] {
+		tree.lazy[node] = tree.compose(tree.lazy[node], update)
+	} else {
+		tree.lazy[node] = update
+		tree.pending[node] = true
+	}
+}
+
+// push hands the pending update of the node down to its children.
+func (tree *Tree) push(node, left, right int) {
+	if tree.pending == nil || !tree.pending[node] {
+		return
+	}
+	middle := (left + right) / 2
+	tree.applyTo(2*node, left, middle, tree.lazy[node])
+	tree.applyTo(2*node+1, middle+1, right, tree.lazy[node])
+	tree.lazy[node] = nil
+	tree.pending[node] = false
+}
+
+// Check that the index is within bounds of the array
+func (tree *Tree) withinRange(index int) bool {
+	return index >= 0 && index < tree.size
+}
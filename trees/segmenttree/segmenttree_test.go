@@ -0,0 +1,254 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package segmenttree
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func addToSum(aggregate interface{}, update interface{}, length int) interface{} {
+	return aggregate.(int) + update.(int)*length
+}
+
+func addToMin(aggregate interface{}, update interface{}, length int) interface{} {
+	return aggregate.(int) + update.(int)
+}
+
+func addCompose(older, newer interface{}) interface{} {
+	return older.(int) + newer.(int)
+}
+
+func assignToMin(aggregate interface{}, update interface{}, length int) interface{} {
+	return update
+}
+
+func assignCompose(older, newer interface{}) interface{} {
+	return newer
+}
+
+func ints(values ...int) []interface{} {
+	result := make([]interface{}, len(values))
+	for i, value := range values {
+		result[i] = value
+	}
+	return result
+}
+
+func TestSegmentTreeQuery(t *testing.T) {
+	tree := New(ints(5, 3, 8, 6, 1, 4), IntSum, 0)
+
+	if actualValue := tree.Size(); actualValue != 6 {
+		t.Errorf("Got %v expected %v", actualValue, 6)
+	}
+	tests := [][]interface{}{
+		{0, 5, 27, true},
+		{1, 3, 17, true},
+		{4, 4, 1, true},
+		{3, 2, 0, false},
+		{-1, 2, 0, false},
+		{0, 6, 0, false},
+	}
+	for _, test := range tests {
+		actualValue, ok := tree.Query(test[0].(int), test[1].(int))
+		if actualValue != test[2] || ok != test[3] {
+			t.Errorf("Got %v,%v expected %v,%v", actualValue, ok, test[2], test[3])
+		}
+	}
+
+	tree.Set(2, 10)
+	tree.Set(6, 10) // out of range
+	if actualValue, _ := tree.Query(1, 3); actualValue != 19 {
+		t.Errorf("Got %v expected %v", actualValue, 19)
+	}
+	if actualValue, ok := tree.Get(2); actualValue != 10 || !ok {
+		t.Errorf("Got %v expected %v", actualValue, 10)
+	}
+	if actualValue, expectedValue := fmt.Sprintf("%v", tree.Values()), "[5 3 10 6 1 4]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+}
+
+func TestSegmentTreeMinMaxGCD(t *testing.T) {
+	values := ints(12, 18, 6, 30, 24)
+	min := New(values, IntMin, math.MaxInt)
+	max := New(values, IntMax, math.MinInt)
+	gcd := New(values, IntGCD, 0)
+
+	if actualValue, _ := min.Query(0, 1); actualValue != 12 {
+		t.Errorf("Got %v expected %v", actualValue, 12)
+	}
+	if actualValue, _ := max.Query(1, 4); actualValue != 30 {
+		t.Errorf("Got %v expected %v", actualValue, 30)
+	}
+	if actualValue, _ := gcd.Query(0, 1); actualValue != 6 {
+		t.Errorf("Got %v expected %v", actualValue, 6)
+	}
+	if actualValue, _ := gcd.Query(3, 4); actualValue != 6 {
+		t.Errorf("Got %v expected %v", actualValue, 6)
+	}
+}
+
+func TestSegmentTreeUpdate(t *testing.T) {
+	tree := NewWithLazy(ints(1, 2, 3, 4, 5), IntSum, 0, addToSum, addCompose)
+	tree.Update(1, 3, 10)
+	tree.Update(0, 1, 1)
+	tree.Update(4, 2, 100) // empty range
+
+	if actualValue, _ := tree.Query(0, 4); actualValue != 47 {
+		t.Errorf("Got %v expected %v", actualValue, 47)
+	}
+	if actualValue, _ := tree.Query(1, 1); actualValue != 13 {
+		t.Errorf("Got %v expected %v", actualValue, 13)
+	}
+	tree.Set(1, 0)
+	if actualValue, expectedValue := fmt.Sprintf("%v", tree.Values()), "[2 0 13 14 5]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+}
+
+func TestSegmentTreeUpdateWithoutLazy(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Expected panic for a range update without lazy functions")
+		}
+	}()
+	New(ints(1, 2), IntSum, 0).Update(0, 1, 1)
+}
+
+func TestSegmentTreeRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	size := 200
+	expected := make([]int, size)
+	for i := range expected {
+		expected[i] = r.Intn(100)
+	}
+	sum := NewWithLazy(ints(expected...), IntSum, 0, addToSum, addCompose)
+	min := NewWithLazy(ints(expected...), IntMin, math.MaxInt, addToMin, addCompose)
+	assign := NewWithLazy(ints(expected...), IntMin, math.MaxInt, assignToMin, assignCompose)
+	assigned := append([]int(nil), expected...)
+
+	for i := 0; i < 2000; i++ {
+		from := r.Intn(size)
+		to := from + r.Intn(size-from)
+		switch r.Intn(3) {
+		case 0:
+			delta := r.Intn(21) - 10
+			sum.Update(from, to, delta)
+			min.Update(from, to, delta)
+			for j := from; j <= to; j++ {
+				expected[j] += delta
+			}
+			value := r.Intn(100)
+			assign.Update(from, to, value)
+			for j := from; j <= to; j++ {
+				assigned[j] = value
+			}
+		case 1:
+			value := r.Intn(100)
+			sum.Set(from, value)
+			min.Set(from, value)
+			expected[from] = value
+		default:
+			expectedSum, expectedMin, expectedAssigned := 0, math.MaxInt, math.MaxInt
+			for j := from; j <= to; j++ {
+				expectedSum += expected[j]
+				if expected[j] < expectedMin {
+					expectedMin = expected[j]
+				}
+				if assigned[j] < expectedAssigned {
+					expectedAssigned = assigned[j]
+				}
+			}
+			if actualValue, _ := sum.Query(from, to); actualValue != expectedSum {
+				t.Errorf("Got %v expected %v for sum of [%v,%v]", actualValue, expectedSum, from, to)
+			}
+			if actualValue, _ := min.Query(from, to); actualValue != expectedMin {
+				t.Errorf("Got %v expected %v for min of [%v,%v]", actualValue, expectedMin, from, to)
+			}
+			if actualValue, _ := assign.Query(from, to); actualValue != expectedAssigned {
+				t.Errorf("Got %v expected %v for min of [%v,%v]", actualValue, expectedAssigned, from, to)
+			}
+		}
+	}
+	if actualValue, expectedValue := fmt.Sprintf("%v", sum.Values()), fmt.Sprintf("%v", expected); actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+}
+
+func TestSegmentTreeClear(t *testing.T) {
+	tree := NewWithLazy(ints(1, 2, 3), IntSum, 0, addToSum, addCompose)
+	tree.Clear()
+	if actualValue := tree.Empty(); actualValue != true {
+		t.Errorf("Got %v expected %v", actualValue, true)
+	}
+	if actualValue, ok := tree.Query(0, 0); actualValue != 0 || ok {
+		t.Errorf("Got %v,%v expected %v,%v", actualValue, ok, 0, false)
+	}
+}
+
+func TestSegmentTreeSerialization(t *testing.T) {
+	floatSum := func(a, b interface{}) interface{} { return a.(float64) + b.(float64) }
+	tree := New([]interface{}{1.0, 2.0, 3.5}, floatSum, 0.0)
+
+	json, err := tree.ToJSON()
+	if err != nil {
+		t.Errorf("Got error %v", err)
+	}
+	if actualValue, expectedValue := string(json), "[1,2,3.5]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if err := tree.FromJSON([]byte("[4,5]")); err != nil {
+		t.Errorf("Got error %v", err)
+	}
+	if actualValue, _ := tree.Query(0, 1); actualValue != 9.0 {
+		t.Errorf("Got %v expected %v", actualValue, 9.0)
+	}
+}
+
+func TestSegmentTreeStats(t *testing.T) {
+	tree := New(ints(), IntSum, 0)
+	if actualValue, expectedValue := fmt.Sprintf("%+v", tree.Stats()), "{Height:0 Nodes:0 AverageDepth:0 Rotations:0 Splits:0 Merges:0 Comparisons:0}"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	tree = New(ints(5, 3, 8, 6, 1), IntSum, 0)
+	if actualValue, expectedValue := fmt.Sprintf("%+v", tree.Stats()), "{Height:4 Nodes:9 AverageDepth:2.4 Rotations:0 Splits:0 Merges:0 Comparisons:0}"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+}
+
+func benchmarkQuery(b *testing.B, tree *Tree, size int) {
+	for i := 0; i < b.N; i++ {
+		for n := 0; n < size; n++ {
+			tree.Query(n/2, n)
+		}
+	}
+}
+
+func benchmarkUpdate(b *testing.B, tree *Tree, size int) {
+	for i := 0; i < b.N; i++ {
+		for n := 0; n < size; n++ {
+			tree.Update(n/2, n, 1)
+		}
+	}
+}
+
+func BenchmarkSegmentTreeQuery10000(b *testing.B) {
+	b.StopTimer()
+	size := 10000
+	tree := New(ints(make([]int, size)...), IntSum, 0)
+	b.StartTimer()
+	benchmarkQuery(b, tree, size)
+}
+
+func BenchmarkSegmentTreeUpdate10000(b *testing.B) {
+	b.StopTimer()
+	size := 10000
+	tree := NewWithLazy(ints(make([]int, size)...), IntSum, 0, addToSum, addCompose)
+	b.StartTimer()
+	benchmarkUpdate(b, tree, size)
+}
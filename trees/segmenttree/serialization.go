@@ -0,0 +1,31 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package segmenttree
+
+import (
+	"encoding/json"
+	"github.com/dairongpeng/gds/containers"
+)
+
+func assertSerializationImplementation() {
+	var _ containers.JSONSerializer = (*Tree)(nil)
+	var _ containers.JSONDeserializer = (*Tree)(nil)
+}
+
+// ToJSON outputs the JSON representation of the array's elements.
+func (tree *Tree) ToJSON() ([]byte, error) {
+	return json.Marshal(tree.Values())
+}
+
+// FromJSON rebuilds the tree over the elements of the input JSON representation, keeping its functions.
+// Decoded elements must suit the combine function (e.g. numbers are decoded as float64).
+func (tree *Tree) FromJSON(data []byte) error {
+	var values []interface{}
+	err := json.Unmarshal(data, &values)
+	if err == nil {
+		tree.build(values)
+	}
+	return err
+}
@@ -0,0 +1,35 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package segmenttree
+
+import "github.com/dairongpeng/gds/trees"
+
+// Stats returns the shape of the tree of segments laid out in the array, whose leaves hold the values.
+// The tree has a fixed shape and does not use a comparator, so the counters stay zero.
+// The shape is measured by walking the segments in O(n).
+func (tree *Tree) Stats() trees.Stats {
+	var s trees.Stats
+	if tree.size == 0 {
+		return s
+	}
+	depths := 0
+	var walk func(left, right, depth int)
+	walk = func(left, right, depth int) {
+		s.Nodes++
+		if depth+1 > s.Height {
+			s.Height = depth + 1
+		}
+		if left == right {
+			depths += depth
+			return
+		}
+		middle := (left + right) / 2
+		walk(left, middle, depth+1)
+		walk(middle+1, right, depth+1)
+	}
+	walk(0, tree.size-1, 0)
+	s.AverageDepth = float64(depths) / float64(tree.size)
+	return s
+}
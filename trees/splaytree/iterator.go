@@ -0,0 +1,246 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package splaytree
+
+import "github.com/dairongpeng/gds/containers"
+
+func assertIteratorImplementation() {
+	var _ containers.ReverseIteratorWithKey = (*Iterator)(nil)
+	var _ containers.IteratorWithRemove = (*Iterator)(nil)
+}
+
+// Iterator holding the iterator's state.
+// Nodes have no parent pointers, so the iterator keeps the path from the root to the current node.
+// The iterator does not splay. After a lookup splayed the tree, the iterator finds its position again from the key
+// of its current node, while moving it after an insertion or removal panics with containers.ErrConcurrentModification.
+type Iterator struct {
+	tree     *Tree
+	path     []*Node // ancestors of the current node, starting with the root
+	node     *Node
+	position position
+	modCount int
+	splays   int  // restructurings of the tree the path was recorded after
+	removed  bool // node was removed, its key finds its neighbours
+}
+
+type position byte
+
+const (
+	begin, between, end position = 0, 1, 2
+)
+
+// Iterator returns a stateful iterator whose elements are key/value pairs.
+func (tree *Tree) Iterator() *Iterator {
+	return &Iterator{tree: tree, node: nil, position: begin, modCount: tree.modCount, splays: tree.splays}
+}
+
+// Next moves the iterator to the next element and returns true if there was a next element in the container.
+// If Next() returns true, then next element's key and value can be retrieved by Key() and Value().
+// If Next() was called for the first time, then it will point the iterator to the first element if it exists.
+// Modifies the state of the iterator.
+func (iterator *Iterator) Next() bool {
+	iterator.checkModification()
+	if iterator.removed || iterator.position == between && iterator.splays != iterator.tree.splays {
+		return iterator.seek(iterator.node.Key, true)
+	}
+	iterator.splays = iterator.tree.splays
+	switch iterator.position {
+	case begin:
+		iterator.position = between
+		iterator.path = iterator.path[:0]
+		iterator.node = iterator.tree.Root
+		iterator.descend(false)
+	case between:
+		if iterator.node.Right != nil {
+			iterator.path = append(iterator.path, iterator.node)
+			iterator.node = iterator.node.Right
+			iterator.descend(false)
+		} else {
+			iterator.ascend(false)
+		}
+	}
+
+	if iterator.node == nil {
+		iterator.position = end
+		return false
+	}
+	return true
+}
+
+// Prev moves the iterator to the previous element and returns true if there was a previous element in the container.
+// If Prev() returns true, then previous element's key and value can be retrieved by Key() and Value().
+// Modifies the state of the iterator.
+func (iterator *Iterator) Prev() bool {
+	iterator.checkModification()
+	if iterator.removed || iterator.position == between && iterator.splays != iterator.tree.splays {
+		return iterator.seek(iterator.node.Key, false)
+	}
+	iterator.splays = iterator.tree.splays
+	switch iterator.position {
+	case end:
+		iterator.position = between
+		iterator.path = iterator.path[:0]
+		iterator.node = iterator.tree.Root
+		iterator.descend(true)
+	case between:
+		if iterator.node.Left != nil {
+			iterator.path = append(iterator.path, iterator.node)
+			iterator.node = iterator.node.Left
+			iterator.descend(true)
+		} else {
+			iterator.ascend(true)
+		}
+	}
+
+	if iterator.node == nil {
+		iterator.position = begin
+		return false
+	}
+	return true
+}
+
+// Value returns the current element's value.
+// Does not modify the state of the iterator.
+func (iterator *Iterator) Value() interface{} {
+	if iterator.node == nil {
+		return nil
+	}
+	return iterator.node.Value
+}
+
+// Key returns the current element's key.
+// Does not modify the state of the iterator.
+func (iterator *Iterator) Key() interface{} {
+	if iterator.node == nil {
+		return nil
+	}
+	return iterator.node.Key
+}
+
+// Begin resets the iterator to its initial state (one-before-first)
+// Call Next() to fetch the first element if any.
+func (iterator *Iterator) Begin() {
+	iterator.node = nil
+	iterator.path = iterator.path[:0]
+	iterator.position = begin
+	iterator.removed = false
+	iterator.modCount = iterator.tree.modCount
+}
+
+// End moves the iterator past the last element (one-past-the-end).
+// Call Prev() to fetch the last element if any.
+func (iterator *Iterator) End() {
+	iterator.node = nil
+	iterator.path = iterator.path[:0]
+	iterator.position = end
+	iterator.removed = false
+	iterator.modCount = iterator.tree.modCount
+}
+
+// First moves the iterator to the first element and returns true if there was a first element in the container.
+// If First() returns true, then first element's key and value can be retrieved by Key() and Value().
+// Modifies the state of the iterator
+func (iterator *Iterator) First() bool {
+	iterator.Begin()
+	return iterator.Next()
+}
+
+// Last moves the iterator to the last element and returns true if there was a last element in the container.
+// If Last() returns true, then last element's key and value can be retrieved by Key() and Value().
+// Modifies the state of the iterator.
+func (iterator *Iterator) Last() bool {
+	iterator.End()
+	return iterator.Prev()
+}
+
+// descend moves to the left-most (or right-most if reverse) node of the current subtree, recording the path.
+func (iterator *Iterator) descend(reverse bool) {
+	if iterator.node == nil {
+		return
+	}
+	for {
+		child := iterator.node.Left
+		if reverse {
+			child = iterator.node.Right
+		}
+		if child == nil {
+			return
+		}
+		iterator.path = append(iterator.path, iterator.node)
+		iterator.node = child
+	}
+}
+
+// ascend moves up to the first ancestor whose left (or right if reverse) subtree holds the current node.
+func (iterator *Iterator) ascend(reverse bool) {
+	child := iterator.node
+	for len(iterator.path) > 0 {
+		parent := iterator.path[len(iterator.path)-1]
+		iterator.path = iterator.path[:len(iterator.path)-1]
+		if (!reverse && parent.Left == child) || (reverse && parent.Right == child) {
+			iterator.node = parent
+			return
+		}
+		child = parent
+	}
+	iterator.node = nil
+}
+
+// Remove removes the current node from the tree.
+// Next() moves the iterator to the node after the removed one and Prev() to the node before it,
+// until then Key() and Value() return the removed node.
+// Panics with containers.ErrIteratorNotOnElement if the iterator is not on a node.
+func (iterator *Iterator) Remove() {
+	iterator.checkModification()
+	if iterator.removed || iterator.position != between {
+		panic(containers.ErrIteratorNotOnElement)
+	}
+	iterator.tree.Remove(iterator.node.Key)
+	iterator.modCount = iterator.tree.modCount
+	iterator.removed = true
+}
+
+// seek moves the iterator to the first node after the key (forward) or to the last node before the key, recording the path.
+// Lookups and removals splay the tree, so the neighbours of the current or removed node are searched from the root.
+func (iterator *Iterator) seek(key interface{}, forward bool) bool {
+	iterator.removed = false
+	iterator.splays = iterator.tree.splays
+	iterator.path = iterator.path[:0]
+	iterator.node = nil
+	depth := 0
+	for n := iterator.tree.Root; n != nil; {
+		compare := iterator.tree.compare(key, n.Key)
+		if forward && compare < 0 || !forward && compare > 0 {
+			iterator.node, depth = n, len(iterator.path)
+		}
+		iterator.path = append(iterator.path, n)
+		if compare < 0 || compare == 0 && !forward {
+			n = n.Left
+		} else {
+			n = n.Right
+		}
+	}
+	iterator.path = iterator.path[:depth]
+	if iterator.node == nil {
+		if forward {
+			iterator.position = end
+		} else {
+			iterator.position = begin
+		}
+		return false
+	}
+	iterator.position = between
+	return true
+}
+
+// checkModification panics if the tree was structurally modified other than through the iterator.
+func (iterator *Iterator) checkModification() {
+	if iterator.modCount != iterator.tree.modCount {
+		if iterator.position != begin {
+			panic(containers.ErrConcurrentModification)
+		}
+		iterator.modCount = iterator.tree.modCount
+	}
+}
@@ -0,0 +1,344 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package splaytree implements a splay tree, a self-adjusting binary search tree.
+//
+// Every access moves the accessed node to the root using top-down splaying, so recently accessed keys are
+// found quickly. Operations take O(log n) amortized time, and workloads that repeatedly access a small set of
+// keys are considerably faster than with a red-black or AVL tree.
+//
+// Note that lookups (Get, Floor, Ceiling) restructure the tree.
+//
+// Structure is not thread safe.
+//
+// References: https://en.wikipedia.org/wiki/Splay_tree
+package splaytree
+
+import (
+	"fmt"
+	"github.com/dairongpeng/gds/trees"
+	"github.com/dairongpeng/gds/utils"
+)
+
+func assertTreeImplementation() {
+	var _ trees.Tree = (*Tree)(nil)
+}
+
+// Tree holds elements of the splay tree
+// Tree 伸展树，每次访问都会把被访问的节点旋转到根节点
+type Tree struct {
+	Root       *Node            // Root node
+	Comparator utils.Comparator // Key comparator
+	size       int              // Total number of keys in the tree
+	modCount   int              // Number of inserted and removed keys, checked by iterators
+	splays     int              // Number of restructurings, iterators find their position again after one
+	stats      stats            // Counts the work done by the tree
+}
+
+// Node is a single element within the tree
+type Node struct {
+	Key   interface{}
+	Value interface{}
+	Left  *Node
+	Right *Node
+}
+
+// NewWith instantiates a splay tree with the custom comparator.
+func NewWith(comparator utils.Comparator) *Tree {
+	return &Tree{Comparator: comparator}
+}
+
+// NewWithIntComparator instantiates a splay tree with the IntComparator, i.e. keys are of type int.
+func NewWithIntComparator() *Tree {
+	return &Tree{Comparator: utils.IntComparator}
+}
+
+// NewWithStringComparator instantiates a splay tree with the StringComparator, i.e. keys are of type string.
+func NewWithStringComparator() *Tree {
+	return &Tree{Comparator: utils.StringComparator}
+}
+
+// Put inserts node into the tree and makes it the root.
+// Key should adhere to the comparator's type assertion, otherwise method panics.
+func (tree *Tree) Put(key interface{}, value interface{}) {
+	if tree.Root == nil {
+		tree.Root = &Node{Key: key, Value: value}
+		tree.size++
+		tree.modCount++
+		return
+	}
+	tree.splay(key)
+	compare := tree.compare(key, tree.Root.Key)
+	if compare == 0 {
+		tree.Root.Key = key
+		tree.Root.Value = value
+		return
+	}
+	node := &Node{Key: key, Value: value}
+	if compare < 0 {
+		node.Left = tree.Root.Left
+		node.Right = tree.Root
+		tree.Root.Left = nil
+	} else {
+		node.Right = tree.Root.Right
+		node.Left = tree.Root
+		tree.Root.Right = nil
+	}
+	tree.Root = node
+	tree.size++
+	tree.modCount++
+}
+
+// Get searches the node in the tree by key and returns its value or nil if key is not found in tree.
+// Second return parameter is true if key was found, otherwise false.
+// Splays the last node on the search path to the root.
+// Key should adhere to the comparator's type assertion, otherwise method panics.
+func (tree *Tree) Get(key interface{}) (value interface{}, found bool) {
+	if tree.Root == nil {
+		return nil, false
+	}
+	tree.splay(key)
+	if tree.compare(key, tree.Root.Key) == 0 {
+		return tree.Root.Value, true
+	}
+	return nil, false
+}
+
+// Remove remove the node from the tree by key.
+// Key should adhere to the comparator's type assertion, otherwise method panics.
+func (tree *Tree) Remove(key interface{}) {
+	if tree.Root == nil {
+		return
+	}
+	tree.splay(key)
+	if tree.compare(key, tree.Root.Key) != 0 {
+		return
+	}
+	if tree.Root.Left == nil {
+		tree.Root = tree.Root.Right
+	} else {
+		right := tree.Root.Right
+		tree.Root = tree.Root.Left
+		// key is larger than every key in the left subtree, so its maximum becomes the root without a right child
+		tree.splay(key)
+		tree.Root.Right = right
+	}
+	tree.size--
+	tree.modCount++
+}
+
+// Empty returns true if tree does not contain any nodes
+func (tree *Tree) Empty() bool {
+	return tree.size == 0
+}
+
+// Size returns number of nodes in the tree.
+func (tree *Tree) Size() int {
+	return tree.size
+}
+
+// Keys returns all keys in-order
+func (tree *Tree) Keys() []interface{} {
+	keys := make([]interface{}, tree.size)
+	it := tree.Iterator()
+	for i := 0; it.Next(); i++ {
+		keys[i] = it.Key()
+	}
+	return keys
+}
+
+// Values returns all values in-order based on the key.
+func (tree *Tree) Values() []interface{} {
+	values := make([]interface{}, tree.size)
+	it := tree.Iterator()
+	for i := 0; it.Next(); i++ {
+		values[i] = it.Value()
+	}
+	return values
+}
+
+// Left returns the left-most (min) node or nil if tree is empty.
+// Does not restructure the tree.
+func (tree *Tree) Left() *Node {
+	var parent *Node
+	current := tree.Root
+	for current != nil {
+		parent = current
+		current = current.Left
+	}
+	return parent
+}
+
+// Right returns the right-most (max) node or nil if tree is empty.
+// Does not restructure the tree.
+func (tree *Tree) Right() *Node {
+	var parent *Node
+	current := tree.Root
+	for current != nil {
+		parent = current
+		current = current.Right
+	}
+	return parent
+}
+
+// Floor Finds floor node of the input key, return the floor node or nil if no floor is found.
+// Second return parameter is true if floor was found, otherwise false.
+//
+// Floor node is defined as the largest node that is smaller than or equal to the given node.
+// A floor node may not be found, either because the tree is empty, or because
+// all nodes in the tree are larger than the given node.
+//
+// Key should adhere to the comparator's type assertion, otherwise method panics.
+func (tree *Tree) Floor(key interface{}) (floor *Node, found bool) {
+	if tree.Root == nil {
+		return nil, false
+	}
+	tree.splay(key)
+	if tree.compare(key, tree.Root.Key) >= 0 {
+		return tree.Root, true
+	}
+	// the root is the smallest key larger than key, so the floor is the maximum of its left subtree
+	if floor = tree.Root.Left; floor == nil {
+		return nil, false
+	}
+	for floor.Right != nil {
+		floor = floor.Right
+	}
+	return floor, true
+}
+
+// Ceiling finds ceiling node of the input key, return the ceiling node or nil if no ceiling is found.
+// Second return parameter is true if ceiling was found, otherwise false.
+//
+// Ceiling node is defined as the smallest node that is larger than or equal to the given node.
+// A ceiling node may not be found, either because the tree is empty, or because
+// all nodes in the tree are smaller than the given node.
+//
+// Key should adhere to the comparator's type assertion, otherwise method panics.
+func (tree *Tree) Ceiling(key interface{}) (ceiling *Node, found bool) {
+	if tree.Root == nil {
+		return nil, false
+	}
+	tree.splay(key)
+	if tree.compare(key, tree.Root.Key) <= 0 {
+		return tree.Root, true
+	}
+	// the root is the largest key smaller than key, so the ceiling is the minimum of its right subtree
+	if ceiling = tree.Root.Right; ceiling == nil {
+		return nil, false
+	}
+	for ceiling.Left != nil {
+		ceiling = ceiling.Left
+	}
+	return ceiling, true
+}
+
+// Clear removes all nodes from the tree.
+func (tree *Tree) Clear() {
+	tree.Root = nil
+	tree.size = 0
+	tree.modCount++
+}
+
+// String returns a string representation of container
+func (tree *Tree) String() string {
+	str := "SplayTree\n"
+	if !tree.Empty() {
+		output(tree.Root, "", true, &str)
+	}
+	return str
+}
+
+func (node *Node) String() string {
+	return fmt.Sprintf("%v", node.Key)
+}
+
+func output(node *Node, prefix string, isTail bool, str *string) {
+	if node.Right != nil {
+		newPrefix := prefix
+		if isTail {
+			newPrefix += "│   "
+		} else {
+			newPrefix += "    "
+		}
+		output(node.Right, newPrefix, false, str)
+	}
+	*str += prefix
+	if isTail {
+		*str += "└── "
+	} else {
+		*str += "┌── "
+	}
+	*str += node.String() + "\n"
+	if node.Left != nil {
+		newPrefix := prefix
+		if isTail {
+			newPrefix += "    "
+		} else {
+			newPrefix += "│   "
+		}
+		output(node.Left, newPrefix, true, str)
+	}
+}
+
+// splay moves the node with the key, or the last node on its search path if the key is not in the tree, to the root.
+// The tree is split top-down into a left tree of smaller keys and a right tree of larger keys,
+// which are reassembled under the new root at the end.
+// splay 自顶向下伸展，把key所在节点（或者查找路径上的最后一个节点）旋转到根节点
+func (tree *Tree) splay(key interface{}) {
+	var header Node
+	left, right := &header, &header // right-most node of the left tree and left-most node of the right tree
+	node := tree.Root
+	for {
+		compare := tree.compare(key, node.Key)
+		if compare < 0 {
+			if node.Left == nil {
+				break
+			}
+			if tree.compare(key, node.Left.Key) < 0 {
+				// zig-zig: rotate right
+				tree.stats.rotations++
+				child := node.Left
+				node.Left = child.Right
+				child.Right = node
+				node = child
+				if node.Left == nil {
+					break
+				}
+			}
+			// link right
+			right.Left = node
+			right = node
+			node = node.Left
+		} else if compare > 0 {
+			if node.Right == nil {
+				break
+			}
+			if tree.compare(key, node.Right.Key) > 0 {
+				// zig-zig: rotate left
+				tree.stats.rotations++
+				child := node.Right
+				node.Right = child.Left
+				child.Left = node
+				node = child
+				if node.Right == nil {
+					break
+				}
+			}
+			// link left
+			left.Right = node
+			left = node
+			node = node.Right
+		} else {
+			break
+		}
+	}
+	// assemble
+	left.Right = node.Left
+	right.Left = node.Right
+	node.Left = header.Right
+	node.Right = header.Left
+	tree.Root = node
+	tree.splays++
+}
@@ -0,0 +1,558 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package splaytree
+
+import (
+	"fmt"
+	"github.com/dairongpeng/gds/containers"
+	"github.com/dairongpeng/gds/trees/avltree"
+	rbt "github.com/dairongpeng/gds/trees/redblacktree"
+	"math/rand"
+	"testing"
+)
+
+func TestSplayTreePut(t *testing.T) {
+	tree := NewWithIntComparator()
+	tree.Put(5, "e")
+	tree.Put(6, "f")
+	tree.Put(7, "g")
+	tree.Put(3, "c")
+	tree.Put(4, "d")
+	tree.Put(1, "x")
+	tree.Put(2, "b")
+	tree.Put(1, "a") //overwrite
+
+	if actualValue := tree.Size(); actualValue != 7 {
+		t.Errorf("Got %v expected %v", actualValue, 7)
+	}
+	if actualValue, expectedValue := tree.Root.Key, 1; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue, expectedValue := fmt.Sprintf("%d%d%d%d%d%d%d", tree.Keys()...), "1234567"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue, expectedValue := fmt.Sprintf("%s%s%s%s%s%s%s", tree.Values()...), "abcdefg"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+
+	tests1 := [][]interface{}{
+		{1, "a", true},
+		{2, "b", true},
+		{3, "c", true},
+		{4, "d", true},
+		{5, "e", true},
+		{6, "f", true},
+		{7, "g", true},
+		{8, nil, false},
+	}
+
+	for _, test := range tests1 {
+		// retrievals
+		actualValue, actualFound := tree.Get(test[0])
+		if actualValue != test[1] || actualFound != test[2] {
+			t.Errorf("Got %v expected %v", actualValue, test[1])
+		}
+		if actualFound && tree.Root.Key != test[0] {
+			t.Errorf("Got root %v expected %v", tree.Root.Key, test[0])
+		}
+	}
+}
+
+func TestSplayTreeRemove(t *testing.T) {
+	tree := NewWithIntComparator()
+	tree.Put(5, "e")
+	tree.Put(6, "f")
+	tree.Put(7, "g")
+	tree.Put(3, "c")
+	tree.Put(4, "d")
+	tree.Put(1, "x")
+	tree.Put(2, "b")
+	tree.Put(1, "a") //overwrite
+
+	tree.Remove(5)
+	tree.Remove(6)
+	tree.Remove(7)
+	tree.Remove(8)
+	tree.Remove(5)
+
+	if actualValue, expectedValue := fmt.Sprintf("%d%d%d%d", tree.Keys()...), "1234"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue, expectedValue := fmt.Sprintf("%s%s%s%s", tree.Values()...), "abcd"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue := tree.Size(); actualValue != 4 {
+		t.Errorf("Got %v expected %v", actualValue, 4)
+	}
+
+	tree.Remove(1)
+	tree.Remove(4)
+	tree.Remove(2)
+	tree.Remove(3)
+	tree.Remove(2)
+	tree.Remove(2)
+
+	if actualValue, expectedValue := fmt.Sprintf("%s", tree.Keys()), "[]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if empty, size := tree.Empty(), tree.Size(); empty != true || size != -0 {
+		t.Errorf("Got %v expected %v", empty, true)
+	}
+}
+
+func TestSplayTreeLeftAndRight(t *testing.T) {
+	tree := NewWithIntComparator()
+
+	if actualValue := tree.Left(); actualValue != nil {
+		t.Errorf("Got %v expected %v", actualValue, nil)
+	}
+	if actualValue := tree.Right(); actualValue != nil {
+		t.Errorf("Got %v expected %v", actualValue, nil)
+	}
+
+	tree.Put(1, "a")
+	tree.Put(5, "e")
+	tree.Put(6, "f")
+	tree.Put(7, "g")
+	tree.Put(3, "c")
+	tree.Put(4, "d")
+	tree.Put(1, "x") // overwrite
+	tree.Put(2, "b")
+
+	if actualValue, expectedValue := fmt.Sprintf("%d", tree.Left().Key), "1"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue, expectedValue := fmt.Sprintf("%s", tree.Left().Value), "x"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue, expectedValue := fmt.Sprintf("%d", tree.Right().Key), "7"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue, expectedValue := fmt.Sprintf("%s", tree.Right().Value), "g"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+}
+
+func TestSplayTreeCeilingAndFloor(t *testing.T) {
+	tree := NewWithIntComparator()
+
+	if node, found := tree.Floor(0); node != nil || found {
+		t.Errorf("Got %v expected %v", node, "<nil>")
+	}
+	if node, found := tree.Ceiling(0); node != nil || found {
+		t.Errorf("Got %v expected %v", node, "<nil>")
+	}
+
+	for _, key := range []int{50, 30, 70, 10, 40, 60, 80} {
+		tree.Put(key, key)
+	}
+
+	tests := [][]interface{}{
+		// key, floor, ceiling
+		{40, 40, 40},
+		{45, 40, 50},
+		{5, nil, 10},
+		{85, 80, nil},
+		{65, 60, 70},
+		{31, 30, 40},
+	}
+	for _, test := range tests {
+		floor, found := tree.Floor(test[0])
+		if (test[1] == nil && (floor != nil || found)) || (test[1] != nil && (floor == nil || floor.Key != test[1])) {
+			t.Errorf("Got %v expected %v for floor of %v", floor, test[1], test[0])
+		}
+		ceiling, found := tree.Ceiling(test[0])
+		if (test[2] == nil && (ceiling != nil || found)) || (test[2] != nil && (ceiling == nil || ceiling.Key != test[2])) {
+			t.Errorf("Got %v expected %v for ceiling of %v", ceiling, test[2], test[0])
+		}
+	}
+}
+
+func TestSplayTreeRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	tree := NewWithIntComparator()
+	expected := make(map[int]int)
+	for i := 0; i < 5000; i++ {
+		key := r.Intn(300)
+		switch r.Intn(3) {
+		case 0:
+			tree.Remove(key)
+			delete(expected, key)
+		case 1:
+			tree.Put(key, i)
+			expected[key] = i
+		default:
+			value, found := tree.Get(key)
+			if expectedValue, expectedFound := expected[key]; found != expectedFound || (found && value != expectedValue) {
+				t.Errorf("Got %v,%v expected %v,%v", value, found, expectedValue, expectedFound)
+			}
+		}
+	}
+	if actualValue := tree.Size(); actualValue != len(expected) {
+		t.Errorf("Got %v expected %v", actualValue, len(expected))
+	}
+	keys := tree.Keys()
+	if actualValue := len(keys); actualValue != len(expected) {
+		t.Errorf("Got %v expected %v", actualValue, len(expected))
+	}
+	for i := 1; i < len(keys); i++ {
+		if keys[i-1].(int) >= keys[i].(int) {
+			t.Errorf("Keys out of order: %v before %v", keys[i-1], keys[i])
+		}
+	}
+}
+
+func TestSplayTreeIteratorNextOnEmpty(t *testing.T) {
+	tree := NewWithIntComparator()
+	it := tree.Iterator()
+	for it.Next() {
+		t.Errorf("Shouldn't iterate on empty tree")
+	}
+}
+
+func TestSplayTreeIteratorPrevOnEmpty(t *testing.T) {
+	tree := NewWithIntComparator()
+	it := tree.Iterator()
+	for it.Prev() {
+		t.Errorf("Shouldn't iterate on empty tree")
+	}
+}
+
+func TestSplayTreeIterator(t *testing.T) {
+	tree := NewWithIntComparator()
+	for _, key := range []int{13, 8, 17, 1, 11, 15, 25, 6, 22, 27} {
+		tree.Put(key, fmt.Sprintf("%d", key))
+	}
+	tree.Get(15) // restructure
+	expected := []int{1, 6, 8, 11, 13, 15, 17, 22, 25, 27}
+
+	it := tree.Iterator()
+	count := 0
+	for it.Next() {
+		if actualValue, expectedValue := it.Key(), expected[count]; actualValue != expectedValue {
+			t.Errorf("Got %v expected %v", actualValue, expectedValue)
+		}
+		count++
+	}
+	if actualValue, expectedValue := count, tree.Size(); actualValue != expectedValue {
+		t.Errorf("Size different. Got %v expected %v", actualValue, expectedValue)
+	}
+
+	for it.Prev() {
+		count--
+		if actualValue, expectedValue := it.Key(), expected[count]; actualValue != expectedValue {
+			t.Errorf("Got %v expected %v", actualValue, expectedValue)
+		}
+	}
+	if actualValue, expectedValue := count, 0; actualValue != expectedValue {
+		t.Errorf("Size different. Got %v expected %v", actualValue, expectedValue)
+	}
+
+	// change direction in the middle
+	it.First()
+	it.Next()
+	it.Next()
+	it.Prev()
+	if actualValue, expectedValue := it.Key(), 6; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+
+	if actualValue := it.Last(); actualValue != true || it.Key() != 27 {
+		t.Errorf("Got %v expected %v", it.Key(), 27)
+	}
+	if actualValue := it.First(); actualValue != true || it.Key() != 1 {
+		t.Errorf("Got %v expected %v", it.Key(), 1)
+	}
+}
+
+func TestSplayTreeIteratorRemove(t *testing.T) {
+	tree := NewWithIntComparator()
+	for _, i := range rand.Perm(100) {
+		tree.Put(i, i)
+	}
+	it := tree.Iterator()
+	for it.Next() {
+		if it.Value().(int)%2 == 0 {
+			it.Remove()
+		}
+	}
+	for it.Prev() {
+		if it.Value().(int)%3 == 0 {
+			it.Remove()
+		}
+	}
+	keys := []interface{}{}
+	for i := 0; i < 100; i++ {
+		if i%2 != 0 && i%3 != 0 {
+			keys = append(keys, i)
+		}
+	}
+	if actualValue, expectedValue := fmt.Sprint(tree.Keys()), fmt.Sprint(keys); actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+
+	it.First()
+	it.Remove()
+	if it.Prev() {
+		t.Errorf("Shouldn't iterate before the beginning")
+	}
+	if !it.Next() || it.Key() != keys[1] {
+		t.Errorf("Got %v expected %v", it.Key(), keys[1])
+	}
+	it.Last()
+	it.Remove()
+	if it.Next() {
+		t.Errorf("Shouldn't iterate past the end")
+	}
+	if !it.Prev() || it.Key() != keys[len(keys)-2] {
+		t.Errorf("Got %v expected %v", it.Key(), keys[len(keys)-2])
+	}
+	if actualValue, expectedValue := tree.Size(), len(keys)-2; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+}
+
+// expectPanic checks that f panics with the expected error.
+func expectPanic(t *testing.T, expected error, f func()) {
+	t.Helper()
+	defer func() {
+		if r := recover(); r != expected {
+			t.Errorf("Got %v expected %v", r, expected)
+		}
+	}()
+	f()
+}
+
+func TestSplayTreeIteratorConcurrentModification(t *testing.T) {
+	tree := NewWithIntComparator()
+	tree.Put(1, 1)
+	tree.Put(2, 2)
+	it := tree.Iterator()
+	it.Next()
+	tree.Remove(2)
+	expectPanic(t, containers.ErrConcurrentModification, func() { it.Next() })
+	expectPanic(t, containers.ErrConcurrentModification, func() { it.Prev() })
+	expectPanic(t, containers.ErrConcurrentModification, func() { it.Remove() })
+
+	// lookups splay the tree, the iterator finds its position again
+	tree.Put(2, 2)
+	tree.Put(3, 3)
+	it.Begin()
+	it.Next()
+	tree.Get(3)
+	if !it.Next() || it.Key() != 2 {
+		t.Errorf("Got %v expected %v", it.Key(), 2)
+	}
+	tree.Put(1, "a")
+	tree.Floor(1)
+	if !it.Prev() || it.Value() != "a" {
+		t.Errorf("Got %v expected %v", it.Value(), "a")
+	}
+	tree.Remove(3)
+	tree.Remove(2)
+
+	it.Begin()
+	tree.Put(2, 2)
+	if !it.Next() || it.Key() != 1 {
+		t.Errorf("Got %v expected %v", it.Key(), 1)
+	}
+	it.Remove()
+	expectPanic(t, containers.ErrIteratorNotOnElement, func() { it.Remove() })
+	it.End()
+	expectPanic(t, containers.ErrIteratorNotOnElement, func() { it.Remove() })
+}
+
+func TestSplayTreeIteratorLookups(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	tree := NewWithIntComparator()
+	for _, key := range r.Perm(100) {
+		tree.Put(key, key)
+	}
+	// lookups, also of missing keys, while iterating in both directions
+	it := tree.Iterator()
+	for expected := 0; it.Next(); expected++ {
+		if actualValue := it.Key(); actualValue != expected {
+			t.Errorf("Got %v expected %v", actualValue, expected)
+		}
+		tree.Get(r.Intn(120))
+		tree.Ceiling(r.Intn(120))
+		tree.Put(r.Intn(100), "x")
+	}
+	for expected := 99; it.Prev(); expected-- {
+		if actualValue := it.Key(); actualValue != expected {
+			t.Errorf("Got %v expected %v", actualValue, expected)
+		}
+		tree.Get(r.Intn(120))
+	}
+}
+
+func TestSplayTreeStats(t *testing.T) {
+	tree := NewWithIntComparator()
+	if actualValue, expectedValue := fmt.Sprintf("%+v", tree.Stats()), "{Height:0 Nodes:0 AverageDepth:0 Rotations:0 Splits:0 Merges:0 Comparisons:0}"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	// ascending puts leave a path
+	for i := 1; i <= 7; i++ {
+		tree.Put(i, i)
+	}
+	if actualValue, expectedValue := fmt.Sprintf("%+v", tree.Stats()), "{Height:7 Nodes:7 AverageDepth:3 Rotations:0 Splits:0 Merges:0 Comparisons:12}"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+
+	// splaying the deepest node about halves the depth of the nodes on its path
+	//
+	//  SplayTree
+	//  │       ┌── 7
+	//  │   ┌── 6
+	//  │   │   │   ┌── 5
+	//  │   │   └── 4
+	//  │   │       │   ┌── 3
+	//  │   │       └── 2
+	//  └── 1
+	//
+	tree.Get(1)
+	if actualValue, expectedValue := fmt.Sprintf("%+v", tree.Stats()), "{Height:5 Nodes:7 AverageDepth:2.142857142857143 Rotations:3 Splits:0 Merges:0 Comparisons:20}"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+}
+
+func TestSplayTreeSerialization(t *testing.T) {
+	tree := NewWithStringComparator()
+	tree.Put("c", "3")
+	tree.Put("b", "2")
+	tree.Put("a", "1")
+
+	var err error
+	assert := func() {
+		if actualValue, expectedValue := tree.Size(), 3; actualValue != expectedValue {
+			t.Errorf("Got %v expected %v", actualValue, expectedValue)
+		}
+		if actualValue := tree.Keys(); actualValue[0].(string) != "a" || actualValue[1].(string) != "b" || actualValue[2].(string) != "c" {
+			t.Errorf("Got %v expected %v", actualValue, "[a,b,c]")
+		}
+		if actualValue := tree.Values(); actualValue[0].(string) != "1" || actualValue[1].(string) != "2" || actualValue[2].(string) != "3" {
+			t.Errorf("Got %v expected %v", actualValue, "[1,2,3]")
+		}
+		if err != nil {
+			t.Errorf("Got error %v", err)
+		}
+	}
+
+	assert()
+
+	json, err := tree.ToJSON()
+	assert()
+
+	err = tree.FromJSON(json)
+	assert()
+}
+
+// zipfKeys returns keys in [0, size) drawn from a Zipf distribution, so that a few keys make up most of the accesses.
+func zipfKeys(size int, count int) []int {
+	zipf := rand.NewZipf(rand.New(rand.NewSource(1)), 1.1, 1, uint64(size-1))
+	keys := make([]int, count)
+	for i := range keys {
+		keys[i] = int(zipf.Uint64())
+	}
+	return keys
+}
+
+func benchmarkZipfGet(b *testing.B, get func(key interface{}) (interface{}, bool), keys []int) {
+	for i := 0; i < b.N; i++ {
+		for _, key := range keys {
+			get(key)
+		}
+	}
+}
+
+func BenchmarkSplayTreeZipfGet100000(b *testing.B) {
+	b.StopTimer()
+	size := 100000
+	tree := NewWithIntComparator()
+	for _, n := range rand.New(rand.NewSource(1)).Perm(size) {
+		tree.Put(n, struct{}{})
+	}
+	keys := zipfKeys(size, size)
+	b.StartTimer()
+	benchmarkZipfGet(b, tree.Get, keys)
+}
+
+func BenchmarkRedBlackTreeZipfGet100000(b *testing.B) {
+	b.StopTimer()
+	size := 100000
+	tree := rbt.NewWithIntComparator()
+	for _, n := range rand.New(rand.NewSource(1)).Perm(size) {
+		tree.Put(n, struct{}{})
+	}
+	keys := zipfKeys(size, size)
+	b.StartTimer()
+	benchmarkZipfGet(b, tree.Get, keys)
+}
+
+func BenchmarkAVLTreeZipfGet100000(b *testing.B) {
+	b.StopTimer()
+	size := 100000
+	tree := avltree.NewWithIntComparator()
+	for _, n := range rand.New(rand.NewSource(1)).Perm(size) {
+		tree.Put(n, struct{}{})
+	}
+	keys := zipfKeys(size, size)
+	b.StartTimer()
+	benchmarkZipfGet(b, tree.Get, keys)
+}
+
+func benchmarkGet(b *testing.B, tree *Tree, size int) {
+	for i := 0; i < b.N; i++ {
+		for n := 0; n < size; n++ {
+			tree.Get(n)
+		}
+	}
+}
+
+func benchmarkPut(b *testing.B, tree *Tree, size int) {
+	for i := 0; i < b.N; i++ {
+		for n := 0; n < size; n++ {
+			tree.Put(n, struct{}{})
+		}
+	}
+}
+
+func benchmarkRemove(b *testing.B, tree *Tree, size int) {
+	for i := 0; i < b.N; i++ {
+		for n := 0; n < size; n++ {
+			tree.Remove(n)
+		}
+	}
+}
+
+func BenchmarkSplayTreeGet10000(b *testing.B) {
+	b.StopTimer()
+	size := 10000
+	tree := NewWithIntComparator()
+	for n := 0; n < size; n++ {
+		tree.Put(n, struct{}{})
+	}
+	b.StartTimer()
+	benchmarkGet(b, tree, size)
+}
+
+func BenchmarkSplayTreePut10000(b *testing.B) {
+	b.StopTimer()
+	size := 10000
+	tree := NewWithIntComparator()
+	b.StartTimer()
+	benchmarkPut(b, tree, size)
+}
+
+func BenchmarkSplayTreeRemove10000(b *testing.B) {
+	b.StopTimer()
+	size := 10000
+	tree := NewWithIntComparator()
+	for n := 0; n < size; n++ {
+		tree.Put(n, struct{}{})
+	}
+	b.StartTimer()
+	benchmarkRemove(b, tree, size)
+}
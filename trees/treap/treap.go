@@ -0,0 +1,506 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package treap implements a treap, a randomized binary search tree.
+//
+// Every node gets a random priority and the tree is kept a binary search tree by key and a max-heap by priority,
+// which makes it balanced in expectation. Besides the usual ordered map operations, the tree can be split
+// by key or by position and two trees can be merged, all in O(log n) expected time. Nodes keep the size of
+// their subtrees, so elements can also be fetched by their position in the key order.
+//
+// The source of randomness can be injected for deterministic behaviour, e.g. in tests.
+//
+// Structure is not thread safe.
+//
+// References: https://en.wikipedia.org/wiki/Treap
+package treap
+
+import (
+	"fmt"
+	"github.com/dairongpeng/gds/trees"
+	"github.com/dairongpeng/gds/utils"
+	"math/rand"
+	"time"
+)
+
+func assertTreeImplementation() {
+	var _ trees.Tree = (*Tree)(nil)
+}
+
+// Tree holds elements of the treap
+// Tree 树堆，按照key满足二叉搜索树的性质，按照随机优先级满足大根堆的性质
+type Tree struct {
+	Root       *Node            // Root node
+	Comparator utils.Comparator // Key comparator
+	random     *rand.Rand       // Source of node priorities
+	modCount   int              // Number of structural modifications, checked by iterators
+	stats      stats            // Counts the work done by the tree
+}
+
+// Node is a single element within the tree
+type Node struct {
+	Key      interface{}
+	Value    interface{}
+	Left     *Node
+	Right    *Node
+	Parent   *Node
+	priority int64
+	size     int // number of nodes in the subtree rooted at this node
+}
+
+// NewWith instantiates a treap with the custom comparator and a randomly seeded source of priorities.
+func NewWith(comparator utils.Comparator) *Tree {
+	return NewWithSource(comparator, rand.NewSource(time.Now().UnixNano()))
+}
+
+// NewWithSource instantiates a treap with the custom comparator that draws node priorities from the source.
+// The same source and sequence of operations always produce the same tree.
+func NewWithSource(comparator utils.Comparator, source rand.Source) *Tree {
+	return &Tree{Comparator: comparator, random: rand.New(source)}
+}
+
+// NewWithIntComparator instantiates a treap with the IntComparator, i.e. keys are of type int.
+func NewWithIntComparator() *Tree {
+	return NewWith(utils.IntComparator)
+}
+
+// NewWithStringComparator instantiates a treap with the StringComparator, i.e. keys are of type string.
+func NewWithStringComparator() *Tree {
+	return NewWith(utils.StringComparator)
+}
+
+// Put inserts node into the tree.
+// Key should adhere to the comparator's type assertion, otherwise method panics.
+func (tree *Tree) Put(key interface{}, value interface{}) {
+	if node := tree.lookup(key); node != nil {
+		node.Key = key
+		node.Value = value
+		return
+	}
+	node := &Node{Key: key, Value: value, priority: tree.random.Int63(), size: 1}
+	tree.Root = tree.insert(tree.Root, node)
+	tree.Root.Parent = nil
+	tree.modCount++
+}
+
+// Get searches the node in the tree by key and returns its value or nil if key is not found in tree.
+// Second return parameter is true if key was found, otherwise false.
+// Key should adhere to the comparator's type assertion, otherwise method panics.
+func (tree *Tree) Get(key interface{}) (value interface{}, found bool) {
+	node := tree.lookup(key)
+	if node != nil {
+		return node.Value, true
+	}
+	return nil, false
+}
+
+// GetAt returns the node at the given position in the key order, or nil if index is out of bounds.
+// GetAt 按照key的顺序返回第index个节点
+func (tree *Tree) GetAt(index int) *Node {
+	if index < 0 || index >= tree.Size() {
+		return nil
+	}
+	node := tree.Root
+	for {
+		left := size(node.Left)
+		switch {
+		case index < left:
+			node = node.Left
+		case index > left:
+			index -= left + 1
+			node = node.Right
+		default:
+			return node
+		}
+	}
+}
+
+// IndexOf returns the position of the key in the key order, or -1 if the key is not found.
+// Key should adhere to the comparator's type assertion, otherwise method panics.
+func (tree *Tree) IndexOf(key interface{}) int {
+	index := 0
+	node := tree.Root
+	for node != nil {
+		compare := tree.compare(key, node.Key)
+		switch {
+		case compare == 0:
+			return index + size(node.Left)
+		case compare < 0:
+			node = node.Left
+		case compare > 0:
+			index += size(node.Left) + 1
+			node = node.Right
+		}
+	}
+	return -1
+}
+
+// Remove remove the node from the tree by key.
+// Key should adhere to the comparator's type assertion, otherwise method panics.
+func (tree *Tree) Remove(key interface{}) {
+	node := tree.lookup(key)
+	if node == nil {
+		return
+	}
+	parent := node.Parent
+	if node.Left != nil && node.Right != nil {
+		tree.stats.merges++
+	}
+	child := tree.merge(node.Left, node.Right)
+	if child != nil {
+		child.Parent = parent
+	}
+	switch {
+	case parent == nil:
+		tree.Root = child
+	case parent.Left == node:
+		parent.Left = child
+	default:
+		parent.Right = child
+	}
+	for ; parent != nil; parent = parent.Parent {
+		parent.size--
+	}
+	tree.modCount++
+}
+
+// Split moves all elements of the tree into two new trees: left holds the keys smaller than key and right the others.
+// Both trees share the comparator and source of priorities of this tree, which is left empty.
+// Key should adhere to the comparator's type assertion, otherwise method panics.
+// Split 按照key把树拆分成两颗树，左树的key都小于给定key，右树的key都大于等于给定key
+func (tree *Tree) Split(key interface{}) (left *Tree, right *Tree) {
+	tree.stats.splits++
+	l, r := tree.split(tree.Root, key)
+	return tree.detach(l, r)
+}
+
+// SplitAt moves the first index elements (in key order) of the tree into left and the remaining ones into right.
+// Both trees share the comparator and source of priorities of this tree, which is left empty.
+func (tree *Tree) SplitAt(index int) (left *Tree, right *Tree) {
+	tree.stats.splits++
+	l, r := tree.splitAt(tree.Root, index)
+	return tree.detach(l, r)
+}
+
+// Merge moves all elements of other to the end of this tree, leaving other empty.
+// All keys of this tree must be smaller than the keys of other, otherwise method panics.
+// Merge 把other树合并到当前树中，要求当前树所有的key都小于other树的key
+func (tree *Tree) Merge(other *Tree) {
+	if last, first := tree.Right(), other.Left(); last != nil && first != nil && tree.compare(last.Key, first.Key) >= 0 {
+		panic(fmt.Sprintf("Cannot merge trees with overlapping keys: %v is not smaller than %v", last.Key, first.Key))
+	}
+	tree.stats.merges++
+	tree.Root = tree.merge(tree.Root, other.Root)
+	if tree.Root != nil {
+		tree.Root.Parent = nil
+	}
+	other.Root = nil
+	tree.modCount++
+	other.modCount++
+}
+
+// Empty returns true if tree does not contain any nodes
+func (tree *Tree) Empty() bool {
+	return tree.Root == nil
+}
+
+// Size returns number of nodes in the tree.
+func (tree *Tree) Size() int {
+	return size(tree.Root)
+}
+
+// Keys returns all keys in-order
+func (tree *Tree) Keys() []interface{} {
+	keys := make([]interface{}, tree.Size())
+	it := tree.Iterator()
+	for i := 0; it.Next(); i++ {
+		keys[i] = it.Key()
+	}
+	return keys
+}
+
+// Values returns all values in-order based on the key.
+func (tree *Tree) Values() []interface{} {
+	values := make([]interface{}, tree.Size())
+	it := tree.Iterator()
+	for i := 0; it.Next(); i++ {
+		values[i] = it.Value()
+	}
+	return values
+}
+
+// Left returns the left-most (min) node or nil if tree is empty.
+func (tree *Tree) Left() *Node {
+	var parent *Node
+	current := tree.Root
+	for current != nil {
+		parent = current
+		current = current.Left
+	}
+	return parent
+}
+
+// Right returns the right-most (max) node or nil if tree is empty.
+func (tree *Tree) Right() *Node {
+	var parent *Node
+	current := tree.Root
+	for current != nil {
+		parent = current
+		current = current.Right
+	}
+	return parent
+}
+
+// Floor Finds floor node of the input key, return the floor node or nil if no floor is found.
+// Second return parameter is true if floor was found, otherwise false.
+//
+// Floor node is defined as the largest node that is smaller than or equal to the given node.
+// A floor node may not be found, either because the tree is empty, or because
+// all nodes in the tree are larger than the given node.
+//
+// Key should adhere to the comparator's type assertion, otherwise method panics.
+func (tree *Tree) Floor(key interface{}) (floor *Node, found bool) {
+	found = false
+	node := tree.Root
+	for node != nil {
+		compare := tree.compare(key, node.Key)
+		switch {
+		case compare == 0:
+			return node, true
+		case compare < 0:
+			node = node.Left
+		case compare > 0:
+			floor, found = node, true
+			node = node.Right
+		}
+	}
+	if found {
+		return floor, true
+	}
+	return nil, false
+}
+
+// Ceiling finds ceiling node of the input key, return the ceiling node or nil if no ceiling is found.
+// Second return parameter is true if ceiling was found, otherwise false.
+//
+// Ceiling node is defined as the smallest node that is larger than or equal to the given node.
+// A ceiling node may not be found, either because the tree is empty, or because
+// all nodes in the tree are smaller than the given node.
+//
+// Key should adhere to the comparator's type assertion, otherwise method panics.
+func (tree *Tree) Ceiling(key interface{}) (ceiling *Node, found bool) {
+	found = false
+	node := tree.Root
+	for node != nil {
+		compare := tree.compare(key, node.Key)
+		switch {
+		case compare == 0:
+			return node, true
+		case compare < 0:
+			ceiling, found = node, true
+			node = node.Left
+		case compare > 0:
+			node = node.Right
+		}
+	}
+	if found {
+		return ceiling, true
+	}
+	return nil, false
+}
+
+// Clear removes all nodes from the tree.
+func (tree *Tree) Clear() {
+	tree.Root = nil
+	tree.modCount++
+}
+
+// String returns a string representation of container
+func (tree *Tree) String() string {
+	str := "Treap\n"
+	if !tree.Empty() {
+		output(tree.Root, "", true, &str)
+	}
+	return str
+}
+
+func (node *Node) String() string {
+	return fmt.Sprintf("%v", node.Key)
+}
+
+// Prev returns the previous element in an inorder walk of the tree.
+func (node *Node) Prev() *Node {
+	if node.Left != nil {
+		node = node.Left
+		for node.Right != nil {
+			node = node.Right
+		}
+		return node
+	}
+	for node.Parent != nil && node.Parent.Left == node {
+		node = node.Parent
+	}
+	return node.Parent
+}
+
+// Next returns the next element in an inorder walk of the tree.
+func (node *Node) Next() *Node {
+	if node.Right != nil {
+		node = node.Right
+		for node.Left != nil {
+			node = node.Left
+		}
+		return node
+	}
+	for node.Parent != nil && node.Parent.Right == node {
+		node = node.Parent
+	}
+	return node.Parent
+}
+
+func output(node *Node, prefix string, isTail bool, str *string) {
+	if node.Right != nil {
+		newPrefix := prefix
+		if isTail {
+			newPrefix += "│   "
+		} else {
+			newPrefix += "    "
+		}
+		output(node.Right, newPrefix, false, str)
+	}
+	*str += prefix
+	if isTail {
+		*str += "└── "
+	} else {
+		*str += "┌── "
+	}
+	*str += node.String() + "\n"
+	if node.Left != nil {
+		newPrefix := prefix
+		if isTail {
+			newPrefix += "    "
+		} else {
+			newPrefix += "│   "
+		}
+		output(node.Left, newPrefix, true, str)
+	}
+}
+
+func (tree *Tree) lookup(key interface{}) *Node {
+	node := tree.Root
+	for node != nil {
+		compare := tree.compare(key, node.Key)
+		switch {
+		case compare == 0:
+			return node
+		case compare < 0:
+			node = node.Left
+		case compare > 0:
+			node = node.Right
+		}
+	}
+	return nil
+}
+
+// insert adds the new node (whose key is not in the tree) to the subtree and returns the subtree's new root.
+func (tree *Tree) insert(root *Node, node *Node) *Node {
+	if root == nil {
+		return node
+	}
+	if node.priority > root.priority {
+		tree.stats.splits++
+		node.Left, node.Right = tree.split(root, node.Key)
+		node.link()
+		return node
+	}
+	if tree.compare(node.Key, root.Key) < 0 {
+		root.Left = tree.insert(root.Left, node)
+	} else {
+		root.Right = tree.insert(root.Right, node)
+	}
+	root.link()
+	return root
+}
+
+// split divides the subtree into the nodes with keys smaller than key and the others.
+func (tree *Tree) split(node *Node, key interface{}) (left *Node, right *Node) {
+	if node == nil {
+		return nil, nil
+	}
+	if tree.compare(node.Key, key) < 0 {
+		node.Right, right = tree.split(node.Right, key)
+		node.link()
+		return node, right
+	}
+	left, node.Left = tree.split(node.Left, key)
+	node.link()
+	return left, node
+}
+
+// splitAt divides the subtree into its first index nodes and the others.
+func (tree *Tree) splitAt(node *Node, index int) (left *Node, right *Node) {
+	if node == nil {
+		return nil, nil
+	}
+	if size(node.Left) < index {
+		node.Right, right = tree.splitAt(node.Right, index-size(node.Left)-1)
+		node.link()
+		return node, right
+	}
+	left, node.Left = tree.splitAt(node.Left, index)
+	node.link()
+	return left, node
+}
+
+// merge joins two subtrees, where all keys of left are smaller than the keys of right, and returns the new root.
+func (tree *Tree) merge(left *Node, right *Node) *Node {
+	if left == nil {
+		return right
+	}
+	if right == nil {
+		return left
+	}
+	if left.priority > right.priority {
+		left.Right = tree.merge(left.Right, right)
+		left.link()
+		return left
+	}
+	right.Left = tree.merge(left, right.Left)
+	right.link()
+	return right
+}
+
+// detach moves the two subtrees into new trees sharing the settings of this tree, which is left empty.
+func (tree *Tree) detach(left *Node, right *Node) (*Tree, *Tree) {
+	if left != nil {
+		left.Parent = nil
+	}
+	if right != nil {
+		right.Parent = nil
+	}
+	tree.Root = nil
+	tree.modCount++
+	return &Tree{Root: left, Comparator: tree.Comparator, random: tree.random},
+		&Tree{Root: right, Comparator: tree.Comparator, random: tree.random}
+}
+
+// link points the children of the node back to it and recomputes the size of its subtree.
+func (node *Node) link() {
+	node.size = 1
+	if node.Left != nil {
+		node.Left.Parent = node
+		node.size += node.Left.size
+	}
+	if node.Right != nil {
+		node.Right.Parent = node
+		node.size += node.Right.size
+	}
+}
+
+func size(node *Node) int {
+	if node == nil {
+		return 0
+	}
+	return node.size
+}
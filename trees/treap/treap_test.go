@@ -0,0 +1,653 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package treap
+
+import (
+	"fmt"
+	"github.com/dairongpeng/gds/containers"
+	"github.com/dairongpeng/gds/utils"
+	"math/rand"
+	"testing"
+)
+
+func newTree() *Tree {
+	return NewWithSource(utils.IntComparator, rand.NewSource(1))
+}
+
+// assertInvariants checks the search tree, heap, parent and size invariants of every node.
+func assertInvariants(t *testing.T, tree *Tree) {
+	var check func(node *Node) int
+	check = func(node *Node) int {
+		if node == nil {
+			return 0
+		}
+		for _, child := range []*Node{node.Left, node.Right} {
+			if child == nil {
+				continue
+			}
+			if child.Parent != node {
+				t.Errorf("Got parent %v expected %v for %v", child.Parent, node, child)
+			}
+			if child.priority > node.priority {
+				t.Errorf("Heap order violated between %v and %v", node, child)
+			}
+		}
+		if node.Left != nil && tree.Comparator(node.Left.Key, node.Key) >= 0 {
+			t.Errorf("Search order violated between %v and %v", node, node.Left)
+		}
+		if node.Right != nil && tree.Comparator(node.Right.Key, node.Key) <= 0 {
+			t.Errorf("Search order violated between %v and %v", node, node.Right)
+		}
+		size := 1 + check(node.Left) + check(node.Right)
+		if node.size != size {
+			t.Errorf("Got size %v expected %v for %v", node.size, size, node)
+		}
+		return size
+	}
+	if tree.Root != nil && tree.Root.Parent != nil {
+		t.Errorf("Got parent %v expected %v for root", tree.Root.Parent, nil)
+	}
+	check(tree.Root)
+}
+
+func TestTreapPut(t *testing.T) {
+	tree := newTree()
+	tree.Put(5, "e")
+	tree.Put(6, "f")
+	tree.Put(7, "g")
+	tree.Put(3, "c")
+	tree.Put(4, "d")
+	tree.Put(1, "x")
+	tree.Put(2, "b")
+	tree.Put(1, "a") //overwrite
+	assertInvariants(t, tree)
+
+	if actualValue := tree.Size(); actualValue != 7 {
+		t.Errorf("Got %v expected %v", actualValue, 7)
+	}
+	if actualValue, expectedValue := fmt.Sprintf("%d%d%d%d%d%d%d", tree.Keys()...), "1234567"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue, expectedValue := fmt.Sprintf("%s%s%s%s%s%s%s", tree.Values()...), "abcdefg"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+
+	tests1 := [][]interface{}{
+		{1, "a", true},
+		{2, "b", true},
+		{3, "c", true},
+		{4, "d", true},
+		{5, "e", true},
+		{6, "f", true},
+		{7, "g", true},
+		{8, nil, false},
+	}
+
+	for _, test := range tests1 {
+		// retrievals
+		actualValue, actualFound := tree.Get(test[0])
+		if actualValue != test[1] || actualFound != test[2] {
+			t.Errorf("Got %v expected %v", actualValue, test[1])
+		}
+	}
+}
+
+func TestTreapRemove(t *testing.T) {
+	tree := newTree()
+	tree.Put(5, "e")
+	tree.Put(6, "f")
+	tree.Put(7, "g")
+	tree.Put(3, "c")
+	tree.Put(4, "d")
+	tree.Put(1, "x")
+	tree.Put(2, "b")
+	tree.Put(1, "a") //overwrite
+
+	tree.Remove(5)
+	tree.Remove(6)
+	tree.Remove(7)
+	tree.Remove(8)
+	tree.Remove(5)
+	assertInvariants(t, tree)
+
+	if actualValue, expectedValue := fmt.Sprintf("%d%d%d%d", tree.Keys()...), "1234"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue, expectedValue := fmt.Sprintf("%s%s%s%s", tree.Values()...), "abcd"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue := tree.Size(); actualValue != 4 {
+		t.Errorf("Got %v expected %v", actualValue, 4)
+	}
+
+	tree.Remove(1)
+	tree.Remove(4)
+	tree.Remove(2)
+	tree.Remove(3)
+	tree.Remove(2)
+	tree.Remove(2)
+
+	if actualValue, expectedValue := fmt.Sprintf("%s", tree.Keys()), "[]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if empty, size := tree.Empty(), tree.Size(); empty != true || size != -0 {
+		t.Errorf("Got %v expected %v", empty, true)
+	}
+}
+
+func TestTreapLeftAndRight(t *testing.T) {
+	tree := newTree()
+
+	if actualValue := tree.Left(); actualValue != nil {
+		t.Errorf("Got %v expected %v", actualValue, nil)
+	}
+	if actualValue := tree.Right(); actualValue != nil {
+		t.Errorf("Got %v expected %v", actualValue, nil)
+	}
+
+	tree.Put(1, "a")
+	tree.Put(5, "e")
+	tree.Put(6, "f")
+	tree.Put(7, "g")
+	tree.Put(3, "c")
+	tree.Put(4, "d")
+	tree.Put(1, "x") // overwrite
+	tree.Put(2, "b")
+
+	if actualValue, expectedValue := fmt.Sprintf("%d", tree.Left().Key), "1"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue, expectedValue := fmt.Sprintf("%s", tree.Left().Value), "x"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue, expectedValue := fmt.Sprintf("%d", tree.Right().Key), "7"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue, expectedValue := fmt.Sprintf("%s", tree.Right().Value), "g"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+}
+
+func TestTreapCeilingAndFloor(t *testing.T) {
+	tree := newTree()
+
+	if node, found := tree.Floor(0); node != nil || found {
+		t.Errorf("Got %v expected %v", node, "<nil>")
+	}
+	if node, found := tree.Ceiling(0); node != nil || found {
+		t.Errorf("Got %v expected %v", node, "<nil>")
+	}
+
+	tree.Put(5, "e")
+	tree.Put(6, "f")
+	tree.Put(7, "g")
+	tree.Put(3, "c")
+	tree.Put(4, "d")
+	tree.Put(1, "x")
+	tree.Put(2, "b")
+
+	if node, found := tree.Floor(4); node.Key != 4 || !found {
+		t.Errorf("Got %v expected %v", node.Key, 4)
+	}
+	if node, found := tree.Floor(0); node != nil || found {
+		t.Errorf("Got %v expected %v", node, "<nil>")
+	}
+	if node, found := tree.Floor(8); node.Key != 7 || !found {
+		t.Errorf("Got %v expected %v", node.Key, 7)
+	}
+
+	if node, found := tree.Ceiling(4); node.Key != 4 || !found {
+		t.Errorf("Got %v expected %v", node.Key, 4)
+	}
+	if node, found := tree.Ceiling(8); node != nil || found {
+		t.Errorf("Got %v expected %v", node, "<nil>")
+	}
+	if node, found := tree.Ceiling(0); node.Key != 1 || !found {
+		t.Errorf("Got %v expected %v", node.Key, 1)
+	}
+}
+
+func TestTreapGetAtAndIndexOf(t *testing.T) {
+	tree := newTree()
+	for _, key := range []int{50, 10, 40, 20, 30} {
+		tree.Put(key, key)
+	}
+	for index, key := range []int{10, 20, 30, 40, 50} {
+		if actualValue := tree.GetAt(index).Key; actualValue != key {
+			t.Errorf("Got %v expected %v", actualValue, key)
+		}
+		if actualValue := tree.IndexOf(key); actualValue != index {
+			t.Errorf("Got %v expected %v", actualValue, index)
+		}
+	}
+	if actualValue := tree.GetAt(5); actualValue != nil {
+		t.Errorf("Got %v expected %v", actualValue, nil)
+	}
+	if actualValue := tree.GetAt(-1); actualValue != nil {
+		t.Errorf("Got %v expected %v", actualValue, nil)
+	}
+	if actualValue := tree.IndexOf(35); actualValue != -1 {
+		t.Errorf("Got %v expected %v", actualValue, -1)
+	}
+}
+
+func TestTreapSplitAndMerge(t *testing.T) {
+	tree := newTree()
+	for i := 1; i <= 10; i++ {
+		tree.Put(i, i)
+	}
+
+	left, right := tree.Split(4)
+	assertInvariants(t, left)
+	assertInvariants(t, right)
+	if actualValue := tree.Empty(); actualValue != true {
+		t.Errorf("Got %v expected %v", actualValue, true)
+	}
+	if actualValue, expectedValue := fmt.Sprintf("%v", left.Keys()), "[1 2 3]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue, expectedValue := fmt.Sprintf("%v", right.Keys()), "[4 5 6 7 8 9 10]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue := right.Size(); actualValue != 7 {
+		t.Errorf("Got %v expected %v", actualValue, 7)
+	}
+
+	middle, last := right.SplitAt(3)
+	assertInvariants(t, middle)
+	assertInvariants(t, last)
+	if actualValue, expectedValue := fmt.Sprintf("%v", middle.Keys()), "[4 5 6]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue, expectedValue := fmt.Sprintf("%v", last.Keys()), "[7 8 9 10]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+
+	left.Merge(last)
+	assertInvariants(t, left)
+	if actualValue, expectedValue := fmt.Sprintf("%v", left.Keys()), "[1 2 3 7 8 9 10]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue := last.Empty(); actualValue != true {
+		t.Errorf("Got %v expected %v", actualValue, true)
+	}
+
+	// split points outside of the keys
+	all, none := left.Split(100)
+	if actualValue, expectedValue := all.Size(), 7; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue := none.Empty(); actualValue != true {
+		t.Errorf("Got %v expected %v", actualValue, true)
+	}
+	none.Merge(all)
+	if actualValue, expectedValue := fmt.Sprintf("%v", none.Keys()), "[1 2 3 7 8 9 10]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+}
+
+func TestTreapMergeOverlapping(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Expected panic when merging overlapping trees")
+		}
+	}()
+	left, right := newTree(), newTree()
+	left.Put(1, 1)
+	left.Put(5, 5)
+	right.Put(5, 5)
+	left.Merge(right)
+}
+
+func TestTreapDeterministic(t *testing.T) {
+	build := func() string {
+		tree := NewWithSource(utils.IntComparator, rand.NewSource(42))
+		for i := 0; i < 20; i++ {
+			tree.Put(i, i)
+		}
+		return tree.String()
+	}
+	if actualValue, expectedValue := build(), build(); actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+}
+
+func TestTreapRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	tree := newTree()
+	expected := make(map[int]int)
+	for i := 0; i < 2000; i++ {
+		key := r.Intn(200)
+		if r.Intn(3) == 0 {
+			tree.Remove(key)
+			delete(expected, key)
+		} else {
+			tree.Put(key, i)
+			expected[key] = i
+		}
+	}
+	assertInvariants(t, tree)
+	if actualValue := tree.Size(); actualValue != len(expected) {
+		t.Errorf("Got %v expected %v", actualValue, len(expected))
+	}
+	for key, value := range expected {
+		if actualValue, found := tree.Get(key); actualValue != value || !found {
+			t.Errorf("Got %v expected %v", actualValue, value)
+		}
+	}
+	for i := 0; i < 50; i++ {
+		key := r.Intn(200)
+		left, right := tree.Split(key)
+		assertInvariants(t, left)
+		assertInvariants(t, right)
+		if last := left.Right(); last != nil && last.Key.(int) >= key {
+			t.Errorf("Got %v expected a key smaller than %v", last.Key, key)
+		}
+		if first := right.Left(); first != nil && first.Key.(int) < key {
+			t.Errorf("Got %v expected a key not smaller than %v", first.Key, key)
+		}
+		left.Merge(right)
+		tree = left
+	}
+	assertInvariants(t, tree)
+	if actualValue := tree.Size(); actualValue != len(expected) {
+		t.Errorf("Got %v expected %v", actualValue, len(expected))
+	}
+}
+
+func TestTreapIteratorNextOnEmpty(t *testing.T) {
+	tree := newTree()
+	it := tree.Iterator()
+	for it.Next() {
+		t.Errorf("Shouldn't iterate on empty tree")
+	}
+}
+
+func TestTreapIteratorPrevOnEmpty(t *testing.T) {
+	tree := newTree()
+	it := tree.Iterator()
+	for it.Prev() {
+		t.Errorf("Shouldn't iterate on empty tree")
+	}
+}
+
+func TestTreapIterator(t *testing.T) {
+	tree := newTree()
+	for _, key := range []int{13, 8, 17, 1, 11, 15, 25, 6, 22, 27} {
+		tree.Put(key, fmt.Sprintf("%d", key))
+	}
+	expected := []int{1, 6, 8, 11, 13, 15, 17, 22, 25, 27}
+
+	it := tree.Iterator()
+	count := 0
+	for it.Next() {
+		if actualValue, expectedValue := it.Key(), expected[count]; actualValue != expectedValue {
+			t.Errorf("Got %v expected %v", actualValue, expectedValue)
+		}
+		count++
+	}
+	if actualValue, expectedValue := count, tree.Size(); actualValue != expectedValue {
+		t.Errorf("Size different. Got %v expected %v", actualValue, expectedValue)
+	}
+
+	for it.Prev() {
+		count--
+		if actualValue, expectedValue := it.Key(), expected[count]; actualValue != expectedValue {
+			t.Errorf("Got %v expected %v", actualValue, expectedValue)
+		}
+	}
+	if actualValue, expectedValue := count, 0; actualValue != expectedValue {
+		t.Errorf("Size different. Got %v expected %v", actualValue, expectedValue)
+	}
+
+	if actualValue := it.Last(); actualValue != true || it.Key() != 27 {
+		t.Errorf("Got %v expected %v", it.Key(), 27)
+	}
+	if actualValue := it.First(); actualValue != true || it.Key() != 1 {
+		t.Errorf("Got %v expected %v", it.Key(), 1)
+	}
+}
+
+func TestTreapIteratorRemove(t *testing.T) {
+	tree := NewWithIntComparator()
+	for _, i := range rand.Perm(100) {
+		tree.Put(i, i)
+	}
+	it := tree.Iterator()
+	for it.Next() {
+		if it.Value().(int)%2 == 0 {
+			it.Remove()
+		}
+	}
+	for it.Prev() {
+		if it.Value().(int)%3 == 0 {
+			it.Remove()
+		}
+	}
+	keys := []interface{}{}
+	for i := 0; i < 100; i++ {
+		if i%2 != 0 && i%3 != 0 {
+			keys = append(keys, i)
+		}
+	}
+	if actualValue, expectedValue := fmt.Sprint(tree.Keys()), fmt.Sprint(keys); actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+
+	it.First()
+	it.Remove()
+	if it.Prev() {
+		t.Errorf("Shouldn't iterate before the beginning")
+	}
+	if !it.Next() || it.Key() != keys[1] {
+		t.Errorf("Got %v expected %v", it.Key(), keys[1])
+	}
+	it.Last()
+	it.Remove()
+	if it.Next() {
+		t.Errorf("Shouldn't iterate past the end")
+	}
+	if !it.Prev() || it.Key() != keys[len(keys)-2] {
+		t.Errorf("Got %v expected %v", it.Key(), keys[len(keys)-2])
+	}
+	if actualValue, expectedValue := tree.Size(), len(keys)-2; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+}
+
+// expectPanic checks that f panics with the expected error.
+func expectPanic(t *testing.T, expected error, f func()) {
+	t.Helper()
+	defer func() {
+		if r := recover(); r != expected {
+			t.Errorf("Got %v expected %v", r, expected)
+		}
+	}()
+	f()
+}
+
+func TestTreapIteratorConcurrentModification(t *testing.T) {
+	tree := NewWithIntComparator()
+	tree.Put(1, 1)
+	tree.Put(2, 2)
+	it := tree.Iterator()
+	it.Next()
+	tree.Remove(2)
+	expectPanic(t, containers.ErrConcurrentModification, func() { it.Next() })
+	expectPanic(t, containers.ErrConcurrentModification, func() { it.Prev() })
+	expectPanic(t, containers.ErrConcurrentModification, func() { it.Remove() })
+
+	// updating a value is not a structural modification
+	it.Begin()
+	it.Next()
+	tree.Put(1, 3)
+	if it.Next() {
+		t.Errorf("Shouldn't iterate past the end")
+	}
+
+	it.Begin()
+	tree.Put(2, 2)
+	if !it.Next() || it.Key() != 1 {
+		t.Errorf("Got %v expected %v", it.Key(), 1)
+	}
+	it.Remove()
+	expectPanic(t, containers.ErrIteratorNotOnElement, func() { it.Remove() })
+	it.End()
+	expectPanic(t, containers.ErrIteratorNotOnElement, func() { it.Remove() })
+}
+
+func TestTreapStats(t *testing.T) {
+	tree := NewWithSource(utils.IntComparator, rand.NewSource(1))
+	if actualValue, expectedValue := fmt.Sprintf("%+v", tree.Stats()), "{Height:0 Nodes:0 AverageDepth:0 Rotations:0 Splits:0 Merges:0 Comparisons:0}"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	for i := 1; i <= 7; i++ {
+		tree.Put(i, i)
+	}
+	//
+	//  Treap
+	//  │       ┌── 7
+	//  │   ┌── 6
+	//  │   │   │       ┌── 5
+	//  │   │   │   ┌── 4
+	//  │   │   └── 3
+	//  └── 2
+	//      └── 1
+	//
+	stats := tree.Stats()
+	if actualValue, expectedValue := fmt.Sprint(stats.Height, stats.Nodes, stats.AverageDepth), fmt.Sprint(5, 7, 13.0/7); actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	// 2 and 6 are put above existing nodes
+	if actualValue, expectedValue := fmt.Sprint(stats.Splits, stats.Merges), "2 0"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+
+	tree.Remove(7)
+	tree.Remove(6)
+	stats = tree.Stats()
+	if actualValue, expectedValue := fmt.Sprint(stats.Height, stats.Nodes, stats.Splits, stats.Merges), "4 5 2 0"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	tree.Remove(2)
+	if actualValue, expectedValue := tree.Stats().Merges, 1; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+
+	left, right := tree.Split(4)
+	if actualValue, expectedValue := tree.Stats().Splits, 3; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	left.Merge(right)
+	if actualValue, expectedValue := fmt.Sprint(left.Stats().Nodes, left.Stats().Merges), "4 1"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+}
+
+func TestTreapSerialization(t *testing.T) {
+	tree := NewWithStringComparator()
+	tree.Put("c", "3")
+	tree.Put("b", "2")
+	tree.Put("a", "1")
+
+	var err error
+	assert := func() {
+		if actualValue, expectedValue := tree.Size(), 3; actualValue != expectedValue {
+			t.Errorf("Got %v expected %v", actualValue, expectedValue)
+		}
+		if actualValue := tree.Keys(); actualValue[0].(string) != "a" || actualValue[1].(string) != "b" || actualValue[2].(string) != "c" {
+			t.Errorf("Got %v expected %v", actualValue, "[a,b,c]")
+		}
+		if actualValue := tree.Values(); actualValue[0].(string) != "1" || actualValue[1].(string) != "2" || actualValue[2].(string) != "3" {
+			t.Errorf("Got %v expected %v", actualValue, "[1,2,3]")
+		}
+		if err != nil {
+			t.Errorf("Got error %v", err)
+		}
+	}
+
+	assert()
+
+	json, err := tree.ToJSON()
+	assert()
+
+	err = tree.FromJSON(json)
+	assert()
+}
+
+func benchmarkGet(b *testing.B, tree *Tree, size int) {
+	for i := 0; i < b.N; i++ {
+		for n := 0; n < size; n++ {
+			tree.Get(n)
+		}
+	}
+}
+
+func benchmarkPut(b *testing.B, tree *Tree, size int) {
+	for i := 0; i < b.N; i++ {
+		for n := 0; n < size; n++ {
+			tree.Put(n, struct{}{})
+		}
+	}
+}
+
+func benchmarkRemove(b *testing.B, tree *Tree, size int) {
+	for i := 0; i < b.N; i++ {
+		for n := 0; n < size; n++ {
+			tree.Remove(n)
+		}
+	}
+}
+
+func benchmarkSplitMerge(b *testing.B, tree *Tree, size int) {
+	for i := 0; i < b.N; i++ {
+		for n := 0; n < size; n++ {
+			left, right := tree.Split(n)
+			left.Merge(right)
+			tree = left
+		}
+	}
+}
+
+func BenchmarkTreapGet10000(b *testing.B) {
+	b.StopTimer()
+	size := 10000
+	tree := NewWithIntComparator()
+	for n := 0; n < size; n++ {
+		tree.Put(n, struct{}{})
+	}
+	b.StartTimer()
+	benchmarkGet(b, tree, size)
+}
+
+func BenchmarkTreapPut10000(b *testing.B) {
+	b.StopTimer()
+	size := 10000
+	tree := NewWithIntComparator()
+	b.StartTimer()
+	benchmarkPut(b, tree, size)
+}
+
+func BenchmarkTreapRemove10000(b *testing.B) {
+	b.StopTimer()
+	size := 10000
+	tree := NewWithIntComparator()
+	for n := 0; n < size; n++ {
+		tree.Put(n, struct{}{})
+	}
+	b.StartTimer()
+	benchmarkRemove(b, tree, size)
+}
+
+func BenchmarkTreapSplitMerge10000(b *testing.B) {
+	b.StopTimer()
+	size := 10000
+	tree := NewWithIntComparator()
+	for n := 0; n < size; n++ {
+		tree.Put(n, struct{}{})
+	}
+	b.StartTimer()
+	benchmarkSplitMerge(b, tree, size)
+}
@@ -19,3 +19,15 @@ type Tree interface {
 	// Clear()
 	// Values() []interface{}
 }
+
+// Stats describes the shape of a tree and the work its operations performed since the tree was created.
+// Counters that do not apply to a kind of tree, e.g. splits in a binary search tree, stay zero.
+type Stats struct {
+	Height       int     // Number of levels, 0 for an empty tree
+	Nodes        int     // Number of nodes
+	AverageDepth float64 // Average depth of the elements, i.e. of the nodes holding them, the root being at depth 0
+	Rotations    int     // Rotations (or rotations of entries through the parent in a B-tree) done to rebalance the tree
+	Splits       int     // Nodes (subtrees in a treap) split in two
+	Merges       int     // Nodes (subtrees in a treap) merged into one
+	Comparisons  int     // Calls of the comparator
+}
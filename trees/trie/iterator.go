@@ -0,0 +1,231 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package trie
+
+import "github.com/dairongpeng/gds/containers"
+
+func assertIteratorImplementation() {
+	var _ containers.ReverseIteratorWithKey = (*Iterator)(nil)
+	var _ containers.IteratorWithRemove = (*Iterator)(nil)
+}
+
+// Iterator holding the iterator's state
+type Iterator struct {
+	tree     *Tree
+	node     *Node
+	position position
+	modCount int
+	removed  bool // the current node was removed, node is its successor
+}
+
+type position byte
+
+const (
+	begin, between, end position = 0, 1, 2
+)
+
+// Iterator returns a stateful iterator whose elements are key/value pairs in lexicographic order of the keys.
+func (tree *Tree) Iterator() *Iterator {
+	return &Iterator{tree: tree, node: nil, position: begin, modCount: tree.modCount}
+}
+
+// Next moves the iterator to the next element and returns true if there was a next element in the container.
+// If Next() returns true, then next element's key and value can be retrieved by Key() and Value().
+// If Next() was called for the first time, then it will point the iterator to the first element if it exists.
+// Modifies the state of the iterator.
+func (iterator *Iterator) Next() bool {
+	iterator.checkModification()
+	if iterator.removed {
+		iterator.removed = false
+		if iterator.node == nil {
+			iterator.position = end
+			return false
+		}
+		return true
+	}
+	switch iterator.position {
+	case begin:
+		iterator.position = between
+		iterator.node = first(iterator.tree.root)
+	case between:
+		iterator.node = next(iterator.node)
+	}
+
+	if iterator.node == nil {
+		iterator.position = end
+		return false
+	}
+	return true
+}
+
+// Prev moves the iterator to the previous element and returns true if there was a previous element in the container.
+// If Prev() returns true, then previous element's key and value can be retrieved by Key() and Value().
+// Modifies the state of the iterator.
+func (iterator *Iterator) Prev() bool {
+	iterator.checkModification()
+	if iterator.removed {
+		iterator.removed = false
+		if iterator.node != nil {
+			iterator.node = prev(iterator.node)
+		} else {
+			iterator.node = last(iterator.tree.root)
+		}
+		if iterator.node == nil {
+			iterator.position = begin
+			return false
+		}
+		return true
+	}
+	switch iterator.position {
+	case end:
+		iterator.position = between
+		iterator.node = last(iterator.tree.root)
+	case between:
+		iterator.node = prev(iterator.node)
+	}
+
+	if iterator.node == nil {
+		iterator.position = begin
+		return false
+	}
+	return true
+}
+
+// Value returns the current element's value.
+// Does not modify the state of the iterator.
+func (iterator *Iterator) Value() interface{} {
+	if iterator.node == nil {
+		return nil
+	}
+	return iterator.node.value
+}
+
+// Key returns the current element's key, which is rebuilt from the path to the root.
+// Does not modify the state of the iterator.
+func (iterator *Iterator) Key() interface{} {
+	if iterator.node == nil {
+		return nil
+	}
+	return iterator.tree.key(iterator.node)
+}
+
+// Begin resets the iterator to its initial state (one-before-first)
+// Call Next() to fetch the first element if any.
+func (iterator *Iterator) Begin() {
+	iterator.node = nil
+	iterator.position = begin
+	iterator.removed = false
+	iterator.modCount = iterator.tree.modCount
+}
+
+// End moves the iterator past the last element (one-past-the-end).
+// Call Prev() to fetch the last element if any.
+func (iterator *Iterator) End() {
+	iterator.node = nil
+	iterator.position = end
+	iterator.removed = false
+	iterator.modCount = iterator.tree.modCount
+}
+
+// First moves the iterator to the first element and returns true if there was a first element in the container.
+// If First() returns true, then first element's key and value can be retrieved by Key() and Value().
+// Modifies the state of the iterator
+func (iterator *Iterator) First() bool {
+	iterator.Begin()
+	return iterator.Next()
+}
+
+// Last moves the iterator to the last element and returns true if there was a last element in the container.
+// If Last() returns true, then last element's key and value can be retrieved by Key() and Value().
+// Modifies the state of the iterator.
+func (iterator *Iterator) Last() bool {
+	iterator.End()
+	return iterator.Prev()
+}
+
+// first returns the smallest key in the subtree, i.e. the first terminal node in pre-order.
+// Every leaf other than an empty root is terminal, so following the first children always ends at a key.
+func first(current *Node) *Node {
+	for !current.terminal {
+		if len(current.children) == 0 {
+			return nil
+		}
+		current = current.children[0]
+	}
+	return current
+}
+
+// last returns the largest key in the subtree, i.e. its right-most leaf or the subtree's root if it has no children.
+func last(current *Node) *Node {
+	for len(current.children) > 0 {
+		current = current.children[len(current.children)-1]
+	}
+	if !current.terminal {
+		return nil
+	}
+	return current
+}
+
+// next returns the terminal node following the node in pre-order, or nil if there is none.
+func next(current *Node) *Node {
+	if len(current.children) > 0 {
+		return first(current.children[0])
+	}
+	for ; current.parent != nil; current = current.parent {
+		if sibling := current.sibling(1); sibling != nil {
+			return first(sibling)
+		}
+	}
+	return nil
+}
+
+// prev returns the terminal node preceding the node in pre-order, or nil if there is none.
+func prev(current *Node) *Node {
+	for ; current.parent != nil; current = current.parent {
+		if sibling := current.sibling(-1); sibling != nil {
+			return last(sibling)
+		}
+		if current.parent.terminal {
+			return current.parent
+		}
+	}
+	return nil
+}
+
+// sibling returns the child of the node's parent at the offset from the node, or nil if there is none.
+func (n *Node) sibling(offset int) *Node {
+	index, _ := n.parent.find(n.label)
+	index += offset
+	if index < 0 || index >= len(n.parent.children) {
+		return nil
+	}
+	return n.parent.children[index]
+}
+
+// Remove removes the current node from the tree.
+// Next() moves the iterator to the node after the removed one and Prev() to the node before it.
+// Panics with containers.ErrIteratorNotOnElement if the iterator is not on a node.
+func (iterator *Iterator) Remove() {
+	iterator.checkModification()
+	if iterator.removed || iterator.position != between {
+		panic(containers.ErrIteratorNotOnElement)
+	}
+	// pruning only removes nodes that lead to no key, the successor and its ancestors stay in place
+	next := next(iterator.node)
+	iterator.tree.Remove(iterator.tree.key(iterator.node))
+	iterator.node = next
+	iterator.modCount = iterator.tree.modCount
+	iterator.removed = true
+}
+
+// checkModification panics if the tree was structurally modified other than through the iterator.
+func (iterator *Iterator) checkModification() {
+	if iterator.modCount != iterator.tree.modCount {
+		if iterator.position != begin || iterator.removed {
+			panic(containers.ErrConcurrentModification)
+		}
+		iterator.modCount = iterator.tree.modCount
+	}
+}
@@ -0,0 +1,34 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package trie
+
+import "github.com/dairongpeng/gds/trees"
+
+// Stats returns the shape of the trie, where the depth of a key is its number of labels.
+// The trie neither rotates, splits nor merges nodes and does not use a comparator, so these counters stay zero.
+// The shape is measured by walking the trie in O(n).
+func (tree *Tree) Stats() trees.Stats {
+	var s trees.Stats
+	if tree.size == 0 {
+		return s
+	}
+	depths := 0
+	var walk func(node *Node, depth int)
+	walk = func(node *Node, depth int) {
+		s.Nodes++
+		if node.terminal {
+			depths += depth
+		}
+		if depth+1 > s.Height {
+			s.Height = depth + 1
+		}
+		for _, child := range node.children {
+			walk(child, depth+1)
+		}
+	}
+	walk(tree.root, 0)
+	s.AverageDepth = float64(depths) / float64(tree.size)
+	return s
+}
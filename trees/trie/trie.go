@@ -0,0 +1,314 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package trie implements a trie (prefix tree) that maps string keys to values.
+//
+// Every node represents a prefix of the keys below it, with one child per following character, so that all keys
+// sharing a prefix are found in one subtree. Characters are either the runes of the keys (the default)
+// or their bytes, which should be used for keys that are not valid UTF-8.
+// Children are kept sorted, so iteration visits the keys in lexicographic order.
+//
+// Structure is not thread safe.
+//
+// Reference: https://en.wikipedia.org/wiki/Trie
+package trie
+
+import (
+	"fmt"
+	"github.com/dairongpeng/gds/maps"
+	"github.com/dairongpeng/gds/trees"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+func assertTreeImplementation() {
+	var _ trees.Tree = (*Tree)(nil)
+	var _ maps.Map = (*Tree)(nil)
+}
+
+// Mode determines the characters keys are split into
+type Mode byte
+
+const (
+	// Runes splits keys into runes, invalid UTF-8 is replaced by utf8.RuneError.
+	Runes Mode = iota
+	// Bytes splits keys into bytes.
+	Bytes
+)
+
+// Tree holds elements of the trie
+// Tree 前缀树，每个节点代表一个前缀，子节点按照字符排序
+type Tree struct {
+	root     *Node
+	size     int
+	mode     Mode
+	modCount int // number of inserted and removed keys, checked by iterators
+}
+
+// Node is a single element within the trie, it represents the prefix spelled by the labels from the root to it.
+// Node 前缀树的节点，代表从根节点到它的路径上的字符组成的前缀
+type Node struct {
+	label    rune    // character leading from the parent to this node, a byte value in Bytes mode
+	parent   *Node   // nil for the root
+	children []*Node // sorted by label
+	value    interface{}
+	terminal bool // a key ends at this node
+}
+
+// New instantiates a trie that splits keys into runes.
+func New() *Tree {
+	return NewWithMode(Runes)
+}
+
+// NewWithMode instantiates a trie that splits keys according to the mode.
+func NewWithMode(mode Mode) *Tree {
+	return &Tree{root: &Node{}, mode: mode}
+}
+
+// Put inserts the key-value pair into the trie.
+// Key should be a string, otherwise method panics.
+func (tree *Tree) Put(key interface{}, value interface{}) {
+	current := tree.root
+	tree.each(key.(string), func(label rune, width int) bool {
+		index, found := current.find(label)
+		if !found {
+			child := &Node{label: label, parent: current}
+			current.children = append(current.children, nil)
+			copy(current.children[index+1:], current.children[index:])
+			current.children[index] = child
+		}
+		current = current.children[index]
+		return true
+	})
+	if !current.terminal {
+		current.terminal = true
+		tree.size++
+		tree.modCount++
+	}
+	current.value = value
+}
+
+// Get searches the element in the trie by key and returns its value or nil if key is not found in trie.
+// Second return parameter is true if key was found, otherwise false.
+// Key should be a string, otherwise method panics.
+func (tree *Tree) Get(key interface{}) (value interface{}, found bool) {
+	if node := tree.lookup(key.(string)); node != nil && node.terminal {
+		return node.value, true
+	}
+	return nil, false
+}
+
+// Remove removes the element from the trie by key and prunes the nodes that no longer lead to any key.
+// Key should be a string, otherwise method panics.
+func (tree *Tree) Remove(key interface{}) {
+	current := tree.lookup(key.(string))
+	if current == nil || !current.terminal {
+		return
+	}
+	current.terminal = false
+	current.value = nil
+	tree.size--
+	tree.modCount++
+	for current.parent != nil && !current.terminal && len(current.children) == 0 {
+		parent := current.parent
+		index, _ := parent.find(current.label)
+		parent.children = append(parent.children[:index], parent.children[index+1:]...)
+		current.parent = nil
+		current = parent
+	}
+}
+
+// Root returns the root node, which represents the empty prefix.
+// Nodes must not be used once their keys were removed, since removals prune the nodes that lead to no key.
+func (tree *Tree) Root() *Node {
+	return tree.root
+}
+
+// KeysWithPrefix returns all keys starting with the prefix in lexicographic order.
+// KeysWithPrefix 返回所有以prefix开头的key
+func (tree *Tree) KeysWithPrefix(prefix string) []string {
+	keys := []string{}
+	start := tree.lookup(prefix)
+	if start == nil {
+		return keys
+	}
+	buffer := []byte(prefix)
+	var collect func(current *Node)
+	collect = func(current *Node) {
+		if current.terminal {
+			keys = append(keys, string(buffer))
+		}
+		for _, child := range current.children {
+			length := len(buffer)
+			buffer = tree.appendLabel(buffer, child.label)
+			collect(child)
+			buffer = buffer[:length]
+		}
+	}
+	collect(start)
+	return keys
+}
+
+// LongestPrefixOf returns the longest key in the trie that is a prefix of s and its value.
+// Third return parameter is false if no key is a prefix of s.
+// LongestPrefixOf 返回trie中是s前缀的最长的key
+func (tree *Tree) LongestPrefixOf(s string) (prefix string, value interface{}, found bool) {
+	current := tree.root
+	if current.terminal {
+		prefix, value, found = "", current.value, true
+	}
+	length := 0
+	tree.each(s, func(label rune, width int) bool {
+		index, ok := current.find(label)
+		if !ok {
+			return false
+		}
+		current = current.children[index]
+		length += width
+		if current.terminal {
+			prefix, value, found = s[:length], current.value, true
+		}
+		return true
+	})
+	return prefix, value, found
+}
+
+// Empty returns true if trie does not contain any keys
+func (tree *Tree) Empty() bool {
+	return tree.size == 0
+}
+
+// Size returns number of keys in the trie.
+func (tree *Tree) Size() int {
+	return tree.size
+}
+
+// Keys returns all keys in lexicographic order.
+func (tree *Tree) Keys() []interface{} {
+	keys := make([]interface{}, tree.size)
+	it := tree.Iterator()
+	for i := 0; it.Next(); i++ {
+		keys[i] = it.Key()
+	}
+	return keys
+}
+
+// Values returns all values in lexicographic order of their keys.
+func (tree *Tree) Values() []interface{} {
+	values := make([]interface{}, tree.size)
+	it := tree.Iterator()
+	for i := 0; it.Next(); i++ {
+		values[i] = it.Value()
+	}
+	return values
+}
+
+// Clear removes all keys from the trie.
+func (tree *Tree) Clear() {
+	tree.root = &Node{}
+	tree.size = 0
+	tree.modCount++
+}
+
+// String returns a string representation of container
+func (tree *Tree) String() string {
+	str := "Trie\nmap["
+	it := tree.Iterator()
+	for it.Next() {
+		str += fmt.Sprintf("%v:%v ", it.Key(), it.Value())
+	}
+	return strings.TrimRight(str, " ") + "]"
+}
+
+// lookup returns the node representing the key, which may not be terminal, or nil if there is none.
+func (tree *Tree) lookup(key string) *Node {
+	current := tree.root
+	tree.each(key, func(label rune, width int) bool {
+		index, found := current.find(label)
+		if !found {
+			current = nil
+			return false
+		}
+		current = current.children[index]
+		return true
+	})
+	return current
+}
+
+// each calls f with every character of the key and its width in bytes until f returns false.
+func (tree *Tree) each(key string, f func(label rune, width int) bool) {
+	for i := 0; i < len(key); {
+		label, width := rune(key[i]), 1
+		if tree.mode == Runes {
+			label, width = utf8.DecodeRuneInString(key[i:])
+		}
+		if !f(label, width) {
+			return
+		}
+		i += width
+	}
+}
+
+// key reconstructs the key of the node from the labels on the path to the root.
+func (tree *Tree) key(current *Node) string {
+	labels := []rune{}
+	for ; current.parent != nil; current = current.parent {
+		labels = append(labels, current.label)
+	}
+	buffer := make([]byte, 0, len(labels))
+	for i := len(labels) - 1; i >= 0; i-- {
+		buffer = tree.appendLabel(buffer, labels[i])
+	}
+	return string(buffer)
+}
+
+func (tree *Tree) appendLabel(buffer []byte, label rune) []byte {
+	if tree.mode == Bytes {
+		return append(buffer, byte(label))
+	}
+	var encoded [utf8.UTFMax]byte
+	return append(buffer, encoded[:utf8.EncodeRune(encoded[:], label)]...)
+}
+
+// Label returns the character leading from the parent to the node, a byte value in Bytes mode.
+func (n *Node) Label() rune {
+	return n.label
+}
+
+// Parent returns the parent of the node, or nil for the root.
+func (n *Node) Parent() *Node {
+	return n.parent
+}
+
+// Child returns the child of the node with the label, or nil if there is none.
+func (n *Node) Child(label rune) *Node {
+	if index, found := n.find(label); found {
+		return n.children[index]
+	}
+	return nil
+}
+
+// Children returns the children of the node sorted by label.
+func (n *Node) Children() []*Node {
+	return append([]*Node(nil), n.children...)
+}
+
+// Terminal returns true if a key ends at the node.
+func (n *Node) Terminal() bool {
+	return n.terminal
+}
+
+// Value returns the value of the key ending at the node, or nil if the node is not terminal.
+func (n *Node) Value() interface{} {
+	return n.value
+}
+
+// find returns the index of the child with the label, or the index it should be inserted at if there is none.
+func (n *Node) find(label rune) (index int, found bool) {
+	index = sort.Search(len(n.children), func(i int) bool {
+		return n.children[i].label >= label
+	})
+	return index, index < len(n.children) && n.children[index].label == label
+}
@@ -0,0 +1,480 @@
+// Copyright (c) 2015, Emir Pasic. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package trie
+
+import (
+	"fmt"
+	"github.com/dairongpeng/gds/containers"
+	"github.com/dairongpeng/gds/maps/treemap"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestTriePutGet(t *testing.T) {
+	tree := New()
+	tree.Put("tea", 1)
+	tree.Put("ten", 2)
+	tree.Put("to", 3)
+	tree.Put("inn", 4)
+	tree.Put("in", 5)
+	tree.Put("i", 6)
+	tree.Put("ten", 7) // overwrite
+
+	if actualValue := tree.Size(); actualValue != 6 {
+		t.Errorf("Got %v expected %v", actualValue, 6)
+	}
+	if actualValue, expectedValue := fmt.Sprintf("%v", tree.Keys()), "[i in inn tea ten to]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue, expectedValue := fmt.Sprintf("%v", tree.Values()), "[6 5 4 1 7 3]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+
+	tests := [][]interface{}{
+		{"tea", 1, true},
+		{"ten", 7, true},
+		{"i", 6, true},
+		{"te", nil, false},
+		{"t", nil, false},
+		{"tease", nil, false},
+		{"", nil, false},
+	}
+	for _, test := range tests {
+		actualValue, actualFound := tree.Get(test[0])
+		if actualValue != test[1] || actualFound != test[2] {
+			t.Errorf("Got %v,%v expected %v,%v for %v", actualValue, actualFound, test[1], test[2], test[0])
+		}
+	}
+
+	tree.Put("", 0)
+	if actualValue, found := tree.Get(""); actualValue != 0 || !found {
+		t.Errorf("Got %v expected %v", actualValue, 0)
+	}
+	if actualValue := tree.Keys()[0]; actualValue != "" {
+		t.Errorf("Got %q expected %q", actualValue, "")
+	}
+}
+
+func TestTrieRemove(t *testing.T) {
+	tree := New()
+	for i, key := range []string{"tea", "ten", "to", "inn", "in", "i"} {
+		tree.Put(key, i)
+	}
+
+	tree.Remove("in")
+	tree.Remove("te")  // not a key
+	tree.Remove("tax") // not a prefix
+	tree.Remove("to")
+	tree.Remove("to")
+
+	if actualValue, expectedValue := fmt.Sprintf("%v", tree.Keys()), "[i inn tea ten]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue := tree.Size(); actualValue != 4 {
+		t.Errorf("Got %v expected %v", actualValue, 4)
+	}
+	if actualValue := len(tree.root.children[1].children); actualValue != 1 {
+		t.Errorf("Got %v expected %v children below t", actualValue, 1)
+	}
+
+	for _, key := range []string{"i", "inn", "tea", "ten"} {
+		tree.Remove(key)
+	}
+	if actualValue := tree.Empty(); actualValue != true {
+		t.Errorf("Got %v expected %v", actualValue, true)
+	}
+	if actualValue := len(tree.root.children); actualValue != 0 {
+		t.Errorf("Got %v expected %v children below root", actualValue, 0)
+	}
+}
+
+func TestTrieKeysWithPrefix(t *testing.T) {
+	tree := New()
+	for _, key := range []string{"car", "cart", "carbon", "cat", "dog", "ca", "日本", "日本語"} {
+		tree.Put(key, true)
+	}
+	tests := []struct {
+		prefix   string
+		expected string
+	}{
+		{"car", "[car carbon cart]"},
+		{"ca", "[ca car carbon cart cat]"},
+		{"cb", "[]"},
+		{"do", "[dog]"},
+		{"dogs", "[]"},
+		{"日", "[日本 日本語]"},
+		{"", "[ca car carbon cart cat dog 日本 日本語]"},
+	}
+	for _, test := range tests {
+		if actualValue := fmt.Sprintf("%v", tree.KeysWithPrefix(test.prefix)); actualValue != test.expected {
+			t.Errorf("Got %v expected %v for %q", actualValue, test.expected, test.prefix)
+		}
+	}
+}
+
+func TestTrieNodes(t *testing.T) {
+	tree := New()
+	tree.Put("ca", 1)
+	tree.Put("cat", 2)
+	tree.Put("dog", 3)
+
+	root := tree.Root()
+	if actualValue := root.Parent(); actualValue != nil {
+		t.Errorf("Got %v expected %v", actualValue, nil)
+	}
+	labels := []rune{}
+	for _, child := range root.Children() {
+		labels = append(labels, child.Label())
+	}
+	if actualValue, expectedValue := string(labels), "cd"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	ca := root.Child('c').Child('a')
+	if actualValue, expectedValue := ca.Terminal(), true; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue, expectedValue := ca.Value(), 1; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue, expectedValue := ca.Parent().Terminal(), false; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue := ca.Child('r'); actualValue != nil {
+		t.Errorf("Got %v expected %v", actualValue, nil)
+	}
+	if actualValue, expectedValue := ca.Child('t').Value(), 2; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+}
+
+func TestTrieLongestPrefixOf(t *testing.T) {
+	tree := New()
+	tree.Put("/", "root")
+	tree.Put("/api", "api")
+	tree.Put("/api/v1", "v1")
+	tree.Put("/static", "static")
+
+	tests := [][]interface{}{
+		{"/api/v1/users", "/api/v1", "v1", true},
+		{"/api/v2", "/api", "api", true},
+		{"/apiary", "/api", "api", true},
+		{"/stat", "/", "root", true},
+		{"api", "", nil, false},
+		{"", "", nil, false},
+	}
+	for _, test := range tests {
+		prefix, value, found := tree.LongestPrefixOf(test[0].(string))
+		if prefix != test[1] || value != test[2] || found != test[3] {
+			t.Errorf("Got %v,%v,%v expected %v,%v,%v for %v", prefix, value, found, test[1], test[2], test[3], test[0])
+		}
+	}
+
+	tree.Put("", "empty")
+	if prefix, value, found := tree.LongestPrefixOf("api"); prefix != "" || value != "empty" || !found {
+		t.Errorf("Got %v,%v,%v expected %v,%v,%v", prefix, value, found, "", "empty", true)
+	}
+}
+
+func TestTrieBytesMode(t *testing.T) {
+	tree := NewWithMode(Bytes)
+	keys := []string{"\xff\xfe", "\xff", "a\x00b", "a", "é"}
+	for i, key := range keys {
+		tree.Put(key, i)
+	}
+	for i, key := range keys {
+		if actualValue, found := tree.Get(key); actualValue != i || !found {
+			t.Errorf("Got %v expected %v for %q", actualValue, i, key)
+		}
+	}
+	sort.Strings(keys)
+	if actualValue, expectedValue := fmt.Sprintf("%q", tree.Keys()), fmt.Sprintf("%q", keys); actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if prefix, _, found := tree.LongestPrefixOf("\xff\xfe\xfd"); prefix != "\xff\xfe" || !found {
+		t.Errorf("Got %q expected %q", prefix, "\xff\xfe")
+	}
+	if actualValue := tree.KeysWithPrefix("\xc3"); len(actualValue) != 1 || actualValue[0] != "é" {
+		t.Errorf("Got %q expected %q", actualValue, []string{"é"})
+	}
+}
+
+func TestTrieRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	randomKey := func() string {
+		alphabet := []rune("abcü")
+		key := make([]rune, r.Intn(5))
+		for i := range key {
+			key[i] = alphabet[r.Intn(len(alphabet))]
+		}
+		return string(key)
+	}
+	for _, mode := range []Mode{Runes, Bytes} {
+		tree := NewWithMode(mode)
+		expected := treemap.NewWithStringComparator()
+		for i := 0; i < 2000; i++ {
+			key := randomKey()
+			if r.Intn(3) == 0 {
+				tree.Remove(key)
+				expected.Remove(key)
+			} else {
+				tree.Put(key, i)
+				expected.Put(key, i)
+			}
+		}
+		if actualValue, expectedValue := fmt.Sprintf("%v", tree.Keys()), fmt.Sprintf("%v", expected.Keys()); actualValue != expectedValue {
+			t.Errorf("Got %v expected %v", actualValue, expectedValue)
+		}
+		if actualValue, expectedValue := fmt.Sprintf("%v", tree.Values()), fmt.Sprintf("%v", expected.Values()); actualValue != expectedValue {
+			t.Errorf("Got %v expected %v", actualValue, expectedValue)
+		}
+
+		// reverse iteration visits the same keys backwards
+		keys := expected.Keys()
+		it := tree.Iterator()
+		it.End()
+		for i := len(keys) - 1; it.Prev(); i-- {
+			if actualValue := it.Key(); actualValue != keys[i] {
+				t.Errorf("Got %v expected %v", actualValue, keys[i])
+			}
+		}
+	}
+}
+
+func TestTrieIteratorOnEmpty(t *testing.T) {
+	tree := New()
+	it := tree.Iterator()
+	for it.Next() {
+		t.Errorf("Shouldn't iterate on empty trie")
+	}
+	for it.Prev() {
+		t.Errorf("Shouldn't iterate on empty trie")
+	}
+}
+
+func TestTrieIterator(t *testing.T) {
+	tree := New()
+	for i, key := range []string{"b", "a", "ab", "abc", "c"} {
+		tree.Put(key, i)
+	}
+	expected := []string{"a", "ab", "abc", "b", "c"}
+
+	it := tree.Iterator()
+	count := 0
+	for it.Next() {
+		if actualValue, expectedValue := it.Key(), expected[count]; actualValue != expectedValue {
+			t.Errorf("Got %v expected %v", actualValue, expectedValue)
+		}
+		count++
+	}
+	if actualValue, expectedValue := count, tree.Size(); actualValue != expectedValue {
+		t.Errorf("Size different. Got %v expected %v", actualValue, expectedValue)
+	}
+
+	for it.Prev() {
+		count--
+		if actualValue, expectedValue := it.Key(), expected[count]; actualValue != expectedValue {
+			t.Errorf("Got %v expected %v", actualValue, expectedValue)
+		}
+	}
+	if actualValue, expectedValue := count, 0; actualValue != expectedValue {
+		t.Errorf("Size different. Got %v expected %v", actualValue, expectedValue)
+	}
+
+	if actualValue := it.Last(); actualValue != true || it.Key() != "c" {
+		t.Errorf("Got %v expected %v", it.Key(), "c")
+	}
+	if actualValue := it.First(); actualValue != true || it.Key() != "a" {
+		t.Errorf("Got %v expected %v", it.Key(), "a")
+	}
+}
+
+func TestTrieIteratorRemove(t *testing.T) {
+	tree := New()
+	for _, i := range rand.Perm(100) {
+		tree.Put(fmt.Sprintf("%02d", i), i)
+	}
+	it := tree.Iterator()
+	for it.Next() {
+		if it.Value().(int)%2 == 0 {
+			it.Remove()
+		}
+	}
+	for it.Prev() {
+		if it.Value().(int)%3 == 0 {
+			it.Remove()
+		}
+	}
+	keys := []interface{}{}
+	for i := 0; i < 100; i++ {
+		if i%2 != 0 && i%3 != 0 {
+			keys = append(keys, fmt.Sprintf("%02d", i))
+		}
+	}
+	if actualValue, expectedValue := fmt.Sprint(tree.Keys()), fmt.Sprint(keys); actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+
+	it.First()
+	it.Remove()
+	if it.Prev() {
+		t.Errorf("Shouldn't iterate before the beginning")
+	}
+	if !it.Next() || it.Key() != keys[1] {
+		t.Errorf("Got %v expected %v", it.Key(), keys[1])
+	}
+	it.Last()
+	it.Remove()
+	if it.Next() {
+		t.Errorf("Shouldn't iterate past the end")
+	}
+	if !it.Prev() || it.Key() != keys[len(keys)-2] {
+		t.Errorf("Got %v expected %v", it.Key(), keys[len(keys)-2])
+	}
+	if actualValue, expectedValue := tree.Size(), len(keys)-2; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+}
+
+// expectPanic checks that f panics with the expected error.
+func expectPanic(t *testing.T, expected error, f func()) {
+	t.Helper()
+	defer func() {
+		if r := recover(); r != expected {
+			t.Errorf("Got %v expected %v", r, expected)
+		}
+	}()
+	f()
+}
+
+func TestTrieIteratorConcurrentModification(t *testing.T) {
+	tree := New()
+	tree.Put("a", 1)
+	tree.Put("ab", 2)
+	it := tree.Iterator()
+	it.Next()
+	tree.Remove("ab")
+	expectPanic(t, containers.ErrConcurrentModification, func() { it.Next() })
+	expectPanic(t, containers.ErrConcurrentModification, func() { it.Prev() })
+	expectPanic(t, containers.ErrConcurrentModification, func() { it.Remove() })
+
+	// updating a value is not a structural modification
+	it.Begin()
+	it.Next()
+	tree.Put("a", 3)
+	if it.Next() {
+		t.Errorf("Shouldn't iterate past the end")
+	}
+
+	it.Begin()
+	tree.Put("ab", 2)
+	if !it.Next() || it.Key() != "a" {
+		t.Errorf("Got %v expected %v", it.Key(), "a")
+	}
+	it.Remove()
+	expectPanic(t, containers.ErrIteratorNotOnElement, func() { it.Remove() })
+	it.End()
+	expectPanic(t, containers.ErrIteratorNotOnElement, func() { it.Remove() })
+}
+
+func TestTrieSerialization(t *testing.T) {
+	tree := New()
+	tree.Put("b", "2")
+	tree.Put("a", "1")
+	tree.Put("ab", "3")
+
+	json, err := tree.ToJSON()
+	if err != nil {
+		t.Errorf("Got error %v", err)
+	}
+	if actualValue, expectedValue := string(json), `{"a":"1","ab":"3","b":"2"}`; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+
+	deserialized := New()
+	if err := deserialized.FromJSON(json); err != nil {
+		t.Errorf("Got error %v", err)
+	}
+	if actualValue, expectedValue := fmt.Sprintf("%v", deserialized.Keys()), "[a ab b]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	if actualValue, expectedValue := deserialized.String(), "Trie\nmap[a:1 ab:3 b:2]"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+}
+
+func TestTrieStats(t *testing.T) {
+	tree := New()
+	if actualValue, expectedValue := fmt.Sprintf("%+v", tree.Stats()), "{Height:0 Nodes:0 AverageDepth:0 Rotations:0 Splits:0 Merges:0 Comparisons:0}"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	tree.Put("a", 1)
+	tree.Put("ab", 2)
+	tree.Put("abc", 3)
+	tree.Put("b", 4)
+	if actualValue, expectedValue := fmt.Sprintf("%+v", tree.Stats()), "{Height:4 Nodes:5 AverageDepth:1.75 Rotations:0 Splits:0 Merges:0 Comparisons:0}"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+	tree.Remove("abc")
+	if actualValue, expectedValue := fmt.Sprintf("%+v", tree.Stats()), "{Height:3 Nodes:4 AverageDepth:1.3333333333333333 Rotations:0 Splits:0 Merges:0 Comparisons:0}"; actualValue != expectedValue {
+		t.Errorf("Got %v expected %v", actualValue, expectedValue)
+	}
+}
+
+func benchmarkKeysWithPrefix(b *testing.B, tree *Tree, prefixes []string) {
+	for i := 0; i < b.N; i++ {
+		for _, prefix := range prefixes {
+			tree.KeysWithPrefix(prefix)
+		}
+	}
+}
+
+func prefixData(size int) (keys []string, prefixes []string) {
+	for n := 0; n < size; n++ {
+		keys = append(keys, fmt.Sprintf("key/%d/%d", n%100, n))
+	}
+	for n := 0; n < 100; n++ {
+		prefixes = append(prefixes, fmt.Sprintf("key/%d/", n))
+	}
+	return keys, prefixes
+}
+
+func BenchmarkTrieKeysWithPrefix10000(b *testing.B) {
+	b.StopTimer()
+	keys, prefixes := prefixData(10000)
+	tree := New()
+	for _, key := range keys {
+		tree.Put(key, struct{}{})
+	}
+	b.StartTimer()
+	benchmarkKeysWithPrefix(b, tree, prefixes)
+}
+
+func BenchmarkTriePut10000(b *testing.B) {
+	b.StopTimer()
+	keys, _ := prefixData(10000)
+	tree := New()
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		for _, key := range keys {
+			tree.Put(key, struct{}{})
+		}
+	}
+}
+
+func BenchmarkTrieGet10000(b *testing.B) {
+	b.StopTimer()
+	keys, _ := prefixData(10000)
+	tree := New()
+	for _, key := range keys {
+		tree.Put(key, struct{}{})
+	}
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		for _, key := range keys {
+			tree.Get(key)
+		}
+	}
+}